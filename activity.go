@@ -0,0 +1,168 @@
+package evdev
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// activityTypes are the event types that indicate user activity. Other types
+// either accompany these, such as EV_SYN and EV_MSC, or are sent to devices.
+var activityTypes = map[EvType]bool{
+	EV_KEY: true,
+	EV_REL: true,
+	EV_ABS: true,
+	EV_SW:  true,
+}
+
+// Activity is reported by an ActivityNotifier.
+type Activity struct {
+	Time  time.Time // time of the latest activity
+	Types []EvType  // event types seen since the previous report, sorted
+}
+
+// ActivityNotifier coalesces the input activity of any number of devices
+// into rate limited notifications, e.g. for screen lockers and idle
+// monitors. To make sure such components can't observe what is typed, the
+// notifier only ever sees event types, never codes or values.
+//
+// The first activity after a quiet period is reported immediately. Further
+// activity is reported at most once per interval, with the time of the
+// latest event.
+type ActivityNotifier struct {
+	interval time.Duration
+	notify   func(Activity)
+
+	mu      sync.Mutex
+	last    time.Time // time of the last notification
+	pending map[EvType]bool
+	latest  time.Time
+	timer   *time.Timer
+	stopped bool
+
+	notifyMu sync.Mutex // serializes notify calls
+}
+
+// NewActivityNotifier creates an ActivityNotifier that calls notify at most
+// once per interval.
+func NewActivityNotifier(interval time.Duration, notify func(Activity)) *ActivityNotifier {
+	return &ActivityNotifier{
+		interval: interval,
+		notify:   notify,
+		pending:  map[EvType]bool{},
+	}
+}
+
+// Watch reports the activity of src, reading from it in a goroutine until
+// reading fails or the notifier is stopped.
+func (an *ActivityNotifier) Watch(src EventSource) {
+	go func() {
+		for {
+			events, err := src.Read()
+			if err != nil {
+				return
+			}
+
+			for i := range events {
+				if !an.Push(events[i].Type) {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// Push reports activity with an event of type t. Types that don't indicate
+// activity, such as EV_SYN, are ignored. It returns false once the notifier
+// has been stopped.
+func (an *ActivityNotifier) Push(t EvType) bool {
+	if !activityTypes[t] {
+		return true
+	}
+
+	now := time.Now()
+
+	an.mu.Lock()
+
+	if an.stopped {
+		an.mu.Unlock()
+		return false
+	}
+
+	an.pending[t] = true
+	an.latest = now
+
+	if an.timer != nil {
+		an.mu.Unlock()
+		return true
+	}
+
+	if wait := an.last.Add(an.interval).Sub(now); wait > 0 {
+		an.timer = time.AfterFunc(wait, an.flush)
+		an.mu.Unlock()
+		return true
+	}
+
+	a := an.take()
+	an.mu.Unlock()
+
+	an.deliver(a)
+
+	return true
+}
+
+// take returns the pending activity and resets it. an.mu must be held.
+func (an *ActivityNotifier) take() Activity {
+	a := Activity{
+		Time:  an.latest,
+		Types: make([]EvType, 0, len(an.pending)),
+	}
+
+	for t := range an.pending {
+		a.Types = append(a.Types, t)
+	}
+
+	sort.Slice(a.Types, func(i, j int) bool { return a.Types[i] < a.Types[j] })
+
+	an.pending = map[EvType]bool{}
+	an.last = time.Now()
+
+	return a
+}
+
+func (an *ActivityNotifier) flush() {
+	an.mu.Lock()
+
+	an.timer = nil
+
+	if an.stopped || len(an.pending) == 0 {
+		an.mu.Unlock()
+		return
+	}
+
+	a := an.take()
+	an.mu.Unlock()
+
+	an.deliver(a)
+}
+
+func (an *ActivityNotifier) deliver(a Activity) {
+	an.notifyMu.Lock()
+	defer an.notifyMu.Unlock()
+
+	an.notify(a)
+}
+
+// Stop stops the notifier. Pending activity is not reported, and goroutines
+// started by Watch end with the next event they read.
+func (an *ActivityNotifier) Stop() {
+	an.mu.Lock()
+	defer an.mu.Unlock()
+
+	an.stopped = true
+
+	if an.timer != nil {
+		an.timer.Stop()
+		an.timer = nil
+	}
+}
@@ -0,0 +1,73 @@
+package evdev
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestActivityNotifier(t *testing.T) {
+	ch := make(chan Activity, 10)
+	an := NewActivityNotifier(50*time.Millisecond, func(a Activity) {
+		ch <- a
+	})
+	defer an.Stop()
+
+	start := time.Now()
+
+	// the first activity is reported immediately
+	an.Push(EV_SYN)
+	an.Push(EV_KEY)
+
+	a := <-ch
+	if !reflect.DeepEqual(a.Types, []EvType{EV_KEY}) || a.Time.Before(start) {
+		t.Errorf("first activity = %+v", a)
+	}
+
+	// activity within the interval is coalesced
+	an.Push(EV_REL)
+	an.Push(EV_MSC)
+	an.Push(EV_ABS)
+
+	a = <-ch
+	if !reflect.DeepEqual(a.Types, []EvType{EV_REL, EV_ABS}) {
+		t.Errorf("coalesced activity types = %v, want %v", a.Types, []EvType{EV_REL, EV_ABS})
+	}
+
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Errorf("coalesced activity reported after %v", d)
+	}
+
+	select {
+	case a := <-ch:
+		t.Errorf("unexpected activity %+v", a)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	an.Stop()
+
+	if an.Push(EV_KEY) {
+		t.Error("Push() after Stop() = true")
+	}
+}
+
+func TestActivityNotifier_Watch(t *testing.T) {
+	ch := make(chan Activity, 10)
+	an := NewActivityNotifier(time.Second, func(a Activity) {
+		ch <- a
+	})
+	defer an.Stop()
+
+	an.Watch(&sliceSource{batches: [][]InputEvent{
+		{{Type: EV_SW, Code: SW_LID, Value: 1}, {Type: EV_SYN, Code: SYN_REPORT}},
+	}})
+
+	select {
+	case a := <-ch:
+		if !reflect.DeepEqual(a.Types, []EvType{EV_SW}) {
+			t.Errorf("activity types = %v, want %v", a.Types, []EvType{EV_SW})
+		}
+	case <-time.After(time.Second):
+		t.Error("no activity reported")
+	}
+}
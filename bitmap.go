@@ -1,18 +1,49 @@
 package evdev
 
-type bitmap struct {
+// maxBitmapBits is the size Set grows a bitmap to at most, far more than any
+// kernel bitmap needs.
+const maxBitmapBits = 1 << 16
+
+// Bitmap is a set of bits in the layout the kernel uses to describe
+// capabilities and states, i.e. bit n is bit n%8 of byte n/8.
+type Bitmap struct {
 	bits []byte
 }
 
-func (bm *bitmap) bitIsSet(bit int) bool {
-	if bit > len(bm.bits)*8 {
+// IsSet returns true if the given bit is set.
+func (bm *Bitmap) IsSet(bit int) bool {
+	if bit < 0 || bit >= len(bm.bits)*8 {
 		return false
 	}
 
 	return bm.bits[bit/8]&(1<<(bit%8)) != 0
 }
 
-func (bm *bitmap) setBits() []int {
+// Set sets the given bit, growing the bitmap if necessary. Bits beyond
+// 65535 are ignored.
+func (bm *Bitmap) Set(bit int) {
+	if bit < 0 || bit >= maxBitmapBits && bit >= len(bm.bits)*8 {
+		return
+	}
+
+	for bit >= len(bm.bits)*8 {
+		bm.bits = append(bm.bits, 0)
+	}
+
+	bm.bits[bit/8] |= 1 << (bit % 8)
+}
+
+// Clear clears the given bit.
+func (bm *Bitmap) Clear(bit int) {
+	if bit < 0 || bit >= len(bm.bits)*8 {
+		return
+	}
+
+	bm.bits[bit/8] &^= 1 << (bit % 8)
+}
+
+// SetBits returns the indices of all set bits in ascending order.
+func (bm *Bitmap) SetBits() []int {
 	a := []int{}
 
 	for i, by := range bm.bits {
@@ -26,8 +57,37 @@ func (bm *bitmap) setBits() []int {
 	return a
 }
 
-func newBitmap(bits []byte) *bitmap {
-	return &bitmap{
+// Bytes returns the bitmap as a byte slice.
+func (bm *Bitmap) Bytes() []byte {
+	return bm.bits
+}
+
+// Words returns the bitmap as a slice of 64 bit words, with bit n stored as
+// bit n%64 of word n/64.
+func (bm *Bitmap) Words() []uint64 {
+	words := make([]uint64, (len(bm.bits)+7)/8)
+
+	for i, by := range bm.bits {
+		words[i/8] |= uint64(by) << (8 * uint(i%8))
+	}
+
+	return words
+}
+
+// NewBitmap creates a Bitmap from the given bytes. The slice is not copied.
+func NewBitmap(bits []byte) *Bitmap {
+	return &Bitmap{
 		bits: bits,
 	}
 }
+
+// NewBitmapFromWords creates a Bitmap from 64 bit words as returned by Words.
+func NewBitmapFromWords(words []uint64) *Bitmap {
+	bits := make([]byte, len(words)*8)
+
+	for i := range bits {
+		bits[i] = byte(words[i/8] >> (8 * uint(i%8)))
+	}
+
+	return NewBitmap(bits)
+}
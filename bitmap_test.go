@@ -5,7 +5,7 @@ import (
 	"testing"
 )
 
-func Test_bitsToArray(t *testing.T) {
+func TestBitmap_SetBits(t *testing.T) {
 	tests := []struct {
 		name string
 		bits []byte
@@ -29,15 +29,15 @@ func Test_bitsToArray(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			bm := newBitmap(tt.bits)
-			if got := bm.setBits(); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("setBits() = %v, want %v", got, tt.want)
+			bm := NewBitmap(tt.bits)
+			if got := bm.SetBits(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SetBits() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func Test_bitmap_bitIsSet(t *testing.T) {
+func TestBitmap_IsSet(t *testing.T) {
 	tests := []struct {
 		name string
 		bits []byte
@@ -56,15 +56,44 @@ func Test_bitmap_bitIsSet(t *testing.T) {
 			bit:  31,
 			want: false,
 		},
+		{
+			name: "out of range",
+			bits: []byte{0xff},
+			bit:  8,
+			want: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			bm := &bitmap{
+			bm := &Bitmap{
 				bits: tt.bits,
 			}
-			if got := bm.bitIsSet(tt.bit); got != tt.want {
-				t.Errorf("bitmap.bitIsSet() = %v, want %v", got, tt.want)
+			if got := bm.IsSet(tt.bit); got != tt.want {
+				t.Errorf("Bitmap.IsSet() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestBitmap_SetClear(t *testing.T) {
+	bm := NewBitmap(nil)
+
+	bm.Set(3)
+	bm.Set(65)
+	bm.Set(1 << 40) // ignored instead of allocating
+	bm.Clear(3)
+	bm.Clear(1000)
+
+	if got, want := bm.SetBits(), []int{65}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SetBits() = %v, want %v", got, want)
+	}
+
+	if got, want := bm.Words(), []uint64{0, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Words() = %v, want %v", got, want)
+	}
+
+	// words are padded to 8 bytes
+	if got := NewBitmapFromWords(bm.Words()).Bytes(); !reflect.DeepEqual(got[:len(bm.Bytes())], bm.Bytes()) {
+		t.Errorf("NewBitmapFromWords() = %v, want %v", got, bm.Bytes())
+	}
+}
@@ -0,0 +1,79 @@
+package evdev
+
+// BraillePattern is a set of Braille dots, with dot n stored in bit n-1.
+// Dots 1 to 8 form the patterns of the Unicode Braille block, dots 9 and
+// 10 are only reported by some Braille keyboards.
+type BraillePattern uint16
+
+// Dot returns true if dot n, from 1 to 10, is part of the pattern.
+func (p BraillePattern) Dot(n int) bool {
+	return n >= 1 && n <= 10 && p&(1<<uint(n-1)) != 0
+}
+
+// Rune returns the character of the Unicode Braille block for dots 1 to 8
+// of the pattern, e.g. '⠃' for dots 1 and 2.
+func (p BraillePattern) Rune() rune {
+	return rune(0x2800 + int(p&0xff))
+}
+
+func (p BraillePattern) String() string {
+	return string(p.Rune())
+}
+
+// brailleDot returns the dot number of a KEY_BRL_DOT* code, or 0.
+func brailleDot(c EvCode) int {
+	if c < KEY_BRL_DOT1 || c > KEY_BRL_DOT10 {
+		return 0
+	}
+
+	return int(c-KEY_BRL_DOT1) + 1
+}
+
+// BrailleChorder combines the KEY_BRL_DOT* keys of a Braille keyboard into
+// patterns. Dots are accumulated while any of them is held, so a chord is
+// complete once all its keys are released, whether they were pressed and
+// released in the same frame or one after another.
+type BrailleChorder struct {
+	held    BraillePattern
+	pressed BraillePattern
+}
+
+// NewBrailleChorder creates a BrailleChorder.
+func NewBrailleChorder() *BrailleChorder {
+	return &BrailleChorder{}
+}
+
+// Push processes an event and returns the pattern of the chord completed at
+// the end of its frame, if any. SYN_DROPPED discards the current chord.
+func (bc *BrailleChorder) Push(e InputEvent) (BraillePattern, bool) {
+	switch {
+	case e.Type == EV_SYN && e.Code == SYN_DROPPED:
+		bc.held, bc.pressed = 0, 0
+
+	case e.Type == EV_SYN && e.Code == SYN_REPORT:
+		if bc.held == 0 && bc.pressed != 0 {
+			p := bc.pressed
+			bc.pressed = 0
+
+			return p, true
+		}
+
+	case e.Type == EV_KEY:
+		dot := brailleDot(e.Code)
+		if dot == 0 {
+			break
+		}
+
+		bit := BraillePattern(1) << uint(dot-1)
+
+		switch KeyState(e.Value) {
+		case KeyDown:
+			bc.held |= bit
+			bc.pressed |= bit
+		case KeyUp:
+			bc.held &^= bit
+		}
+	}
+
+	return 0, false
+}
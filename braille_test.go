@@ -0,0 +1,72 @@
+package evdev
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBraillePattern(t *testing.T) {
+	tests := []struct {
+		p    BraillePattern
+		want string
+		dots []int
+	}{
+		{0, "⠀", []int{}},
+		{0x03, "⠃", []int{1, 2}},
+		{0xff, "⣿", []int{1, 2, 3, 4, 5, 6, 7, 8}},
+		{0x301, "⠁", []int{1, 9, 10}},
+	}
+	for _, tt := range tests {
+		if got := tt.p.String(); got != tt.want {
+			t.Errorf("%#x: String() = %q, want %q", uint16(tt.p), got, tt.want)
+		}
+
+		dots := []int{}
+		for n := 0; n <= 11; n++ {
+			if tt.p.Dot(n) {
+				dots = append(dots, n)
+			}
+		}
+
+		if !reflect.DeepEqual(dots, tt.dots) {
+			t.Errorf("%#x: dots = %v, want %v", uint16(tt.p), dots, tt.dots)
+		}
+	}
+}
+
+func TestBrailleChorder(t *testing.T) {
+	key := func(c EvCode, v int32) InputEvent { return InputEvent{Type: EV_KEY, Code: c, Value: v} }
+	syn := InputEvent{Type: EV_SYN, Code: SYN_REPORT}
+
+	events := []InputEvent{
+		// dots 1 and 2 in one frame
+		key(KEY_BRL_DOT1, 1), key(KEY_BRL_DOT2, 1), syn,
+		key(KEY_BRL_DOT1, 0), key(KEY_BRL_DOT2, 0), syn,
+		// a rolled chord of dots 1, 4 and 5, with other keys in between
+		key(KEY_BRL_DOT1, 1), syn,
+		key(KEY_BRL_DOT4, 1), key(KEY_A, 1), syn,
+		key(KEY_BRL_DOT1, 0), syn,
+		key(KEY_BRL_DOT5, 1), key(KEY_BRL_DOT5, 2), syn,
+		key(KEY_BRL_DOT4, 0), key(KEY_BRL_DOT5, 0), syn,
+		// discarded by SYN_DROPPED
+		key(KEY_BRL_DOT3, 1), syn,
+		{Type: EV_SYN, Code: SYN_DROPPED}, syn,
+		key(KEY_BRL_DOT3, 0), syn,
+		// pressed and released in the same frame
+		key(KEY_BRL_DOT7, 1), key(KEY_BRL_DOT7, 0), syn,
+	}
+
+	bc := NewBrailleChorder()
+	got := []BraillePattern{}
+
+	for _, e := range events {
+		if p, ok := bc.Push(e); ok {
+			got = append(got, p)
+		}
+	}
+
+	want := []BraillePattern{0x03, 0x19, 0x40}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("patterns = %v, want %v", got, want)
+	}
+}
@@ -14,6 +14,23 @@ type kv map[string]string
 
 var defs map[string]kv
 
+// order keeps the names of each type in the order they were defined in
+var order map[string][]string
+
+// rangeMarkers are names that mark the beginning of a range of codes and
+// share their value with the first code of the range. Like libevdev, the
+// name maps use the name of the code instead.
+var rangeMarkers = []string{
+	"BTN_MISC",
+	"BTN_MOUSE",
+	"BTN_JOYSTICK",
+	"BTN_GAMEPAD",
+	"BTN_DIGI",
+	"BTN_WHEEL",
+	"BTN_TRIGGER_HAPPY",
+	"KEY_MIN_INTERESTING",
+}
+
 func stringInSlice(s string, slice []string) bool {
 	for _, x := range slice {
 		if x == s {
@@ -68,7 +85,7 @@ func readfile(fname string) error {
 
 		if varType == "INPUT" && strings.HasPrefix(varName, "PROP_") {
 			varType = "PROP"
-			varName = strings.TrimLeft(varName, "PROP_")
+			varName = strings.TrimPrefix(varName, "PROP_")
 		}
 
 		content, ok := defs[varType]
@@ -76,6 +93,10 @@ func readfile(fname string) error {
 			content = make(kv)
 		}
 
+		if _, ok := content[varName]; !ok {
+			order[varType] = append(order[varType], varName)
+		}
+
 		content[varName] = varValue
 		defs[varType] = content
 	}
@@ -122,6 +143,7 @@ func sortedStringKvKeys(m map[string]kv) []string {
 
 func main() {
 	defs = make(map[string]kv)
+	order = make(map[string][]string)
 
 	for _, f := range os.Args[1:] {
 		err := readfile(f)
@@ -147,12 +169,14 @@ func main() {
 		fmt.Printf(")\n")
 
 		m := make(map[int]string)
-		for k, v := range dContent {
-			a := strings.Split(v, " ")
+		for _, k := range order[dType] {
+			a := strings.Split(dContent[k], " ")
 			n, err := strconv.ParseInt(a[0], 0, 32)
-			if k != "MAX" && err == nil {
-				// FIXME: handle duplicates
-				m[int(n)] = k
+			if k != "MAX" && err == nil && !stringInSlice(dType+"_"+k, rangeMarkers) {
+				// for duplicate values, the name defined first wins
+				if _, ok := m[int(n)]; !ok {
+					m[int(n)] = k
+				}
 			}
 		}
 
@@ -172,5 +196,11 @@ func main() {
 			fmt.Printf("\t%d: \"%s_%s\",\n", k, dType, m[k])
 		}
 		fmt.Printf("}\n")
+
+		fmt.Printf("var %sByName = map[string]%s {\n", strings.Title(dType), mType)
+		for _, k := range sortedStringKeys(dContent) {
+			fmt.Printf("\t\"%s_%s\": %s_%s,\n", dType, k, dType, k)
+		}
+		fmt.Printf("}\n")
 	}
 }
@@ -0,0 +1,228 @@
+package evdev
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type buttonHandlers struct {
+	press    func()
+	release  func(held time.Duration, holdFired bool)
+	hold     func()
+	holdTime time.Duration
+
+	pressedAt time.Time
+	timer     *time.Timer
+	seq       uint64 // incremented with every press and release
+	holdFired bool
+}
+
+// Buttons is a simple wrapper for devices with a few named EV_KEY buttons,
+// such as gpio-keys devices on embedded boards. It dispatches press, release
+// and hold callbacks per button. Callbacks never run concurrently, and those
+// of a button run in the order press, hold, release.
+type Buttons struct {
+	// callbacks run with cb held. When both are needed, cb is locked
+	// before mu.
+	cb       sync.Mutex
+	mu       sync.Mutex
+	dev      *InputDevice
+	codes    map[string]EvCode
+	handlers map[EvCode]*buttonHandlers
+}
+
+// NewButtons creates a Buttons wrapper for d with the given button names and
+// their EV_KEY codes.
+func NewButtons(d *InputDevice, buttons map[string]EvCode) *Buttons {
+	b := &Buttons{
+		dev:      d,
+		codes:    map[string]EvCode{},
+		handlers: map[EvCode]*buttonHandlers{},
+	}
+
+	for name, code := range buttons {
+		b.codes[name] = code
+		b.handlers[code] = &buttonHandlers{}
+	}
+
+	return b
+}
+
+func (b *Buttons) lookup(name string) (*buttonHandlers, error) {
+	code, ok := b.codes[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown button %q", name)
+	}
+
+	return b.handlers[code], nil
+}
+
+// OnPress sets the function to call when the named button is pressed.
+func (b *Buttons) OnPress(name string, f func()) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	h, err := b.lookup(name)
+	if err != nil {
+		return err
+	}
+
+	h.press = f
+
+	return nil
+}
+
+// OnRelease sets the function to call when the named button is released.
+// It is passed the time the button was held down and whether the hold
+// callback ran for this press.
+func (b *Buttons) OnRelease(name string, f func(held time.Duration, holdFired bool)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	h, err := b.lookup(name)
+	if err != nil {
+		return err
+	}
+
+	h.release = f
+
+	return nil
+}
+
+// OnHold sets the function to call when the named button has been held down
+// for the given duration. It is called from a separate goroutine.
+func (b *Buttons) OnHold(name string, holdTime time.Duration, f func()) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	h, err := b.lookup(name)
+	if err != nil {
+		return err
+	}
+
+	h.hold = f
+	h.holdTime = holdTime
+
+	return nil
+}
+
+// Pressed queries the current state of the named button from the device.
+func (b *Buttons) Pressed(name string) (bool, error) {
+	code, ok := b.codes[name]
+	if !ok {
+		return false, fmt.Errorf("Unknown button %q", name)
+	}
+
+	state, err := b.dev.State(EV_KEY)
+	if err != nil {
+		return false, err
+	}
+
+	return state[code], nil
+}
+
+// Run reads events from the device and dispatches the callbacks. It blocks
+// until reading from the device fails and returns the error. The quirks and
+// stored settings of the device, see UseSettingsStore, are applied.
+func (b *Buttons) Run() error {
+	filter, err := newDeviceFilter(b.dev)
+	if err != nil {
+		return err
+	}
+
+	for {
+		e, err := b.dev.ReadOne()
+		if err != nil {
+			return err
+		}
+
+		for _, fe := range filter.Push(*e) {
+			if fe.Type == EV_KEY {
+				b.handle(&fe)
+			}
+		}
+	}
+}
+
+func (b *Buttons) handle(e *InputEvent) {
+	b.cb.Lock()
+	defer b.cb.Unlock()
+
+	b.mu.Lock()
+
+	h, ok := b.handlers[e.Code]
+	if !ok {
+		b.mu.Unlock()
+		return
+	}
+
+	switch KeyState(e.Value) {
+	case KeyDown:
+		h.seq++
+		h.pressedAt = e.Timestamp()
+		h.holdFired = false
+		press := h.press
+
+		b.mu.Unlock()
+
+		if press != nil {
+			press()
+		}
+
+		b.mu.Lock()
+
+		// start the timer only after the press callback, so hold can't
+		// overtake it
+		if h.hold != nil {
+			seq := h.seq
+			h.timer = time.AfterFunc(h.holdTime, func() { b.fireHold(h, seq) })
+		}
+
+		b.mu.Unlock()
+
+	case KeyUp:
+		h.seq++
+
+		// a timer that already fired sees the changed seq and does nothing,
+		// unless it marked the hold as fired before
+		if h.timer != nil {
+			h.timer.Stop()
+			h.timer = nil
+		}
+
+		release := h.release
+		held := e.Timestamp().Sub(h.pressedAt)
+		holdFired := h.holdFired
+
+		b.mu.Unlock()
+
+		if release != nil {
+			release(held, holdFired)
+		}
+
+	default:
+		b.mu.Unlock()
+	}
+}
+
+// fireHold runs the hold callback of h, unless the button was released or
+// pressed again since the timer with the given seq was started.
+func (b *Buttons) fireHold(h *buttonHandlers, seq uint64) {
+	b.cb.Lock()
+	defer b.cb.Unlock()
+
+	b.mu.Lock()
+
+	if h.seq != seq {
+		b.mu.Unlock()
+		return
+	}
+
+	h.holdFired = true
+	hold := h.hold
+
+	b.mu.Unlock()
+
+	hold()
+}
@@ -0,0 +1,62 @@
+package evdev
+
+import (
+	"reflect"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestButtons(t *testing.T) {
+	b := NewButtons(nil, map[string]EvCode{"power": KEY_POWER})
+
+	mu := sync.Mutex{}
+	calls := []string{}
+	record := func(s string) {
+		mu.Lock()
+		calls = append(calls, s)
+		mu.Unlock()
+	}
+
+	held := make(chan struct{}, 1)
+
+	b.OnPress("power", func() { record("press") })
+	b.OnHold("power", 20*time.Millisecond, func() {
+		record("hold")
+		held <- struct{}{}
+	})
+	b.OnRelease("power", func(d time.Duration, holdFired bool) {
+		if holdFired {
+			record("release after hold")
+		} else {
+			record("release")
+		}
+	})
+
+	if err := b.OnPress("reset", func() {}); err == nil {
+		t.Errorf("OnPress() for an unknown button succeeded, want error")
+	}
+
+	key := func(v int32) *InputEvent {
+		return &InputEvent{Time: syscall.Timeval{Sec: 1}, Type: EV_KEY, Code: KEY_POWER, Value: v}
+	}
+
+	// a short press does not trigger the hold callback
+	b.handle(key(1))
+	b.handle(key(0))
+	time.Sleep(50 * time.Millisecond)
+
+	// a long press does
+	b.handle(key(1))
+	<-held
+	b.handle(key(0))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	want := []string{"press", "release", "press", "hold", "release after hold"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("callbacks = %v, want %v", calls, want)
+	}
+}
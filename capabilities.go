@@ -0,0 +1,221 @@
+package evdev
+
+import "strings"
+
+// Capabilities describes the supported event types and codes and the
+// properties of a device.
+type Capabilities struct {
+	Codes map[EvType][]EvCode
+	Props []EvProp
+}
+
+// Capabilities returns the supported event types and codes and the
+// properties of the device.
+func (d *InputDevice) Capabilities() Capabilities {
+	c := Capabilities{
+		Codes: map[EvType][]EvCode{},
+		Props: d.Properties(),
+	}
+
+	for _, t := range d.CapableTypes() {
+		c.Codes[t] = d.CapableEvents(t)
+	}
+
+	return c
+}
+
+// AbsInfoDiff describes an axis whose range, fuzz, flat or resolution
+// differ between two devices.
+type AbsInfoDiff struct {
+	Code EvCode
+	A, B AbsInfo
+}
+
+// CapabilityDiff lists the differences between the capabilities of two
+// devices A and B.
+type CapabilityDiff struct {
+	OnlyA   Capabilities // types, codes and properties only A has
+	OnlyB   Capabilities // types, codes and properties only B has
+	AbsInfo []AbsInfoDiff
+}
+
+// Empty returns true if there are no differences.
+func (cd *CapabilityDiff) Empty() bool {
+	return len(cd.OnlyA.Codes) == 0 && len(cd.OnlyA.Props) == 0 &&
+		len(cd.OnlyB.Codes) == 0 && len(cd.OnlyB.Props) == 0 &&
+		len(cd.AbsInfo) == 0
+}
+
+// capabilitiesMinus returns the types, codes and properties in a that are
+// not in b.
+func capabilitiesMinus(a, b Capabilities) Capabilities {
+	c := Capabilities{
+		Codes: map[EvType][]EvCode{},
+		Props: []EvProp{},
+	}
+
+	for t, codes := range a.Codes {
+		other, ok := b.Codes[t]
+		if !ok {
+			c.Codes[t] = codes
+			continue
+		}
+
+		have := map[EvCode]bool{}
+		for _, code := range other {
+			have[code] = true
+		}
+
+		for _, code := range codes {
+			if !have[code] {
+				c.Codes[t] = append(c.Codes[t], code)
+			}
+		}
+	}
+
+	have := map[EvProp]bool{}
+	for _, p := range b.Props {
+		have[p] = true
+	}
+
+	for _, p := range a.Props {
+		if !have[p] {
+			c.Props = append(c.Props, p)
+		}
+	}
+
+	return c
+}
+
+func compareAbsInfos(a, b map[EvCode]AbsInfo) []AbsInfoDiff {
+	diffs := []AbsInfoDiff{}
+
+	codes := []EvCode{}
+	for code := range a {
+		codes = append(codes, code)
+	}
+
+	for _, code := range sortCodes(codes) {
+		ai := a[code]
+		bi, ok := b[code]
+		if !ok {
+			continue
+		}
+
+		if ai.Minimum != bi.Minimum || ai.Maximum != bi.Maximum ||
+			ai.Fuzz != bi.Fuzz || ai.Flat != bi.Flat ||
+			ai.Resolution != bi.Resolution {
+			diffs = append(diffs, AbsInfoDiff{Code: code, A: ai, B: bi})
+		}
+	}
+
+	return diffs
+}
+
+// CompareCapabilities lists the event types, codes and properties present
+// on one of the devices but not the other, and the axes whose AbsInfo
+// ranges differ. This is useful to find out why a clone or proxy of a
+// device behaves differently from the original.
+func CompareCapabilities(a, b *InputDevice) CapabilityDiff {
+	capsA := a.Capabilities()
+	capsB := b.Capabilities()
+
+	absA, _ := a.AbsInfos()
+	absB, _ := b.AbsInfos()
+
+	return CapabilityDiff{
+		OnlyA:   capabilitiesMinus(capsA, capsB),
+		OnlyB:   capabilitiesMinus(capsB, capsA),
+		AbsInfo: compareAbsInfos(absA, absB),
+	}
+}
+
+// CapRequirement is a capability an application requires from a device.
+// Requirements are created with RequireType, RequireCode and RequireProp.
+type CapRequirement struct {
+	name  string
+	check func(c *Capabilities) bool
+}
+
+// RequireType requires support for the EvType t.
+func RequireType(t EvType) CapRequirement {
+	return CapRequirement{
+		name: TypeName(t),
+		check: func(c *Capabilities) bool {
+			_, ok := c.Codes[t]
+			return ok
+		},
+	}
+}
+
+// RequireCode requires support for the given code of EvType t.
+func RequireCode(t EvType, code EvCode) CapRequirement {
+	return CapRequirement{
+		name: CodeName(t, code),
+		check: func(c *Capabilities) bool {
+			for _, have := range c.Codes[t] {
+				if have == code {
+					return true
+				}
+			}
+
+			return false
+		},
+	}
+}
+
+// RequireProp requires the property p.
+func RequireProp(p EvProp) CapRequirement {
+	return CapRequirement{
+		name: PropName(p),
+		check: func(c *Capabilities) bool {
+			for _, have := range c.Props {
+				if have == p {
+					return true
+				}
+			}
+
+			return false
+		},
+	}
+}
+
+// MissingCapabilitiesError is returned by RequireCapabilities and lists all
+// capabilities a device lacks.
+type MissingCapabilitiesError struct {
+	Missing []string
+}
+
+func (e *MissingCapabilitiesError) Error() string {
+	msgs := []string{}
+	for _, m := range e.Missing {
+		msgs = append(msgs, "needs "+m)
+	}
+
+	return "Missing capabilities: " + strings.Join(msgs, ", ")
+}
+
+// Require checks all requirements and returns a *MissingCapabilitiesError
+// listing the ones that are not met, or nil if all of them are.
+func (c Capabilities) Require(reqs ...CapRequirement) error {
+	missing := []string{}
+
+	for _, r := range reqs {
+		if !r.check(&c) {
+			missing = append(missing, r.name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return &MissingCapabilitiesError{Missing: missing}
+	}
+
+	return nil
+}
+
+// RequireCapabilities checks that the device meets all requirements and
+// returns a *MissingCapabilitiesError listing everything it lacks, so
+// applications can fail early with a useful message.
+func (d *InputDevice) RequireCapabilities(reqs ...CapRequirement) error {
+	return d.Capabilities().Require(reqs...)
+}
@@ -0,0 +1,78 @@
+package evdev
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_capabilitiesMinus(t *testing.T) {
+	a := Capabilities{
+		Codes: map[EvType][]EvCode{
+			EV_KEY: {BTN_LEFT, BTN_RIGHT},
+			EV_REL: {REL_X, REL_Y, REL_WHEEL},
+		},
+		Props: []EvProp{PROP_POINTER},
+	}
+	b := Capabilities{
+		Codes: map[EvType][]EvCode{
+			EV_REL: {REL_X, REL_Y},
+		},
+		Props: []EvProp{PROP_POINTER},
+	}
+
+	want := Capabilities{
+		Codes: map[EvType][]EvCode{
+			EV_KEY: {BTN_LEFT, BTN_RIGHT},
+			EV_REL: {REL_WHEEL},
+		},
+		Props: []EvProp{},
+	}
+	if got := capabilitiesMinus(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("capabilitiesMinus() = %v, want %v", got, want)
+	}
+
+	want = Capabilities{
+		Codes: map[EvType][]EvCode{},
+		Props: []EvProp{},
+	}
+	if got := capabilitiesMinus(b, a); !reflect.DeepEqual(got, want) {
+		t.Errorf("capabilitiesMinus() = %v, want %v", got, want)
+	}
+}
+
+func Test_compareAbsInfos(t *testing.T) {
+	a := map[EvCode]AbsInfo{
+		ABS_X: {Value: 5, Maximum: 100},
+		ABS_Y: {Maximum: 100},
+		ABS_Z: {Maximum: 255},
+	}
+	b := map[EvCode]AbsInfo{
+		ABS_X: {Value: 7, Maximum: 100},
+		ABS_Y: {Maximum: 200},
+	}
+
+	want := []AbsInfoDiff{{Code: ABS_Y, A: a[ABS_Y], B: b[ABS_Y]}}
+	if got := compareAbsInfos(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("compareAbsInfos() = %v, want %v", got, want)
+	}
+}
+
+func TestCapabilities_Require(t *testing.T) {
+	c := Capabilities{
+		Codes: map[EvType][]EvCode{
+			EV_ABS: {ABS_X, ABS_Y},
+		},
+		Props: []EvProp{PROP_POINTER},
+	}
+
+	if err := c.Require(RequireType(EV_ABS), RequireCode(EV_ABS, ABS_X), RequireProp(PROP_POINTER)); err != nil {
+		t.Errorf("Require() error = %v, want nil", err)
+	}
+
+	err := c.Require(RequireType(EV_KEY), RequireCode(EV_ABS, ABS_MT_POSITION_X), RequireProp(PROP_DIRECT))
+
+	want := &MissingCapabilitiesError{Missing: []string{"EV_KEY", "ABS_MT_POSITION_X", "PROP_DIRECT"}}
+	if !reflect.DeepEqual(err, want) {
+		t.Errorf("Require() error = %v, want %v", err, want)
+	}
+}
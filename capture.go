@@ -0,0 +1,647 @@
+package evdev
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"syscall"
+)
+
+// The binary capture format starts with captureMagic followed by a
+// sequence of records. Each record begins with a one-byte record type.
+// Device records describe a device and must precede the events that refer
+// to it. All integers are little endian. The last byte of the magic is the
+// format version. Version 1 device records only contain the input ID and
+// the name.
+var captureMagic = [8]byte{'E', 'V', 'C', 'A', 'P', 0, 0, 2}
+
+const (
+	captureRecordDevice = 1
+	captureRecordEvent  = 2
+)
+
+// Limits of the capture format beyond those of its integer fields, so that
+// malformed or hostile captures can't make the reader allocate excessively.
+const (
+	captureMaxDevices = 0x10000 // devices are referenced by a uint16
+	captureMaxProps   = PROP_MAX + 1
+	captureMaxCodes   = KEY_CNT // codes per type, KEY has the most
+)
+
+// ErrCaptureFormat is returned when a capture stream is malformed. The
+// errors returned by CaptureReader are CaptureFormatErrors, which match
+// ErrCaptureFormat with errors.Is.
+var ErrCaptureFormat = errors.New("malformed capture stream")
+
+// CaptureFormatError describes where and why a capture stream is malformed.
+type CaptureFormatError struct {
+	Offset int64 // offset of the malformed record in the stream
+	Reason string
+}
+
+func (e *CaptureFormatError) Error() string {
+	return fmt.Sprintf("%v at offset %d: %s", ErrCaptureFormat, e.Offset, e.Reason)
+}
+
+// Is reports whether target is ErrCaptureFormat.
+func (e *CaptureFormatError) Is(target error) bool {
+	return target == ErrCaptureFormat
+}
+
+// checkCaptureDevice returns why dev exceeds the limits of the capture
+// format, or an empty string.
+func checkCaptureDevice(dev *CaptureDevice) string {
+	if len(dev.Name) > 0xffff || len(dev.Phys) > 0xffff || len(dev.Uniq) > 0xffff {
+		return "device name, phys or uniq too long"
+	}
+
+	if len(dev.Capabilities.Props) > captureMaxProps {
+		return "too many device properties"
+	}
+
+	for t, codes := range dev.Capabilities.Codes {
+		if t >= EV_CNT {
+			return fmt.Sprintf("invalid event type %d", t)
+		}
+
+		if len(codes) > captureMaxCodes {
+			return "too many device capabilities"
+		}
+	}
+
+	for c := range dev.AbsInfos {
+		if c >= ABS_CNT {
+			return fmt.Sprintf("invalid axis %d", c)
+		}
+	}
+
+	return ""
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// CaptureDevice describes a device whose events are stored in a capture.
+type CaptureDevice struct {
+	Name         string
+	Phys         string
+	Uniq         string
+	ID           InputID
+	Capabilities Capabilities
+	AbsInfos     map[EvCode]AbsInfo
+}
+
+// CaptureEvent is a single event stored in a capture, tagged with the
+// index of the device it originates from.
+type CaptureEvent struct {
+	Device int   // index into the capture's device list
+	Time   int64 // nanoseconds since the epoch of the device's clock
+	Type   EvType
+	Code   EvCode
+	Value  int32
+}
+
+type captureEventRecord struct {
+	Device uint16
+	Time   int64
+	Type   EvType
+	Code   EvCode
+	Value  int32
+}
+
+// InputEvent converts the captured event back into an InputEvent.
+// The timestamp is truncated to microseconds.
+func (ce *CaptureEvent) InputEvent() InputEvent {
+	return InputEvent{
+		Time:  syscall.NsecToTimeval(ce.Time),
+		Type:  ce.Type,
+		Code:  ce.Code,
+		Value: ce.Value,
+	}
+}
+
+// CaptureDeviceOf returns the descriptor of an opened InputDevice.
+func CaptureDeviceOf(d *InputDevice) (CaptureDevice, error) {
+	name, err := d.Name()
+	if err != nil {
+		return CaptureDevice{}, fmt.Errorf("Cannot get device name: %v", err)
+	}
+
+	phys, err := d.PhysicalLocation()
+	if err != nil {
+		return CaptureDevice{}, fmt.Errorf("Cannot get physical location: %v", err)
+	}
+
+	uniq, err := d.UniqueID()
+	if err != nil {
+		return CaptureDevice{}, fmt.Errorf("Cannot get unique ID: %v", err)
+	}
+
+	id, err := d.InputID()
+	if err != nil {
+		return CaptureDevice{}, fmt.Errorf("Cannot get input ID: %v", err)
+	}
+
+	absInfos, err := d.AbsInfos()
+	if err != nil {
+		return CaptureDevice{}, fmt.Errorf("Cannot get abs infos: %v", err)
+	}
+
+	if len(absInfos) == 0 {
+		absInfos = nil
+	}
+
+	return CaptureDevice{
+		Name:         name,
+		Phys:         phys,
+		Uniq:         uniq,
+		ID:           id,
+		Capabilities: d.Capabilities(),
+		AbsInfos:     absInfos,
+	}, nil
+}
+
+func writeCaptureString(buf *bytes.Buffer, s string) {
+	binary.Write(buf, binary.LittleEndian, uint16(len(s)))
+	buf.WriteString(s)
+}
+
+func readCaptureString(r io.Reader) (string, error) {
+	n := uint16(0)
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return "", err
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+func readCaptureUint16s(r io.Reader, max int) ([]uint16, error) {
+	n := uint16(0)
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return nil, err
+	}
+
+	if int(n) > max {
+		return nil, fmt.Errorf("%d values exceed the limit of %d", n, max)
+	}
+
+	if n == 0 {
+		return nil, nil
+	}
+
+	values := make([]uint16, n)
+	if err := binary.Read(r, binary.LittleEndian, values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// encodeCaptureDevice encodes the body of a device record: the input ID,
+// name, phys and uniq, then the properties, the codes of each type and the
+// absinfos, each preceded by their count.
+func encodeCaptureDevice(buf *bytes.Buffer, dev *CaptureDevice) {
+	binary.Write(buf, binary.LittleEndian, dev.ID)
+	writeCaptureString(buf, dev.Name)
+	writeCaptureString(buf, dev.Phys)
+	writeCaptureString(buf, dev.Uniq)
+
+	binary.Write(buf, binary.LittleEndian, uint16(len(dev.Capabilities.Props)))
+	binary.Write(buf, binary.LittleEndian, dev.Capabilities.Props)
+
+	types := []EvType{}
+	for t := range dev.Capabilities.Codes {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	binary.Write(buf, binary.LittleEndian, uint16(len(types)))
+	for _, t := range types {
+		codes := dev.Capabilities.Codes[t]
+		binary.Write(buf, binary.LittleEndian, t)
+		binary.Write(buf, binary.LittleEndian, uint16(len(codes)))
+		binary.Write(buf, binary.LittleEndian, codes)
+	}
+
+	codes := []EvCode{}
+	for c := range dev.AbsInfos {
+		codes = append(codes, c)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+
+	binary.Write(buf, binary.LittleEndian, uint16(len(codes)))
+	for _, c := range codes {
+		binary.Write(buf, binary.LittleEndian, c)
+		binary.Write(buf, binary.LittleEndian, dev.AbsInfos[c])
+	}
+}
+
+// decodeCaptureDevice decodes the body of a device record written in the
+// given format version.
+func decodeCaptureDevice(r io.Reader, version byte) (CaptureDevice, error) {
+	dev := CaptureDevice{}
+
+	if err := binary.Read(r, binary.LittleEndian, &dev.ID); err != nil {
+		return dev, err
+	}
+
+	name, err := readCaptureString(r)
+	if err != nil {
+		return dev, err
+	}
+
+	dev.Name = name
+
+	if version < 2 {
+		return dev, nil
+	}
+
+	if dev.Phys, err = readCaptureString(r); err != nil {
+		return dev, err
+	}
+
+	if dev.Uniq, err = readCaptureString(r); err != nil {
+		return dev, err
+	}
+
+	props, err := readCaptureUint16s(r, captureMaxProps)
+	if err != nil {
+		return dev, err
+	}
+
+	for _, p := range props {
+		dev.Capabilities.Props = append(dev.Capabilities.Props, EvProp(p))
+	}
+
+	numTypes := uint16(0)
+	if err := binary.Read(r, binary.LittleEndian, &numTypes); err != nil {
+		return dev, err
+	}
+
+	for i := 0; i < int(numTypes); i++ {
+		t := EvType(0)
+		if err := binary.Read(r, binary.LittleEndian, &t); err != nil {
+			return dev, err
+		}
+
+		if t >= EV_CNT {
+			return dev, fmt.Errorf("invalid event type %d", t)
+		}
+
+		if _, ok := dev.Capabilities.Codes[t]; ok {
+			return dev, fmt.Errorf("duplicate event type %d", t)
+		}
+
+		codes, err := readCaptureUint16s(r, captureMaxCodes)
+		if err != nil {
+			return dev, err
+		}
+
+		if dev.Capabilities.Codes == nil {
+			dev.Capabilities.Codes = map[EvType][]EvCode{}
+		}
+
+		dev.Capabilities.Codes[t] = []EvCode{}
+		for _, c := range codes {
+			dev.Capabilities.Codes[t] = append(dev.Capabilities.Codes[t], EvCode(c))
+		}
+	}
+
+	numAbs := uint16(0)
+	if err := binary.Read(r, binary.LittleEndian, &numAbs); err != nil {
+		return dev, err
+	}
+
+	if numAbs > ABS_CNT {
+		return dev, fmt.Errorf("%d axes exceed the limit of %d", numAbs, ABS_CNT)
+	}
+
+	for i := 0; i < int(numAbs); i++ {
+		c := EvCode(0)
+		info := AbsInfo{}
+
+		if err := binary.Read(r, binary.LittleEndian, &c); err != nil {
+			return dev, err
+		}
+
+		if err := binary.Read(r, binary.LittleEndian, &info); err != nil {
+			return dev, err
+		}
+
+		if c >= ABS_CNT {
+			return dev, fmt.Errorf("invalid axis %d", c)
+		}
+
+		if dev.AbsInfos == nil {
+			dev.AbsInfos = map[EvCode]AbsInfo{}
+		}
+
+		dev.AbsInfos[c] = info
+	}
+
+	return dev, nil
+}
+
+// CaptureWriter writes events from one or more devices into a binary capture.
+type CaptureWriter struct {
+	w       io.Writer
+	devices int
+}
+
+// NewCaptureWriter writes the capture header to w and returns a CaptureWriter.
+func NewCaptureWriter(w io.Writer) (*CaptureWriter, error) {
+	if _, err := w.Write(captureMagic[:]); err != nil {
+		return nil, err
+	}
+
+	return &CaptureWriter{w: w}, nil
+}
+
+// AddDevice writes a device descriptor and returns the index events of this
+// device have to be written with.
+func (cw *CaptureWriter) AddDevice(dev CaptureDevice) (int, error) {
+	if cw.devices >= captureMaxDevices {
+		return 0, fmt.Errorf("Too many devices in capture")
+	}
+
+	if reason := checkCaptureDevice(&dev); reason != "" {
+		return 0, fmt.Errorf("Cannot add device: %s", reason)
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteByte(captureRecordDevice)
+	encodeCaptureDevice(buf, &dev)
+
+	if _, err := cw.w.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+
+	cw.devices++
+
+	return cw.devices - 1, nil
+}
+
+// Write writes a captured event.
+func (cw *CaptureWriter) Write(ce *CaptureEvent) error {
+	if ce.Device < 0 || ce.Device >= cw.devices {
+		return fmt.Errorf("Unknown device index %d", ce.Device)
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteByte(captureRecordEvent)
+	binary.Write(buf, binary.LittleEndian, captureEventRecord{
+		Device: uint16(ce.Device),
+		Time:   ce.Time,
+		Type:   ce.Type,
+		Code:   ce.Code,
+		Value:  ce.Value,
+	})
+
+	_, err := cw.w.Write(buf.Bytes())
+	return err
+}
+
+// WriteEvent writes an InputEvent read from the device with the given index.
+func (cw *CaptureWriter) WriteEvent(device int, e *InputEvent) error {
+	return cw.Write(&CaptureEvent{
+		Device: device,
+		Time:   e.Time.Nano(),
+		Type:   e.Type,
+		Code:   e.Code,
+		Value:  e.Value,
+	})
+}
+
+// CaptureReader reads events from a binary capture. Malformed captures are
+// reported as CaptureFormatError, including device records that exceed the
+// limits of the kernel, such as unknown event types or axes.
+type CaptureReader struct {
+	r       *countingReader
+	version byte
+	devices []CaptureDevice
+}
+
+func (cr *CaptureReader) formatError(offset int64, reason string) error {
+	return &CaptureFormatError{Offset: offset, Reason: reason}
+}
+
+// NewCaptureReader reads and verifies the capture header from r.
+func NewCaptureReader(r io.Reader) (*CaptureReader, error) {
+	magic := [8]byte{}
+
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, &CaptureFormatError{Reason: "missing header"}
+	}
+
+	// all versions up to the current one can be read
+	version := magic[7]
+	magic[7] = captureMagic[7]
+
+	if magic != captureMagic {
+		return nil, &CaptureFormatError{Reason: "invalid header"}
+	}
+
+	if version < 1 || version > captureMagic[7] {
+		return nil, &CaptureFormatError{Reason: fmt.Sprintf("unsupported version %d", version)}
+	}
+
+	return &CaptureReader{r: &countingReader{r: r, n: int64(len(magic))}, version: version}, nil
+}
+
+// Devices returns the descriptors of all devices read so far.
+func (cr *CaptureReader) Devices() []CaptureDevice {
+	return cr.devices
+}
+
+// Next returns the next event in the capture. Device records are consumed
+// transparently and made available via Devices. Returns io.EOF at the end
+// of the capture.
+func (cr *CaptureReader) Next() (*CaptureEvent, error) {
+	recordType := [1]byte{}
+
+	for {
+		offset := cr.r.n
+
+		if _, err := io.ReadFull(cr.r, recordType[:]); err != nil {
+			return nil, err
+		}
+
+		switch recordType[0] {
+		case captureRecordDevice:
+			if len(cr.devices) >= captureMaxDevices {
+				return nil, cr.formatError(offset, "too many devices")
+			}
+
+			dev, err := decodeCaptureDevice(cr.r, cr.version)
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil, cr.formatError(offset, "truncated device record")
+			}
+			if err != nil {
+				return nil, cr.formatError(offset, err.Error())
+			}
+
+			cr.devices = append(cr.devices, dev)
+
+		case captureRecordEvent:
+			rec := captureEventRecord{}
+
+			if err := binary.Read(cr.r, binary.LittleEndian, &rec); err != nil {
+				return nil, cr.formatError(offset, "truncated event record")
+			}
+
+			if int(rec.Device) >= len(cr.devices) {
+				return nil, cr.formatError(offset, fmt.Sprintf("unknown device index %d", rec.Device))
+			}
+
+			return &CaptureEvent{
+				Device: int(rec.Device),
+				Time:   rec.Time,
+				Type:   rec.Type,
+				Code:   rec.Code,
+				Value:  rec.Value,
+			}, nil
+
+		default:
+			return nil, cr.formatError(offset, fmt.Sprintf("unknown record type %d", recordType[0]))
+		}
+	}
+}
+
+// ReadAll reads all remaining events in the capture.
+func (cr *CaptureReader) ReadAll() ([]CaptureEvent, error) {
+	events := []CaptureEvent{}
+
+	for {
+		ce, err := cr.Next()
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return events, err
+		}
+
+		events = append(events, *ce)
+	}
+}
+
+// MergeCaptures reads all captures from inputs and writes a single capture
+// to w that contains all their devices and all their events in time order.
+func MergeCaptures(w io.Writer, inputs ...io.Reader) error {
+	devices := []CaptureDevice{}
+	events := []CaptureEvent{}
+
+	for i, in := range inputs {
+		cr, err := NewCaptureReader(in)
+		if err != nil {
+			return fmt.Errorf("Cannot read capture %d: %v", i, err)
+		}
+
+		all, err := cr.ReadAll()
+		if err != nil {
+			return fmt.Errorf("Cannot read capture %d: %v", i, err)
+		}
+
+		for _, ce := range all {
+			ce.Device += len(devices)
+			events = append(events, ce)
+		}
+
+		devices = append(devices, cr.Devices()...)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time < events[j].Time
+	})
+
+	cw, err := NewCaptureWriter(w)
+	if err != nil {
+		return err
+	}
+
+	for _, dev := range devices {
+		if _, err := cw.AddDevice(dev); err != nil {
+			return err
+		}
+	}
+
+	for i := range events {
+		if err := cw.Write(&events[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SplitCapture reads a capture from r and writes the events of each device
+// into a separate capture. The create function is called once per device to
+// obtain the writer for that device's capture.
+func SplitCapture(r io.Reader, create func(dev CaptureDevice) (io.Writer, error)) error {
+	cr, err := NewCaptureReader(r)
+	if err != nil {
+		return err
+	}
+
+	writers := []*CaptureWriter{}
+
+	// create outputs for all device records seen by the reader so far
+	addWriters := func() error {
+		for _, dev := range cr.Devices()[len(writers):] {
+			w, err := create(dev)
+			if err != nil {
+				return err
+			}
+
+			cw, err := NewCaptureWriter(w)
+			if err != nil {
+				return err
+			}
+
+			if _, err := cw.AddDevice(dev); err != nil {
+				return err
+			}
+
+			writers = append(writers, cw)
+		}
+
+		return nil
+	}
+
+	for {
+		ce, err := cr.Next()
+		if err == io.EOF {
+			return addWriters()
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := addWriters(); err != nil {
+			return err
+		}
+
+		out := *ce
+		out.Device = 0
+
+		if err := writers[ce.Device].Write(&out); err != nil {
+			return err
+		}
+	}
+}
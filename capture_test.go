@@ -0,0 +1,219 @@
+package evdev
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func writeTestCapture(t *testing.T, dev CaptureDevice, times ...int64) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+
+	cw, err := NewCaptureWriter(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err := cw.AddDevice(dev)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ts := range times {
+		err = cw.Write(&CaptureEvent{Device: idx, Time: ts, Type: EV_KEY, Code: KEY_A, Value: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return buf
+}
+
+func TestCaptureRoundTrip(t *testing.T) {
+	dev := CaptureDevice{
+		Name: "touchpad",
+		Phys: "usb-0000:00:14.0-1/input0",
+		Uniq: "0123",
+		ID:   InputID{BusType: 3, Vendor: 0x46d},
+		Capabilities: Capabilities{
+			Codes: map[EvType][]EvCode{
+				EV_KEY: {BTN_LEFT, BTN_TOOL_FINGER},
+				EV_ABS: {ABS_X, ABS_Y},
+			},
+			Props: []EvProp{PROP_POINTER, PROP_BUTTONPAD},
+		},
+		AbsInfos: map[EvCode]AbsInfo{
+			ABS_X: {Maximum: 1000, Resolution: 12},
+			ABS_Y: {Minimum: -5, Maximum: 500},
+		},
+	}
+	buf := writeTestCapture(t, dev, 1000, 2000)
+
+	cr, err := NewCaptureReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := cr.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []CaptureEvent{
+		{Device: 0, Time: 1000, Type: EV_KEY, Code: KEY_A, Value: 1},
+		{Device: 0, Time: 2000, Type: EV_KEY, Code: KEY_A, Value: 1},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("ReadAll() = %v, want %v", events, want)
+	}
+
+	if got := cr.Devices(); !reflect.DeepEqual(got, []CaptureDevice{dev}) {
+		t.Errorf("Devices() = %v, want %v", got, []CaptureDevice{dev})
+	}
+}
+
+func TestCaptureReaderVersion1(t *testing.T) {
+	buf := &bytes.Buffer{}
+	buf.Write([]byte{'E', 'V', 'C', 'A', 'P', 0, 0, 1})
+	buf.Write([]byte{captureRecordDevice, 3, 0, 0x6d, 0x04, 0, 0, 0, 0, 2, 0, 'k', 'b'})
+	buf.Write([]byte{captureRecordEvent, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 1, 0, 30, 0, 1, 0, 0, 0})
+
+	cr, err := NewCaptureReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := cr.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []CaptureEvent{{Time: 1, Type: EV_KEY, Code: KEY_A, Value: 1}}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("ReadAll() = %v, want %v", events, want)
+	}
+
+	dev := CaptureDevice{Name: "kb", ID: InputID{BusType: 3, Vendor: 0x46d}}
+	if got := cr.Devices(); !reflect.DeepEqual(got, []CaptureDevice{dev}) {
+		t.Errorf("Devices() = %v, want %v", got, []CaptureDevice{dev})
+	}
+}
+
+func TestCaptureReaderMalformed(t *testing.T) {
+	if _, err := NewCaptureReader(bytes.NewBufferString("garbage!")); !errors.Is(err, ErrCaptureFormat) {
+		t.Errorf("NewCaptureReader() error = %v, want %v", err, ErrCaptureFormat)
+	}
+
+	buf := &bytes.Buffer{}
+	buf.Write(captureMagic[:])
+	buf.Write([]byte{captureRecordEvent, 0, 0})
+
+	cr, err := NewCaptureReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cr.Next(); !errors.Is(err, ErrCaptureFormat) {
+		t.Errorf("Next() error = %v, want %v", err, ErrCaptureFormat)
+	}
+}
+
+func TestCaptureReaderLimits(t *testing.T) {
+	tests := []struct {
+		record []byte
+		reason string
+	}{
+		{[]byte{9}, "unknown record type 9"},
+		{[]byte{captureRecordEvent, 0, 0}, "truncated event record"},
+		{[]byte{captureRecordDevice, 0, 0}, "truncated device record"},
+		{
+			// 0x21 properties
+			[]byte{captureRecordDevice, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x21, 0},
+			"33 values exceed the limit of 32",
+		},
+		{
+			// one type, EV_CNT
+			[]byte{captureRecordDevice, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0x20, 0},
+			"invalid event type 32",
+		},
+		{
+			// EV_KEY twice
+			[]byte{captureRecordDevice, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2, 0, 1, 0, 0, 0, 1, 0, 0, 0},
+			"duplicate event type 1",
+		},
+		{
+			// 0x41 axes
+			[]byte{captureRecordDevice, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x41, 0},
+			"65 axes exceed the limit of 64",
+		},
+	}
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		buf.Write(captureMagic[:])
+		buf.Write(tt.record)
+
+		cr, err := NewCaptureReader(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = cr.Next()
+
+		fe, ok := err.(*CaptureFormatError)
+		if !ok {
+			t.Errorf("%v: error = %v, want a CaptureFormatError", tt.record, err)
+			continue
+		}
+
+		if fe.Offset != 8 || fe.Reason != tt.reason {
+			t.Errorf("%v: error = %+v, want reason %q at offset 8", tt.record, fe, tt.reason)
+		}
+	}
+}
+
+func TestMergeAndSplitCaptures(t *testing.T) {
+	a := CaptureDevice{Name: "a"}
+	b := CaptureDevice{Name: "b"}
+
+	merged := &bytes.Buffer{}
+	err := MergeCaptures(merged, writeTestCapture(t, a, 10, 30), writeTestCapture(t, b, 20))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cr, err := NewCaptureReader(bytes.NewReader(merged.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := cr.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	devices := []int{}
+	for _, e := range events {
+		devices = append(devices, e.Device)
+	}
+	if want := []int{0, 1, 0}; !reflect.DeepEqual(devices, want) {
+		t.Errorf("merged device order = %v, want %v", devices, want)
+	}
+
+	split := map[string]*bytes.Buffer{}
+	err = SplitCapture(bytes.NewReader(merged.Bytes()), func(dev CaptureDevice) (io.Writer, error) {
+		split[dev.Name] = &bytes.Buffer{}
+		return split[dev.Name], nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(split["a"].Bytes(), writeTestCapture(t, a, 10, 30).Bytes()) {
+		t.Errorf("split capture for device a differs from original")
+	}
+	if !bytes.Equal(split["b"].Bytes(), writeTestCapture(t, b, 20).Bytes()) {
+		t.Errorf("split capture for device b differs from original")
+	}
+}
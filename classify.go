@@ -0,0 +1,151 @@
+package evdev
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DeviceClass is a kind of input device, named like the ID_INPUT_*
+// properties udev assigns.
+type DeviceClass string
+
+// Device classes reported by Classify
+const (
+	ClassKeyboard      DeviceClass = "keyboard" // a keyboard with letter keys
+	ClassKey           DeviceClass = "key"      // any device with keys, e.g. a power button
+	ClassMouse         DeviceClass = "mouse"
+	ClassPointingStick DeviceClass = "pointingstick"
+	ClassTouchpad      DeviceClass = "touchpad"
+	ClassTouchscreen   DeviceClass = "touchscreen"
+	ClassTablet        DeviceClass = "tablet"
+	ClassTabletPad     DeviceClass = "tablet-pad"
+	ClassJoystick      DeviceClass = "joystick"
+	ClassAccelerometer DeviceClass = "accelerometer"
+	ClassSwitch        DeviceClass = "switch"
+)
+
+func (info *deviceInfo) has(t EvType, codes ...EvCode) bool {
+	supported, ok := info.codes[t]
+	if !ok {
+		return false
+	}
+
+	for _, c := range codes {
+		if !supported[c] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasRange returns true if the device supports any code of t from first to
+// last.
+func (info *deviceInfo) hasRange(t EvType, first, last EvCode) bool {
+	for c := range info.codes[t] {
+		if c >= first && c <= last {
+			return true
+		}
+	}
+
+	return false
+}
+
+// classify derives the classes of a device from its capabilities, using
+// the same heuristics as udev's input_id builtin.
+func classify(info *deviceInfo) []DeviceClass {
+	classes := []DeviceClass{}
+
+	if info.props[PROP_ACCELEROMETER] {
+		return append(classes, ClassAccelerometer)
+	}
+
+	stylus := info.has(EV_KEY, BTN_STYLUS) || info.has(EV_KEY, BTN_TOOL_PEN)
+	finger := info.has(EV_KEY, BTN_TOOL_FINGER)
+	direct := info.props[PROP_DIRECT]
+	joystickButtons := info.hasRange(EV_KEY, BTN_JOYSTICK, BTN_DIGI-1) || info.hasRange(EV_KEY, BTN_TRIGGER_HAPPY, BTN_TRIGGER_HAPPY+0x27)
+
+	switch {
+	case info.has(EV_ABS, ABS_X, ABS_Y) || info.has(EV_ABS, ABS_MT_POSITION_X, ABS_MT_POSITION_Y):
+		switch {
+		case stylus:
+			classes = append(classes, ClassTablet)
+		case finger && direct:
+			classes = append(classes, ClassTouchscreen)
+		case finger:
+			classes = append(classes, ClassTouchpad)
+		case info.has(EV_KEY, BTN_LEFT):
+			// e.g. the absolute pointer of a virtual machine
+			classes = append(classes, ClassMouse)
+		case info.has(EV_KEY, BTN_TOUCH) || direct:
+			classes = append(classes, ClassTouchscreen)
+		case info.has(EV_KEY, BTN_0) && !joystickButtons:
+			classes = append(classes, ClassTabletPad)
+		case joystickButtons || info.has(EV_ABS, ABS_RX) || info.has(EV_ABS, ABS_THROTTLE):
+			classes = append(classes, ClassJoystick)
+		}
+
+	case info.has(EV_ABS, ABS_WHEEL) && info.has(EV_KEY, BTN_0):
+		classes = append(classes, ClassTabletPad)
+
+	case info.has(EV_REL, REL_X, REL_Y) && info.has(EV_KEY, BTN_LEFT):
+		if info.props[PROP_POINTING_STICK] {
+			classes = append(classes, ClassPointingStick)
+		} else {
+			classes = append(classes, ClassMouse)
+		}
+
+	case joystickButtons:
+		classes = append(classes, ClassJoystick)
+	}
+
+	if info.hasRange(EV_KEY, KEY_ESC, BTN_MISC-1) || info.hasRange(EV_KEY, KEY_OK, BTN_TRIGGER_HAPPY-1) {
+		classes = append(classes, ClassKey)
+	}
+
+	// udev requires the keys from KEY_ESC to KEY_S
+	keyboard := true
+	for c := EvCode(KEY_ESC); c <= KEY_S; c++ {
+		if !info.has(EV_KEY, c) {
+			keyboard = false
+			break
+		}
+	}
+
+	if keyboard {
+		classes = append(classes, ClassKeyboard)
+	}
+
+	if _, ok := info.codes[EV_SW]; ok {
+		classes = append(classes, ClassSwitch)
+	}
+
+	return classes
+}
+
+// Classify returns the classes of the device, derived from its
+// capabilities. A device can have several classes, e.g. a keyboard with a
+// touchpad, or none.
+func (d *InputDevice) Classify() []DeviceClass {
+	return classify(deviceInfoOf(d))
+}
+
+// ListDevicePaths returns the paths of the event device nodes in
+// /dev/input, ordered by their number.
+func ListDevicePaths() ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(devInputDir, "event*"))
+	if err != nil {
+		return nil, err
+	}
+
+	number := func(path string) int {
+		n, _ := strconv.Atoi(strings.TrimPrefix(filepath.Base(path), "event"))
+		return n
+	}
+
+	sort.Slice(paths, func(i, j int) bool { return number(paths[i]) < number(paths[j]) })
+
+	return paths, nil
+}
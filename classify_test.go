@@ -0,0 +1,96 @@
+package evdev
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func infoWith(props []EvProp, codes map[EvType][]EvCode) *deviceInfo {
+	info := &deviceInfo{
+		codes: map[EvType]map[EvCode]bool{},
+		props: map[EvProp]bool{},
+	}
+
+	for t, cs := range codes {
+		info.codes[t] = map[EvCode]bool{}
+		for _, c := range cs {
+			info.codes[t][c] = true
+		}
+	}
+
+	for _, p := range props {
+		info.props[p] = true
+	}
+
+	return info
+}
+
+func TestClassify(t *testing.T) {
+	keyboard := []EvCode{}
+	for c := EvCode(KEY_ESC); c <= KEY_SPACE; c++ {
+		keyboard = append(keyboard, c)
+	}
+
+	tests := []struct {
+		name string
+		info *deviceInfo
+		want []DeviceClass
+	}{
+		{"keyboard", infoWith(nil, map[EvType][]EvCode{EV_KEY: keyboard, EV_LED: {LED_CAPSL}}), []DeviceClass{ClassKey, ClassKeyboard}},
+		{"power button", infoWith(nil, map[EvType][]EvCode{EV_KEY: {KEY_POWER}}), []DeviceClass{ClassKey}},
+		{"mouse", infoWith(nil, map[EvType][]EvCode{EV_REL: {REL_X, REL_Y, REL_WHEEL}, EV_KEY: {BTN_LEFT, BTN_RIGHT}}), []DeviceClass{ClassMouse}},
+		{"pointing stick", infoWith([]EvProp{PROP_POINTING_STICK}, map[EvType][]EvCode{EV_REL: {REL_X, REL_Y}, EV_KEY: {BTN_LEFT}}), []DeviceClass{ClassPointingStick}},
+		{"touchpad", infoWith([]EvProp{PROP_POINTER}, map[EvType][]EvCode{EV_ABS: {ABS_X, ABS_Y}, EV_KEY: {BTN_LEFT, BTN_TOOL_FINGER, BTN_TOUCH}}), []DeviceClass{ClassTouchpad}},
+		{"touchscreen", infoWith([]EvProp{PROP_DIRECT}, map[EvType][]EvCode{EV_ABS: {ABS_MT_POSITION_X, ABS_MT_POSITION_Y}, EV_KEY: {BTN_TOUCH}}), []DeviceClass{ClassTouchscreen}},
+		{"tablet", infoWith(nil, map[EvType][]EvCode{EV_ABS: {ABS_X, ABS_Y, ABS_PRESSURE}, EV_KEY: {BTN_TOOL_PEN, BTN_STYLUS, BTN_TOUCH}}), []DeviceClass{ClassTablet}},
+		{"tablet pad", infoWith(nil, map[EvType][]EvCode{EV_ABS: {ABS_X, ABS_Y, ABS_WHEEL}, EV_KEY: {BTN_0, BTN_1}}), []DeviceClass{ClassTabletPad}},
+		{"gamepad", infoWith(nil, map[EvType][]EvCode{EV_ABS: {ABS_X, ABS_Y, ABS_RX, ABS_RY}, EV_KEY: {BTN_SOUTH, BTN_EAST, BTN_START}}), []DeviceClass{ClassJoystick}},
+		{"accelerometer", infoWith([]EvProp{PROP_ACCELEROMETER}, map[EvType][]EvCode{EV_ABS: {ABS_X, ABS_Y, ABS_Z}}), []DeviceClass{ClassAccelerometer}},
+		{"lid switch", infoWith(nil, map[EvType][]EvCode{EV_SW: {SW_LID}}), []DeviceClass{ClassSwitch}},
+	}
+
+	for _, tt := range tests {
+		if got := classify(tt.info); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: classify() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	m, err := ParseMatcher(`Class("tablet-pad") || Class("joystick")`)
+	if err != nil {
+		t.Fatalf("ParseMatcher() error = %v", err)
+	}
+
+	if !m.match(tests[7].info) || !m.match(tests[8].info) || m.match(tests[0].info) {
+		t.Errorf("%q matches the wrong devices", m)
+	}
+}
+
+func TestListDevicePaths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "input")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	defer func(old string) { devInputDir = old }(devInputDir)
+	devInputDir = dir
+
+	for _, name := range []string{"event10", "event2", "mice", "event0"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths, err := ListDevicePaths()
+	if err != nil {
+		t.Fatalf("ListDevicePaths() error = %v", err)
+	}
+
+	want := []string{filepath.Join(dir, "event0"), filepath.Join(dir, "event2"), filepath.Join(dir, "event10")}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("ListDevicePaths() = %v, want %v", paths, want)
+	}
+}
@@ -0,0 +1,116 @@
+package evdev
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// Cleanup owns a set of devices and guarantees that their grabs are released
+// and they are closed when the program receives a termination signal. A
+// leaked grab leaves the device unusable for everyone else.
+type Cleanup struct {
+	mu      sync.Mutex
+	devices []*InputDevice
+	funcs   []func()
+	signals chan os.Signal
+	done    bool
+}
+
+// CloseOnSignal returns a Cleanup that runs when one of the given signals is
+// received, or on SIGINT and SIGTERM if none are given. After cleaning up,
+// the signal is re-raised with its default disposition, so the program
+// terminates as it would have without the handler.
+func CloseOnSignal(signals ...os.Signal) *Cleanup {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+
+	c := &Cleanup{
+		signals: make(chan os.Signal, 1),
+	}
+
+	signal.Notify(c.signals, signals...)
+
+	go func() {
+		sig, ok := <-c.signals
+		if !ok {
+			return
+		}
+
+		c.Close()
+
+		signal.Reset(sig)
+
+		if s, ok := sig.(syscall.Signal); ok {
+			syscall.Kill(os.Getpid(), s)
+		}
+	}()
+
+	return c
+}
+
+// Add hands the ownership of d to the Cleanup. If the Cleanup has already
+// run, d is released immediately.
+func (c *Cleanup) Add(d *InputDevice) {
+	c.mu.Lock()
+
+	if c.done {
+		c.mu.Unlock()
+		d.Ungrab()
+		d.Close()
+		return
+	}
+
+	c.devices = append(c.devices, d)
+	c.mu.Unlock()
+}
+
+// AddFunc registers a function that runs after all devices have been
+// released, e.g. to tear down virtual devices. Functions run in the order
+// they were added. If the Cleanup has already run, f runs immediately.
+func (c *Cleanup) AddFunc(f func()) {
+	c.mu.Lock()
+
+	if c.done {
+		c.mu.Unlock()
+		f()
+		return
+	}
+
+	c.funcs = append(c.funcs, f)
+	c.mu.Unlock()
+}
+
+// Close ungrabs and closes all devices and runs the registered functions.
+// It is safe to call Close more than once, only the first call has an effect.
+// Signals are no longer watched afterwards.
+func (c *Cleanup) Close() {
+	c.mu.Lock()
+
+	if c.done {
+		c.mu.Unlock()
+		return
+	}
+
+	c.done = true
+	signal.Stop(c.signals)
+	close(c.signals)
+
+	devices := c.devices
+	funcs := c.funcs
+	c.devices = nil
+	c.funcs = nil
+
+	c.mu.Unlock()
+
+	for _, d := range devices {
+		d.Ungrab()
+		d.Close()
+	}
+
+	for _, f := range funcs {
+		f()
+	}
+}
@@ -0,0 +1,28 @@
+package evdev
+
+import (
+	"reflect"
+	"syscall"
+	"testing"
+)
+
+func TestCleanup(t *testing.T) {
+	c := CloseOnSignal(syscall.SIGUSR1)
+
+	order := []int{}
+	c.AddFunc(func() { order = append(order, 1) })
+	c.AddFunc(func() {
+		order = append(order, 2)
+
+		// registering from within a cleanup function must not deadlock
+		c.AddFunc(func() { order = append(order, 4) })
+	})
+	c.AddFunc(func() { order = append(order, 3) })
+
+	c.Close()
+	c.Close()
+
+	if want := []int{1, 2, 4, 3}; !reflect.DeepEqual(order, want) {
+		t.Errorf("functions ran in order %v, want %v", order, want)
+	}
+}
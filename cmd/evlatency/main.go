@@ -0,0 +1,147 @@
+// Command evlatency measures the time from the kernel timestamp of input
+// frames until they have been read and passed through a chain of
+// transforms, and prints percentiles and a histogram.
+//
+//	evlatency -device /dev/input/event5 [-transforms chain.json] [-n 1000]
+//	evlatency -loopback [-rate 500] [-transforms chain.json] [-n 1000]
+//
+// With -device, frames are read from a device while it is used. With
+// -loopback, key presses are injected into a virtual keyboard at the given
+// rate and read back. The transform chain is a JSON array of transforms,
+// such as [{"name": "script", "config": "..."}]. Frames it drops are not
+// measured.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/neodaemmerung/go-evdev"
+)
+
+func loadChain(path string) (evdev.Transform, error) {
+	if path == "" {
+		return evdev.Chain(), nil
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	specs := []evdev.TransformSpec{}
+	if err := json.Unmarshal(b, &specs); err != nil {
+		return nil, fmt.Errorf("Cannot parse %s: %v", path, err)
+	}
+
+	return evdev.NewTransformChain(specs)
+}
+
+// openLoopback creates a virtual keyboard and opens its event device node.
+func openLoopback() (*evdev.UInputDevice, *evdev.InputDevice, error) {
+	u, err := evdev.NewUInputBuilder("evlatency keyboard").Keyboard().Create()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	path, err := u.DevicePath(5 * time.Second)
+	if err != nil {
+		u.Close()
+		return nil, nil, err
+	}
+
+	d, err := evdev.Open(path)
+	if err != nil {
+		u.Close()
+		return nil, nil, err
+	}
+
+	return u, d, nil
+}
+
+// inject presses and releases a key n times at the given rate.
+func inject(u *evdev.UInputDevice, n int, rate float64) {
+	frames := []evdev.ScheduledFrame{}
+	interval := time.Duration(float64(time.Second) / rate)
+
+	for i := 0; i < n; i++ {
+		frames = append(frames, evdev.ScheduledFrame{
+			At: time.Duration(i) * interval,
+			Events: []evdev.InputEvent{
+				{Type: evdev.EV_KEY, Code: evdev.KEY_F24, Value: int32(1 - i%2)},
+				{Type: evdev.EV_SYN, Code: evdev.SYN_REPORT},
+			},
+		})
+	}
+
+	if _, err := evdev.NewScheduler(u.Write).Run(context.Background(), frames); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot inject events: %v\n", err)
+	}
+}
+
+func main() {
+	device := flag.String("device", "", "measure the frames of the device `node`")
+	loopback := flag.Bool("loopback", false, "measure frames injected into a virtual keyboard")
+	rate := flag.Float64("rate", 500, "frames per second injected with -loopback")
+	chainPath := flag.String("transforms", "", "pass the frames through the transform chain in `file`")
+	n := flag.Int("n", 1000, "number of frames to measure")
+	bucket := flag.Duration("bucket", 100*time.Microsecond, "width of the histogram buckets")
+	flag.Parse()
+
+	if (*device == "") == !*loopback || *n <= 0 || *rate <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	chain, err := loadChain(*chainPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	var d *evdev.InputDevice
+
+	if *loopback {
+		var u *evdev.UInputDevice
+
+		if u, d, err = openLoopback(); err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot create virtual keyboard: %v\n", err)
+			os.Exit(1)
+		}
+		defer u.Close()
+
+		go inject(u, *n, *rate)
+	} else if d, err = evdev.OpenReadOnly(*device); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot open %s: %v\n", *device, err)
+		os.Exit(1)
+	}
+	defer d.Close()
+
+	fa := evdev.NewFrameAssembler(evdev.FramePassThrough)
+	lr := evdev.NewLatencyRecorder()
+
+	for lr.Count() < *n {
+		events, err := d.Read()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot read: %v\n", err)
+			break
+		}
+
+		for _, e := range events {
+			for _, f := range fa.Push(e) {
+				if len(chain.ProcessFrame(f)) == 0 {
+					continue
+				}
+
+				lr.AddFrame(f, time.Now())
+			}
+		}
+	}
+
+	lr.WriteSummary(os.Stdout, *bucket)
+}
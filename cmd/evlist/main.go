@@ -0,0 +1,131 @@
+// Command evlist lists input devices with their identity, classes and
+// capabilities, optionally filtered by a matcher expression.
+//
+//	evlist [-json] [-match 'Class("keyboard") && Bus==0x03']
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/neodaemmerung/go-evdev"
+)
+
+type deviceID struct {
+	Bus     uint16 `json:"bus"`
+	Vendor  uint16 `json:"vendor"`
+	Product uint16 `json:"product"`
+	Version uint16 `json:"version"`
+}
+
+type device struct {
+	Path         string              `json:"path"`
+	Name         string              `json:"name"`
+	Phys         string              `json:"phys"`
+	Uniq         string              `json:"uniq"`
+	Seat         string              `json:"seat"`
+	ID           deviceID            `json:"id"`
+	Classes      []string            `json:"classes"`
+	Capabilities map[string][]string `json:"capabilities"`
+	Properties   []string            `json:"properties"`
+}
+
+func describe(d *evdev.InputDevice) device {
+	info := device{
+		Path:         d.Path(),
+		Classes:      []string{},
+		Capabilities: map[string][]string{},
+		Properties:   []string{},
+	}
+
+	info.Name, _ = d.Name()
+	info.Phys, _ = d.PhysicalLocation()
+	info.Uniq, _ = d.UniqueID()
+	info.Seat, _ = d.Seat()
+
+	if id, err := d.InputID(); err == nil {
+		info.ID = deviceID{id.BusType, id.Vendor, id.Product, id.Version}
+	}
+
+	for _, c := range d.Classify() {
+		info.Classes = append(info.Classes, string(c))
+	}
+
+	caps := d.Capabilities()
+
+	for t, codes := range caps.Codes {
+		names := []string{}
+		for _, c := range codes {
+			names = append(names, evdev.CodeName(t, c))
+		}
+
+		info.Capabilities[evdev.TypeName(t)] = names
+	}
+
+	for _, p := range caps.Props {
+		info.Properties = append(info.Properties, evdev.PropName(p))
+	}
+
+	sort.Strings(info.Properties)
+
+	return info
+}
+
+func main() {
+	asJSON := flag.Bool("json", false, "print the devices as JSON")
+	match := flag.String("match", "", "only list devices matching the `expression`")
+	flag.Parse()
+
+	var m *evdev.Matcher
+
+	if *match != "" {
+		var err error
+
+		if m, err = evdev.ParseMatcher(*match); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid matcher: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	paths, err := evdev.ListDevicePaths()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot list devices: %v\n", err)
+		os.Exit(1)
+	}
+
+	devices := []device{}
+
+	for _, path := range paths {
+		d, err := evdev.OpenReadOnly(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot open %s: %v\n", path, err)
+			continue
+		}
+
+		if m == nil || m.Match(d) {
+			devices = append(devices, describe(d))
+		}
+
+		d.Close()
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(devices); err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot write devices: %v\n", err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	for _, d := range devices {
+		fmt.Printf("%s\t%04x:%04x:%04x\t%s\t%s\n", d.Path, d.ID.Bus, d.ID.Vendor, d.ID.Product, strings.Join(d.Classes, ","), d.Name)
+	}
+}
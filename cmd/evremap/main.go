@@ -0,0 +1,342 @@
+// Command evremap grabs input devices and passes their events through chains
+// of transforms to virtual devices, as configured by a JSON file such as
+//
+//	{
+//	  "devices": [
+//	    {
+//	      "match": "Class(\"keyboard\") && Bus==0x11",
+//	      "name": "remapped keyboard",
+//	      "transforms": [
+//	        {"name": "script", "config": "type == EV_KEY && code == KEY_CAPSLOCK -> code = KEY_ESC"}
+//	      ]
+//	    }
+//	  ]
+//	}
+//
+// The transforms are reloaded when the file changes or on SIGHUP. Changes to
+// the devices themselves require a restart.
+//
+// The quirks of the devices are applied before their transforms, including
+// those loaded with -quirks, see evdev.LoadQuirks. With -settings, the
+// settings stored for the devices are applied as well, see
+// evdev.SettingsStore.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/neodaemmerung/go-evdev"
+)
+
+type deviceConfig struct {
+	Match      string                `json:"match"`
+	Name       string                `json:"name"`
+	Transforms []evdev.TransformSpec `json:"transforms"`
+}
+
+type config struct {
+	Devices []deviceConfig `json:"devices"`
+}
+
+func loadConfig(path string) (*config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &config{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("Cannot parse %s: %v", path, err)
+	}
+
+	return c, nil
+}
+
+func loadQuirks(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := evdev.LoadQuirks(f); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	return nil
+}
+
+// buildChains creates the transform chains of all devices, so a reload only
+// takes effect if all of them are valid.
+func buildChains(c *config) ([]evdev.Transform, error) {
+	chains := []evdev.Transform{}
+
+	for i, dc := range c.Devices {
+		chain, err := evdev.NewTransformChain(dc.Transforms)
+		if err != nil {
+			return nil, fmt.Errorf("Device %d: %v", i, err)
+		}
+
+		chains = append(chains, chain)
+	}
+
+	return chains, nil
+}
+
+// remap is a grabbed device and the virtual device its events are passed
+// on to.
+type remap struct {
+	in   *evdev.InputDevice
+	out  *evdev.UInputDevice
+	t    *evdev.ReloadableTransform
+	p    *evdev.Pipeline
+	done chan struct{} // closed when the pipeline ended, nil if it never ran
+}
+
+func findDevice(expr string, taken map[string]bool) (*evdev.InputDevice, error) {
+	m, err := evdev.ParseMatcher(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := evdev.ListDevicePaths()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		if taken[path] {
+			continue
+		}
+
+		d, err := evdev.Open(path)
+		if err != nil {
+			continue
+		}
+
+		if m.Match(d) {
+			return d, nil
+		}
+
+		d.Close()
+	}
+
+	return nil, fmt.Errorf("No device matches %s", m)
+}
+
+func setup(dc deviceConfig, chain evdev.Transform, store *evdev.SettingsStore, taken map[string]bool) (*remap, error) {
+	in, err := findDevice(dc.Match, taken)
+	if err != nil {
+		return nil, err
+	}
+
+	name := dc.Name
+	if name == "" {
+		devName, _ := in.Name()
+		name = devName + " (remapped)"
+	}
+
+	id, err := in.InputID()
+	if err != nil {
+		in.Close()
+		return nil, err
+	}
+
+	for _, q := range evdev.QuirksFor(id) {
+		fmt.Fprintf(os.Stderr, "Applying quirk %q to %s\n", q.Name, in.Path())
+	}
+
+	quirks, err := evdev.NewQuirkTransform(id)
+	if err != nil {
+		in.Close()
+		return nil, err
+	}
+
+	if store != nil {
+		settings, err := store.ApplyFor(in)
+		if err != nil {
+			in.Close()
+			return nil, fmt.Errorf("%s: %v", in.Path(), err)
+		}
+
+		quirks = evdev.Chain(quirks, settings)
+	}
+
+	b, err := evdev.NewUInputBuilderFrom(in, name)
+	if err != nil {
+		in.Close()
+		return nil, err
+	}
+
+	if err := in.Grab(); err != nil {
+		in.Close()
+		return nil, fmt.Errorf("Cannot grab %s: %v", in.Path(), err)
+	}
+
+	out, err := b.Create()
+	if err != nil {
+		in.Close()
+		return nil, err
+	}
+
+	taken[in.Path()] = true
+
+	r := &remap{in: in, out: out, t: evdev.NewReloadableTransform(chain)}
+
+	// the pipeline releases keys held on the virtual device and ungrabs the
+	// input device before destroying the virtual device on shutdown. The
+	// queue blocks rather than dropping events, so no key release is lost.
+	r.p, err = evdev.NewPipelineBuilder().
+		Device(in.Path(), in).
+		Transform("quirks", quirks).
+		Transform("transforms", r.t).
+		Sink(name, out.Sink(), evdev.SinkOptions{QueueSize: 256, Overflow: evdev.OverflowBlock, Required: true}).
+		Build()
+	if err != nil {
+		r.close()
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *remap) start(errs chan<- error) {
+	r.done = make(chan struct{})
+
+	go func() {
+		defer close(r.done)
+
+		if err := r.p.Run(); err != nil {
+			errs <- fmt.Errorf("Cannot read %s: %v", r.in.Path(), err)
+		}
+	}()
+}
+
+func (r *remap) close() {
+	if r.done == nil {
+		r.out.Close()
+		r.in.Ungrab()
+		r.in.Close()
+
+		return
+	}
+
+	r.p.Stop()
+	<-r.done
+}
+
+func main() {
+	path := flag.String("config", "", "the configuration `file`")
+	interval := flag.Duration("watch", time.Second, "how often to check the configuration file for changes, 0 to disable")
+	quirksPath := flag.String("quirks", "", "a JSON `file` with additional device quirks")
+	settingsDir := flag.String("settings", "", "the `directory` of stored device settings to apply")
+	flag.Parse()
+
+	if *path == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *quirksPath != "" {
+		if err := loadQuirks(*quirksPath); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	c, err := loadConfig(*path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	chains, err := buildChains(c)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	var store *evdev.SettingsStore
+	if *settingsDir != "" {
+		store = evdev.NewSettingsStore(*settingsDir)
+	}
+
+	remaps := []*remap{}
+	taken := map[string]bool{}
+
+	closeAll := func() {
+		for _, r := range remaps {
+			r.close()
+		}
+	}
+
+	for i, dc := range c.Devices {
+		r, err := setup(dc, chains[i], store, taken)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			closeAll()
+			os.Exit(1)
+		}
+
+		remaps = append(remaps, r)
+	}
+
+	reloader := evdev.NewReloader(func() error {
+		c, err := loadConfig(*path)
+		if err != nil {
+			return err
+		}
+
+		if len(c.Devices) != len(remaps) {
+			return fmt.Errorf("The number of devices changed, restart to apply it")
+		}
+
+		chains, err := buildChains(c)
+		if err != nil {
+			return err
+		}
+
+		for i, r := range remaps {
+			r.t.Swap(chains[i])
+		}
+
+		fmt.Fprintf(os.Stderr, "Reloaded %s\n", *path)
+
+		return nil
+	}, func(err error) {
+		fmt.Fprintf(os.Stderr, "Cannot reload: %v\n", err)
+	})
+
+	reloader.OnSignal()
+	if *interval > 0 {
+		reloader.WatchFile(*path, *interval)
+	}
+
+	errs := make(chan error, len(remaps))
+	for _, r := range remaps {
+		r.start(errs)
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	exit := 0
+
+	select {
+	case <-stop:
+	case err := <-errs:
+		fmt.Fprintln(os.Stderr, err)
+		exit = 1
+	}
+
+	reloader.Stop()
+	closeAll()
+	os.Exit(exit)
+}
@@ -0,0 +1,290 @@
+// Command evsend injects events into a device, or into a new virtual device,
+// from its arguments, e.g. to script desktop actions.
+//
+//	evsend [-device /dev/input/event5] [-delay 10ms] COMMAND...
+//
+// Each command sends one frame, except key, which sends a press and a
+// release:
+//
+//	key CODE            press and release a key or button
+//	press CODE          press a key or button
+//	release CODE        release a key or button
+//	rel CODE VALUE      relative motion, e.g. rel REL_WHEEL -1
+//	abs CODE VALUE      absolute position
+//	raw TYPE CODE VALUE any event, e.g. raw EV_SW SW_LID 1
+//	sleep DURATION      wait, e.g. sleep 100ms
+//
+// Without -device, a virtual device with the codes used by the commands is
+// created, and removed after the events were sent.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/neodaemmerung/go-evdev"
+)
+
+// step is a frame to send, or a pause if frame is nil.
+type step struct {
+	frame []evdev.InputEvent
+	sleep time.Duration
+}
+
+func parseCode(t evdev.EvType, name string) (evdev.EvCode, error) {
+	if c, ok := evdev.CodeByName(t, name); ok {
+		return c, nil
+	}
+
+	n, err := strconv.ParseUint(name, 0, 16)
+	if err != nil {
+		return 0, fmt.Errorf("Unknown %s code %q", evdev.TypeName(t), name)
+	}
+
+	return evdev.EvCode(n), nil
+}
+
+func parseValue(s string) (int32, error) {
+	v, err := strconv.ParseInt(s, 0, 32)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid value %q", s)
+	}
+
+	return int32(v), nil
+}
+
+func event(t evdev.EvType, c evdev.EvCode, v int32) step {
+	return step{frame: []evdev.InputEvent{
+		{Type: t, Code: c, Value: v},
+		{Type: evdev.EV_SYN, Code: evdev.SYN_REPORT},
+	}}
+}
+
+// parseCommands parses the command line into steps.
+func parseCommands(args []string) ([]step, error) {
+	steps := []step{}
+
+	need := func(n int) error {
+		if len(args) < n+1 {
+			return fmt.Errorf("%s needs %d arguments", args[0], n)
+		}
+
+		return nil
+	}
+
+	for len(args) > 0 {
+		var err error
+		n := 0
+
+		switch args[0] {
+		case "key", "press", "release":
+			if err = need(1); err != nil {
+				break
+			}
+
+			n = 1
+
+			var c evdev.EvCode
+			if c, err = parseCode(evdev.EV_KEY, args[1]); err != nil {
+				break
+			}
+
+			if args[0] != "release" {
+				steps = append(steps, event(evdev.EV_KEY, c, 1))
+			}
+
+			if args[0] != "press" {
+				steps = append(steps, event(evdev.EV_KEY, c, 0))
+			}
+
+		case "rel", "abs", "raw":
+			t := evdev.EvType(evdev.EV_REL)
+			if args[0] == "abs" {
+				t = evdev.EV_ABS
+			}
+
+			n = 2
+			if args[0] == "raw" {
+				n = 3
+			}
+
+			if err = need(n); err != nil {
+				break
+			}
+
+			fields := args[1 : n+1]
+
+			if args[0] == "raw" {
+				var ok bool
+				if t, ok = evdev.TypeByName(fields[0]); !ok {
+					err = fmt.Errorf("Unknown event type %q", fields[0])
+					break
+				}
+
+				fields = fields[1:]
+			}
+
+			var c evdev.EvCode
+			var v int32
+
+			if c, err = parseCode(t, fields[0]); err != nil {
+				break
+			}
+
+			if v, err = parseValue(fields[1]); err != nil {
+				break
+			}
+
+			steps = append(steps, event(t, c, v))
+
+		case "sleep":
+			if err = need(1); err != nil {
+				break
+			}
+
+			n = 1
+
+			var d time.Duration
+			if d, err = time.ParseDuration(args[1]); err != nil {
+				break
+			}
+
+			steps = append(steps, step{sleep: d})
+
+		default:
+			err = fmt.Errorf("Unknown command %q", args[0])
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		args = args[n+1:]
+	}
+
+	return steps, nil
+}
+
+// builderFor returns a builder for a virtual device supporting the events
+// of steps.
+func builderFor(steps []step) *evdev.UInputBuilder {
+	b := evdev.NewUInputBuilder("evsend").WithID(evdev.InputID{BusType: evdev.BUS_VIRTUAL})
+	abs := map[evdev.EvCode]evdev.AbsInfo{}
+
+	for _, s := range steps {
+		for _, e := range s.frame {
+			switch e.Type {
+			case evdev.EV_SYN:
+			case evdev.EV_ABS:
+				info, ok := abs[e.Code]
+				if !ok {
+					info = evdev.AbsInfo{Maximum: 65535}
+				}
+
+				if e.Value < info.Minimum {
+					info.Minimum = e.Value
+				}
+
+				if e.Value > info.Maximum {
+					info.Maximum = e.Value
+				}
+
+				abs[e.Code] = info
+			default:
+				b.WithCodes(e.Type, e.Code)
+			}
+		}
+	}
+
+	for c, info := range abs {
+		b.WithAbs(c, info)
+	}
+
+	return b
+}
+
+func main() {
+	device := flag.String("device", "", "inject into the device `node` instead of a virtual device")
+	delay := flag.Duration("delay", 10*time.Millisecond, "pause between frames")
+	settle := flag.Duration("settle", 500*time.Millisecond, "time a new virtual device is given to be picked up before and after sending")
+	flag.Parse()
+
+	steps, err := parseCommands(flag.Args())
+	if err != nil || len(steps) == 0 {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var write func(events ...evdev.InputEvent) error
+	var done func()
+
+	if *device != "" {
+		d, err := evdev.OpenReadWrite(*device)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot open %s: %v\n", *device, err)
+			os.Exit(1)
+		}
+
+		done = d.Close
+		write = func(events ...evdev.InputEvent) error {
+			for i := range events {
+				if err := d.WriteOne(&events[i]); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+	} else {
+		u, err := builderFor(steps).Create()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot create virtual device: %v\n", err)
+			os.Exit(1)
+		}
+
+		// give e.g. the compositor time to open the device, and to process
+		// the events before it disappears
+		time.Sleep(*settle)
+		done = func() {
+			time.Sleep(*settle)
+			u.Close()
+		}
+		write = u.Write
+	}
+
+	err = send(steps, write, *delay)
+	done()
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot send events: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// send writes the frames of steps with write, delay apart, and sleeps as
+// the steps say.
+func send(steps []step, write func(events ...evdev.InputEvent) error, delay time.Duration) error {
+	for i, s := range steps {
+		if s.frame == nil {
+			time.Sleep(s.sleep)
+			continue
+		}
+
+		if i > 0 && steps[i-1].frame != nil {
+			time.Sleep(delay)
+		}
+
+		if err := write(s.frame...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
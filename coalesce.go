@@ -0,0 +1,64 @@
+package evdev
+
+// Coalesce merges redundant EV_REL and EV_ABS events in events into one
+// event per code. Relative values are summed, absolute values are replaced
+// by the last one. The merged event takes the position of the first and the
+// timestamp of the last event it replaces. Relative motion that sums up to
+// zero is dropped.
+//
+// Events are never merged across EV_SYN events or ABS_MT_SLOT changes, so
+// frames and multitouch slots stay intact. All other events are passed
+// through unchanged.
+func Coalesce(events []InputEvent) []InputEvent {
+	out := make([]InputEvent, 0, len(events))
+
+	type key struct {
+		t EvType
+		c EvCode
+	}
+
+	// index into out for the codes seen since the last barrier
+	seen := map[key]int{}
+
+	for _, e := range events {
+		if e.Type == EV_SYN || (e.Type == EV_ABS && e.Code == ABS_MT_SLOT) {
+			seen = map[key]int{}
+			out = append(out, e)
+			continue
+		}
+
+		if e.Type != EV_REL && e.Type != EV_ABS {
+			out = append(out, e)
+			continue
+		}
+
+		k := key{e.Type, e.Code}
+
+		i, ok := seen[k]
+		if !ok {
+			seen[k] = len(out)
+			out = append(out, e)
+			continue
+		}
+
+		if e.Type == EV_REL {
+			out[i].Value += e.Value
+		} else {
+			out[i].Value = e.Value
+		}
+
+		out[i].Time = e.Time
+	}
+
+	filtered := out[:0]
+
+	for _, e := range out {
+		if e.Type == EV_REL && e.Value == 0 {
+			continue
+		}
+
+		filtered = append(filtered, e)
+	}
+
+	return filtered
+}
@@ -0,0 +1,76 @@
+package evdev
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCoalesce(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []InputEvent
+		want   []InputEvent
+	}{
+		{
+			name: "rel summed",
+			events: []InputEvent{
+				{Type: EV_REL, Code: REL_X, Value: 1},
+				{Type: EV_REL, Code: REL_Y, Value: 2},
+				{Type: EV_REL, Code: REL_X, Value: 3},
+				{Type: EV_SYN, Code: SYN_REPORT},
+			},
+			want: []InputEvent{
+				{Type: EV_REL, Code: REL_X, Value: 4},
+				{Type: EV_REL, Code: REL_Y, Value: 2},
+				{Type: EV_SYN, Code: SYN_REPORT},
+			},
+		},
+		{
+			name: "rel zero sum dropped",
+			events: []InputEvent{
+				{Type: EV_REL, Code: REL_X, Value: 1},
+				{Type: EV_REL, Code: REL_X, Value: -1},
+				{Type: EV_KEY, Code: BTN_LEFT, Value: 1},
+			},
+			want: []InputEvent{
+				{Type: EV_KEY, Code: BTN_LEFT, Value: 1},
+			},
+		},
+		{
+			name: "abs last wins",
+			events: []InputEvent{
+				{Type: EV_ABS, Code: ABS_X, Value: 10},
+				{Type: EV_ABS, Code: ABS_X, Value: 20},
+			},
+			want: []InputEvent{
+				{Type: EV_ABS, Code: ABS_X, Value: 20},
+			},
+		},
+		{
+			name: "no merge across frames and slots",
+			events: []InputEvent{
+				{Type: EV_ABS, Code: ABS_MT_SLOT, Value: 0},
+				{Type: EV_ABS, Code: ABS_MT_POSITION_X, Value: 1},
+				{Type: EV_ABS, Code: ABS_MT_SLOT, Value: 1},
+				{Type: EV_ABS, Code: ABS_MT_POSITION_X, Value: 2},
+				{Type: EV_SYN, Code: SYN_REPORT},
+				{Type: EV_ABS, Code: ABS_MT_POSITION_X, Value: 3},
+			},
+			want: []InputEvent{
+				{Type: EV_ABS, Code: ABS_MT_SLOT, Value: 0},
+				{Type: EV_ABS, Code: ABS_MT_POSITION_X, Value: 1},
+				{Type: EV_ABS, Code: ABS_MT_SLOT, Value: 1},
+				{Type: EV_ABS, Code: ABS_MT_POSITION_X, Value: 2},
+				{Type: EV_SYN, Code: SYN_REPORT},
+				{Type: EV_ABS, Code: ABS_MT_POSITION_X, Value: 3},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Coalesce(tt.events); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Coalesce() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
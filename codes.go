@@ -34,6 +34,7 @@ const (
 	ABS_MT_WIDTH_MAJOR = 0x32
 	ABS_MT_WIDTH_MINOR = 0x33
 	ABS_PRESSURE       = 0x18
+	ABS_PROFILE        = 0x21
 	ABS_RESERVED       = 0x2e
 	ABS_RUDDER         = 0x07
 	ABS_RX             = 0x03
@@ -76,6 +77,7 @@ var ABSName = map[EvCode]string{
 	27: "ABS_TILT_Y",
 	28: "ABS_TOOL_WIDTH",
 	32: "ABS_VOLUME",
+	33: "ABS_PROFILE",
 	40: "ABS_MISC",
 	46: "ABS_RESERVED",
 	47: "ABS_MT_SLOT",
@@ -94,6 +96,53 @@ var ABSName = map[EvCode]string{
 	60: "ABS_MT_TOOL_X",
 	61: "ABS_MT_TOOL_Y",
 }
+var ABSByName = map[string]EvCode{
+	"ABS_BRAKE":          ABS_BRAKE,
+	"ABS_CNT":            ABS_CNT,
+	"ABS_DISTANCE":       ABS_DISTANCE,
+	"ABS_GAS":            ABS_GAS,
+	"ABS_HAT0X":          ABS_HAT0X,
+	"ABS_HAT0Y":          ABS_HAT0Y,
+	"ABS_HAT1X":          ABS_HAT1X,
+	"ABS_HAT1Y":          ABS_HAT1Y,
+	"ABS_HAT2X":          ABS_HAT2X,
+	"ABS_HAT2Y":          ABS_HAT2Y,
+	"ABS_HAT3X":          ABS_HAT3X,
+	"ABS_HAT3Y":          ABS_HAT3Y,
+	"ABS_MAX":            ABS_MAX,
+	"ABS_MISC":           ABS_MISC,
+	"ABS_MT_BLOB_ID":     ABS_MT_BLOB_ID,
+	"ABS_MT_DISTANCE":    ABS_MT_DISTANCE,
+	"ABS_MT_ORIENTATION": ABS_MT_ORIENTATION,
+	"ABS_MT_POSITION_X":  ABS_MT_POSITION_X,
+	"ABS_MT_POSITION_Y":  ABS_MT_POSITION_Y,
+	"ABS_MT_PRESSURE":    ABS_MT_PRESSURE,
+	"ABS_MT_SLOT":        ABS_MT_SLOT,
+	"ABS_MT_TOOL_TYPE":   ABS_MT_TOOL_TYPE,
+	"ABS_MT_TOOL_X":      ABS_MT_TOOL_X,
+	"ABS_MT_TOOL_Y":      ABS_MT_TOOL_Y,
+	"ABS_MT_TOUCH_MAJOR": ABS_MT_TOUCH_MAJOR,
+	"ABS_MT_TOUCH_MINOR": ABS_MT_TOUCH_MINOR,
+	"ABS_MT_TRACKING_ID": ABS_MT_TRACKING_ID,
+	"ABS_MT_WIDTH_MAJOR": ABS_MT_WIDTH_MAJOR,
+	"ABS_MT_WIDTH_MINOR": ABS_MT_WIDTH_MINOR,
+	"ABS_PRESSURE":       ABS_PRESSURE,
+	"ABS_PROFILE":        ABS_PROFILE,
+	"ABS_RESERVED":       ABS_RESERVED,
+	"ABS_RUDDER":         ABS_RUDDER,
+	"ABS_RX":             ABS_RX,
+	"ABS_RY":             ABS_RY,
+	"ABS_RZ":             ABS_RZ,
+	"ABS_THROTTLE":       ABS_THROTTLE,
+	"ABS_TILT_X":         ABS_TILT_X,
+	"ABS_TILT_Y":         ABS_TILT_Y,
+	"ABS_TOOL_WIDTH":     ABS_TOOL_WIDTH,
+	"ABS_VOLUME":         ABS_VOLUME,
+	"ABS_WHEEL":          ABS_WHEEL,
+	"ABS_X":              ABS_X,
+	"ABS_Y":              ABS_Y,
+	"ABS_Z":              ABS_Z,
+}
 
 // BTN
 const (
@@ -229,7 +278,7 @@ var BTNName = map[EvCode]string{
 	263: "BTN_7",
 	264: "BTN_8",
 	265: "BTN_9",
-	272: "BTN_MOUSE",
+	272: "BTN_LEFT",
 	273: "BTN_RIGHT",
 	274: "BTN_MIDDLE",
 	275: "BTN_SIDE",
@@ -237,7 +286,7 @@ var BTNName = map[EvCode]string{
 	277: "BTN_FORWARD",
 	278: "BTN_BACK",
 	279: "BTN_TASK",
-	288: "BTN_JOYSTICK",
+	288: "BTN_TRIGGER",
 	289: "BTN_THUMB",
 	290: "BTN_THUMB2",
 	291: "BTN_TOP",
@@ -250,7 +299,7 @@ var BTNName = map[EvCode]string{
 	298: "BTN_BASE5",
 	299: "BTN_BASE6",
 	303: "BTN_DEAD",
-	304: "BTN_GAMEPAD",
+	304: "BTN_SOUTH",
 	305: "BTN_EAST",
 	306: "BTN_C",
 	307: "BTN_NORTH",
@@ -287,7 +336,7 @@ var BTNName = map[EvCode]string{
 	545: "BTN_DPAD_DOWN",
 	546: "BTN_DPAD_LEFT",
 	547: "BTN_DPAD_RIGHT",
-	704: "BTN_TRIGGER_HAPPY",
+	704: "BTN_TRIGGER_HAPPY1",
 	705: "BTN_TRIGGER_HAPPY2",
 	706: "BTN_TRIGGER_HAPPY3",
 	707: "BTN_TRIGGER_HAPPY4",
@@ -328,10 +377,132 @@ var BTNName = map[EvCode]string{
 	742: "BTN_TRIGGER_HAPPY39",
 	743: "BTN_TRIGGER_HAPPY40",
 }
+var BTNByName = map[string]EvCode{
+	"BTN_0":               BTN_0,
+	"BTN_1":               BTN_1,
+	"BTN_2":               BTN_2,
+	"BTN_3":               BTN_3,
+	"BTN_4":               BTN_4,
+	"BTN_5":               BTN_5,
+	"BTN_6":               BTN_6,
+	"BTN_7":               BTN_7,
+	"BTN_8":               BTN_8,
+	"BTN_9":               BTN_9,
+	"BTN_A":               BTN_A,
+	"BTN_B":               BTN_B,
+	"BTN_BACK":            BTN_BACK,
+	"BTN_BASE":            BTN_BASE,
+	"BTN_BASE2":           BTN_BASE2,
+	"BTN_BASE3":           BTN_BASE3,
+	"BTN_BASE4":           BTN_BASE4,
+	"BTN_BASE5":           BTN_BASE5,
+	"BTN_BASE6":           BTN_BASE6,
+	"BTN_C":               BTN_C,
+	"BTN_DEAD":            BTN_DEAD,
+	"BTN_DIGI":            BTN_DIGI,
+	"BTN_DPAD_DOWN":       BTN_DPAD_DOWN,
+	"BTN_DPAD_LEFT":       BTN_DPAD_LEFT,
+	"BTN_DPAD_RIGHT":      BTN_DPAD_RIGHT,
+	"BTN_DPAD_UP":         BTN_DPAD_UP,
+	"BTN_EAST":            BTN_EAST,
+	"BTN_EXTRA":           BTN_EXTRA,
+	"BTN_FORWARD":         BTN_FORWARD,
+	"BTN_GAMEPAD":         BTN_GAMEPAD,
+	"BTN_GEAR_DOWN":       BTN_GEAR_DOWN,
+	"BTN_GEAR_UP":         BTN_GEAR_UP,
+	"BTN_JOYSTICK":        BTN_JOYSTICK,
+	"BTN_LEFT":            BTN_LEFT,
+	"BTN_MIDDLE":          BTN_MIDDLE,
+	"BTN_MISC":            BTN_MISC,
+	"BTN_MODE":            BTN_MODE,
+	"BTN_MOUSE":           BTN_MOUSE,
+	"BTN_NORTH":           BTN_NORTH,
+	"BTN_PINKIE":          BTN_PINKIE,
+	"BTN_RIGHT":           BTN_RIGHT,
+	"BTN_SELECT":          BTN_SELECT,
+	"BTN_SIDE":            BTN_SIDE,
+	"BTN_SOUTH":           BTN_SOUTH,
+	"BTN_START":           BTN_START,
+	"BTN_STYLUS":          BTN_STYLUS,
+	"BTN_STYLUS2":         BTN_STYLUS2,
+	"BTN_STYLUS3":         BTN_STYLUS3,
+	"BTN_TASK":            BTN_TASK,
+	"BTN_THUMB":           BTN_THUMB,
+	"BTN_THUMB2":          BTN_THUMB2,
+	"BTN_THUMBL":          BTN_THUMBL,
+	"BTN_THUMBR":          BTN_THUMBR,
+	"BTN_TL":              BTN_TL,
+	"BTN_TL2":             BTN_TL2,
+	"BTN_TOOL_AIRBRUSH":   BTN_TOOL_AIRBRUSH,
+	"BTN_TOOL_BRUSH":      BTN_TOOL_BRUSH,
+	"BTN_TOOL_DOUBLETAP":  BTN_TOOL_DOUBLETAP,
+	"BTN_TOOL_FINGER":     BTN_TOOL_FINGER,
+	"BTN_TOOL_LENS":       BTN_TOOL_LENS,
+	"BTN_TOOL_MOUSE":      BTN_TOOL_MOUSE,
+	"BTN_TOOL_PEN":        BTN_TOOL_PEN,
+	"BTN_TOOL_PENCIL":     BTN_TOOL_PENCIL,
+	"BTN_TOOL_QUADTAP":    BTN_TOOL_QUADTAP,
+	"BTN_TOOL_QUINTTAP":   BTN_TOOL_QUINTTAP,
+	"BTN_TOOL_RUBBER":     BTN_TOOL_RUBBER,
+	"BTN_TOOL_TRIPLETAP":  BTN_TOOL_TRIPLETAP,
+	"BTN_TOP":             BTN_TOP,
+	"BTN_TOP2":            BTN_TOP2,
+	"BTN_TOUCH":           BTN_TOUCH,
+	"BTN_TR":              BTN_TR,
+	"BTN_TR2":             BTN_TR2,
+	"BTN_TRIGGER":         BTN_TRIGGER,
+	"BTN_TRIGGER_HAPPY":   BTN_TRIGGER_HAPPY,
+	"BTN_TRIGGER_HAPPY1":  BTN_TRIGGER_HAPPY1,
+	"BTN_TRIGGER_HAPPY10": BTN_TRIGGER_HAPPY10,
+	"BTN_TRIGGER_HAPPY11": BTN_TRIGGER_HAPPY11,
+	"BTN_TRIGGER_HAPPY12": BTN_TRIGGER_HAPPY12,
+	"BTN_TRIGGER_HAPPY13": BTN_TRIGGER_HAPPY13,
+	"BTN_TRIGGER_HAPPY14": BTN_TRIGGER_HAPPY14,
+	"BTN_TRIGGER_HAPPY15": BTN_TRIGGER_HAPPY15,
+	"BTN_TRIGGER_HAPPY16": BTN_TRIGGER_HAPPY16,
+	"BTN_TRIGGER_HAPPY17": BTN_TRIGGER_HAPPY17,
+	"BTN_TRIGGER_HAPPY18": BTN_TRIGGER_HAPPY18,
+	"BTN_TRIGGER_HAPPY19": BTN_TRIGGER_HAPPY19,
+	"BTN_TRIGGER_HAPPY2":  BTN_TRIGGER_HAPPY2,
+	"BTN_TRIGGER_HAPPY20": BTN_TRIGGER_HAPPY20,
+	"BTN_TRIGGER_HAPPY21": BTN_TRIGGER_HAPPY21,
+	"BTN_TRIGGER_HAPPY22": BTN_TRIGGER_HAPPY22,
+	"BTN_TRIGGER_HAPPY23": BTN_TRIGGER_HAPPY23,
+	"BTN_TRIGGER_HAPPY24": BTN_TRIGGER_HAPPY24,
+	"BTN_TRIGGER_HAPPY25": BTN_TRIGGER_HAPPY25,
+	"BTN_TRIGGER_HAPPY26": BTN_TRIGGER_HAPPY26,
+	"BTN_TRIGGER_HAPPY27": BTN_TRIGGER_HAPPY27,
+	"BTN_TRIGGER_HAPPY28": BTN_TRIGGER_HAPPY28,
+	"BTN_TRIGGER_HAPPY29": BTN_TRIGGER_HAPPY29,
+	"BTN_TRIGGER_HAPPY3":  BTN_TRIGGER_HAPPY3,
+	"BTN_TRIGGER_HAPPY30": BTN_TRIGGER_HAPPY30,
+	"BTN_TRIGGER_HAPPY31": BTN_TRIGGER_HAPPY31,
+	"BTN_TRIGGER_HAPPY32": BTN_TRIGGER_HAPPY32,
+	"BTN_TRIGGER_HAPPY33": BTN_TRIGGER_HAPPY33,
+	"BTN_TRIGGER_HAPPY34": BTN_TRIGGER_HAPPY34,
+	"BTN_TRIGGER_HAPPY35": BTN_TRIGGER_HAPPY35,
+	"BTN_TRIGGER_HAPPY36": BTN_TRIGGER_HAPPY36,
+	"BTN_TRIGGER_HAPPY37": BTN_TRIGGER_HAPPY37,
+	"BTN_TRIGGER_HAPPY38": BTN_TRIGGER_HAPPY38,
+	"BTN_TRIGGER_HAPPY39": BTN_TRIGGER_HAPPY39,
+	"BTN_TRIGGER_HAPPY4":  BTN_TRIGGER_HAPPY4,
+	"BTN_TRIGGER_HAPPY40": BTN_TRIGGER_HAPPY40,
+	"BTN_TRIGGER_HAPPY5":  BTN_TRIGGER_HAPPY5,
+	"BTN_TRIGGER_HAPPY6":  BTN_TRIGGER_HAPPY6,
+	"BTN_TRIGGER_HAPPY7":  BTN_TRIGGER_HAPPY7,
+	"BTN_TRIGGER_HAPPY8":  BTN_TRIGGER_HAPPY8,
+	"BTN_TRIGGER_HAPPY9":  BTN_TRIGGER_HAPPY9,
+	"BTN_WEST":            BTN_WEST,
+	"BTN_WHEEL":           BTN_WHEEL,
+	"BTN_X":               BTN_X,
+	"BTN_Y":               BTN_Y,
+	"BTN_Z":               BTN_Z,
+}
 
 // BUS
 const (
 	BUS_ADB         = 0x17
+	BUS_AMD_SFH     = 0x20
 	BUS_AMIGA       = 0x16
 	BUS_ATARI       = 0x1B
 	BUS_BLUETOOTH   = 0x05
@@ -378,6 +549,32 @@ var BUSName = map[EvCode]string{
 	29: "BUS_RMI",
 	30: "BUS_CEC",
 	31: "BUS_INTEL_ISHTP",
+	32: "BUS_AMD_SFH",
+}
+var BUSByName = map[string]EvCode{
+	"BUS_ADB":         BUS_ADB,
+	"BUS_AMD_SFH":     BUS_AMD_SFH,
+	"BUS_AMIGA":       BUS_AMIGA,
+	"BUS_ATARI":       BUS_ATARI,
+	"BUS_BLUETOOTH":   BUS_BLUETOOTH,
+	"BUS_CEC":         BUS_CEC,
+	"BUS_GAMEPORT":    BUS_GAMEPORT,
+	"BUS_GSC":         BUS_GSC,
+	"BUS_HIL":         BUS_HIL,
+	"BUS_HOST":        BUS_HOST,
+	"BUS_I2C":         BUS_I2C,
+	"BUS_I8042":       BUS_I8042,
+	"BUS_INTEL_ISHTP": BUS_INTEL_ISHTP,
+	"BUS_ISA":         BUS_ISA,
+	"BUS_ISAPNP":      BUS_ISAPNP,
+	"BUS_PARPORT":     BUS_PARPORT,
+	"BUS_PCI":         BUS_PCI,
+	"BUS_RMI":         BUS_RMI,
+	"BUS_RS232":       BUS_RS232,
+	"BUS_SPI":         BUS_SPI,
+	"BUS_USB":         BUS_USB,
+	"BUS_VIRTUAL":     BUS_VIRTUAL,
+	"BUS_XTKBD":       BUS_XTKBD,
 }
 
 // EV
@@ -412,6 +609,22 @@ var EVName = map[EvType]string{
 	22: "EV_PWR",
 	23: "EV_FF_STATUS",
 }
+var EVByName = map[string]EvType{
+	"EV_ABS":       EV_ABS,
+	"EV_CNT":       EV_CNT,
+	"EV_FF":        EV_FF,
+	"EV_FF_STATUS": EV_FF_STATUS,
+	"EV_KEY":       EV_KEY,
+	"EV_LED":       EV_LED,
+	"EV_MAX":       EV_MAX,
+	"EV_MSC":       EV_MSC,
+	"EV_PWR":       EV_PWR,
+	"EV_REL":       EV_REL,
+	"EV_REP":       EV_REP,
+	"EV_SND":       EV_SND,
+	"EV_SW":        EV_SW,
+	"EV_SYN":       EV_SYN,
+}
 
 // FF
 const (
@@ -445,7 +658,7 @@ const (
 
 var FFName = map[EvCode]string{
 	0:  "FF_STATUS_STOPPED",
-	1:  "FF_STATUS_MAX",
+	1:  "FF_STATUS_PLAYING",
 	80: "FF_RUMBLE",
 	81: "FF_PERIODIC",
 	82: "FF_CONSTANT",
@@ -463,6 +676,34 @@ var FFName = map[EvCode]string{
 	96: "FF_GAIN",
 	97: "FF_AUTOCENTER",
 }
+var FFByName = map[string]EvCode{
+	"FF_AUTOCENTER":     FF_AUTOCENTER,
+	"FF_CNT":            FF_CNT,
+	"FF_CONSTANT":       FF_CONSTANT,
+	"FF_CUSTOM":         FF_CUSTOM,
+	"FF_DAMPER":         FF_DAMPER,
+	"FF_EFFECT_MAX":     FF_EFFECT_MAX,
+	"FF_EFFECT_MIN":     FF_EFFECT_MIN,
+	"FF_FRICTION":       FF_FRICTION,
+	"FF_GAIN":           FF_GAIN,
+	"FF_INERTIA":        FF_INERTIA,
+	"FF_MAX":            FF_MAX,
+	"FF_MAX_EFFECTS":    FF_MAX_EFFECTS,
+	"FF_PERIODIC":       FF_PERIODIC,
+	"FF_RAMP":           FF_RAMP,
+	"FF_RUMBLE":         FF_RUMBLE,
+	"FF_SAW_DOWN":       FF_SAW_DOWN,
+	"FF_SAW_UP":         FF_SAW_UP,
+	"FF_SINE":           FF_SINE,
+	"FF_SPRING":         FF_SPRING,
+	"FF_SQUARE":         FF_SQUARE,
+	"FF_STATUS_MAX":     FF_STATUS_MAX,
+	"FF_STATUS_PLAYING": FF_STATUS_PLAYING,
+	"FF_STATUS_STOPPED": FF_STATUS_STOPPED,
+	"FF_TRIANGLE":       FF_TRIANGLE,
+	"FF_WAVEFORM_MAX":   FF_WAVEFORM_MAX,
+	"FF_WAVEFORM_MIN":   FF_WAVEFORM_MIN,
+}
 
 // ID
 const (
@@ -476,6 +717,11 @@ var IDName = map[EvCode]string{
 	1: "ID_VENDOR",
 	2: "ID_PRODUCT",
 }
+var IDByName = map[string]EvCode{
+	"ID_BUS":     ID_BUS,
+	"ID_PRODUCT": ID_PRODUCT,
+	"ID_VENDOR":  ID_VENDOR,
+}
 
 // KEY
 const (
@@ -497,6 +743,7 @@ const (
 	KEY_AB                       = 0x196
 	KEY_ADDRESSBOOK              = 0x1ad
 	KEY_AGAIN                    = 129
+	KEY_ALL_APPLICATIONS         = 204
 	KEY_ALS_TOGGLE               = 0x230
 	KEY_ALTERASE                 = 222
 	KEY_ANGLE                    = 0x173
@@ -510,6 +757,7 @@ const (
 	KEY_ATTENDANT_TOGGLE         = 0x21d
 	KEY_AUDIO                    = 0x188
 	KEY_AUDIO_DESC               = 0x26e
+	KEY_AUTOPILOT_ENGAGE_TOGGLE  = 0x27d
 	KEY_AUX                      = 0x186
 	KEY_B                        = 48
 	KEY_BACK                     = 158
@@ -526,6 +774,7 @@ const (
 	KEY_BRIGHTNESS_AUTO          = 244
 	KEY_BRIGHTNESS_CYCLE         = 243
 	KEY_BRIGHTNESS_MAX           = 0x251
+	KEY_BRIGHTNESS_MENU          = 0x289
 	KEY_BRIGHTNESS_MIN           = 0x250
 	KEY_BRIGHTNESS_TOGGLE        = KEY_DISPLAYTOGGLE
 	KEY_BRIGHTNESS_ZERO          = KEY_BRIGHTNESS_AUTO
@@ -559,6 +808,7 @@ const (
 	KEY_CHANNELUP                = 0x192
 	KEY_CHAT                     = 216
 	KEY_CLEAR                    = 0x163
+	KEY_CLEARVU_SONAR            = 0x286
 	KEY_CLOSE                    = 206
 	KEY_CLOSECD                  = 160
 	KEY_CNT                      = (KEY_MAX + 1)
@@ -574,7 +824,7 @@ const (
 	KEY_CUT                      = 137
 	KEY_CYCLEWINDOWS             = 154
 	KEY_D                        = 32
-	KEY_DASHBOARD                = 204
+	KEY_DASHBOARD                = KEY_ALL_APPLICATIONS
 	KEY_DATA                     = 0x277
 	KEY_DATABASE                 = 0x1aa
 	KEY_DELETE                   = 111
@@ -582,6 +832,7 @@ const (
 	KEY_DEL_EOL                  = 0x1c0
 	KEY_DEL_EOS                  = 0x1c1
 	KEY_DEL_LINE                 = 0x1c3
+	KEY_DICTATE                  = 0x24a
 	KEY_DIGITS                   = 0x19d
 	KEY_DIRECTION                = KEY_ROTATE_DISPLAY
 	KEY_DIRECTORY                = 0x18a
@@ -591,6 +842,7 @@ const (
 	KEY_DOLLAR                   = 0x1b2
 	KEY_DOT                      = 52
 	KEY_DOWN                     = 108
+	KEY_DUAL_RANGE_RADAR         = 0x283
 	KEY_DVD                      = 0x185
 	KEY_E                        = 18
 	KEY_EDIT                     = 176
@@ -598,6 +850,7 @@ const (
 	KEY_EJECTCD                  = 161
 	KEY_EJECTCLOSECD             = 162
 	KEY_EMAIL                    = 215
+	KEY_EMOJI_PICKER             = 0x249
 	KEY_END                      = 107
 	KEY_ENTER                    = 28
 	KEY_EPG                      = 0x16d
@@ -637,6 +890,7 @@ const (
 	KEY_FINANCE                  = 219
 	KEY_FIND                     = 136
 	KEY_FIRST                    = 0x194
+	KEY_FISHING_CHART            = 0x281
 	KEY_FN                       = 0x1d0
 	KEY_FN_1                     = 0x1de
 	KEY_FN_2                     = 0x1df
@@ -744,6 +998,7 @@ const (
 	KEY_LEFT_UP                  = 0x268
 	KEY_LIGHTS_TOGGLE            = 0x21e
 	KEY_LINEFEED                 = 101
+	KEY_LINK_PHONE               = 0x1bf
 	KEY_LIST                     = 0x18b
 	KEY_LOGOFF                   = 0x1b1
 	KEY_M                        = 50
@@ -785,6 +1040,7 @@ const (
 	KEY_MACRO_RECORD_START       = 0x2b0
 	KEY_MACRO_RECORD_STOP        = 0x2b1
 	KEY_MAIL                     = 155
+	KEY_MARK_WAYPOINT            = 0x27e
 	KEY_MAX                      = 0x2ff
 	KEY_MEDIA                    = 226
 	KEY_MEDIA_REPEAT             = 0x1b7
@@ -803,10 +1059,13 @@ const (
 	KEY_MUHENKAN                 = 94
 	KEY_MUTE                     = 113
 	KEY_N                        = 49
+	KEY_NAV_CHART                = 0x280
+	KEY_NAV_INFO                 = 0x288
 	KEY_NEW                      = 181
 	KEY_NEWS                     = 0x1ab
 	KEY_NEXT                     = 0x197
 	KEY_NEXTSONG                 = 163
+	KEY_NEXT_ELEMENT             = 0x27b
 	KEY_NEXT_FAVORITE            = 0x270
 	KEY_NOTIFICATION_CENTER      = 0x1bc
 	KEY_NUMERIC_0                = 0x200
@@ -852,6 +1111,7 @@ const (
 	KEY_PRESENTATION             = 0x1a9
 	KEY_PREVIOUS                 = 0x19c
 	KEY_PREVIOUSSONG             = 165
+	KEY_PREVIOUS_ELEMENT         = 0x27c
 	KEY_PRINT                    = 210
 	KEY_PRIVACY_SCREEN_TOGGLE    = 0x279
 	KEY_PROG1                    = 148
@@ -864,11 +1124,13 @@ const (
 	KEY_Q                        = 16
 	KEY_QUESTION                 = 214
 	KEY_R                        = 19
+	KEY_RADAR_OVERLAY            = 0x284
 	KEY_RADIO                    = 0x181
 	KEY_RECORD                   = 167
 	KEY_RED                      = 0x18e
 	KEY_REDO                     = 182
 	KEY_REFRESH                  = 173
+	KEY_REFRESH_RATE_TOGGLE      = 0x232
 	KEY_REPLY                    = 232
 	KEY_RESERVED                 = 0
 	KEY_RESTART                  = 0x198
@@ -906,10 +1168,13 @@ const (
 	KEY_SETUP                    = 141
 	KEY_SHOP                     = 221
 	KEY_SHUFFLE                  = 0x19a
+	KEY_SIDEVU_SONAR             = 0x287
+	KEY_SINGLE_RANGE_RADAR       = 0x282
 	KEY_SLASH                    = 53
 	KEY_SLEEP                    = 142
 	KEY_SLOW                     = 0x199
 	KEY_SLOWREVERSE              = 0x276
+	KEY_SOS                      = 0x27f
 	KEY_SOUND                    = 213
 	KEY_SPACE                    = 57
 	KEY_SPELLCHECK               = 0x1b0
@@ -933,6 +1198,7 @@ const (
 	KEY_TOUCHPAD_OFF             = 0x214
 	KEY_TOUCHPAD_ON              = 0x213
 	KEY_TOUCHPAD_TOGGLE          = 0x212
+	KEY_TRADITIONAL_SONAR        = 0x285
 	KEY_TUNER                    = 0x182
 	KEY_TV                       = 0x179
 	KEY_TV2                      = 0x17a
@@ -1176,7 +1442,7 @@ var KEYName = map[EvCode]string{
 	201: "KEY_PAUSECD",
 	202: "KEY_PROG3",
 	203: "KEY_PROG4",
-	204: "KEY_DASHBOARD",
+	204: "KEY_ALL_APPLICATIONS",
 	205: "KEY_SUSPEND",
 	206: "KEY_CLOSE",
 	207: "KEY_PLAY",
@@ -1315,6 +1581,7 @@ var KEYName = map[EvCode]string{
 	444: "KEY_NOTIFICATION_CENTER",
 	445: "KEY_PICKUP_PHONE",
 	446: "KEY_HANGUP_PHONE",
+	447: "KEY_LINK_PHONE",
 	448: "KEY_DEL_EOL",
 	449: "KEY_DEL_EOS",
 	450: "KEY_INS_LINE",
@@ -1384,6 +1651,7 @@ var KEYName = map[EvCode]string{
 	542: "KEY_LIGHTS_TOGGLE",
 	560: "KEY_ALS_TOGGLE",
 	561: "KEY_ROTATE_LOCK_TOGGLE",
+	562: "KEY_REFRESH_RATE_TOGGLE",
 	576: "KEY_BUTTONCONFIG",
 	577: "KEY_TASKMANAGER",
 	578: "KEY_JOURNAL",
@@ -1393,6 +1661,8 @@ var KEYName = map[EvCode]string{
 	582: "KEY_VOICECOMMAND",
 	583: "KEY_ASSISTANT",
 	584: "KEY_KBD_LAYOUT_NEXT",
+	585: "KEY_EMOJI_PICKER",
+	586: "KEY_DICTATE",
 	592: "KEY_BRIGHTNESS_MIN",
 	593: "KEY_BRIGHTNESS_MAX",
 	608: "KEY_KBDINPUTASSIST_PREV",
@@ -1422,6 +1692,21 @@ var KEYName = map[EvCode]string{
 	632: "KEY_ONSCREEN_KEYBOARD",
 	633: "KEY_PRIVACY_SCREEN_TOGGLE",
 	634: "KEY_SELECTIVE_SCREENSHOT",
+	635: "KEY_NEXT_ELEMENT",
+	636: "KEY_PREVIOUS_ELEMENT",
+	637: "KEY_AUTOPILOT_ENGAGE_TOGGLE",
+	638: "KEY_MARK_WAYPOINT",
+	639: "KEY_SOS",
+	640: "KEY_NAV_CHART",
+	641: "KEY_FISHING_CHART",
+	642: "KEY_SINGLE_RANGE_RADAR",
+	643: "KEY_DUAL_RANGE_RADAR",
+	644: "KEY_RADAR_OVERLAY",
+	645: "KEY_TRADITIONAL_SONAR",
+	646: "KEY_CLEARVU_SONAR",
+	647: "KEY_SIDEVU_SONAR",
+	648: "KEY_NAV_INFO",
+	649: "KEY_BRIGHTNESS_MENU",
 	656: "KEY_MACRO1",
 	657: "KEY_MACRO2",
 	658: "KEY_MACRO3",
@@ -1464,6 +1749,524 @@ var KEYName = map[EvCode]string{
 	699: "KEY_KBD_LCD_MENU4",
 	700: "KEY_KBD_LCD_MENU5",
 }
+var KEYByName = map[string]EvCode{
+	"KEY_0":                        KEY_0,
+	"KEY_1":                        KEY_1,
+	"KEY_102ND":                    KEY_102ND,
+	"KEY_10CHANNELSDOWN":           KEY_10CHANNELSDOWN,
+	"KEY_10CHANNELSUP":             KEY_10CHANNELSUP,
+	"KEY_2":                        KEY_2,
+	"KEY_3":                        KEY_3,
+	"KEY_3D_MODE":                  KEY_3D_MODE,
+	"KEY_4":                        KEY_4,
+	"KEY_5":                        KEY_5,
+	"KEY_6":                        KEY_6,
+	"KEY_7":                        KEY_7,
+	"KEY_8":                        KEY_8,
+	"KEY_9":                        KEY_9,
+	"KEY_A":                        KEY_A,
+	"KEY_AB":                       KEY_AB,
+	"KEY_ADDRESSBOOK":              KEY_ADDRESSBOOK,
+	"KEY_AGAIN":                    KEY_AGAIN,
+	"KEY_ALL_APPLICATIONS":         KEY_ALL_APPLICATIONS,
+	"KEY_ALS_TOGGLE":               KEY_ALS_TOGGLE,
+	"KEY_ALTERASE":                 KEY_ALTERASE,
+	"KEY_ANGLE":                    KEY_ANGLE,
+	"KEY_APOSTROPHE":               KEY_APOSTROPHE,
+	"KEY_APPSELECT":                KEY_APPSELECT,
+	"KEY_ARCHIVE":                  KEY_ARCHIVE,
+	"KEY_ASPECT_RATIO":             KEY_ASPECT_RATIO,
+	"KEY_ASSISTANT":                KEY_ASSISTANT,
+	"KEY_ATTENDANT_OFF":            KEY_ATTENDANT_OFF,
+	"KEY_ATTENDANT_ON":             KEY_ATTENDANT_ON,
+	"KEY_ATTENDANT_TOGGLE":         KEY_ATTENDANT_TOGGLE,
+	"KEY_AUDIO":                    KEY_AUDIO,
+	"KEY_AUDIO_DESC":               KEY_AUDIO_DESC,
+	"KEY_AUTOPILOT_ENGAGE_TOGGLE":  KEY_AUTOPILOT_ENGAGE_TOGGLE,
+	"KEY_AUX":                      KEY_AUX,
+	"KEY_B":                        KEY_B,
+	"KEY_BACK":                     KEY_BACK,
+	"KEY_BACKSLASH":                KEY_BACKSLASH,
+	"KEY_BACKSPACE":                KEY_BACKSPACE,
+	"KEY_BASSBOOST":                KEY_BASSBOOST,
+	"KEY_BATTERY":                  KEY_BATTERY,
+	"KEY_BLUE":                     KEY_BLUE,
+	"KEY_BLUETOOTH":                KEY_BLUETOOTH,
+	"KEY_BOOKMARKS":                KEY_BOOKMARKS,
+	"KEY_BREAK":                    KEY_BREAK,
+	"KEY_BRIGHTNESSDOWN":           KEY_BRIGHTNESSDOWN,
+	"KEY_BRIGHTNESSUP":             KEY_BRIGHTNESSUP,
+	"KEY_BRIGHTNESS_AUTO":          KEY_BRIGHTNESS_AUTO,
+	"KEY_BRIGHTNESS_CYCLE":         KEY_BRIGHTNESS_CYCLE,
+	"KEY_BRIGHTNESS_MAX":           KEY_BRIGHTNESS_MAX,
+	"KEY_BRIGHTNESS_MENU":          KEY_BRIGHTNESS_MENU,
+	"KEY_BRIGHTNESS_MIN":           KEY_BRIGHTNESS_MIN,
+	"KEY_BRIGHTNESS_TOGGLE":        KEY_BRIGHTNESS_TOGGLE,
+	"KEY_BRIGHTNESS_ZERO":          KEY_BRIGHTNESS_ZERO,
+	"KEY_BRL_DOT1":                 KEY_BRL_DOT1,
+	"KEY_BRL_DOT10":                KEY_BRL_DOT10,
+	"KEY_BRL_DOT2":                 KEY_BRL_DOT2,
+	"KEY_BRL_DOT3":                 KEY_BRL_DOT3,
+	"KEY_BRL_DOT4":                 KEY_BRL_DOT4,
+	"KEY_BRL_DOT5":                 KEY_BRL_DOT5,
+	"KEY_BRL_DOT6":                 KEY_BRL_DOT6,
+	"KEY_BRL_DOT7":                 KEY_BRL_DOT7,
+	"KEY_BRL_DOT8":                 KEY_BRL_DOT8,
+	"KEY_BRL_DOT9":                 KEY_BRL_DOT9,
+	"KEY_BUTTONCONFIG":             KEY_BUTTONCONFIG,
+	"KEY_C":                        KEY_C,
+	"KEY_CALC":                     KEY_CALC,
+	"KEY_CALENDAR":                 KEY_CALENDAR,
+	"KEY_CAMERA":                   KEY_CAMERA,
+	"KEY_CAMERA_DOWN":              KEY_CAMERA_DOWN,
+	"KEY_CAMERA_FOCUS":             KEY_CAMERA_FOCUS,
+	"KEY_CAMERA_LEFT":              KEY_CAMERA_LEFT,
+	"KEY_CAMERA_RIGHT":             KEY_CAMERA_RIGHT,
+	"KEY_CAMERA_UP":                KEY_CAMERA_UP,
+	"KEY_CAMERA_ZOOMIN":            KEY_CAMERA_ZOOMIN,
+	"KEY_CAMERA_ZOOMOUT":           KEY_CAMERA_ZOOMOUT,
+	"KEY_CANCEL":                   KEY_CANCEL,
+	"KEY_CAPSLOCK":                 KEY_CAPSLOCK,
+	"KEY_CD":                       KEY_CD,
+	"KEY_CHANNEL":                  KEY_CHANNEL,
+	"KEY_CHANNELDOWN":              KEY_CHANNELDOWN,
+	"KEY_CHANNELUP":                KEY_CHANNELUP,
+	"KEY_CHAT":                     KEY_CHAT,
+	"KEY_CLEAR":                    KEY_CLEAR,
+	"KEY_CLEARVU_SONAR":            KEY_CLEARVU_SONAR,
+	"KEY_CLOSE":                    KEY_CLOSE,
+	"KEY_CLOSECD":                  KEY_CLOSECD,
+	"KEY_CNT":                      KEY_CNT,
+	"KEY_COFFEE":                   KEY_COFFEE,
+	"KEY_COMMA":                    KEY_COMMA,
+	"KEY_COMPOSE":                  KEY_COMPOSE,
+	"KEY_COMPUTER":                 KEY_COMPUTER,
+	"KEY_CONFIG":                   KEY_CONFIG,
+	"KEY_CONNECT":                  KEY_CONNECT,
+	"KEY_CONTEXT_MENU":             KEY_CONTEXT_MENU,
+	"KEY_CONTROLPANEL":             KEY_CONTROLPANEL,
+	"KEY_COPY":                     KEY_COPY,
+	"KEY_CUT":                      KEY_CUT,
+	"KEY_CYCLEWINDOWS":             KEY_CYCLEWINDOWS,
+	"KEY_D":                        KEY_D,
+	"KEY_DASHBOARD":                KEY_DASHBOARD,
+	"KEY_DATA":                     KEY_DATA,
+	"KEY_DATABASE":                 KEY_DATABASE,
+	"KEY_DELETE":                   KEY_DELETE,
+	"KEY_DELETEFILE":               KEY_DELETEFILE,
+	"KEY_DEL_EOL":                  KEY_DEL_EOL,
+	"KEY_DEL_EOS":                  KEY_DEL_EOS,
+	"KEY_DEL_LINE":                 KEY_DEL_LINE,
+	"KEY_DICTATE":                  KEY_DICTATE,
+	"KEY_DIGITS":                   KEY_DIGITS,
+	"KEY_DIRECTION":                KEY_DIRECTION,
+	"KEY_DIRECTORY":                KEY_DIRECTORY,
+	"KEY_DISPLAYTOGGLE":            KEY_DISPLAYTOGGLE,
+	"KEY_DISPLAY_OFF":              KEY_DISPLAY_OFF,
+	"KEY_DOCUMENTS":                KEY_DOCUMENTS,
+	"KEY_DOLLAR":                   KEY_DOLLAR,
+	"KEY_DOT":                      KEY_DOT,
+	"KEY_DOWN":                     KEY_DOWN,
+	"KEY_DUAL_RANGE_RADAR":         KEY_DUAL_RANGE_RADAR,
+	"KEY_DVD":                      KEY_DVD,
+	"KEY_E":                        KEY_E,
+	"KEY_EDIT":                     KEY_EDIT,
+	"KEY_EDITOR":                   KEY_EDITOR,
+	"KEY_EJECTCD":                  KEY_EJECTCD,
+	"KEY_EJECTCLOSECD":             KEY_EJECTCLOSECD,
+	"KEY_EMAIL":                    KEY_EMAIL,
+	"KEY_EMOJI_PICKER":             KEY_EMOJI_PICKER,
+	"KEY_END":                      KEY_END,
+	"KEY_ENTER":                    KEY_ENTER,
+	"KEY_EPG":                      KEY_EPG,
+	"KEY_EQUAL":                    KEY_EQUAL,
+	"KEY_ESC":                      KEY_ESC,
+	"KEY_EURO":                     KEY_EURO,
+	"KEY_EXIT":                     KEY_EXIT,
+	"KEY_F":                        KEY_F,
+	"KEY_F1":                       KEY_F1,
+	"KEY_F10":                      KEY_F10,
+	"KEY_F11":                      KEY_F11,
+	"KEY_F12":                      KEY_F12,
+	"KEY_F13":                      KEY_F13,
+	"KEY_F14":                      KEY_F14,
+	"KEY_F15":                      KEY_F15,
+	"KEY_F16":                      KEY_F16,
+	"KEY_F17":                      KEY_F17,
+	"KEY_F18":                      KEY_F18,
+	"KEY_F19":                      KEY_F19,
+	"KEY_F2":                       KEY_F2,
+	"KEY_F20":                      KEY_F20,
+	"KEY_F21":                      KEY_F21,
+	"KEY_F22":                      KEY_F22,
+	"KEY_F23":                      KEY_F23,
+	"KEY_F24":                      KEY_F24,
+	"KEY_F3":                       KEY_F3,
+	"KEY_F4":                       KEY_F4,
+	"KEY_F5":                       KEY_F5,
+	"KEY_F6":                       KEY_F6,
+	"KEY_F7":                       KEY_F7,
+	"KEY_F8":                       KEY_F8,
+	"KEY_F9":                       KEY_F9,
+	"KEY_FASTFORWARD":              KEY_FASTFORWARD,
+	"KEY_FASTREVERSE":              KEY_FASTREVERSE,
+	"KEY_FAVORITES":                KEY_FAVORITES,
+	"KEY_FILE":                     KEY_FILE,
+	"KEY_FINANCE":                  KEY_FINANCE,
+	"KEY_FIND":                     KEY_FIND,
+	"KEY_FIRST":                    KEY_FIRST,
+	"KEY_FISHING_CHART":            KEY_FISHING_CHART,
+	"KEY_FN":                       KEY_FN,
+	"KEY_FN_1":                     KEY_FN_1,
+	"KEY_FN_2":                     KEY_FN_2,
+	"KEY_FN_B":                     KEY_FN_B,
+	"KEY_FN_D":                     KEY_FN_D,
+	"KEY_FN_E":                     KEY_FN_E,
+	"KEY_FN_ESC":                   KEY_FN_ESC,
+	"KEY_FN_F":                     KEY_FN_F,
+	"KEY_FN_F1":                    KEY_FN_F1,
+	"KEY_FN_F10":                   KEY_FN_F10,
+	"KEY_FN_F11":                   KEY_FN_F11,
+	"KEY_FN_F12":                   KEY_FN_F12,
+	"KEY_FN_F2":                    KEY_FN_F2,
+	"KEY_FN_F3":                    KEY_FN_F3,
+	"KEY_FN_F4":                    KEY_FN_F4,
+	"KEY_FN_F5":                    KEY_FN_F5,
+	"KEY_FN_F6":                    KEY_FN_F6,
+	"KEY_FN_F7":                    KEY_FN_F7,
+	"KEY_FN_F8":                    KEY_FN_F8,
+	"KEY_FN_F9":                    KEY_FN_F9,
+	"KEY_FN_RIGHT_SHIFT":           KEY_FN_RIGHT_SHIFT,
+	"KEY_FN_S":                     KEY_FN_S,
+	"KEY_FORWARD":                  KEY_FORWARD,
+	"KEY_FORWARDMAIL":              KEY_FORWARDMAIL,
+	"KEY_FRAMEBACK":                KEY_FRAMEBACK,
+	"KEY_FRAMEFORWARD":             KEY_FRAMEFORWARD,
+	"KEY_FRONT":                    KEY_FRONT,
+	"KEY_FULL_SCREEN":              KEY_FULL_SCREEN,
+	"KEY_G":                        KEY_G,
+	"KEY_GAMES":                    KEY_GAMES,
+	"KEY_GOTO":                     KEY_GOTO,
+	"KEY_GRAPHICSEDITOR":           KEY_GRAPHICSEDITOR,
+	"KEY_GRAVE":                    KEY_GRAVE,
+	"KEY_GREEN":                    KEY_GREEN,
+	"KEY_H":                        KEY_H,
+	"KEY_HANGEUL":                  KEY_HANGEUL,
+	"KEY_HANGUEL":                  KEY_HANGUEL,
+	"KEY_HANGUP_PHONE":             KEY_HANGUP_PHONE,
+	"KEY_HANJA":                    KEY_HANJA,
+	"KEY_HELP":                     KEY_HELP,
+	"KEY_HENKAN":                   KEY_HENKAN,
+	"KEY_HIRAGANA":                 KEY_HIRAGANA,
+	"KEY_HOME":                     KEY_HOME,
+	"KEY_HOMEPAGE":                 KEY_HOMEPAGE,
+	"KEY_HP":                       KEY_HP,
+	"KEY_I":                        KEY_I,
+	"KEY_IMAGES":                   KEY_IMAGES,
+	"KEY_INFO":                     KEY_INFO,
+	"KEY_INSERT":                   KEY_INSERT,
+	"KEY_INS_LINE":                 KEY_INS_LINE,
+	"KEY_ISO":                      KEY_ISO,
+	"KEY_J":                        KEY_J,
+	"KEY_JOURNAL":                  KEY_JOURNAL,
+	"KEY_K":                        KEY_K,
+	"KEY_KATAKANA":                 KEY_KATAKANA,
+	"KEY_KATAKANAHIRAGANA":         KEY_KATAKANAHIRAGANA,
+	"KEY_KBDILLUMDOWN":             KEY_KBDILLUMDOWN,
+	"KEY_KBDILLUMTOGGLE":           KEY_KBDILLUMTOGGLE,
+	"KEY_KBDILLUMUP":               KEY_KBDILLUMUP,
+	"KEY_KBDINPUTASSIST_ACCEPT":    KEY_KBDINPUTASSIST_ACCEPT,
+	"KEY_KBDINPUTASSIST_CANCEL":    KEY_KBDINPUTASSIST_CANCEL,
+	"KEY_KBDINPUTASSIST_NEXT":      KEY_KBDINPUTASSIST_NEXT,
+	"KEY_KBDINPUTASSIST_NEXTGROUP": KEY_KBDINPUTASSIST_NEXTGROUP,
+	"KEY_KBDINPUTASSIST_PREV":      KEY_KBDINPUTASSIST_PREV,
+	"KEY_KBDINPUTASSIST_PREVGROUP": KEY_KBDINPUTASSIST_PREVGROUP,
+	"KEY_KBD_LAYOUT_NEXT":          KEY_KBD_LAYOUT_NEXT,
+	"KEY_KBD_LCD_MENU1":            KEY_KBD_LCD_MENU1,
+	"KEY_KBD_LCD_MENU2":            KEY_KBD_LCD_MENU2,
+	"KEY_KBD_LCD_MENU3":            KEY_KBD_LCD_MENU3,
+	"KEY_KBD_LCD_MENU4":            KEY_KBD_LCD_MENU4,
+	"KEY_KBD_LCD_MENU5":            KEY_KBD_LCD_MENU5,
+	"KEY_KEYBOARD":                 KEY_KEYBOARD,
+	"KEY_KP0":                      KEY_KP0,
+	"KEY_KP1":                      KEY_KP1,
+	"KEY_KP2":                      KEY_KP2,
+	"KEY_KP3":                      KEY_KP3,
+	"KEY_KP4":                      KEY_KP4,
+	"KEY_KP5":                      KEY_KP5,
+	"KEY_KP6":                      KEY_KP6,
+	"KEY_KP7":                      KEY_KP7,
+	"KEY_KP8":                      KEY_KP8,
+	"KEY_KP9":                      KEY_KP9,
+	"KEY_KPASTERISK":               KEY_KPASTERISK,
+	"KEY_KPCOMMA":                  KEY_KPCOMMA,
+	"KEY_KPDOT":                    KEY_KPDOT,
+	"KEY_KPENTER":                  KEY_KPENTER,
+	"KEY_KPEQUAL":                  KEY_KPEQUAL,
+	"KEY_KPJPCOMMA":                KEY_KPJPCOMMA,
+	"KEY_KPLEFTPAREN":              KEY_KPLEFTPAREN,
+	"KEY_KPMINUS":                  KEY_KPMINUS,
+	"KEY_KPPLUS":                   KEY_KPPLUS,
+	"KEY_KPPLUSMINUS":              KEY_KPPLUSMINUS,
+	"KEY_KPRIGHTPAREN":             KEY_KPRIGHTPAREN,
+	"KEY_KPSLASH":                  KEY_KPSLASH,
+	"KEY_L":                        KEY_L,
+	"KEY_LANGUAGE":                 KEY_LANGUAGE,
+	"KEY_LAST":                     KEY_LAST,
+	"KEY_LEFT":                     KEY_LEFT,
+	"KEY_LEFTALT":                  KEY_LEFTALT,
+	"KEY_LEFTBRACE":                KEY_LEFTBRACE,
+	"KEY_LEFTCTRL":                 KEY_LEFTCTRL,
+	"KEY_LEFTMETA":                 KEY_LEFTMETA,
+	"KEY_LEFTSHIFT":                KEY_LEFTSHIFT,
+	"KEY_LEFT_DOWN":                KEY_LEFT_DOWN,
+	"KEY_LEFT_UP":                  KEY_LEFT_UP,
+	"KEY_LIGHTS_TOGGLE":            KEY_LIGHTS_TOGGLE,
+	"KEY_LINEFEED":                 KEY_LINEFEED,
+	"KEY_LINK_PHONE":               KEY_LINK_PHONE,
+	"KEY_LIST":                     KEY_LIST,
+	"KEY_LOGOFF":                   KEY_LOGOFF,
+	"KEY_M":                        KEY_M,
+	"KEY_MACRO":                    KEY_MACRO,
+	"KEY_MACRO1":                   KEY_MACRO1,
+	"KEY_MACRO10":                  KEY_MACRO10,
+	"KEY_MACRO11":                  KEY_MACRO11,
+	"KEY_MACRO12":                  KEY_MACRO12,
+	"KEY_MACRO13":                  KEY_MACRO13,
+	"KEY_MACRO14":                  KEY_MACRO14,
+	"KEY_MACRO15":                  KEY_MACRO15,
+	"KEY_MACRO16":                  KEY_MACRO16,
+	"KEY_MACRO17":                  KEY_MACRO17,
+	"KEY_MACRO18":                  KEY_MACRO18,
+	"KEY_MACRO19":                  KEY_MACRO19,
+	"KEY_MACRO2":                   KEY_MACRO2,
+	"KEY_MACRO20":                  KEY_MACRO20,
+	"KEY_MACRO21":                  KEY_MACRO21,
+	"KEY_MACRO22":                  KEY_MACRO22,
+	"KEY_MACRO23":                  KEY_MACRO23,
+	"KEY_MACRO24":                  KEY_MACRO24,
+	"KEY_MACRO25":                  KEY_MACRO25,
+	"KEY_MACRO26":                  KEY_MACRO26,
+	"KEY_MACRO27":                  KEY_MACRO27,
+	"KEY_MACRO28":                  KEY_MACRO28,
+	"KEY_MACRO29":                  KEY_MACRO29,
+	"KEY_MACRO3":                   KEY_MACRO3,
+	"KEY_MACRO30":                  KEY_MACRO30,
+	"KEY_MACRO4":                   KEY_MACRO4,
+	"KEY_MACRO5":                   KEY_MACRO5,
+	"KEY_MACRO6":                   KEY_MACRO6,
+	"KEY_MACRO7":                   KEY_MACRO7,
+	"KEY_MACRO8":                   KEY_MACRO8,
+	"KEY_MACRO9":                   KEY_MACRO9,
+	"KEY_MACRO_PRESET1":            KEY_MACRO_PRESET1,
+	"KEY_MACRO_PRESET2":            KEY_MACRO_PRESET2,
+	"KEY_MACRO_PRESET3":            KEY_MACRO_PRESET3,
+	"KEY_MACRO_PRESET_CYCLE":       KEY_MACRO_PRESET_CYCLE,
+	"KEY_MACRO_RECORD_START":       KEY_MACRO_RECORD_START,
+	"KEY_MACRO_RECORD_STOP":        KEY_MACRO_RECORD_STOP,
+	"KEY_MAIL":                     KEY_MAIL,
+	"KEY_MARK_WAYPOINT":            KEY_MARK_WAYPOINT,
+	"KEY_MAX":                      KEY_MAX,
+	"KEY_MEDIA":                    KEY_MEDIA,
+	"KEY_MEDIA_REPEAT":             KEY_MEDIA_REPEAT,
+	"KEY_MEDIA_TOP_MENU":           KEY_MEDIA_TOP_MENU,
+	"KEY_MEMO":                     KEY_MEMO,
+	"KEY_MENU":                     KEY_MENU,
+	"KEY_MESSENGER":                KEY_MESSENGER,
+	"KEY_MHP":                      KEY_MHP,
+	"KEY_MICMUTE":                  KEY_MICMUTE,
+	"KEY_MINUS":                    KEY_MINUS,
+	"KEY_MIN_INTERESTING":          KEY_MIN_INTERESTING,
+	"KEY_MODE":                     KEY_MODE,
+	"KEY_MOVE":                     KEY_MOVE,
+	"KEY_MP3":                      KEY_MP3,
+	"KEY_MSDOS":                    KEY_MSDOS,
+	"KEY_MUHENKAN":                 KEY_MUHENKAN,
+	"KEY_MUTE":                     KEY_MUTE,
+	"KEY_N":                        KEY_N,
+	"KEY_NAV_CHART":                KEY_NAV_CHART,
+	"KEY_NAV_INFO":                 KEY_NAV_INFO,
+	"KEY_NEW":                      KEY_NEW,
+	"KEY_NEWS":                     KEY_NEWS,
+	"KEY_NEXT":                     KEY_NEXT,
+	"KEY_NEXTSONG":                 KEY_NEXTSONG,
+	"KEY_NEXT_ELEMENT":             KEY_NEXT_ELEMENT,
+	"KEY_NEXT_FAVORITE":            KEY_NEXT_FAVORITE,
+	"KEY_NOTIFICATION_CENTER":      KEY_NOTIFICATION_CENTER,
+	"KEY_NUMERIC_0":                KEY_NUMERIC_0,
+	"KEY_NUMERIC_1":                KEY_NUMERIC_1,
+	"KEY_NUMERIC_11":               KEY_NUMERIC_11,
+	"KEY_NUMERIC_12":               KEY_NUMERIC_12,
+	"KEY_NUMERIC_2":                KEY_NUMERIC_2,
+	"KEY_NUMERIC_3":                KEY_NUMERIC_3,
+	"KEY_NUMERIC_4":                KEY_NUMERIC_4,
+	"KEY_NUMERIC_5":                KEY_NUMERIC_5,
+	"KEY_NUMERIC_6":                KEY_NUMERIC_6,
+	"KEY_NUMERIC_7":                KEY_NUMERIC_7,
+	"KEY_NUMERIC_8":                KEY_NUMERIC_8,
+	"KEY_NUMERIC_9":                KEY_NUMERIC_9,
+	"KEY_NUMERIC_A":                KEY_NUMERIC_A,
+	"KEY_NUMERIC_B":                KEY_NUMERIC_B,
+	"KEY_NUMERIC_C":                KEY_NUMERIC_C,
+	"KEY_NUMERIC_D":                KEY_NUMERIC_D,
+	"KEY_NUMERIC_POUND":            KEY_NUMERIC_POUND,
+	"KEY_NUMERIC_STAR":             KEY_NUMERIC_STAR,
+	"KEY_NUMLOCK":                  KEY_NUMLOCK,
+	"KEY_O":                        KEY_O,
+	"KEY_OK":                       KEY_OK,
+	"KEY_ONSCREEN_KEYBOARD":        KEY_ONSCREEN_KEYBOARD,
+	"KEY_OPEN":                     KEY_OPEN,
+	"KEY_OPTION":                   KEY_OPTION,
+	"KEY_P":                        KEY_P,
+	"KEY_PAGEDOWN":                 KEY_PAGEDOWN,
+	"KEY_PAGEUP":                   KEY_PAGEUP,
+	"KEY_PASTE":                    KEY_PASTE,
+	"KEY_PAUSE":                    KEY_PAUSE,
+	"KEY_PAUSECD":                  KEY_PAUSECD,
+	"KEY_PAUSE_RECORD":             KEY_PAUSE_RECORD,
+	"KEY_PC":                       KEY_PC,
+	"KEY_PHONE":                    KEY_PHONE,
+	"KEY_PICKUP_PHONE":             KEY_PICKUP_PHONE,
+	"KEY_PLAY":                     KEY_PLAY,
+	"KEY_PLAYCD":                   KEY_PLAYCD,
+	"KEY_PLAYER":                   KEY_PLAYER,
+	"KEY_PLAYPAUSE":                KEY_PLAYPAUSE,
+	"KEY_POWER":                    KEY_POWER,
+	"KEY_POWER2":                   KEY_POWER2,
+	"KEY_PRESENTATION":             KEY_PRESENTATION,
+	"KEY_PREVIOUS":                 KEY_PREVIOUS,
+	"KEY_PREVIOUSSONG":             KEY_PREVIOUSSONG,
+	"KEY_PREVIOUS_ELEMENT":         KEY_PREVIOUS_ELEMENT,
+	"KEY_PRINT":                    KEY_PRINT,
+	"KEY_PRIVACY_SCREEN_TOGGLE":    KEY_PRIVACY_SCREEN_TOGGLE,
+	"KEY_PROG1":                    KEY_PROG1,
+	"KEY_PROG2":                    KEY_PROG2,
+	"KEY_PROG3":                    KEY_PROG3,
+	"KEY_PROG4":                    KEY_PROG4,
+	"KEY_PROGRAM":                  KEY_PROGRAM,
+	"KEY_PROPS":                    KEY_PROPS,
+	"KEY_PVR":                      KEY_PVR,
+	"KEY_Q":                        KEY_Q,
+	"KEY_QUESTION":                 KEY_QUESTION,
+	"KEY_R":                        KEY_R,
+	"KEY_RADAR_OVERLAY":            KEY_RADAR_OVERLAY,
+	"KEY_RADIO":                    KEY_RADIO,
+	"KEY_RECORD":                   KEY_RECORD,
+	"KEY_RED":                      KEY_RED,
+	"KEY_REDO":                     KEY_REDO,
+	"KEY_REFRESH":                  KEY_REFRESH,
+	"KEY_REFRESH_RATE_TOGGLE":      KEY_REFRESH_RATE_TOGGLE,
+	"KEY_REPLY":                    KEY_REPLY,
+	"KEY_RESERVED":                 KEY_RESERVED,
+	"KEY_RESTART":                  KEY_RESTART,
+	"KEY_REWIND":                   KEY_REWIND,
+	"KEY_RFKILL":                   KEY_RFKILL,
+	"KEY_RIGHT":                    KEY_RIGHT,
+	"KEY_RIGHTALT":                 KEY_RIGHTALT,
+	"KEY_RIGHTBRACE":               KEY_RIGHTBRACE,
+	"KEY_RIGHTCTRL":                KEY_RIGHTCTRL,
+	"KEY_RIGHTMETA":                KEY_RIGHTMETA,
+	"KEY_RIGHTSHIFT":               KEY_RIGHTSHIFT,
+	"KEY_RIGHT_DOWN":               KEY_RIGHT_DOWN,
+	"KEY_RIGHT_UP":                 KEY_RIGHT_UP,
+	"KEY_RO":                       KEY_RO,
+	"KEY_ROOT_MENU":                KEY_ROOT_MENU,
+	"KEY_ROTATE_DISPLAY":           KEY_ROTATE_DISPLAY,
+	"KEY_ROTATE_LOCK_TOGGLE":       KEY_ROTATE_LOCK_TOGGLE,
+	"KEY_S":                        KEY_S,
+	"KEY_SAT":                      KEY_SAT,
+	"KEY_SAT2":                     KEY_SAT2,
+	"KEY_SAVE":                     KEY_SAVE,
+	"KEY_SCALE":                    KEY_SCALE,
+	"KEY_SCREEN":                   KEY_SCREEN,
+	"KEY_SCREENLOCK":               KEY_SCREENLOCK,
+	"KEY_SCREENSAVER":              KEY_SCREENSAVER,
+	"KEY_SCROLLDOWN":               KEY_SCROLLDOWN,
+	"KEY_SCROLLLOCK":               KEY_SCROLLLOCK,
+	"KEY_SCROLLUP":                 KEY_SCROLLUP,
+	"KEY_SEARCH":                   KEY_SEARCH,
+	"KEY_SELECT":                   KEY_SELECT,
+	"KEY_SELECTIVE_SCREENSHOT":     KEY_SELECTIVE_SCREENSHOT,
+	"KEY_SEMICOLON":                KEY_SEMICOLON,
+	"KEY_SEND":                     KEY_SEND,
+	"KEY_SENDFILE":                 KEY_SENDFILE,
+	"KEY_SETUP":                    KEY_SETUP,
+	"KEY_SHOP":                     KEY_SHOP,
+	"KEY_SHUFFLE":                  KEY_SHUFFLE,
+	"KEY_SIDEVU_SONAR":             KEY_SIDEVU_SONAR,
+	"KEY_SINGLE_RANGE_RADAR":       KEY_SINGLE_RANGE_RADAR,
+	"KEY_SLASH":                    KEY_SLASH,
+	"KEY_SLEEP":                    KEY_SLEEP,
+	"KEY_SLOW":                     KEY_SLOW,
+	"KEY_SLOWREVERSE":              KEY_SLOWREVERSE,
+	"KEY_SOS":                      KEY_SOS,
+	"KEY_SOUND":                    KEY_SOUND,
+	"KEY_SPACE":                    KEY_SPACE,
+	"KEY_SPELLCHECK":               KEY_SPELLCHECK,
+	"KEY_SPORT":                    KEY_SPORT,
+	"KEY_SPREADSHEET":              KEY_SPREADSHEET,
+	"KEY_STOP":                     KEY_STOP,
+	"KEY_STOPCD":                   KEY_STOPCD,
+	"KEY_STOP_RECORD":              KEY_STOP_RECORD,
+	"KEY_SUBTITLE":                 KEY_SUBTITLE,
+	"KEY_SUSPEND":                  KEY_SUSPEND,
+	"KEY_SWITCHVIDEOMODE":          KEY_SWITCHVIDEOMODE,
+	"KEY_SYSRQ":                    KEY_SYSRQ,
+	"KEY_T":                        KEY_T,
+	"KEY_TAB":                      KEY_TAB,
+	"KEY_TAPE":                     KEY_TAPE,
+	"KEY_TASKMANAGER":              KEY_TASKMANAGER,
+	"KEY_TEEN":                     KEY_TEEN,
+	"KEY_TEXT":                     KEY_TEXT,
+	"KEY_TIME":                     KEY_TIME,
+	"KEY_TITLE":                    KEY_TITLE,
+	"KEY_TOUCHPAD_OFF":             KEY_TOUCHPAD_OFF,
+	"KEY_TOUCHPAD_ON":              KEY_TOUCHPAD_ON,
+	"KEY_TOUCHPAD_TOGGLE":          KEY_TOUCHPAD_TOGGLE,
+	"KEY_TRADITIONAL_SONAR":        KEY_TRADITIONAL_SONAR,
+	"KEY_TUNER":                    KEY_TUNER,
+	"KEY_TV":                       KEY_TV,
+	"KEY_TV2":                      KEY_TV2,
+	"KEY_TWEN":                     KEY_TWEN,
+	"KEY_U":                        KEY_U,
+	"KEY_UNDO":                     KEY_UNDO,
+	"KEY_UNKNOWN":                  KEY_UNKNOWN,
+	"KEY_UNMUTE":                   KEY_UNMUTE,
+	"KEY_UP":                       KEY_UP,
+	"KEY_UWB":                      KEY_UWB,
+	"KEY_V":                        KEY_V,
+	"KEY_VCR":                      KEY_VCR,
+	"KEY_VCR2":                     KEY_VCR2,
+	"KEY_VENDOR":                   KEY_VENDOR,
+	"KEY_VIDEO":                    KEY_VIDEO,
+	"KEY_VIDEOPHONE":               KEY_VIDEOPHONE,
+	"KEY_VIDEO_NEXT":               KEY_VIDEO_NEXT,
+	"KEY_VIDEO_PREV":               KEY_VIDEO_PREV,
+	"KEY_VOD":                      KEY_VOD,
+	"KEY_VOICECOMMAND":             KEY_VOICECOMMAND,
+	"KEY_VOICEMAIL":                KEY_VOICEMAIL,
+	"KEY_VOLUMEDOWN":               KEY_VOLUMEDOWN,
+	"KEY_VOLUMEUP":                 KEY_VOLUMEUP,
+	"KEY_W":                        KEY_W,
+	"KEY_WAKEUP":                   KEY_WAKEUP,
+	"KEY_WIMAX":                    KEY_WIMAX,
+	"KEY_WLAN":                     KEY_WLAN,
+	"KEY_WORDPROCESSOR":            KEY_WORDPROCESSOR,
+	"KEY_WPS_BUTTON":               KEY_WPS_BUTTON,
+	"KEY_WWAN":                     KEY_WWAN,
+	"KEY_WWW":                      KEY_WWW,
+	"KEY_X":                        KEY_X,
+	"KEY_XFER":                     KEY_XFER,
+	"KEY_Y":                        KEY_Y,
+	"KEY_YELLOW":                   KEY_YELLOW,
+	"KEY_YEN":                      KEY_YEN,
+	"KEY_Z":                        KEY_Z,
+	"KEY_ZENKAKUHANKAKU":           KEY_ZENKAKUHANKAKU,
+	"KEY_ZOOM":                     KEY_ZOOM,
+	"KEY_ZOOMIN":                   KEY_ZOOMIN,
+	"KEY_ZOOMOUT":                  KEY_ZOOMOUT,
+	"KEY_ZOOMRESET":                KEY_ZOOMRESET,
+}
 
 // LED
 const (
@@ -1495,6 +2298,21 @@ var LEDName = map[EvCode]string{
 	9:  "LED_MAIL",
 	10: "LED_CHARGING",
 }
+var LEDByName = map[string]EvCode{
+	"LED_CAPSL":    LED_CAPSL,
+	"LED_CHARGING": LED_CHARGING,
+	"LED_CNT":      LED_CNT,
+	"LED_COMPOSE":  LED_COMPOSE,
+	"LED_KANA":     LED_KANA,
+	"LED_MAIL":     LED_MAIL,
+	"LED_MAX":      LED_MAX,
+	"LED_MISC":     LED_MISC,
+	"LED_MUTE":     LED_MUTE,
+	"LED_NUML":     LED_NUML,
+	"LED_SCROLLL":  LED_SCROLLL,
+	"LED_SLEEP":    LED_SLEEP,
+	"LED_SUSPEND":  LED_SUSPEND,
+}
 
 // MSC
 const (
@@ -1516,6 +2334,16 @@ var MSCName = map[EvCode]string{
 	4: "MSC_SCAN",
 	5: "MSC_TIMESTAMP",
 }
+var MSCByName = map[string]EvCode{
+	"MSC_CNT":       MSC_CNT,
+	"MSC_GESTURE":   MSC_GESTURE,
+	"MSC_MAX":       MSC_MAX,
+	"MSC_PULSELED":  MSC_PULSELED,
+	"MSC_RAW":       MSC_RAW,
+	"MSC_SCAN":      MSC_SCAN,
+	"MSC_SERIAL":    MSC_SERIAL,
+	"MSC_TIMESTAMP": MSC_TIMESTAMP,
+}
 
 // MT
 const (
@@ -1533,28 +2361,45 @@ var MTName = map[EvCode]string{
 	10: "MT_TOOL_DIAL",
 	15: "MT_TOOL_MAX",
 }
+var MTByName = map[string]EvCode{
+	"MT_TOOL_DIAL":   MT_TOOL_DIAL,
+	"MT_TOOL_FINGER": MT_TOOL_FINGER,
+	"MT_TOOL_MAX":    MT_TOOL_MAX,
+	"MT_TOOL_PALM":   MT_TOOL_PALM,
+	"MT_TOOL_PEN":    MT_TOOL_PEN,
+}
 
 // PROP
 const (
-	PROP_ACCELEROMETER = 0x06
-	PROP_BUTTONPAD     = 0x02
-	PROP_DIRECT        = 0x01
-	PROP_INTER         = 0x00
-	PROP_INTING_STICK  = 0x05
-	PROP_MAX           = 0x1f
-	PROP_SEMI_MT       = 0x03
-	PROP_TOPBUTTONPAD  = 0x04
+	PROP_ACCELEROMETER  = 0x06
+	PROP_BUTTONPAD      = 0x02
+	PROP_DIRECT         = 0x01
+	PROP_MAX            = 0x1f
+	PROP_POINTER        = 0x00
+	PROP_POINTING_STICK = 0x05
+	PROP_SEMI_MT        = 0x03
+	PROP_TOPBUTTONPAD   = 0x04
 )
 
 var PROPName = map[EvProp]string{
-	0: "PROP_INTER",
+	0: "PROP_POINTER",
 	1: "PROP_DIRECT",
 	2: "PROP_BUTTONPAD",
 	3: "PROP_SEMI_MT",
 	4: "PROP_TOPBUTTONPAD",
-	5: "PROP_INTING_STICK",
+	5: "PROP_POINTING_STICK",
 	6: "PROP_ACCELEROMETER",
 }
+var PROPByName = map[string]EvProp{
+	"PROP_ACCELEROMETER":  PROP_ACCELEROMETER,
+	"PROP_BUTTONPAD":      PROP_BUTTONPAD,
+	"PROP_DIRECT":         PROP_DIRECT,
+	"PROP_MAX":            PROP_MAX,
+	"PROP_POINTER":        PROP_POINTER,
+	"PROP_POINTING_STICK": PROP_POINTING_STICK,
+	"PROP_SEMI_MT":        PROP_SEMI_MT,
+	"PROP_TOPBUTTONPAD":   PROP_TOPBUTTONPAD,
+}
 
 // REL
 const (
@@ -1590,6 +2435,23 @@ var RELName = map[EvCode]string{
 	11: "REL_WHEEL_HI_RES",
 	12: "REL_HWHEEL_HI_RES",
 }
+var RELByName = map[string]EvCode{
+	"REL_CNT":           REL_CNT,
+	"REL_DIAL":          REL_DIAL,
+	"REL_HWHEEL":        REL_HWHEEL,
+	"REL_HWHEEL_HI_RES": REL_HWHEEL_HI_RES,
+	"REL_MAX":           REL_MAX,
+	"REL_MISC":          REL_MISC,
+	"REL_RESERVED":      REL_RESERVED,
+	"REL_RX":            REL_RX,
+	"REL_RY":            REL_RY,
+	"REL_RZ":            REL_RZ,
+	"REL_WHEEL":         REL_WHEEL,
+	"REL_WHEEL_HI_RES":  REL_WHEEL_HI_RES,
+	"REL_X":             REL_X,
+	"REL_Y":             REL_Y,
+	"REL_Z":             REL_Z,
+}
 
 // REP
 const (
@@ -1603,6 +2465,12 @@ var REPName = map[EvCode]string{
 	0: "REP_DELAY",
 	1: "REP_PERIOD",
 }
+var REPByName = map[string]EvCode{
+	"REP_CNT":    REP_CNT,
+	"REP_DELAY":  REP_DELAY,
+	"REP_MAX":    REP_MAX,
+	"REP_PERIOD": REP_PERIOD,
+}
 
 // SND
 const (
@@ -1618,6 +2486,13 @@ var SNDName = map[EvCode]string{
 	1: "SND_BELL",
 	2: "SND_TONE",
 }
+var SNDByName = map[string]EvCode{
+	"SND_BELL":  SND_BELL,
+	"SND_CLICK": SND_CLICK,
+	"SND_CNT":   SND_CNT,
+	"SND_MAX":   SND_MAX,
+	"SND_TONE":  SND_TONE,
+}
 
 // SW
 const (
@@ -1662,6 +2537,28 @@ var SWName = map[EvCode]string{
 	15: "SW_PEN_INSERTED",
 	16: "SW_MACHINE_COVER",
 }
+var SWByName = map[string]EvCode{
+	"SW_CAMERA_LENS_COVER":    SW_CAMERA_LENS_COVER,
+	"SW_CNT":                  SW_CNT,
+	"SW_DOCK":                 SW_DOCK,
+	"SW_FRONT_PROXIMITY":      SW_FRONT_PROXIMITY,
+	"SW_HEADPHONE_INSERT":     SW_HEADPHONE_INSERT,
+	"SW_JACK_PHYSICAL_INSERT": SW_JACK_PHYSICAL_INSERT,
+	"SW_KEYPAD_SLIDE":         SW_KEYPAD_SLIDE,
+	"SW_LID":                  SW_LID,
+	"SW_LINEIN_INSERT":        SW_LINEIN_INSERT,
+	"SW_LINEOUT_INSERT":       SW_LINEOUT_INSERT,
+	"SW_MACHINE_COVER":        SW_MACHINE_COVER,
+	"SW_MAX":                  SW_MAX,
+	"SW_MICROPHONE_INSERT":    SW_MICROPHONE_INSERT,
+	"SW_MUTE_DEVICE":          SW_MUTE_DEVICE,
+	"SW_PEN_INSERTED":         SW_PEN_INSERTED,
+	"SW_RADIO":                SW_RADIO,
+	"SW_RFKILL_ALL":           SW_RFKILL_ALL,
+	"SW_ROTATE_LOCK":          SW_ROTATE_LOCK,
+	"SW_TABLET_MODE":          SW_TABLET_MODE,
+	"SW_VIDEOOUT_INSERT":      SW_VIDEOOUT_INSERT,
+}
 
 // SYN
 const (
@@ -1679,3 +2576,11 @@ var SYNName = map[EvCode]string{
 	2: "SYN_MT_REPORT",
 	3: "SYN_DROPPED",
 }
+var SYNByName = map[string]EvCode{
+	"SYN_CNT":       SYN_CNT,
+	"SYN_CONFIG":    SYN_CONFIG,
+	"SYN_DROPPED":   SYN_DROPPED,
+	"SYN_MAX":       SYN_MAX,
+	"SYN_MT_REPORT": SYN_MT_REPORT,
+	"SYN_REPORT":    SYN_REPORT,
+}
@@ -0,0 +1,277 @@
+// Package control provides a small RPC interface over a unix domain socket,
+// so long running programs built on evdev can be inspected and controlled at
+// runtime, e.g. to list the devices they manage, toggle grabs, enable or
+// disable remapping rules or query statistics.
+//
+// Requests and responses are lines of JSON:
+//
+//	{"method":"grab","params":{"device":"keyboard"}}
+//	{"result":null}
+//
+// Clients need nothing but a unix socket and a JSON encoder, e.g. socat.
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Handler handles the requests of a method. params is the raw JSON value of
+// the request's params, or null. The result is encoded as JSON.
+type Handler func(params json.RawMessage) (interface{}, error)
+
+type request struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	Result interface{} `json:"result"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// Server serves registered methods to clients connected to a unix socket.
+// The method "methods" lists all registered methods.
+type Server struct {
+	mu        sync.Mutex
+	handlers  map[string]Handler
+	listeners map[net.Listener]bool
+	conns     map[net.Conn]bool
+	wg        sync.WaitGroup
+	closed    bool
+}
+
+// NewServer creates a Server without any methods besides "methods".
+func NewServer() *Server {
+	s := &Server{
+		handlers:  map[string]Handler{},
+		listeners: map[net.Listener]bool{},
+		conns:     map[net.Conn]bool{},
+	}
+
+	s.Handle("methods", func(json.RawMessage) (interface{}, error) {
+		return s.methods(), nil
+	})
+
+	return s
+}
+
+// Handle registers the handler for a method, replacing any previous one.
+// Handlers may be called concurrently for different clients.
+func (s *Server) Handle(method string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handlers[method] = h
+}
+
+func (s *Server) methods() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	methods := make([]string, 0, len(s.handlers))
+	for m := range s.handlers {
+		methods = append(methods, m)
+	}
+
+	sort.Strings(methods)
+
+	return methods
+}
+
+// ListenAndServe listens on the unix socket at path and serves clients until
+// the server is closed. A stale socket file at path is removed first. The
+// socket is created with the permissions of the process umask, so access can
+// be restricted by the permissions of its directory.
+func (s *Server) ListenAndServe(path string) error {
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("Cannot listen on %s: %v", path, err)
+	}
+
+	return s.Serve(l)
+}
+
+// Serve accepts clients on l until the server is closed, in which case nil
+// is returned.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		l.Close()
+		return nil
+	}
+	s.listeners[l] = true
+	s.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			delete(s.listeners, l)
+			s.mu.Unlock()
+
+			if closed {
+				return nil
+			}
+
+			return err
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			continue
+		}
+		s.conns[conn] = true
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go s.serveConn(conn)
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer s.wg.Done()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+
+		conn.Close()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	enc := json.NewEncoder(conn)
+
+	for scanner.Scan() {
+		if err := enc.Encode(s.call(scanner.Bytes())); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) call(line []byte) response {
+	req := request{}
+	if err := json.Unmarshal(line, &req); err != nil {
+		return response{Error: fmt.Sprintf("Invalid request: %v", err)}
+	}
+
+	s.mu.Lock()
+	h, ok := s.handlers[req.Method]
+	s.mu.Unlock()
+
+	if !ok {
+		return response{Error: fmt.Sprintf("Unknown method %q", req.Method)}
+	}
+
+	result, err := h(req.Params)
+	if err != nil {
+		return response{Error: err.Error()}
+	}
+
+	return response{Result: result}
+}
+
+// Close stops all listeners, disconnects all clients and waits for the
+// requests in progress.
+func (s *Server) Close() {
+	s.mu.Lock()
+	s.closed = true
+
+	for l := range s.listeners {
+		l.Close()
+	}
+
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+// Client calls the methods of a Server.
+type Client struct {
+	mu      sync.Mutex
+	conn    net.Conn
+	scanner *bufio.Scanner
+}
+
+// Dial connects to the server listening on the unix socket at path.
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot connect to %s: %v", path, err)
+	}
+
+	return &Client{
+		conn:    conn,
+		scanner: bufio.NewScanner(conn),
+	}, nil
+}
+
+// Call calls a method with the given params, which are encoded as JSON, and
+// decodes its result into result, unless result is nil.
+func (c *Client) Call(method string, params interface{}, result interface{}) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("Cannot encode params: %v", err)
+	}
+
+	req, err := json.Marshal(request{Method: method, Params: raw})
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.conn.Write(append(req, '\n')); err != nil {
+		return err
+	}
+
+	if !c.scanner.Scan() {
+		if err := c.scanner.Err(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("Connection closed by server")
+	}
+
+	resp := struct {
+		Result json.RawMessage `json:"result"`
+		Error  string          `json:"error"`
+	}{}
+
+	if err := json.Unmarshal(c.scanner.Bytes(), &resp); err != nil {
+		return fmt.Errorf("Invalid response: %v", err)
+	}
+
+	if resp.Error != "" {
+		return fmt.Errorf("%s", resp.Error)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(resp.Result, result)
+}
+
+// Close closes the connection to the server.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
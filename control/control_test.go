@@ -0,0 +1,93 @@
+package control
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/neodaemmerung/go-evdev"
+)
+
+func TestServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "evdev-control")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "control.sock")
+
+	s := NewServer()
+	rules := HandleRules(s)
+	HandleDevices(s)
+
+	script, err := evdev.ParseScript("code == KEY_A -> code = KEY_B")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr := rules.Add("swap", script)
+
+	done := make(chan error)
+	go func() {
+		done <- s.ListenAndServe(path)
+	}()
+
+	// wait for the server to listen
+	var c *Client
+	for c == nil {
+		c, _ = Dial(path)
+		time.Sleep(time.Millisecond)
+	}
+	defer c.Close()
+
+	methods := []string{}
+	if err := c.Call("methods", nil, &methods); err != nil {
+		t.Fatalf("Call(methods) error = %v", err)
+	}
+
+	want := []string{"devices", "disable", "enable", "grab", "methods", "rules", "ungrab"}
+	if !reflect.DeepEqual(methods, want) {
+		t.Errorf("methods = %v, want %v", methods, want)
+	}
+
+	frame := []evdev.InputEvent{{Type: evdev.EV_KEY, Code: evdev.KEY_A, Value: 1}}
+
+	if got := tr.ProcessFrame(frame); got[0].Code != evdev.KEY_B {
+		t.Errorf("enabled rules changed KEY_A to %s", evdev.CodeName(evdev.EV_KEY, got[0].Code))
+	}
+
+	if err := c.Call("disable", nameParams{Name: "swap"}, nil); err != nil {
+		t.Fatalf("Call(disable) error = %v", err)
+	}
+
+	if got := tr.ProcessFrame(frame); got[0].Code != evdev.KEY_A {
+		t.Errorf("disabled rules changed KEY_A to %s", evdev.CodeName(evdev.EV_KEY, got[0].Code))
+	}
+
+	infos := []RuleInfo{}
+	if err := c.Call("rules", nil, &infos); err != nil || !reflect.DeepEqual(infos, []RuleInfo{{Name: "swap"}}) {
+		t.Errorf("Call(rules) = %v, %v", infos, err)
+	}
+
+	if err := c.Call("enable", nameParams{Name: "nope"}, nil); err == nil {
+		t.Error("Call(enable) of unknown rules succeeded")
+	}
+
+	if err := c.Call("grab", nameParams{Name: "keyboard"}, nil); err == nil {
+		t.Error("Call(grab) of unknown device succeeded")
+	}
+
+	if err := c.Call("nope", nil, nil); err == nil {
+		t.Error("Call() of unknown method succeeded")
+	}
+
+	s.Close()
+
+	if err := <-done; err != nil {
+		t.Errorf("ListenAndServe() error = %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/neodaemmerung/go-evdev"
+)
+
+type nameParams struct {
+	Name string `json:"name"`
+}
+
+func decodeName(params json.RawMessage) (string, error) {
+	p := nameParams{}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return "", fmt.Errorf("Invalid params: %v", err)
+	}
+
+	return p.Name, nil
+}
+
+// DeviceInfo describes a device in the response of the "devices" method.
+type DeviceInfo struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Device  string `json:"device"` // the name the device reports
+	Seat    string `json:"seat"`
+	Grabbed bool   `json:"grabbed"`
+}
+
+// Devices is a set of named devices controlled through a Server with the
+// methods "devices", which lists them, and "grab" and "ungrab", which take
+// the name of a device as {"name":"..."}.
+type Devices struct {
+	mu      sync.Mutex
+	devices map[string]*evdev.InputDevice
+	grabbed map[string]bool
+}
+
+// HandleDevices registers the device methods with s.
+func HandleDevices(s *Server) *Devices {
+	ds := &Devices{
+		devices: map[string]*evdev.InputDevice{},
+		grabbed: map[string]bool{},
+	}
+
+	s.Handle("devices", func(json.RawMessage) (interface{}, error) {
+		return ds.list(), nil
+	})
+
+	s.Handle("grab", func(params json.RawMessage) (interface{}, error) {
+		return nil, ds.setGrab(params, true)
+	})
+
+	s.Handle("ungrab", func(params json.RawMessage) (interface{}, error) {
+		return nil, ds.setGrab(params, false)
+	})
+
+	return ds
+}
+
+// Add adds a device under the given name. If grabbed is true, the device was
+// grabbed by the caller.
+func (ds *Devices) Add(name string, d *evdev.InputDevice, grabbed bool) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	ds.devices[name] = d
+	ds.grabbed[name] = grabbed
+}
+
+// Remove removes a device, e.g. after it was closed.
+func (ds *Devices) Remove(name string) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	delete(ds.devices, name)
+	delete(ds.grabbed, name)
+}
+
+func (ds *Devices) list() []DeviceInfo {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	infos := []DeviceInfo{}
+
+	for name, d := range ds.devices {
+		devName, _ := d.Name()
+		seat, _ := d.Seat()
+
+		infos = append(infos, DeviceInfo{
+			Name:    name,
+			Path:    d.Path(),
+			Device:  devName,
+			Seat:    seat,
+			Grabbed: ds.grabbed[name],
+		})
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+
+	return infos
+}
+
+func (ds *Devices) setGrab(params json.RawMessage, grab bool) error {
+	name, err := decodeName(params)
+	if err != nil {
+		return err
+	}
+
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	d, ok := ds.devices[name]
+	if !ok {
+		return fmt.Errorf("Unknown device %q", name)
+	}
+
+	if grab {
+		err = d.Grab()
+	} else {
+		err = d.Ungrab()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	ds.grabbed[name] = grab
+
+	return nil
+}
+
+// RuleInfo describes a rule set in the response of the "rules" method.
+type RuleInfo struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Rules is a set of named transforms that can be enabled and disabled
+// through a Server with the methods "rules", which lists them, and "enable"
+// and "disable", which take the name of a rule set as {"name":"..."}.
+type Rules struct {
+	mu    sync.Mutex
+	rules map[string]*toggle
+}
+
+type toggle struct {
+	t       evdev.Transform
+	rt      *evdev.ReloadableTransform
+	enabled bool
+}
+
+// HandleRules registers the rule methods with s.
+func HandleRules(s *Server) *Rules {
+	rs := &Rules{
+		rules: map[string]*toggle{},
+	}
+
+	s.Handle("rules", func(json.RawMessage) (interface{}, error) {
+		return rs.list(), nil
+	})
+
+	s.Handle("enable", func(params json.RawMessage) (interface{}, error) {
+		return nil, rs.setEnabled(params, true)
+	})
+
+	s.Handle("disable", func(params json.RawMessage) (interface{}, error) {
+		return nil, rs.setEnabled(params, false)
+	})
+
+	return rs
+}
+
+// Add adds an enabled transform under the given name and returns a Transform
+// to use in its place, which passes frames through t while it is enabled and
+// unchanged otherwise. Toggling takes effect between frames and releases
+// the keys held through the rules, like ReloadableTransform.Swap.
+func (rs *Rules) Add(name string, t evdev.Transform) evdev.Transform {
+	tg := &toggle{t: t, rt: evdev.NewReloadableTransform(t), enabled: true}
+
+	rs.mu.Lock()
+	rs.rules[name] = tg
+	rs.mu.Unlock()
+
+	return tg.rt
+}
+
+func (rs *Rules) list() []RuleInfo {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	infos := []RuleInfo{}
+	for name, tg := range rs.rules {
+		infos = append(infos, RuleInfo{Name: name, Enabled: tg.enabled})
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+
+	return infos
+}
+
+func (rs *Rules) setEnabled(params json.RawMessage, enabled bool) error {
+	name, err := decodeName(params)
+	if err != nil {
+		return err
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	tg, ok := rs.rules[name]
+	if !ok {
+		return fmt.Errorf("Unknown rules %q", name)
+	}
+
+	if tg.enabled == enabled {
+		return nil
+	}
+
+	tg.enabled = enabled
+
+	if enabled {
+		tg.rt.Swap(tg.t)
+	} else {
+		tg.rt.Swap(evdev.Chain())
+	}
+
+	return nil
+}
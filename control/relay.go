@@ -0,0 +1,439 @@
+package control
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/neodaemmerung/go-evdev"
+)
+
+// RelayAuthorizer decides whether the process with the given credentials may
+// receive the events of a device, and which ones, in the format of
+// evdev.SinkOptions.Allow. A nil allow-list grants all events.
+type RelayAuthorizer func(cred *syscall.Ucred, device string) (allow map[evdev.EvType][]evdev.EvCode, ok bool)
+
+// AllowUIDs returns a RelayAuthorizer granting all events of all devices to
+// processes running as one of the given users.
+func AllowUIDs(uids ...uint32) RelayAuthorizer {
+	return func(cred *syscall.Ucred, device string) (map[evdev.EvType][]evdev.EvCode, bool) {
+		for _, uid := range uids {
+			if cred.Uid == uid {
+				return nil, true
+			}
+		}
+
+		return nil, false
+	}
+}
+
+// relayWriteTimeout is how long writing events to a client may block before
+// the client is disconnected, so clients that stop reading can't stall the
+// hub.
+var relayWriteTimeout = 5 * time.Second
+
+// relayRequest is the first line a relay client sends.
+type relayRequest struct {
+	Device string                          `json:"device"`
+	Allow  map[evdev.EvType][]evdev.EvCode `json:"allow,omitempty"`
+}
+
+// Relay passes the events of devices read by a privileged process to
+// clients connected to a unix socket, so they don't need access to
+// /dev/input themselves. Clients are identified by their credentials
+// (SO_PEERCRED), which an authorizer checks for each device.
+//
+// A client sends a line of JSON naming the device and optionally the events
+// it wants:
+//
+//	{"device":"keyboard","allow":{"1":[114,115]}}
+//
+// and receives a response line like the control server's, followed by the
+// events in the kernel's binary input_event format. The connection is
+// closed when the device's hub stops, the device is removed or the client
+// doesn't keep up with reading.
+type Relay struct {
+	authorize RelayAuthorizer
+
+	mu        sync.Mutex
+	hubs      map[string]*evdev.Hub
+	sinks     map[*evdev.HubSink]string // sinks of the clients by device
+	listeners map[net.Listener]bool
+	conns     map[net.Conn]bool
+	wg        sync.WaitGroup
+	closed    bool
+}
+
+// NewRelay creates a Relay that checks clients with authorize.
+func NewRelay(authorize RelayAuthorizer) *Relay {
+	return &Relay{
+		authorize: authorize,
+		hubs:      map[string]*evdev.Hub{},
+		sinks:     map[*evdev.HubSink]string{},
+		listeners: map[net.Listener]bool{},
+		conns:     map[net.Conn]bool{},
+	}
+}
+
+// AddDevice makes the events distributed by hub available to clients under
+// the given name. The hub is run by the caller.
+func (r *Relay) AddDevice(name string, hub *evdev.Hub) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.hubs[name] = hub
+}
+
+// RemoveDevice stops offering the device and disconnects its clients.
+func (r *Relay) RemoveDevice(name string) {
+	r.mu.Lock()
+	delete(r.hubs, name)
+
+	sinks := []*evdev.HubSink{}
+	for hs, device := range r.sinks {
+		if device == name {
+			sinks = append(sinks, hs)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, hs := range sinks {
+		hs.Remove()
+	}
+}
+
+// ListenAndServe listens on the unix socket at path and serves clients until
+// the relay is closed, like Server.ListenAndServe.
+func (r *Relay) ListenAndServe(path string) error {
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("Cannot listen on %s: %v", path, err)
+	}
+
+	return r.Serve(l)
+}
+
+// Serve accepts clients on l until the relay is closed, in which case nil
+// is returned. l must be a unix socket listener.
+func (r *Relay) Serve(l net.Listener) error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		l.Close()
+		return nil
+	}
+	r.listeners[l] = true
+	r.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			r.mu.Lock()
+			closed := r.closed
+			delete(r.listeners, l)
+			r.mu.Unlock()
+
+			if closed {
+				return nil
+			}
+
+			return err
+		}
+
+		r.mu.Lock()
+		if r.closed {
+			r.mu.Unlock()
+			conn.Close()
+			continue
+		}
+		r.conns[conn] = true
+		r.wg.Add(1)
+		r.mu.Unlock()
+
+		go r.serveConn(conn)
+	}
+}
+
+func peerCred(conn net.Conn) (*syscall.Ucred, error) {
+	uc, ok := conn.(*net.UnixConn)
+	if !ok {
+		return nil, errors.New("Not a unix socket connection")
+	}
+
+	raw, err := uc.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+
+	var cred *syscall.Ucred
+	var credErr error
+
+	err = raw.Control(func(fd uintptr) {
+		cred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cred, credErr
+}
+
+func (r *Relay) serveConn(conn net.Conn) {
+	defer r.wg.Done()
+
+	defer func() {
+		r.mu.Lock()
+		delete(r.conns, conn)
+		r.mu.Unlock()
+
+		conn.Close()
+	}()
+
+	br := bufio.NewReader(conn)
+	enc := json.NewEncoder(conn)
+
+	line, err := br.ReadBytes('\n')
+	if err != nil {
+		return
+	}
+
+	device, hub, allow, err := r.subscribe(conn, line)
+	if err != nil {
+		enc.Encode(response{Error: err.Error()})
+		return
+	}
+
+	// the sink is added before responding, so clients receive all events
+	// after the response, but must not write before it
+	w := &lockedWriter{conn: conn}
+	w.mu.Lock()
+
+	sink := hub.AddSink(evdev.WriterSink(w), evdev.SinkOptions{
+		Overflow: evdev.OverflowDropOldest,
+		Allow:    allow,
+	})
+	defer sink.Remove()
+
+	r.mu.Lock()
+	removed := r.hubs[device] != hub
+	r.sinks[sink] = device
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		delete(r.sinks, sink)
+		r.mu.Unlock()
+	}()
+
+	if removed {
+		w.mu.Unlock()
+		enc.Encode(response{Error: fmt.Sprintf("Device %q not available", device)})
+		return
+	}
+
+	err = enc.Encode(response{})
+	w.mu.Unlock()
+
+	if err != nil {
+		return
+	}
+
+	// clients send nothing further, so this returns once they disconnect
+	go func() {
+		io.Copy(ioutil.Discard, br)
+		sink.Remove()
+	}()
+
+	<-sink.Done()
+}
+
+// lockedWriter writes to a client connection, failing if a write blocks
+// for longer than relayWriteTimeout.
+type lockedWriter struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (lw *lockedWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	lw.conn.SetWriteDeadline(time.Now().Add(relayWriteTimeout))
+
+	return lw.conn.Write(p)
+}
+
+// subscribe checks the request of a client and returns the requested
+// device, its hub and the events the client is allowed to receive.
+func (r *Relay) subscribe(conn net.Conn, line []byte) (string, *evdev.Hub, map[evdev.EvType][]evdev.EvCode, error) {
+	req := relayRequest{}
+	if err := json.Unmarshal(line, &req); err != nil {
+		return "", nil, nil, fmt.Errorf("Invalid request: %v", err)
+	}
+
+	cred, err := peerCred(conn)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("Cannot get peer credentials: %v", err)
+	}
+
+	r.mu.Lock()
+	hub, ok := r.hubs[req.Device]
+	r.mu.Unlock()
+
+	granted, authorized := r.authorize(cred, req.Device)
+
+	// unknown and forbidden devices are indistinguishable to clients
+	if !ok || !authorized {
+		return "", nil, nil, fmt.Errorf("Device %q not available", req.Device)
+	}
+
+	allow := intersectAllow(granted, req.Allow)
+	if allow != nil && len(allow) == 0 {
+		return "", nil, nil, fmt.Errorf("No requested events allowed for device %q", req.Device)
+	}
+
+	return req.Device, hub, allow, nil
+}
+
+// intersectAllow returns the events allowed by both allow-lists. nil allows
+// all events, as do types without codes. The result is nil if both are
+// nil, and empty if they have no events in common.
+func intersectAllow(a, b map[evdev.EvType][]evdev.EvCode) map[evdev.EvType][]evdev.EvCode {
+	if a == nil {
+		return b
+	}
+
+	if b == nil {
+		return a
+	}
+
+	allow := map[evdev.EvType][]evdev.EvCode{}
+
+	for t, ac := range a {
+		bc, ok := b[t]
+		switch {
+		case !ok:
+		case len(ac) == 0:
+			allow[t] = bc
+		case len(bc) == 0:
+			allow[t] = ac
+		default:
+			in := map[evdev.EvCode]bool{}
+			for _, c := range ac {
+				in[c] = true
+			}
+
+			codes := []evdev.EvCode{}
+			for _, c := range bc {
+				if in[c] {
+					codes = append(codes, c)
+				}
+			}
+
+			if len(codes) > 0 {
+				allow[t] = codes
+			}
+		}
+	}
+
+	return allow
+}
+
+// Close stops all listeners and disconnects all clients.
+func (r *Relay) Close() {
+	r.mu.Lock()
+	r.closed = true
+
+	for l := range r.listeners {
+		l.Close()
+	}
+
+	for c := range r.conns {
+		c.Close()
+	}
+	r.mu.Unlock()
+
+	r.wg.Wait()
+}
+
+// RelayClient receives the events of a device from a Relay. It is an
+// evdev.EventSource.
+type RelayClient struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// DialRelay connects to the relay listening at path and requests the events
+// of the given device, optionally limited to those in allow.
+func DialRelay(path, device string, allow map[evdev.EvType][]evdev.EvCode) (*RelayClient, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot connect to %s: %v", path, err)
+	}
+
+	c := &RelayClient{conn: conn, r: bufio.NewReader(conn)}
+
+	if err := json.NewEncoder(conn).Encode(relayRequest{Device: device, Allow: allow}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	line, err := c.r.ReadBytes('\n')
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("Cannot read response: %v", err)
+	}
+
+	resp := struct {
+		Error string `json:"error"`
+	}{}
+
+	if err := json.Unmarshal(line, &resp); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("Invalid response: %v", err)
+	}
+
+	if resp.Error != "" {
+		conn.Close()
+		return nil, errors.New(resp.Error)
+	}
+
+	return c, nil
+}
+
+// relayEventSize is the size of an event on the wire.
+var relayEventSize = binary.Size(evdev.InputEvent{})
+
+// Read returns the events received, blocking until there is at least one.
+func (c *RelayClient) Read() ([]evdev.InputEvent, error) {
+	events := []evdev.InputEvent{}
+
+	for len(events) == 0 || c.r.Buffered() >= relayEventSize {
+		e := evdev.InputEvent{}
+		if err := binary.Read(c.r, binary.LittleEndian, &e); err != nil {
+			return events, err
+		}
+
+		events = append(events, e)
+	}
+
+	return events, nil
+}
+
+// Close disconnects from the relay.
+func (c *RelayClient) Close() error {
+	return c.conn.Close()
+}
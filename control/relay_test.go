@@ -0,0 +1,247 @@
+package control
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/neodaemmerung/go-evdev"
+)
+
+// chanSource is an EventSource fed from a channel, ending when it's closed.
+type chanSource chan []evdev.InputEvent
+
+func (c chanSource) Read() ([]evdev.InputEvent, error) {
+	events, ok := <-c
+	if !ok {
+		return nil, io.EOF
+	}
+
+	return events, nil
+}
+
+func TestRelay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "evdev-relay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "relay.sock")
+
+	src := make(chanSource)
+	hub := evdev.NewHub(src)
+	go hub.Run()
+	defer close(src)
+
+	r := NewRelay(func(cred *syscall.Ucred, device string) (map[evdev.EvType][]evdev.EvCode, bool) {
+		if cred.Uid != uint32(os.Getuid()) || cred.Pid != int32(os.Getpid()) {
+			return nil, false
+		}
+
+		// volume keys only
+		return map[evdev.EvType][]evdev.EvCode{evdev.EV_KEY: {evdev.KEY_VOLUMEDOWN, evdev.KEY_VOLUMEUP}}, true
+	})
+	r.AddDevice("keyboard", hub)
+	defer r.Close()
+
+	go r.ListenAndServe(path)
+
+	var c *RelayClient
+	for i := 0; c == nil && i < 1000; i++ {
+		c, err = DialRelay(path, "keyboard", nil)
+		time.Sleep(time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err := DialRelay(path, "mouse", nil); err == nil {
+		t.Errorf("DialRelay() succeeded for an unknown device")
+	}
+
+	only := map[evdev.EvType][]evdev.EvCode{evdev.EV_REL: nil}
+	if _, err := DialRelay(path, "keyboard", only); err == nil {
+		t.Errorf("DialRelay() succeeded for events that aren't allowed")
+	}
+
+	up := map[evdev.EvType][]evdev.EvCode{evdev.EV_KEY: {evdev.KEY_VOLUMEUP}}
+	cu, err := DialRelay(path, "keyboard", up)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cu.Close()
+
+	src <- []evdev.InputEvent{
+		{Type: evdev.EV_KEY, Code: evdev.KEY_A, Value: 1},
+		{Type: evdev.EV_SYN, Code: evdev.SYN_REPORT},
+		{Type: evdev.EV_KEY, Code: evdev.KEY_VOLUMEDOWN, Value: 1},
+		{Type: evdev.EV_KEY, Code: evdev.KEY_VOLUMEUP, Value: 1},
+		{Type: evdev.EV_SYN, Code: evdev.SYN_REPORT},
+	}
+
+	tests := []struct {
+		c     *RelayClient
+		codes []evdev.EvCode
+	}{
+		{c, []evdev.EvCode{evdev.KEY_VOLUMEDOWN, evdev.KEY_VOLUMEUP, evdev.SYN_REPORT}},
+		{cu, []evdev.EvCode{evdev.KEY_VOLUMEUP, evdev.SYN_REPORT}},
+	}
+	for i, tt := range tests {
+		codes := []evdev.EvCode{}
+		for len(codes) < len(tt.codes) {
+			events, err := tt.c.Read()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, e := range events {
+				codes = append(codes, e.Code)
+			}
+		}
+
+		if !reflect.DeepEqual(codes, tt.codes) {
+			t.Errorf("client %d received %v, want %v", i, codes, tt.codes)
+		}
+	}
+}
+
+func TestIntersectAllow(t *testing.T) {
+	type allow = map[evdev.EvType][]evdev.EvCode
+
+	tests := []struct {
+		a, b, want allow
+	}{
+		{nil, nil, nil},
+		{nil, allow{evdev.EV_KEY: nil}, allow{evdev.EV_KEY: nil}},
+		{allow{evdev.EV_KEY: {1, 2}}, allow{evdev.EV_KEY: nil, evdev.EV_REL: nil}, allow{evdev.EV_KEY: {1, 2}}},
+		{allow{evdev.EV_KEY: {1, 2}}, allow{evdev.EV_KEY: {2, 3}}, allow{evdev.EV_KEY: {2}}},
+		{allow{evdev.EV_KEY: {1}}, allow{evdev.EV_KEY: {2}}, allow{}},
+	}
+	for i, tt := range tests {
+		if got := intersectAllow(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%d: intersectAllow() = %v, want %v", i, got, tt.want)
+		}
+	}
+}
+
+func TestRelay_Disconnect(t *testing.T) {
+	dir, err := ioutil.TempDir("", "evdev-relay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "relay.sock")
+
+	r := NewRelay(AllowUIDs(uint32(os.Getuid())))
+	defer r.Close()
+
+	go r.ListenAndServe(path)
+
+	dial := func(device string) *RelayClient {
+		t.Helper()
+
+		var c *RelayClient
+		for i := 0; c == nil && i < 1000; i++ {
+			c, err = DialRelay(path, device, nil)
+			time.Sleep(time.Millisecond)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return c
+	}
+
+	tests := []struct {
+		name string
+		// end ends the client's subscription and returns the error of the
+		// hub's Run
+		end func(src chanSource, run <-chan error, c *RelayClient) error
+	}{
+		{
+			name: "hub stopped",
+			end: func(src chanSource, run <-chan error, c *RelayClient) error {
+				close(src)
+				return <-run
+			},
+		},
+		{
+			name: "device removed",
+			end: func(src chanSource, run <-chan error, c *RelayClient) error {
+				r.RemoveDevice("device")
+				close(src)
+				return <-run
+			},
+		},
+	}
+	for _, tt := range tests {
+		src := make(chanSource)
+		hub := evdev.NewHub(src)
+		run := make(chan error, 1)
+		go func() { run <- hub.Run() }()
+
+		r.AddDevice("device", hub)
+		c := dial("device")
+
+		if err := tt.end(src, run, c); err != io.EOF {
+			t.Errorf("%s: Run() = %v, want %v", tt.name, err, io.EOF)
+		}
+
+		// the client sees the end of the stream after any queued events
+		done := make(chan error, 1)
+		go func() {
+			for {
+				if _, err := c.Read(); err != nil {
+					done <- err
+					return
+				}
+			}
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Errorf("%s: client not disconnected", tt.name)
+		}
+
+		c.Close()
+	}
+}
+
+func TestRelay_SlowClient(t *testing.T) {
+	defer func(old time.Duration) { relayWriteTimeout = old }(relayWriteTimeout)
+	relayWriteTimeout = 50 * time.Millisecond
+
+	// writes to a pipe block until the other end reads, which it never does
+	server, client := net.Pipe()
+	defer client.Close()
+
+	src := make(chanSource)
+	hub := evdev.NewHub(src)
+	sink := hub.AddSink(evdev.WriterSink(&lockedWriter{conn: server}), evdev.SinkOptions{})
+
+	run := make(chan error, 1)
+	go func() { run <- hub.Run() }()
+
+	src <- []evdev.InputEvent{{Type: evdev.EV_KEY, Code: evdev.KEY_A, Value: 1}}
+	close(src)
+
+	select {
+	case <-run:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the hub is blocked by a client that doesn't read")
+	}
+
+	if err, ok := sink.Err().(net.Error); !ok || !err.Timeout() {
+		t.Errorf("sink error = %v, want a timeout", sink.Err())
+	}
+}
@@ -0,0 +1,424 @@
+package evdev
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ButtonAxis maps a pair of buttons to a synthetic EV_ABS axis, e.g. a
+// d-pad to ABS_HAT0X. Pressing Positive moves the axis to Max, pressing
+// Negative to Min, and releasing both, or pressing both, to the center. If
+// Ramp is set, the axis takes that long to move from the center to Max or
+// Min, like an analog stick would.
+type ButtonAxis struct {
+	Negative, Positive EvCode
+	Axis               EvCode
+	Min, Max           int32
+	Ramp               time.Duration
+}
+
+type buttonAxisState struct {
+	ButtonAxis
+	negative, positive bool
+	value              float64
+	reported           int32
+	time               time.Time
+}
+
+func (s *buttonAxisState) center() float64 {
+	return (float64(s.Min) + float64(s.Max)) / 2
+}
+
+// target returns the position of the buttons.
+func (s *buttonAxisState) target() float64 {
+	switch {
+	case s.positive && !s.negative:
+		return float64(s.Max)
+	case s.negative && !s.positive:
+		return float64(s.Min)
+	}
+
+	return s.center()
+}
+
+// advance moves the axis towards the position of the buttons until now and
+// returns true if its rounded value changed.
+func (s *buttonAxisState) advance(now time.Time) bool {
+	target := s.target()
+
+	if s.Ramp <= 0 || s.time.IsZero() {
+		s.value = target
+	} else {
+		step := (float64(s.Max) - float64(s.Min)) / 2 * float64(now.Sub(s.time)) / float64(s.Ramp)
+
+		if s.value < target {
+			s.value = math.Min(target, s.value+step)
+		} else {
+			s.value = math.Max(target, s.value-step)
+		}
+	}
+
+	s.time = now
+
+	v := int32(math.Round(s.value))
+	if v == s.reported {
+		return false
+	}
+
+	s.reported = v
+
+	return true
+}
+
+// buttonAxisTick is how often ramping axes move between frames.
+const buttonAxisTick = 10 * time.Millisecond
+
+// ButtonsToAxis is a Transform that converts pairs of buttons to axes as
+// configured by ButtonAxis. The events of the buttons are consumed. While
+// an axis ramps, it only moves when frames pass or Tick is called, which
+// pipelines do as it requests.
+type ButtonsToAxis struct {
+	axes []*buttonAxisState
+}
+
+// NewButtonsToAxis creates a ButtonsToAxis transform. Its axes start at
+// their center.
+func NewButtonsToAxis(axes ...ButtonAxis) *ButtonsToAxis {
+	ba := &ButtonsToAxis{}
+
+	for _, a := range axes {
+		s := &buttonAxisState{ButtonAxis: a}
+		s.value = s.center()
+		s.reported = int32(math.Round(s.value))
+
+		ba.axes = append(ba.axes, s)
+	}
+
+	return ba
+}
+
+// events returns the events of the axes marked in changed.
+func (ba *ButtonsToAxis) events(changed []bool, now time.Time) []InputEvent {
+	events := []InputEvent{}
+	tv := syscall.NsecToTimeval(now.UnixNano())
+
+	for i, s := range ba.axes {
+		if changed[i] {
+			events = append(events, InputEvent{Time: tv, Type: EV_ABS, Code: s.Axis, Value: s.reported})
+		}
+	}
+
+	return events
+}
+
+// ProcessFrame implements Transform.
+func (ba *ButtonsToAxis) ProcessFrame(frame []InputEvent) []InputEvent {
+	last := frame[len(frame)-1]
+
+	if last.Type == EV_SYN && last.Code == SYN_DROPPED {
+		// the buttons are picked up again with their next press
+		for _, s := range ba.axes {
+			s.negative, s.positive = false, false
+		}
+
+		return frame
+	}
+
+	now := last.Timestamp()
+
+	// the axes move with the buttons as they were up to this frame
+	changed := make([]bool, len(ba.axes))
+	for i, s := range ba.axes {
+		changed[i] = s.advance(now)
+	}
+
+	out := make([]InputEvent, 0, len(frame)+len(ba.axes))
+	consumed := false
+
+	for _, e := range frame[:len(frame)-1] {
+		mapped := false
+
+		for _, s := range ba.axes {
+			if e.Type != EV_KEY || e.Code != s.Negative && e.Code != s.Positive {
+				continue
+			}
+
+			if e.Code == s.Negative {
+				s.negative = e.Value != 0
+			} else {
+				s.positive = e.Value != 0
+			}
+
+			mapped = true
+		}
+
+		if mapped {
+			consumed = true
+			continue
+		}
+
+		out = append(out, e)
+	}
+
+	for i, s := range ba.axes {
+		if s.advance(now) {
+			changed[i] = true
+		}
+	}
+
+	out = append(out, ba.events(changed, now)...)
+
+	if consumed && len(out) == 0 {
+		return nil
+	}
+
+	return append(out, last)
+}
+
+// MapCapabilities implements CapabilityMapper. The buttons are replaced by
+// the axes.
+func (ba *ButtonsToAxis) MapCapabilities(in Capabilities) Capabilities {
+	for _, s := range ba.axes {
+		in = withoutCodes(in, EV_KEY, s.Negative, s.Positive)
+		in = withCodes(in, EV_ABS, s.Axis)
+	}
+
+	return in
+}
+
+// Tick implements Ticker. It moves ramping axes until now and returns a
+// frame with their new values, or nil if none changed, and the time to move
+// them further while any of them ramps.
+func (ba *ButtonsToAxis) Tick(now time.Time) ([]InputEvent, time.Time) {
+	changed := make([]bool, len(ba.axes))
+	var next time.Time
+
+	for i, s := range ba.axes {
+		changed[i] = s.advance(now)
+
+		if s.value != s.target() {
+			next = now.Add(buttonAxisTick)
+		}
+	}
+
+	events := ba.events(changed, now)
+	if len(events) == 0 {
+		return nil, next
+	}
+
+	return append(events, InputEvent{Time: syscall.NsecToTimeval(now.UnixNano()), Type: EV_SYN, Code: SYN_REPORT}), next
+}
+
+// AxisButton maps an EV_ABS axis to a synthetic button, e.g. an analog
+// trigger to BTN_TL2. The button is pressed when the axis reaches
+// Threshold, or falls to it if Below is set, and released once the axis is
+// back beyond Threshold by more than Hysteresis, so noise around the
+// threshold doesn't make the button flicker.
+type AxisButton struct {
+	Axis       EvCode
+	Button     EvCode
+	Threshold  int32
+	Hysteresis int32
+	Below      bool
+}
+
+type axisButtons struct {
+	buttons []AxisButton
+	pressed []bool
+}
+
+// AxisToButtons returns a Transform that adds button events to the frames
+// of the axes configured by buttons. The events of the axes are kept.
+func AxisToButtons(buttons ...AxisButton) Transform {
+	return &axisButtons{
+		buttons: buttons,
+		pressed: make([]bool, len(buttons)),
+	}
+}
+
+// MapCapabilities implements CapabilityMapper.
+func (ab *axisButtons) MapCapabilities(in Capabilities) Capabilities {
+	for _, b := range ab.buttons {
+		in = withCodes(in, EV_KEY, b.Button)
+	}
+
+	return in
+}
+
+func (ab *axisButtons) ProcessFrame(frame []InputEvent) []InputEvent {
+	out := make([]InputEvent, 0, len(frame))
+
+	for _, e := range frame {
+		out = append(out, e)
+
+		if e.Type != EV_ABS {
+			continue
+		}
+
+		for i, b := range ab.buttons {
+			if b.Axis != e.Code {
+				continue
+			}
+
+			pressed := ab.pressed[i]
+
+			switch {
+			case !b.Below && !pressed:
+				pressed = e.Value >= b.Threshold
+			case !b.Below:
+				pressed = e.Value >= b.Threshold-b.Hysteresis
+			case !pressed:
+				pressed = e.Value <= b.Threshold
+			default:
+				pressed = e.Value <= b.Threshold+b.Hysteresis
+			}
+
+			if pressed == ab.pressed[i] {
+				continue
+			}
+
+			ab.pressed[i] = pressed
+
+			value := int32(0)
+			if pressed {
+				value = 1
+			}
+
+			out = append(out, InputEvent{Time: e.Time, Type: EV_KEY, Code: b.Button, Value: value})
+		}
+	}
+
+	return out
+}
+
+// parseRange parses a range such as "-32768..32767".
+func parseRange(s string) (int32, int32, error) {
+	bounds := strings.SplitN(s, "..", 2)
+	min, err1 := strconv.ParseInt(bounds[0], 0, 32)
+	max, err2 := strconv.ParseInt(bounds[len(bounds)-1], 0, 32)
+
+	if len(bounds) != 2 || err1 != nil || err2 != nil || min >= max {
+		return 0, 0, fmt.Errorf("Invalid range %q", s)
+	}
+
+	return int32(min), int32(max), nil
+}
+
+// ParseButtonAxes parses button axes separated by semicolons or newlines,
+// each the negative and positive button, the axis, its range and
+// optionally the ramp time, e.g.
+//
+//	BTN_DPAD_LEFT BTN_DPAD_RIGHT ABS_X -32768..32767 ramp=150ms
+func ParseButtonAxes(config string) ([]ButtonAxis, error) {
+	axes := []ButtonAxis{}
+
+	for _, line := range strings.FieldsFunc(config, func(r rune) bool { return r == ';' || r == '\n' }) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if len(fields) < 4 || len(fields) > 5 {
+			return nil, fmt.Errorf("Invalid button axis %q", strings.TrimSpace(line))
+		}
+
+		a := ButtonAxis{}
+		ok1, ok2, ok3 := false, false, false
+
+		a.Negative, ok1 = CodeByName(EV_KEY, fields[0])
+		a.Positive, ok2 = CodeByName(EV_KEY, fields[1])
+		a.Axis, ok3 = CodeByName(EV_ABS, fields[2])
+		if !ok1 || !ok2 || !ok3 {
+			return nil, fmt.Errorf("Unknown code in button axis %q", strings.TrimSpace(line))
+		}
+
+		var err error
+		if a.Min, a.Max, err = parseRange(fields[3]); err != nil {
+			return nil, err
+		}
+
+		if len(fields) == 5 {
+			ramp := strings.TrimPrefix(fields[4], "ramp=")
+			if a.Ramp, err = time.ParseDuration(ramp); err != nil || ramp == fields[4] || a.Ramp < 0 {
+				return nil, fmt.Errorf("Invalid ramp %q", fields[4])
+			}
+		}
+
+		axes = append(axes, a)
+	}
+
+	return axes, nil
+}
+
+// ParseAxisButtons parses axis buttons separated by semicolons or newlines,
+// each the axis, the button, "above" or "below", the threshold and
+// optionally the hysteresis, e.g.
+//
+//	ABS_Z BTN_TL2 above 128 hysteresis=16
+func ParseAxisButtons(config string) ([]AxisButton, error) {
+	buttons := []AxisButton{}
+
+	for _, line := range strings.FieldsFunc(config, func(r rune) bool { return r == ';' || r == '\n' }) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if len(fields) < 4 || len(fields) > 5 || fields[2] != "above" && fields[2] != "below" {
+			return nil, fmt.Errorf("Invalid axis button %q", strings.TrimSpace(line))
+		}
+
+		b := AxisButton{Below: fields[2] == "below"}
+		ok1, ok2 := false, false
+
+		b.Axis, ok1 = CodeByName(EV_ABS, fields[0])
+		b.Button, ok2 = CodeByName(EV_KEY, fields[1])
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("Unknown code in axis button %q", strings.TrimSpace(line))
+		}
+
+		threshold, err := strconv.ParseInt(fields[3], 0, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid threshold %q", fields[3])
+		}
+
+		b.Threshold = int32(threshold)
+
+		if len(fields) == 5 {
+			h, err := strconv.ParseInt(strings.TrimPrefix(fields[4], "hysteresis="), 0, 32)
+			if err != nil || h < 0 || !strings.HasPrefix(fields[4], "hysteresis=") {
+				return nil, fmt.Errorf("Invalid hysteresis %q", fields[4])
+			}
+
+			b.Hysteresis = int32(h)
+		}
+
+		buttons = append(buttons, b)
+	}
+
+	return buttons, nil
+}
+
+func init() {
+	RegisterTransform("buttonaxis", func(config string) (Transform, error) {
+		axes, err := ParseButtonAxes(config)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewButtonsToAxis(axes...), nil
+	})
+
+	RegisterTransform("axisbutton", func(config string) (Transform, error) {
+		buttons, err := ParseAxisButtons(config)
+		if err != nil {
+			return nil, err
+		}
+
+		return AxisToButtons(buttons...), nil
+	})
+}
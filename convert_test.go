@@ -0,0 +1,155 @@
+package evdev
+
+import (
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestButtonsToAxis(t *testing.T) {
+	at := func(ms int64) syscall.Timeval { return syscall.NsecToTimeval(ms * int64(time.Millisecond)) }
+	key := func(ms int64, c EvCode, v int32) InputEvent {
+		return InputEvent{Time: at(ms), Type: EV_KEY, Code: c, Value: v}
+	}
+	abs := func(ms int64, v int32) InputEvent {
+		return InputEvent{Time: at(ms), Type: EV_ABS, Code: ABS_X, Value: v}
+	}
+	syn := func(ms int64) InputEvent { return InputEvent{Time: at(ms), Type: EV_SYN, Code: SYN_REPORT} }
+
+	tests := []struct {
+		name   string
+		config string
+		frames [][]InputEvent
+		want   []InputEvent
+	}{
+		{
+			name:   "instant",
+			config: "BTN_DPAD_LEFT BTN_DPAD_RIGHT ABS_X -100..100",
+			frames: [][]InputEvent{
+				{key(10, BTN_DPAD_RIGHT, 1), key(10, BTN_A, 1), syn(10)},
+				{key(20, BTN_DPAD_LEFT, 1), syn(20)},
+				{key(30, BTN_DPAD_RIGHT, 0), syn(30)},
+				{key(40, BTN_DPAD_LEFT, 2), syn(40)},
+				{key(50, BTN_DPAD_LEFT, 0), syn(50)},
+			},
+			want: []InputEvent{
+				key(10, BTN_A, 1), abs(10, 100), syn(10),
+				abs(20, 0), syn(20),
+				abs(30, -100), syn(30),
+				abs(50, 0), syn(50),
+			},
+		},
+		{
+			name:   "ramp",
+			config: "BTN_DPAD_LEFT BTN_DPAD_RIGHT ABS_X -100..100 ramp=100ms",
+			frames: [][]InputEvent{
+				{key(0, BTN_DPAD_RIGHT, 1), syn(0)},
+				{key(50, BTN_A, 1), syn(50)},
+				{key(200, BTN_A, 0), syn(200)},
+				{key(210, BTN_DPAD_RIGHT, 0), syn(210)},
+				{key(260, BTN_A, 1), syn(260)},
+			},
+			want: []InputEvent{
+				key(50, BTN_A, 1), abs(50, 50), syn(50),
+				key(200, BTN_A, 0), abs(200, 100), syn(200),
+				key(260, BTN_A, 1), abs(260, 50), syn(260),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		ba, err := NewTransform("buttonaxis", tt.config)
+		if err != nil {
+			t.Fatalf("%s: NewTransform() error = %v", tt.name, err)
+		}
+
+		got := []InputEvent{}
+		for _, f := range tt.frames {
+			got = append(got, ba.ProcessFrame(f)...)
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestButtonsToAxis_Tick(t *testing.T) {
+	ba := NewButtonsToAxis(ButtonAxis{Negative: BTN_DPAD_UP, Positive: BTN_DPAD_DOWN, Axis: ABS_Y, Min: 0, Max: 200, Ramp: 100 * time.Millisecond})
+
+	ba.ProcessFrame([]InputEvent{{Type: EV_KEY, Code: BTN_DPAD_UP, Value: 1}, {Type: EV_SYN, Code: SYN_REPORT}})
+
+	want := []InputEvent{
+		{Time: syscall.Timeval{Usec: 20000}, Type: EV_ABS, Code: ABS_Y, Value: 80},
+		{Time: syscall.Timeval{Usec: 20000}, Type: EV_SYN, Code: SYN_REPORT},
+	}
+
+	got, next := ba.Tick(time.Unix(0, int64(20*time.Millisecond)))
+	if !reflect.DeepEqual(got, want) || !next.Equal(time.Unix(0, int64(30*time.Millisecond))) {
+		t.Errorf("Tick() = %v, %v, want %v, 30ms", got, next, want)
+	}
+
+	ba.Tick(time.Unix(0, int64(200*time.Millisecond)))
+
+	if got, next := ba.Tick(time.Unix(0, int64(300*time.Millisecond))); got != nil || !next.IsZero() {
+		t.Errorf("Tick() = %v, %v, want nil and no next tick", got, next)
+	}
+}
+
+func TestAxisToButtons(t *testing.T) {
+	ab, err := NewTransform("axisbutton", "ABS_Z BTN_TL2 above 128 hysteresis=16; ABS_Y BTN_DPAD_UP below -50")
+	if err != nil {
+		t.Fatalf("NewTransform() error = %v", err)
+	}
+
+	tests := []struct {
+		code  EvCode
+		value int32
+		want  []InputEvent
+	}{
+		{ABS_Z, 100, nil},
+		{ABS_Z, 130, []InputEvent{{Type: EV_KEY, Code: BTN_TL2, Value: 1}}},
+		{ABS_Z, 113, nil},
+		{ABS_Z, 112, nil},
+		{ABS_Z, 111, []InputEvent{{Type: EV_KEY, Code: BTN_TL2, Value: 0}}},
+		{ABS_Y, -50, []InputEvent{{Type: EV_KEY, Code: BTN_DPAD_UP, Value: 1}}},
+		{ABS_Y, -49, []InputEvent{{Type: EV_KEY, Code: BTN_DPAD_UP, Value: 0}}},
+	}
+
+	for _, tt := range tests {
+		e := InputEvent{Type: EV_ABS, Code: tt.code, Value: tt.value}
+		syn := InputEvent{Type: EV_SYN, Code: SYN_REPORT}
+
+		want := append(append([]InputEvent{e}, tt.want...), syn)
+
+		if got := ab.ProcessFrame([]InputEvent{e, syn}); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s %d: got %v, want %v", CodeName(EV_ABS, tt.code), tt.value, got, want)
+		}
+	}
+}
+
+func TestParseConversionErrors(t *testing.T) {
+	for _, config := range []string{
+		"BTN_DPAD_LEFT BTN_DPAD_RIGHT ABS_X",
+		"BTN_DPAD_LEFT BTN_DPAD_RIGHT REL_X -1..1",
+		"BTN_DPAD_LEFT BTN_DPAD_RIGHT ABS_X 1..-1",
+		"BTN_DPAD_LEFT BTN_DPAD_RIGHT ABS_X -1..1 150ms",
+	} {
+		if _, err := ParseButtonAxes(config); err == nil {
+			t.Errorf("ParseButtonAxes(%q) succeeded", config)
+		}
+	}
+
+	for _, config := range []string{
+		"ABS_Z BTN_TL2 128",
+		"ABS_Z BTN_TL2 over 128",
+		"ABS_Z KEY_BOGUS above 128",
+		"ABS_Z BTN_TL2 above 128 16",
+		"ABS_Z BTN_TL2 above 128 hysteresis=-1",
+	} {
+		if _, err := ParseAxisButtons(config); err == nil {
+			t.Errorf("ParseAxisButtons(%q) succeeded", config)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package evdev
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Curve maps the deflection of an axis, from 0 to 1, to the deflection to
+// report instead. It should map 0 to 0 and 1 to 1.
+type Curve func(x float64) float64
+
+// ExponentialCurve returns a Curve that raises the deflection to the power
+// of exp. Exponents above 1 make the axis less sensitive near the rest
+// position, exponents below 1 more sensitive.
+func ExponentialCurve(exp float64) Curve {
+	return func(x float64) float64 {
+		return math.Pow(x, exp)
+	}
+}
+
+// CurvePoint is a point of a piecewise linear Curve.
+type CurvePoint struct {
+	X, Y float64
+}
+
+// PiecewiseCurve returns a Curve that interpolates linearly between the
+// given points, which must be ordered by X and include X=0 and X=1.
+func PiecewiseCurve(points ...CurvePoint) (Curve, error) {
+	if len(points) < 2 || points[0].X != 0 || points[len(points)-1].X != 1 {
+		return nil, fmt.Errorf("Curve must start at 0 and end at 1")
+	}
+
+	for i := 1; i < len(points); i++ {
+		if points[i].X <= points[i-1].X {
+			return nil, fmt.Errorf("Curve points must be ordered")
+		}
+	}
+
+	points = append([]CurvePoint{}, points...)
+
+	return func(x float64) float64 {
+		i := sort.Search(len(points), func(i int) bool { return points[i].X >= x })
+		if i == 0 {
+			return points[0].Y
+		}
+		if i == len(points) {
+			return points[len(points)-1].Y
+		}
+
+		a, b := points[i-1], points[i]
+
+		return a.Y + (x-a.X)*(b.Y-a.Y)/(b.X-a.X)
+	}, nil
+}
+
+// AxisCurve applies a Curve to an EV_ABS axis with the given range, e.g. as
+// reported by AbsInfos. The deflection of centered axes, like sticks, is
+// measured from the center of the range in both directions, that of other
+// axes, like pedals, from the minimum.
+type AxisCurve struct {
+	Code     EvCode
+	Min, Max int32
+	Centered bool
+	Curve    Curve
+}
+
+func (ac *AxisCurve) apply(v int32) int32 {
+	if ac.Max <= ac.Min {
+		return v
+	}
+
+	min, max := float64(ac.Min), float64(ac.Max)
+	x := math.Max(min, math.Min(max, float64(v)))
+
+	if !ac.Centered {
+		return int32(math.Round(min + ac.Curve((x-min)/(max-min))*(max-min)))
+	}
+
+	center, half := (min+max)/2, (max-min)/2
+	d := (x - center) / half
+
+	if d < 0 {
+		return int32(math.Round(center - ac.Curve(-d)*half))
+	}
+
+	return int32(math.Round(center + ac.Curve(d)*half))
+}
+
+type responseCurves map[EvCode]*AxisCurve
+
+func (rc responseCurves) ProcessFrame(frame []InputEvent) []InputEvent {
+	out := make([]InputEvent, len(frame))
+
+	for i, e := range frame {
+		if ac, ok := rc[e.Code]; ok && e.Type == EV_ABS {
+			e.Value = ac.apply(e.Value)
+		}
+
+		out[i] = e
+	}
+
+	return out
+}
+
+// ResponseCurves returns a Transform that applies the given curves to the
+// values of their axes, so flight or racing controls can be shaped in the
+// same chain as they are remapped. Multitouch axes cannot be shaped. It is
+// registered as "curve", configured by ParseAxisCurves.
+func ResponseCurves(curves ...AxisCurve) (Transform, error) {
+	rc := responseCurves{}
+
+	for i := range curves {
+		ac := curves[i]
+
+		if ac.Code == ABS_MT_SLOT || isMTAxis(ac.Code) {
+			return nil, fmt.Errorf("Cannot shape multitouch axis %s", CodeName(EV_ABS, ac.Code))
+		}
+
+		rc[ac.Code] = &ac
+	}
+
+	return rc, nil
+}
+
+// ParseAxisCurves parses curves separated by semicolons or newlines, each
+// an EV_ABS code, its range, optionally "centered", and either an exponent
+// or the points of a piecewise linear curve, e.g.
+//
+//	ABS_X -32768..32767 centered expo=2; ABS_GAS 0..255 points=0:0,0.5:0.2,1:1
+func ParseAxisCurves(config string) ([]AxisCurve, error) {
+	curves := []AxisCurve{}
+
+	for _, line := range strings.FieldsFunc(config, func(r rune) bool { return r == ';' || r == '\n' }) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("Incomplete curve %q", strings.TrimSpace(line))
+		}
+
+		c, ok := CodeByName(EV_ABS, fields[0])
+		if !ok {
+			return nil, fmt.Errorf("Unknown axis %q", fields[0])
+		}
+
+		ac := AxisCurve{Code: c}
+
+		var err error
+		if ac.Min, ac.Max, err = parseRange(fields[1]); err != nil {
+			return nil, err
+		}
+
+		for _, f := range fields[2:] {
+			switch {
+			case f == "centered":
+				ac.Centered = true
+
+			case strings.HasPrefix(f, "expo="):
+				exp, perr := strconv.ParseFloat(strings.TrimPrefix(f, "expo="), 64)
+				if perr != nil || exp <= 0 {
+					return nil, fmt.Errorf("Invalid exponent %q", f)
+				}
+
+				ac.Curve = ExponentialCurve(exp)
+
+			case strings.HasPrefix(f, "points="):
+				ac.Curve, err = parseCurvePoints(strings.TrimPrefix(f, "points="))
+
+			default:
+				err = fmt.Errorf("Unexpected %q", f)
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("Invalid curve for %s: %v", fields[0], err)
+			}
+		}
+
+		if ac.Curve == nil {
+			return nil, fmt.Errorf("Missing curve for %s", fields[0])
+		}
+
+		curves = append(curves, ac)
+	}
+
+	return curves, nil
+}
+
+func parseCurvePoints(s string) (Curve, error) {
+	points := []CurvePoint{}
+
+	for _, p := range strings.Split(s, ",") {
+		xy := strings.SplitN(p, ":", 2)
+		if len(xy) != 2 {
+			return nil, fmt.Errorf("Invalid point %q", p)
+		}
+
+		x, err1 := strconv.ParseFloat(xy[0], 64)
+		y, err2 := strconv.ParseFloat(xy[1], 64)
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("Invalid point %q", p)
+		}
+
+		points = append(points, CurvePoint{x, y})
+	}
+
+	return PiecewiseCurve(points...)
+}
+
+func init() {
+	RegisterTransform("curve", func(config string) (Transform, error) {
+		curves, err := ParseAxisCurves(config)
+		if err != nil {
+			return nil, err
+		}
+
+		return ResponseCurves(curves...)
+	})
+}
@@ -0,0 +1,57 @@
+package evdev
+
+import (
+	"testing"
+)
+
+func TestResponseCurves(t *testing.T) {
+	c, err := NewTransform("curve", "ABS_X -100..100 centered expo=2; ABS_GAS 0..255 points=0:0,0.5:0.2,1:1")
+	if err != nil {
+		t.Fatalf("NewTransform() error = %v", err)
+	}
+
+	tests := []struct {
+		code  EvCode
+		value int32
+		want  int32
+	}{
+		{ABS_X, 0, 0},
+		{ABS_X, 50, 25},
+		{ABS_X, -50, -25},
+		{ABS_X, 100, 100},
+		{ABS_X, -120, -100},
+		{ABS_GAS, 0, 0},
+		{ABS_GAS, 51, 20},
+		{ABS_GAS, 255, 255},
+		{ABS_Y, 50, 50},
+	}
+
+	for _, tt := range tests {
+		out := c.ProcessFrame([]InputEvent{{Type: EV_ABS, Code: tt.code, Value: tt.value}, {Type: EV_SYN, Code: SYN_REPORT}})
+
+		if got := out[0].Value; got != tt.want {
+			t.Errorf("%s %d: got %d, want %d", CodeName(EV_ABS, tt.code), tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseAxisCurvesErrors(t *testing.T) {
+	for _, config := range []string{
+		"ABS_X -100..100",
+		"ABS_X 100..-100 expo=2",
+		"ABS_X 0..100 expo=-1",
+		"REL_X 0..100 expo=2",
+		"ABS_X 0..100 points=0:0,1",
+		"ABS_X 0..100 points=0.5:0,1:1",
+		"ABS_X 0..100 points=0:0,1:1,0.5:0.5",
+		"ABS_X 0..100 linear",
+	} {
+		if _, err := ParseAxisCurves(config); err == nil {
+			t.Errorf("ParseAxisCurves(%q) succeeded", config)
+		}
+	}
+
+	if _, err := NewTransform("curve", "ABS_MT_POSITION_X 0..100 expo=2"); err == nil {
+		t.Error("NewTransform() succeeded for a multitouch axis")
+	}
+}
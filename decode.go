@@ -0,0 +1,51 @@
+package evdev
+
+import (
+	"bytes"
+	"encoding/binary"
+	"syscall"
+	"unsafe"
+)
+
+// directDecode is true if the memory layout of InputEvent matches the
+// kernel's struct input_event on this architecture, so raw bytes read from
+// the device can be copied into InputEvent values without decoding.
+var directDecode = checkDirectDecode()
+
+func checkDirectDecode() bool {
+	e := InputEvent{}
+	tv := unsafe.Sizeof(syscall.Timeval{})
+
+	if unsafe.Offsetof(e.Type) != tv ||
+		unsafe.Offsetof(e.Code) != tv+2 ||
+		unsafe.Offsetof(e.Value) != tv+4 ||
+		unsafe.Sizeof(e) != tv+8 {
+		return false
+	}
+
+	// the fallback path decodes little endian, so does the kernel on
+	// the architectures we copy directly on
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}
+
+// eventBytes returns the memory backing events as a byte slice.
+func eventBytes(events []InputEvent) []byte {
+	if len(events) == 0 {
+		return nil
+	}
+
+	n := len(events) * eventsize
+	return (*[1 << 30]byte)(unsafe.Pointer(&events[0]))[:n:n]
+}
+
+// decodeEvents fills events from the raw bytes in src, which must hold
+// exactly len(events) kernel input_event structs.
+func decodeEvents(events []InputEvent, src []byte) error {
+	if directDecode {
+		copy(eventBytes(events), src)
+		return nil
+	}
+
+	return binary.Read(bytes.NewReader(src), binary.LittleEndian, events)
+}
@@ -0,0 +1,30 @@
+package evdev
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"syscall"
+	"testing"
+)
+
+func Test_decodeEvents(t *testing.T) {
+	want := []InputEvent{
+		{Time: syscall.Timeval{Sec: 1, Usec: 2}, Type: EV_KEY, Code: KEY_A, Value: 1},
+		{Time: syscall.Timeval{Sec: 3, Usec: 4}, Type: EV_REL, Code: REL_X, Value: -5},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := binary.Write(buf, binary.LittleEndian, want); err != nil {
+		t.Fatal(err)
+	}
+
+	got := make([]InputEvent, len(want))
+	if err := decodeEvents(got, buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decodeEvents() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,80 @@
+package evdev
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"syscall"
+)
+
+// DeviceErrorKind classifies why a device can no longer be read.
+type DeviceErrorKind int
+
+const (
+	// DeviceGone means the device was disconnected (ENODEV).
+	DeviceGone DeviceErrorKind = iota
+	// DeviceIOFailed means the driver reported an I/O error (EIO).
+	DeviceIOFailed
+	// DeviceRevoked means access was given up with Revoke.
+	DeviceRevoked
+)
+
+var deviceErrorKindNames = map[DeviceErrorKind]string{
+	DeviceGone:     "gone",
+	DeviceIOFailed: "failed",
+	DeviceRevoked:  "revoked",
+}
+
+func (k DeviceErrorKind) String() string {
+	name, ok := deviceErrorKindNames[k]
+	if ok {
+		return name
+	}
+
+	return "UNKNOWN"
+}
+
+// DeviceError is returned by Read and ReadOne when the device can no longer
+// be read, and thus by the Hub and subscriptions reading it. It identifies
+// the device, so reconnect logic can look for it again.
+type DeviceError struct {
+	Kind DeviceErrorKind
+	Path string
+	Name string
+	ID   InputID
+	Err  error
+}
+
+func (e *DeviceError) Error() string {
+	return fmt.Sprintf("Device %q at %s %s: %v", e.Name, e.Path, e.Kind, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *DeviceError) Unwrap() error {
+	return e.Err
+}
+
+// deviceError wraps err in a DeviceError if it means that the device is
+// gone, and returns it unchanged otherwise.
+func (d *InputDevice) deviceError(err error) error {
+	var kind DeviceErrorKind
+
+	switch {
+	case errors.Is(err, syscall.ENODEV) && atomic.LoadUint32(&d.revoked) != 0:
+		kind = DeviceRevoked
+	case errors.Is(err, syscall.ENODEV):
+		kind = DeviceGone
+	case errors.Is(err, syscall.EIO):
+		kind = DeviceIOFailed
+	default:
+		return err
+	}
+
+	return &DeviceError{
+		Kind: kind,
+		Path: d.file.Name(),
+		Name: d.name,
+		ID:   d.id,
+		Err:  err,
+	}
+}
@@ -0,0 +1,67 @@
+package evdev
+
+import (
+	"errors"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestInputDevice_deviceError(t *testing.T) {
+	d, w := pipeDevice(t)
+	defer d.Close()
+	defer w.Close()
+
+	d.name = "keyboard"
+	d.id = InputID{BusType: BUS_USB, Vendor: 1, Product: 2}
+
+	pathError := func(errno syscall.Errno) error {
+		return &os.PathError{Op: "read", Path: d.Path(), Err: errno}
+	}
+
+	tests := []struct {
+		err     error
+		revoked bool
+		kind    DeviceErrorKind
+		wrapped bool
+	}{
+		{pathError(syscall.ENODEV), false, DeviceGone, true},
+		{pathError(syscall.EIO), false, DeviceIOFailed, true},
+		{pathError(syscall.ENODEV), true, DeviceRevoked, true},
+		{pathError(syscall.EBADF), false, 0, false},
+		{io.EOF, false, 0, false},
+	}
+	for _, tt := range tests {
+		d.revoked = 0
+		if tt.revoked {
+			d.revoked = 1
+		}
+
+		err := d.deviceError(tt.err)
+
+		var de *DeviceError
+		if !errors.As(err, &de) {
+			if tt.wrapped {
+				t.Errorf("%v: not a DeviceError", tt.err)
+			} else if err != tt.err {
+				t.Errorf("%v: error changed to %v", tt.err, err)
+			}
+
+			continue
+		}
+
+		if !tt.wrapped {
+			t.Errorf("%v: unexpected DeviceError", tt.err)
+			continue
+		}
+
+		if de.Kind != tt.kind || de.Name != d.name || de.ID != d.id || de.Path != d.Path() {
+			t.Errorf("%v: DeviceError = %+v", tt.err, de)
+		}
+
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%v: DeviceError doesn't wrap the read error", tt.err)
+		}
+	}
+}
@@ -1,39 +1,95 @@
 package evdev
 
 import (
-	"bytes"
-	"encoding/binary"
+	"errors"
 	"fmt"
 	"os"
+	"sync"
+	"sync/atomic"
+	"time"
 	"unsafe"
 )
 
 var eventsize = int(unsafe.Sizeof(InputEvent{}))
 
+// defaultReadBatchSize is the maximum number of events returned by a single
+// Read unless changed with SetReadBatchSize.
+const defaultReadBatchSize = 16
+
+// readBufferPool holds byte buffers for the Read paths, so reading from
+// high-frequency devices doesn't allocate a new buffer for each call.
+var readBufferPool = sync.Pool{
+	New: func() interface{} {
+		buffer := make([]byte, eventsize*defaultReadBatchSize)
+		return &buffer
+	},
+}
+
+// ErrReadOnly is returned by the methods writing to a device that was opened
+// read-only.
+var ErrReadOnly = errors.New("device is opened read-only")
+
 // InputDevice represent a Linux kernel input device in userspace.
 // It can be used to query and write device properties, read input events,
 // or grab it for exclusive access.
 type InputDevice struct {
+	droppedCount  uint64 // accessed atomically, keep 64-bit aligned
 	file          *os.File
+	name          string  // queried on open, for DeviceError
+	id            InputID // queried on open, for DeviceError
+	revoked       uint32  // accessed atomically, set by Revoke
 	driverVersion int32
+	readOnly      bool
+	clockID       int32
+	readBatchSize int
+	retryPolicy   RetryPolicy
+	mu            sync.RWMutex // guards panicSwitch and stateCache
+	panicSwitch   *PanicSwitch
+	stateCache    *stateCache
+	subscribers   subscribers
 }
 
 // Open creates a new InputDevice from the given path. Returns an error if
 // the device node could not be opened or its properties failed to read.
+// The device is opened read-only, like with OpenReadOnly.
 func Open(path string) (*InputDevice, error) {
-	d := &InputDevice{}
+	return openDevice(path, os.O_RDONLY)
+}
+
+// OpenReadOnly is like Open. Methods that write to the device, such as
+// WriteOne, SetLED and the force feedback methods, fail with ErrReadOnly.
+func OpenReadOnly(path string) (*InputDevice, error) {
+	return openDevice(path, os.O_RDONLY)
+}
+
+// OpenReadWrite is like Open, but opens the device for reading and writing,
+// as required to set LEDs and to play force feedback effects.
+func OpenReadWrite(path string) (*InputDevice, error) {
+	return openDevice(path, os.O_RDWR)
+}
+
+func openDevice(path string, flag int) (*InputDevice, error) {
+	d := &InputDevice{
+		readBatchSize: defaultReadBatchSize,
+		readOnly:      flag&(os.O_WRONLY|os.O_RDWR) == 0,
+	}
 
 	var err error
-	d.file, err = os.Open(path)
+	d.file, err = os.OpenFile(path, flag, 0)
 	if err != nil {
 		return nil, err
 	}
 
-	d.driverVersion, err = ioctlEVIOCGVERSION(d.file.Fd())
+	d.driverVersion, err = ioctlEVIOCGVERSION(d.fd())
 	if err != nil {
+		d.file.Close()
 		return nil, fmt.Errorf("Cannot get driver version: %v", err)
 	}
 
+	// only used to identify the device once it's gone
+	d.name, _ = ioctlEVIOCGNAME(d.fd())
+	d.id, _ = ioctlEVIOCGID(d.fd())
+
 	return d, nil
 }
 
@@ -59,54 +115,72 @@ func (d *InputDevice) DriverVersion() (int, int, int) {
 
 // Name returns the device's name as reported by the kernel.
 func (d *InputDevice) Name() (string, error) {
-	return ioctlEVIOCGNAME(d.file.Fd())
+	return ioctlEVIOCGNAME(d.fd())
 }
 
 // PhysicalLocation returns the device's physical location as reported by the kernel.
 func (d *InputDevice) PhysicalLocation() (string, error) {
-	return ioctlEVIOCGPHYS(d.file.Fd())
+	return ioctlEVIOCGPHYS(d.fd())
 }
 
 // UniqueID returns the device's unique identifier as reported by the kernel.
 func (d *InputDevice) UniqueID() (string, error) {
-	return ioctlEVIOCGUNIQ(d.file.Fd())
+	return ioctlEVIOCGUNIQ(d.fd())
 }
 
 // InputID returns the device's vendor/product/busType/version information as reported by the kernel.
 func (d *InputDevice) InputID() (InputID, error) {
-	return ioctlEVIOCGID(d.file.Fd())
+	return ioctlEVIOCGID(d.fd())
 }
 
 // CapableTypes returns a slice of EvType that are the device supports
 func (d *InputDevice) CapableTypes() []EvType {
 	types := []EvType{}
 
-	evBits, err := ioctlEVIOCGBIT(d.file.Fd(), 0)
+	evBits, err := ioctlEVIOCGBIT(d.fd(), 0)
 	if err != nil {
 		return []EvType{}
 	}
 
-	evBitmap := newBitmap(evBits)
+	evBitmap := NewBitmap(evBits)
 
-	for _, t := range evBitmap.setBits() {
+	for _, t := range evBitmap.SetBits() {
 		types = append(types, EvType(t))
 	}
 
 	return types
 }
 
+// CapableEvents returns a slice of EvCode that the device supports for the given EvType
+func (d *InputDevice) CapableEvents(t EvType) []EvCode {
+	codes := []EvCode{}
+
+	codeBits, err := ioctlEVIOCGBIT(d.fd(), int(t))
+	if err != nil {
+		return []EvCode{}
+	}
+
+	codeBitmap := NewBitmap(codeBits)
+
+	for _, c := range codeBitmap.SetBits() {
+		codes = append(codes, EvCode(c))
+	}
+
+	return codes
+}
+
 // Properties returns a slice of EvProp that are the device supports
 func (d *InputDevice) Properties() []EvProp {
 	props := []EvProp{}
 
-	propBits, err := ioctlEVIOCGPROP(d.file.Fd())
+	propBits, err := ioctlEVIOCGPROP(d.fd())
 	if err != nil {
 		return []EvProp{}
 	}
 
-	propBitmap := newBitmap(propBits)
+	propBitmap := NewBitmap(propBits)
 
-	for _, p := range propBitmap.setBits() {
+	for _, p := range propBitmap.SetBits() {
 		props = append(props, EvProp(p))
 	}
 
@@ -116,16 +190,16 @@ func (d *InputDevice) Properties() []EvProp {
 // State return a StateMap for the given type. The map will be empty if the requested type
 // is not supported by the device.
 func (d *InputDevice) State(t EvType) (StateMap, error) {
-	fd := d.file.Fd()
+	fd := d.fd()
 
 	evBits, err := ioctlEVIOCGBIT(fd, 0)
 	if err != nil {
 		return nil, fmt.Errorf("Cannot get evBits: %v", err)
 	}
 
-	evBitmap := newBitmap(evBits)
+	evBitmap := NewBitmap(evBits)
 
-	if !evBitmap.bitIsSet(int(t)) {
+	if !evBitmap.IsSet(int(t)) {
 		return StateMap{}, nil
 	}
 
@@ -134,7 +208,7 @@ func (d *InputDevice) State(t EvType) (StateMap, error) {
 		return nil, fmt.Errorf("Cannot get evBits: %v", err)
 	}
 
-	codeBitmap := newBitmap(codeBits)
+	codeBitmap := NewBitmap(codeBits)
 
 	stateBits := []byte{}
 
@@ -155,11 +229,11 @@ func (d *InputDevice) State(t EvType) (StateMap, error) {
 		return nil, err
 	}
 
-	stateBitmap := newBitmap(stateBits)
+	stateBitmap := NewBitmap(stateBits)
 	st := StateMap{}
 
-	for _, code := range codeBitmap.setBits() {
-		st[EvCode(code)] = stateBitmap.bitIsSet(code)
+	for _, code := range codeBitmap.SetBits() {
+		st[EvCode(code)] = stateBitmap.IsSet(code)
 	}
 
 	return st, nil
@@ -169,15 +243,15 @@ func (d *InputDevice) State(t EvType) (StateMap, error) {
 func (d *InputDevice) AbsInfos() (map[EvCode]AbsInfo, error) {
 	a := make(map[EvCode]AbsInfo)
 
-	absBits, err := ioctlEVIOCGBIT(d.file.Fd(), EV_ABS)
+	absBits, err := ioctlEVIOCGBIT(d.fd(), EV_ABS)
 	if err != nil {
 		return nil, fmt.Errorf("Cannot get absBits: %v", err)
 	}
 
-	absBitmap := newBitmap(absBits)
+	absBitmap := NewBitmap(absBits)
 
-	for _, abs := range absBitmap.setBits() {
-		absInfo, err := ioctlEVIOCGABS(d.file.Fd(), abs)
+	for _, abs := range absBitmap.SetBits() {
+		absInfo, err := ioctlEVIOCGABS(d.fd(), abs)
 		if err == nil {
 			a[EvCode(abs)] = absInfo
 		}
@@ -186,60 +260,181 @@ func (d *InputDevice) AbsInfos() (map[EvCode]AbsInfo, error) {
 	return a, nil
 }
 
+// SetAbsInfo changes the range, fuzz, flat and resolution of an axis, e.g.
+// to apply a calibration. The change affects all clients of the device
+// until it is reset, e.g. by unplugging it.
+func (d *InputDevice) SetAbsInfo(code EvCode, info AbsInfo) error {
+	return ioctlEVIOCSABS(d.fd(), int(code), info)
+}
+
 // Grab grabs the device for exclusive access. No other process will receive
 // input events until the device instance is active.
 func (d *InputDevice) Grab() error {
-	return ioctlEVIOCGRAB(d.file.Fd())
+	return ioctlEVIOCGRAB(d.fd(), true)
 }
 
-// Revoke releases a previously taken exclusive use with Grab().
+// Ungrab releases a previously taken exclusive use with Grab().
+func (d *InputDevice) Ungrab() error {
+	return ioctlEVIOCGRAB(d.fd(), false)
+}
+
+// Revoke revokes this file descriptor's access to the device. All further
+// operations on the InputDevice will fail.
 func (d *InputDevice) Revoke() error {
-	return ioctlEVIOCREVOKE(d.file.Fd())
+	atomic.StoreUint32(&d.revoked, 1)
+	return ioctlEVIOCREVOKE(d.fd())
+}
+
+// fd returns the device's file descriptor for ioctls. Unlike File.Fd, it
+// leaves the descriptor in non-blocking mode, so that pending reads can
+// still be interrupted with a read deadline.
+func (d *InputDevice) fd() uintptr {
+	fd := ^uintptr(0)
+
+	if rc, err := d.file.SyscallConn(); err == nil {
+		rc.Control(func(f uintptr) { fd = f })
+	}
+
+	return fd
+}
+
+// interruptRead makes a pending read and all further reads fail with a
+// timeout, without releasing the device like closing it would.
+func (d *InputDevice) interruptRead() {
+	d.file.SetReadDeadline(time.Now())
 }
 
 // Read and return a slice of input events from device.
 func (d *InputDevice) Read() ([]InputEvent, error) {
-	events := make([]InputEvent, 16)
-	buffer := make([]byte, eventsize*16)
+	bufp := readBufferPool.Get().(*[]byte)
+	defer readBufferPool.Put(bufp)
+
+	if cap(*bufp) < eventsize*d.readBatchSize {
+		*bufp = make([]byte, eventsize*d.readBatchSize)
+	}
+
+	buffer := (*bufp)[:eventsize*d.readBatchSize]
 
-	_, err := d.file.Read(buffer)
+	n, err := readRetrying(d.file.Read, buffer, d.retryPolicy)
 	if err != nil {
-		return events, err
+		return []InputEvent{}, d.deviceError(err)
 	}
 
-	b := bytes.NewBuffer(buffer)
-	err = binary.Read(b, binary.LittleEndian, &events)
+	events := make([]InputEvent, n/eventsize)
+
+	err = decodeEvents(events, buffer[:len(events)*eventsize])
 	if err != nil {
-		return events, err
+		return []InputEvent{}, err
 	}
 
-	// remove trailing structures
 	for i := range events {
-		if events[i].Time.Sec == 0 {
-			events = append(events[:i])
-			break
-		}
+		d.inspect(&events[i])
 	}
 
-	return events, err
+	return events, nil
 }
 
 // ReadOne reads one InputEvent from the device. It blocks until an event has
 // been received or an error has occured.
 func (d *InputDevice) ReadOne() (*InputEvent, error) {
-	event := InputEvent{}
-	buffer := make([]byte, eventsize)
+	event := []InputEvent{{}}
+
+	bufp := readBufferPool.Get().(*[]byte)
+	defer readBufferPool.Put(bufp)
 
-	_, err := d.file.Read(buffer)
+	buffer := (*bufp)[:eventsize]
+
+	_, err := readRetrying(d.file.Read, buffer, d.retryPolicy)
 	if err != nil {
-		return &event, err
+		return &event[0], d.deviceError(err)
 	}
 
-	b := bytes.NewBuffer(buffer)
-	err = binary.Read(b, binary.LittleEndian, &event)
+	err = decodeEvents(event, buffer)
 	if err != nil {
 		return nil, err
 	}
 
-	return &event, nil
+	d.inspect(&event[0])
+
+	return &event[0], nil
+}
+
+// ReadOnly returns true if the device was opened read-only.
+func (d *InputDevice) ReadOnly() bool {
+	return d.readOnly
+}
+
+// WriteOne writes an event to the device, which passes it to the driver
+// like an event from userspace, e.g. to set an LED or play a sound.
+func (d *InputDevice) WriteOne(e *InputEvent) error {
+	if d.readOnly {
+		return ErrReadOnly
+	}
+
+	if _, err := d.file.Write(eventBytes([]InputEvent{*e})); err != nil {
+		return fmt.Errorf("Cannot write event: %v", err)
+	}
+
+	return nil
+}
+
+// SetLED turns an LED of the device, such as LED_CAPSL, on or off.
+func (d *InputDevice) SetLED(led EvCode, on bool) error {
+	value := int32(0)
+	if on {
+		value = 1
+	}
+
+	return d.WriteOne(&InputEvent{Type: EV_LED, Code: led, Value: value})
+}
+
+// SetReadBatchSize sets the maximum number of events returned by a single
+// call to Read. The default is 16.
+//
+// Note that this only affects the user-space side. The size of the kernel's
+// per-client event buffer is chosen by the evdev driver based on the
+// device's capabilities and can neither be changed nor queried from
+// userspace. If it overflows, the kernel discards the queued events and
+// reports a SYN_DROPPED event, see DroppedCount.
+func (d *InputDevice) SetReadBatchSize(n int) error {
+	if n < 1 {
+		return fmt.Errorf("Invalid read batch size %d", n)
+	}
+
+	d.readBatchSize = n
+
+	return nil
+}
+
+// ReadBatchSize returns the maximum number of events returned by a single
+// call to Read.
+func (d *InputDevice) ReadBatchSize() int {
+	return d.readBatchSize
+}
+
+// DroppedCount returns the number of SYN_DROPPED events read from the device
+// so far. Each of them indicates that the kernel's event buffer overflowed
+// and events were lost.
+func (d *InputDevice) DroppedCount() uint64 {
+	return atomic.LoadUint64(&d.droppedCount)
+}
+
+// inspect is called for every event read from the device.
+func (d *InputDevice) inspect(e *InputEvent) {
+	if e.Type == EV_SYN && e.Code == SYN_DROPPED {
+		atomic.AddUint64(&d.droppedCount, 1)
+	}
+
+	d.mu.RLock()
+	stateCache := d.stateCache
+	panicSwitch := d.panicSwitch
+	d.mu.RUnlock()
+
+	if stateCache != nil {
+		stateCache.update(e)
+	}
+
+	if panicSwitch != nil {
+		panicSwitch.Process(e)
+	}
 }
@@ -0,0 +1,57 @@
+package evdev
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestInputDevice_ReadOnly(t *testing.T) {
+	d, w := pipeDevice(t)
+	defer d.Close()
+	defer w.Close()
+
+	d.readOnly = true
+
+	calls := map[string]func() error{
+		"WriteOne":      func() error { return d.WriteOne(&InputEvent{Type: EV_LED}) },
+		"SetLED":        func() error { return d.SetLED(LED_CAPSL, true) },
+		"UploadEffect":  func() error { return d.UploadEffect(NewRumbleEffect(1, 1, 0)) },
+		"EraseEffect":   func() error { return d.EraseEffect(0) },
+		"PlayEffect":    func() error { return d.PlayEffect(0, 1) },
+		"SetEffectGain": func() error { return d.SetEffectGain(0xffff) },
+	}
+	for name, call := range calls {
+		if err := call(); err != ErrReadOnly {
+			t.Errorf("%s() error = %v, want %v", name, err, ErrReadOnly)
+		}
+	}
+}
+
+func TestInputDevice_SetLED(t *testing.T) {
+	r, w := pipeDevice(t)
+	defer r.Close()
+
+	// the write end of the pipe acts as the device
+	d := &InputDevice{file: w}
+
+	if err := d.SetLED(LED_CAPSL, true); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	data, err := ioutil.ReadAll(r.file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make([]InputEvent, len(data)/eventsize)
+	if err := decodeEvents(got, data); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []InputEvent{{Type: EV_LED, Code: LED_CAPSL, Value: 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("written events = %v, want %v", got, want)
+	}
+}
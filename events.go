@@ -0,0 +1,171 @@
+package evdev
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// Event is implemented by the typed event structs KeyEvent, RelEvent,
+// AbsEvent, SyncEvent and OtherEvent, so consumers can handle events with a
+// type switch:
+//
+//	switch e := e.(type) {
+//	case KeyEvent:
+//		fmt.Println(e.Name(), e.Value)
+//	case RelEvent:
+//		...
+//	}
+type Event interface {
+	// Raw returns the event as InputEvent.
+	Raw() InputEvent
+	String() string
+}
+
+// KeyEvent is an EV_KEY event, reported by keys and buttons.
+type KeyEvent struct {
+	Time  syscall.Timeval
+	Code  EvCode
+	Value int32 // see State
+}
+
+// NewKeyEvent creates a KeyEvent without timestamp.
+func NewKeyEvent(code EvCode, value int32) KeyEvent {
+	return KeyEvent{Code: code, Value: value}
+}
+
+// Raw returns the event as InputEvent.
+func (e KeyEvent) Raw() InputEvent {
+	return InputEvent{Time: e.Time, Type: EV_KEY, Code: e.Code, Value: e.Value}
+}
+
+// Name returns the name of the key, e.g. "KEY_A".
+func (e KeyEvent) Name() string {
+	return CodeName(EV_KEY, e.Code)
+}
+
+func (e KeyEvent) String() string {
+	return fmt.Sprintf("%s %d", e.Name(), e.Value)
+}
+
+// RelEvent is an EV_REL event, reported by relative axes such as mouse
+// motion and wheels.
+type RelEvent struct {
+	Time  syscall.Timeval
+	Code  EvCode
+	Value int32
+}
+
+// NewRelEvent creates a RelEvent without timestamp.
+func NewRelEvent(code EvCode, value int32) RelEvent {
+	return RelEvent{Code: code, Value: value}
+}
+
+// Raw returns the event as InputEvent.
+func (e RelEvent) Raw() InputEvent {
+	return InputEvent{Time: e.Time, Type: EV_REL, Code: e.Code, Value: e.Value}
+}
+
+// Name returns the name of the axis, e.g. "REL_X".
+func (e RelEvent) Name() string {
+	return CodeName(EV_REL, e.Code)
+}
+
+func (e RelEvent) String() string {
+	return fmt.Sprintf("%s %d", e.Name(), e.Value)
+}
+
+// AbsEvent is an EV_ABS event, reported by absolute axes such as joysticks
+// and touch surfaces.
+type AbsEvent struct {
+	Time  syscall.Timeval
+	Code  EvCode
+	Value int32
+}
+
+// NewAbsEvent creates an AbsEvent without timestamp.
+func NewAbsEvent(code EvCode, value int32) AbsEvent {
+	return AbsEvent{Code: code, Value: value}
+}
+
+// Raw returns the event as InputEvent.
+func (e AbsEvent) Raw() InputEvent {
+	return InputEvent{Time: e.Time, Type: EV_ABS, Code: e.Code, Value: e.Value}
+}
+
+// Name returns the name of the axis, e.g. "ABS_X".
+func (e AbsEvent) Name() string {
+	return CodeName(EV_ABS, e.Code)
+}
+
+func (e AbsEvent) String() string {
+	return fmt.Sprintf("%s %d", e.Name(), e.Value)
+}
+
+// SyncEvent is an EV_SYN event, e.g. the SYN_REPORT ending each frame.
+type SyncEvent struct {
+	Time  syscall.Timeval
+	Code  EvCode
+	Value int32
+}
+
+// NewSyncEvent creates a SyncEvent without timestamp.
+func NewSyncEvent(code EvCode) SyncEvent {
+	return SyncEvent{Code: code}
+}
+
+// Raw returns the event as InputEvent.
+func (e SyncEvent) Raw() InputEvent {
+	return InputEvent{Time: e.Time, Type: EV_SYN, Code: e.Code, Value: e.Value}
+}
+
+// Name returns the name of the event, e.g. "SYN_REPORT".
+func (e SyncEvent) Name() string {
+	return CodeName(EV_SYN, e.Code)
+}
+
+func (e SyncEvent) String() string {
+	return e.Name()
+}
+
+// OtherEvent is an event of any other type, such as EV_MSC or EV_SW.
+type OtherEvent InputEvent
+
+// Raw returns the event as InputEvent.
+func (e OtherEvent) Raw() InputEvent {
+	return InputEvent(e)
+}
+
+func (e OtherEvent) String() string {
+	return fmt.Sprintf("%s %s %d", TypeName(e.Type), CodeName(e.Type, e.Code), e.Value)
+}
+
+// Typed returns the event as one of the typed event structs.
+func (e InputEvent) Typed() Event {
+	switch e.Type {
+	case EV_KEY:
+		return KeyEvent{Time: e.Time, Code: e.Code, Value: e.Value}
+	case EV_REL:
+		return RelEvent{Time: e.Time, Code: e.Code, Value: e.Value}
+	case EV_ABS:
+		return AbsEvent{Time: e.Time, Code: e.Code, Value: e.Value}
+	case EV_SYN:
+		return SyncEvent{Time: e.Time, Code: e.Code, Value: e.Value}
+	default:
+		return OtherEvent(e)
+	}
+}
+
+// ReadEvents is like Read, but returns the events as typed event structs.
+func (d *InputDevice) ReadEvents() ([]Event, error) {
+	events, err := d.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	typed := make([]Event, len(events))
+	for i, e := range events {
+		typed[i] = e.Typed()
+	}
+
+	return typed, nil
+}
@@ -0,0 +1,60 @@
+package evdev
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestInputEvent_Typed(t *testing.T) {
+	tv := syscall.Timeval{Sec: 1, Usec: 2}
+
+	tests := []struct {
+		e    InputEvent
+		want Event
+		str  string
+	}{
+		{
+			e:    InputEvent{Time: tv, Type: EV_KEY, Code: KEY_A, Value: 1},
+			want: KeyEvent{Time: tv, Code: KEY_A, Value: 1},
+			str:  "KEY_A 1",
+		},
+		{
+			e:    InputEvent{Time: tv, Type: EV_REL, Code: REL_WHEEL, Value: -1},
+			want: RelEvent{Time: tv, Code: REL_WHEEL, Value: -1},
+			str:  "REL_WHEEL -1",
+		},
+		{
+			e:    InputEvent{Time: tv, Type: EV_ABS, Code: ABS_X, Value: 100},
+			want: AbsEvent{Time: tv, Code: ABS_X, Value: 100},
+			str:  "ABS_X 100",
+		},
+		{
+			e:    InputEvent{Time: tv, Type: EV_SYN, Code: SYN_REPORT},
+			want: SyncEvent{Time: tv, Code: SYN_REPORT},
+			str:  "SYN_REPORT",
+		},
+		{
+			e:    InputEvent{Time: tv, Type: EV_SW, Code: SW_LID, Value: 1},
+			want: OtherEvent{Time: tv, Type: EV_SW, Code: SW_LID, Value: 1},
+			str:  "EV_SW SW_LID 1",
+		},
+	}
+	for _, tt := range tests {
+		got := tt.e.Typed()
+		if got != tt.want {
+			t.Errorf("Typed() = %#v, want %#v", got, tt.want)
+		}
+
+		if got.Raw() != tt.e {
+			t.Errorf("Raw() = %v, want %v", got.Raw(), tt.e)
+		}
+
+		if got.String() != tt.str {
+			t.Errorf("String() = %q, want %q", got.String(), tt.str)
+		}
+	}
+
+	if e := NewKeyEvent(KEY_B, 0).Raw(); e != (InputEvent{Type: EV_KEY, Code: KEY_B}) {
+		t.Errorf("NewKeyEvent().Raw() = %v", e)
+	}
+}
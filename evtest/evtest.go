@@ -0,0 +1,206 @@
+// Package evtest creates virtual input devices through uinput and reads them
+// back, to test code that handles input events against the kernel's input
+// subsystem instead of recorded data.
+//
+// Creating uinput devices requires write access to /dev/uinput and read
+// access to the created event device nodes, so tests using the package
+// should call Require to skip where that's not available.
+package evtest
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	evdev "github.com/neodaemmerung/go-evdev"
+)
+
+// uinputPath is the device node of the uinput driver.
+var uinputPath = "/dev/uinput"
+
+// DefaultTimeout is how long a Loopback waits for its device node to appear
+// and for expected events to arrive.
+var DefaultTimeout = 5 * time.Second
+
+// Available returns an error if uinput devices can't be created.
+func Available() error {
+	f, err := os.OpenFile(uinputPath, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("uinput is not available: %v", err)
+	}
+
+	return f.Close()
+}
+
+// Loopback is a virtual device together with its event device node opened
+// for reading, so the events injected into it can be checked.
+type Loopback struct {
+	Virtual *evdev.UInputDevice
+	Device  *evdev.InputDevice
+
+	frames chan []evdev.InputEvent
+	stop   chan struct{}
+	done   chan struct{}
+	err    error
+}
+
+// NewLoopback creates the device described by b and opens it for reading.
+func NewLoopback(b *evdev.UInputBuilder) (*Loopback, error) {
+	virtual, err := b.Create()
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := virtual.DevicePath(DefaultTimeout)
+	if err != nil {
+		virtual.Close()
+		return nil, err
+	}
+
+	// udev may still be adjusting the permissions of the new node
+	var dev *evdev.InputDevice
+	for deadline := time.Now().Add(DefaultTimeout); ; time.Sleep(10 * time.Millisecond) {
+		dev, err = evdev.Open(path)
+		if err == nil || time.Now().After(deadline) {
+			break
+		}
+	}
+
+	if err != nil {
+		virtual.Close()
+		return nil, err
+	}
+
+	l := &Loopback{
+		Virtual: virtual,
+		Device:  dev,
+		frames:  make(chan []evdev.InputEvent, 256),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go l.read()
+
+	return l, nil
+}
+
+// Require is like NewLoopback, but skips the test if uinput is not
+// available and fails it if the device can't be created.
+func Require(t testing.TB, b *evdev.UInputBuilder) *Loopback {
+	t.Helper()
+
+	if err := Available(); err != nil {
+		t.Skip(err)
+	}
+
+	l, err := NewLoopback(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return l
+}
+
+// read splits the events of the device into frames.
+func (l *Loopback) read() {
+	defer close(l.done)
+	defer close(l.frames)
+
+	frame := []evdev.InputEvent{}
+
+	for {
+		events, err := l.Device.Read()
+		if err != nil {
+			l.err = err
+			return
+		}
+
+		for _, e := range events {
+			if e.Type == evdev.EV_SYN && e.Code == evdev.SYN_REPORT {
+				select {
+				case l.frames <- frame:
+				case <-l.stop:
+					return
+				}
+
+				frame = []evdev.InputEvent{}
+
+				continue
+			}
+
+			frame = append(frame, e)
+		}
+	}
+}
+
+// Inject writes each frame to the virtual device, followed by a SYN_REPORT.
+func (l *Loopback) Inject(frames ...[]evdev.InputEvent) error {
+	for _, frame := range frames {
+		if err := l.Virtual.WriteFrame(frame...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReadFrame returns the events of the next frame read from the device,
+// without the SYN_REPORT, waiting up to DefaultTimeout for it.
+func (l *Loopback) ReadFrame() ([]evdev.InputEvent, error) {
+	select {
+	case frame, ok := <-l.frames:
+		if !ok {
+			return nil, fmt.Errorf("Cannot read frame: %v", l.err)
+		}
+
+		return frame, nil
+
+	case <-time.After(DefaultTimeout):
+		return nil, fmt.Errorf("No frame within %v", DefaultTimeout)
+	}
+}
+
+// Expect reads as many frames as given and compares their types, codes and
+// values, ignoring the timestamps. It returns an error describing the first
+// difference.
+func (l *Loopback) Expect(frames ...[]evdev.InputEvent) error {
+	for i, want := range frames {
+		got, err := l.ReadFrame()
+		if err != nil {
+			return fmt.Errorf("frame %d: %v", i, err)
+		}
+
+		if err := compareFrame(got, want); err != nil {
+			return fmt.Errorf("frame %d: %v", i, err)
+		}
+	}
+
+	return nil
+}
+
+func compareFrame(got, want []evdev.InputEvent) error {
+	for i := 0; i < len(got) || i < len(want); i++ {
+		switch {
+		case i >= len(got):
+			return fmt.Errorf("missing %s", want[i].Typed().String())
+		case i >= len(want):
+			return fmt.Errorf("unexpected %s", got[i].Typed().String())
+		case got[i].Type != want[i].Type || got[i].Code != want[i].Code || got[i].Value != want[i].Value:
+			return fmt.Errorf("got %s, want %s", got[i].Typed().String(), want[i].Typed().String())
+		}
+	}
+
+	return nil
+}
+
+// Close closes the device node and destroys the virtual device.
+func (l *Loopback) Close() error {
+	close(l.stop)
+	l.Device.Close()
+	err := l.Virtual.Close()
+
+	<-l.done
+
+	return err
+}
@@ -0,0 +1,49 @@
+package evtest
+
+import (
+	"testing"
+
+	evdev "github.com/neodaemmerung/go-evdev"
+)
+
+func key(code evdev.EvCode, value int32) evdev.InputEvent {
+	return evdev.InputEvent{Type: evdev.EV_KEY, Code: code, Value: value}
+}
+
+func TestCompareFrame(t *testing.T) {
+	frame := []evdev.InputEvent{key(evdev.KEY_A, 1), key(evdev.KEY_B, 1)}
+
+	tests := []struct {
+		got     []evdev.InputEvent
+		wantErr bool
+	}{
+		{frame, false},
+		{frame[:1], true},
+		{append(frame, key(evdev.KEY_C, 1)), true},
+		{[]evdev.InputEvent{key(evdev.KEY_A, 1), key(evdev.KEY_B, 0)}, true},
+	}
+	for i, tt := range tests {
+		if err := compareFrame(tt.got, frame); (err != nil) != tt.wantErr {
+			t.Errorf("%d: err = %v, want error %v", i, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLoopback(t *testing.T) {
+	l := Require(t, evdev.NewUInputBuilder("evtest keyboard").WithCodes(evdev.EV_KEY, evdev.KEY_A, evdev.KEY_B))
+	defer l.Close()
+
+	frames := [][]evdev.InputEvent{
+		{key(evdev.KEY_A, 1)},
+		{key(evdev.KEY_A, 0), key(evdev.KEY_B, 1)},
+		{key(evdev.KEY_B, 0)},
+	}
+
+	if err := l.Inject(frames...); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := l.Expect(frames...); err != nil {
+		t.Error(err)
+	}
+}
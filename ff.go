@@ -0,0 +1,210 @@
+package evdev
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// EffectTrigger configures a button that starts an effect.
+type EffectTrigger struct {
+	Button   uint16 // button code, 0 if none
+	Interval uint16 // minimum time between triggers in ms
+}
+
+// EffectReplay configures the timing of an effect.
+type EffectReplay struct {
+	Length uint16 // duration in ms, 0 for infinite
+	Delay  uint16 // delay before playing in ms
+}
+
+// Envelope shapes the start and end of constant, ramp and periodic effects.
+type Envelope struct {
+	AttackLength uint16 // duration of the attack in ms
+	AttackLevel  uint16 // level at the start of the attack
+	FadeLength   uint16 // duration of the fade in ms
+	FadeLevel    uint16 // level at the end of the fade
+}
+
+// RumbleEffect is an FF_RUMBLE effect, driving the strong (low frequency)
+// and weak (high frequency) motor of e.g. a gamepad.
+type RumbleEffect struct {
+	StrongMagnitude uint16
+	WeakMagnitude   uint16
+}
+
+// PeriodicEffect is an FF_PERIODIC effect. Custom waveforms are not
+// supported.
+type PeriodicEffect struct {
+	Waveform  EvCode // FF_SQUARE, FF_TRIANGLE, FF_SINE, FF_SAW_UP or FF_SAW_DOWN
+	Period    uint16 // in ms
+	Magnitude int16
+	Offset    int16
+	Phase     uint16
+	Envelope  Envelope
+}
+
+// ConstantEffect is an FF_CONSTANT effect.
+type ConstantEffect struct {
+	Level    int16
+	Envelope Envelope
+}
+
+// RampEffect is an FF_RAMP effect.
+type RampEffect struct {
+	StartLevel int16
+	EndLevel   int16
+	Envelope   Envelope
+}
+
+// ConditionEffect configures one axis of an FF_SPRING, FF_FRICTION,
+// FF_DAMPER or FF_INERTIA effect.
+type ConditionEffect struct {
+	RightSaturation uint16
+	LeftSaturation  uint16
+	RightCoeff      int16
+	LeftCoeff       int16
+	Deadband        uint16
+	Center          int16
+}
+
+// Effect is a force feedback effect. Only the parameters matching its Type
+// are used.
+type Effect struct {
+	Type      EvCode // FF_RUMBLE, FF_PERIODIC, FF_CONSTANT, ...
+	ID        int16  // assigned by UploadEffect, -1 for a new effect
+	Direction uint16 // 0x0000 is down, 0x4000 left, 0x8000 up and 0xc000 right
+	Trigger   EffectTrigger
+	Replay    EffectReplay
+
+	Rumble    RumbleEffect
+	Periodic  PeriodicEffect
+	Constant  ConstantEffect
+	Ramp      RampEffect
+	Condition [2]ConditionEffect // x and y axis
+}
+
+// ffEffect is the kernel's struct ff_effect. The union of effect parameters
+// ends with a pointer in struct ff_periodic_effect, which determines its size.
+type ffEffect struct {
+	typ       uint16
+	id        int16
+	direction uint16
+	trigger   EffectTrigger
+	replay    EffectReplay
+	_         uint16
+	u         [24 + unsafe.Sizeof(uintptr(0))]byte
+}
+
+func (e *Effect) encode() (ffEffect, error) {
+	fe := ffEffect{
+		typ:       uint16(e.Type),
+		id:        e.ID,
+		direction: e.Direction,
+		trigger:   e.Trigger,
+		replay:    e.Replay,
+	}
+
+	// all parameters used are 16-bit values
+	u := (*[12]uint16)(unsafe.Pointer(&fe.u[0]))
+
+	envelope := func(i int, env Envelope) {
+		u[i], u[i+1], u[i+2], u[i+3] = env.AttackLength, env.AttackLevel, env.FadeLength, env.FadeLevel
+	}
+
+	switch e.Type {
+	case FF_RUMBLE:
+		u[0], u[1] = e.Rumble.StrongMagnitude, e.Rumble.WeakMagnitude
+
+	case FF_PERIODIC:
+		p := &e.Periodic
+		u[0], u[1], u[2], u[3], u[4] = uint16(p.Waveform), p.Period, uint16(p.Magnitude), uint16(p.Offset), p.Phase
+		envelope(5, p.Envelope)
+
+	case FF_CONSTANT:
+		u[0] = uint16(e.Constant.Level)
+		envelope(1, e.Constant.Envelope)
+
+	case FF_RAMP:
+		u[0], u[1] = uint16(e.Ramp.StartLevel), uint16(e.Ramp.EndLevel)
+		envelope(2, e.Ramp.Envelope)
+
+	case FF_SPRING, FF_FRICTION, FF_DAMPER, FF_INERTIA:
+		for i, c := range e.Condition {
+			j := i * 6
+			u[j], u[j+1], u[j+2], u[j+3], u[j+4], u[j+5] = c.RightSaturation, c.LeftSaturation,
+				uint16(c.RightCoeff), uint16(c.LeftCoeff), c.Deadband, uint16(c.Center)
+		}
+
+	default:
+		return fe, fmt.Errorf("Unsupported effect type %d", e.Type)
+	}
+
+	return fe, nil
+}
+
+// UploadEffect uploads an effect to the device. If e.ID is -1, a new effect
+// is created and e.ID is set to its ID. Otherwise, the existing effect is
+// updated, which also works while it is playing.
+func (d *InputDevice) UploadEffect(e *Effect) error {
+	if d.readOnly {
+		return ErrReadOnly
+	}
+
+	fe, err := e.encode()
+	if err != nil {
+		return err
+	}
+
+	err = ioctlEVIOCSFF(d.fd(), &fe)
+	if err != nil {
+		return fmt.Errorf("Cannot upload effect: %v", err)
+	}
+
+	e.ID = fe.id
+
+	return nil
+}
+
+// EraseEffect removes an uploaded effect from the device.
+func (d *InputDevice) EraseEffect(id int16) error {
+	if d.readOnly {
+		return ErrReadOnly
+	}
+
+	err := ioctlEVIOCRMFF(d.fd(), id)
+	if err != nil {
+		return fmt.Errorf("Cannot erase effect %d: %v", id, err)
+	}
+
+	return nil
+}
+
+// PlayEffect plays an uploaded effect count times. Like all force feedback
+// methods, it requires the device to be opened with OpenReadWrite.
+func (d *InputDevice) PlayEffect(id int16, count int32) error {
+	return d.writeFF(EvCode(id), count)
+}
+
+// StopEffect stops an effect that is playing.
+func (d *InputDevice) StopEffect(id int16) error {
+	return d.writeFF(EvCode(id), 0)
+}
+
+// SetEffectGain sets the overall strength of all effects, from 0 to 0xffff,
+// on devices supporting FF_GAIN.
+func (d *InputDevice) SetEffectGain(gain uint16) error {
+	return d.writeFF(FF_GAIN, int32(gain))
+}
+
+func (d *InputDevice) writeFF(code EvCode, value int32) error {
+	if d.readOnly {
+		return ErrReadOnly
+	}
+
+	_, err := d.file.Write(eventBytes([]InputEvent{{Type: EV_FF, Code: code, Value: value}}))
+	if err != nil {
+		return fmt.Errorf("Cannot write force feedback event: %v", err)
+	}
+
+	return nil
+}
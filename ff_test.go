@@ -0,0 +1,35 @@
+package evdev
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestEffect_encode(t *testing.T) {
+	// struct ff_effect is 48 bytes on 64-bit and 44 bytes on 32-bit systems
+	if size := unsafe.Sizeof(ffEffect{}); size != 40+unsafe.Sizeof(uintptr(0)) {
+		t.Errorf("size of ffEffect = %d", size)
+	}
+
+	e := Effect{
+		Type:     FF_PERIODIC,
+		ID:       -1,
+		Replay:   EffectReplay{Length: 500},
+		Periodic: PeriodicEffect{Waveform: FF_SINE, Period: 20, Magnitude: -2, Envelope: Envelope{FadeLevel: 7}},
+	}
+
+	fe, err := e.encode()
+	if err != nil {
+		t.Fatalf("encode() error = %v", err)
+	}
+
+	u := (*[12]uint16)(unsafe.Pointer(&fe.u[0]))
+	if fe.typ != FF_PERIODIC || fe.id != -1 || fe.replay.Length != 500 ||
+		u[0] != FF_SINE || u[1] != 20 || u[2] != 0xfffe || u[8] != 7 {
+		t.Errorf("encode() = %+v", fe)
+	}
+
+	if _, err := (&Effect{Type: FF_GAIN}).encode(); err == nil {
+		t.Error("encode() of FF_GAIN effect succeeded")
+	}
+}
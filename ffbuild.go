@@ -0,0 +1,163 @@
+package evdev
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// effectMs converts a duration to the milliseconds used by effects, capped
+// at 65.535s.
+func effectMs(d time.Duration) uint16 {
+	ms := d / time.Millisecond
+	switch {
+	case ms < 0:
+		return 0
+	case ms > math.MaxUint16:
+		return math.MaxUint16
+	}
+
+	return uint16(ms)
+}
+
+func ffCodeName(c EvCode) string {
+	if name, ok := FFName[c]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("0x%x", uint16(c))
+}
+
+// NewRumbleEffect creates an FF_RUMBLE effect playing for d.
+func NewRumbleEffect(strong, weak uint16, d time.Duration) *Effect {
+	return &Effect{
+		Type:   FF_RUMBLE,
+		ID:     -1,
+		Replay: EffectReplay{Length: effectMs(d)},
+		Rumble: RumbleEffect{StrongMagnitude: strong, WeakMagnitude: weak},
+	}
+}
+
+// NewPeriodicEffect creates an FF_PERIODIC effect with the given waveform,
+// one of FF_SQUARE, FF_TRIANGLE, FF_SINE, FF_SAW_UP and FF_SAW_DOWN,
+// playing for d.
+func NewPeriodicEffect(waveform EvCode, period time.Duration, magnitude int16, d time.Duration) *Effect {
+	return &Effect{
+		Type:   FF_PERIODIC,
+		ID:     -1,
+		Replay: EffectReplay{Length: effectMs(d)},
+		Periodic: PeriodicEffect{
+			Waveform:  waveform,
+			Period:    effectMs(period),
+			Magnitude: magnitude,
+		},
+	}
+}
+
+// NewConstantEffect creates an FF_CONSTANT effect playing for d.
+func NewConstantEffect(level int16, d time.Duration) *Effect {
+	return &Effect{
+		Type:     FF_CONSTANT,
+		ID:       -1,
+		Replay:   EffectReplay{Length: effectMs(d)},
+		Constant: ConstantEffect{Level: level},
+	}
+}
+
+// NewRampEffect creates an FF_RAMP effect changing from start to end level
+// over d.
+func NewRampEffect(start, end int16, d time.Duration) *Effect {
+	return &Effect{
+		Type:   FF_RAMP,
+		ID:     -1,
+		Replay: EffectReplay{Length: effectMs(d)},
+		Ramp:   RampEffect{StartLevel: start, EndLevel: end},
+	}
+}
+
+// WithEnvelope sets the envelope of a periodic, constant or ramp effect: the
+// level rises from attackLevel over the attack duration and falls to
+// fadeLevel over the fade duration. It returns e.
+func (e *Effect) WithEnvelope(attack time.Duration, attackLevel uint16, fade time.Duration, fadeLevel uint16) *Effect {
+	env := Envelope{
+		AttackLength: effectMs(attack),
+		AttackLevel:  attackLevel,
+		FadeLength:   effectMs(fade),
+		FadeLevel:    fadeLevel,
+	}
+
+	e.Periodic.Envelope = env
+	e.Constant.Envelope = env
+	e.Ramp.Envelope = env
+
+	return e
+}
+
+// WithDirection sets the direction of the effect in degrees, counterclockwise
+// from down, i.e. 90 is left, 180 up and 270 right. It returns e.
+func (e *Effect) WithDirection(degrees float64) *Effect {
+	degrees = math.Mod(degrees, 360)
+	if degrees < 0 {
+		degrees += 360
+	}
+
+	e.Direction = uint16(degrees / 360 * 0x10000)
+
+	return e
+}
+
+// WithDelay sets the delay before the effect starts playing. It returns e.
+func (e *Effect) WithDelay(d time.Duration) *Effect {
+	e.Replay.Delay = effectMs(d)
+	return e
+}
+
+// Validate checks the parameters of the effect and, if supported is not nil,
+// that its type and waveform are among the supported EV_FF codes, e.g. as
+// returned by CapableEvents(EV_FF).
+func (e *Effect) Validate(supported []EvCode) error {
+	switch e.Type {
+	case FF_RUMBLE, FF_CONSTANT, FF_RAMP, FF_SPRING, FF_FRICTION, FF_DAMPER, FF_INERTIA:
+	case FF_PERIODIC:
+		if e.Periodic.Waveform < FF_SQUARE || e.Periodic.Waveform > FF_SAW_DOWN {
+			return fmt.Errorf("Unsupported waveform %d", e.Periodic.Waveform)
+		}
+
+		if e.Periodic.Period == 0 {
+			return fmt.Errorf("Periodic effect without period")
+		}
+	default:
+		return fmt.Errorf("Unsupported effect type %d", e.Type)
+	}
+
+	env := e.Periodic.Envelope
+	switch e.Type {
+	case FF_CONSTANT:
+		env = e.Constant.Envelope
+	case FF_RAMP:
+		env = e.Ramp.Envelope
+	}
+
+	if e.Replay.Length > 0 && uint32(env.AttackLength)+uint32(env.FadeLength) > uint32(e.Replay.Length) {
+		return fmt.Errorf("Envelope is longer than the effect")
+	}
+
+	if supported == nil {
+		return nil
+	}
+
+	has := map[EvCode]bool{}
+	for _, c := range supported {
+		has[c] = true
+	}
+
+	if !has[e.Type] {
+		return fmt.Errorf("Device does not support %s", ffCodeName(e.Type))
+	}
+
+	if e.Type == FF_PERIODIC && !has[e.Periodic.Waveform] {
+		return fmt.Errorf("Device does not support %s", ffCodeName(e.Periodic.Waveform))
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEffect_Validate(t *testing.T) {
+	supported := []EvCode{FF_RUMBLE, FF_PERIODIC, FF_SINE}
+
+	tests := []struct {
+		name      string
+		e         *Effect
+		supported []EvCode
+		wantErr   bool
+	}{
+		{
+			name:      "sine",
+			e:         NewPeriodicEffect(FF_SINE, 20*time.Millisecond, 0x4000, time.Second).WithEnvelope(100*time.Millisecond, 0, 200*time.Millisecond, 0),
+			supported: supported,
+		},
+		{
+			name:      "unsupported waveform",
+			e:         NewPeriodicEffect(FF_SQUARE, 20*time.Millisecond, 0x4000, time.Second),
+			supported: supported,
+			wantErr:   true,
+		},
+		{
+			name:      "unsupported type",
+			e:         NewConstantEffect(0x1000, time.Second),
+			supported: supported,
+			wantErr:   true,
+		},
+		{
+			name: "any device",
+			e:    NewRampEffect(0, 0x1000, time.Second).WithDirection(90),
+		},
+		{
+			name:    "no period",
+			e:       NewPeriodicEffect(FF_SINE, 0, 0x4000, time.Second),
+			wantErr: true,
+		},
+		{
+			name:    "envelope too long",
+			e:       NewConstantEffect(0x1000, 100*time.Millisecond).WithEnvelope(80*time.Millisecond, 0, 80*time.Millisecond, 0),
+			wantErr: true,
+		},
+		{
+			name:    "custom waveform",
+			e:       NewPeriodicEffect(FF_CUSTOM, 20*time.Millisecond, 0x4000, time.Second),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.e.Validate(tt.supported); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEffect_WithDirection(t *testing.T) {
+	tests := []struct {
+		degrees float64
+		want    uint16
+	}{
+		{degrees: 0, want: 0},
+		{degrees: 90, want: 0x4000},
+		{degrees: 180, want: 0x8000},
+		{degrees: -90, want: 0xc000},
+		{degrees: 450, want: 0x4000},
+	}
+	for _, tt := range tests {
+		if got := NewConstantEffect(0, 0).WithDirection(tt.degrees).Direction; got != tt.want {
+			t.Errorf("WithDirection(%v) = 0x%x, want 0x%x", tt.degrees, got, tt.want)
+		}
+	}
+
+	if got := NewRumbleEffect(1, 2, time.Hour).Replay.Length; got != 0xffff {
+		t.Errorf("length of effect = %d, want 0xffff", got)
+	}
+}
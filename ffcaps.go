@@ -0,0 +1,91 @@
+package evdev
+
+import "fmt"
+
+// FFCapabilities describes the force feedback support of a device.
+type FFCapabilities struct {
+	Effects    []EvCode // supported effect types, FF_RUMBLE to FF_RAMP
+	Waveforms  []EvCode // supported periodic waveforms, FF_SQUARE to FF_CUSTOM
+	Gain       bool     // FF_GAIN is supported, see SetEffectGain
+	Autocenter bool     // FF_AUTOCENTER is supported
+	MaxEffects int      // number of effects that can be uploaded at once
+}
+
+func ffCapabilitiesOf(codes []EvCode, maxEffects int) FFCapabilities {
+	c := FFCapabilities{
+		Effects:    []EvCode{},
+		Waveforms:  []EvCode{},
+		MaxEffects: maxEffects,
+	}
+
+	for _, code := range codes {
+		switch {
+		case code >= FF_EFFECT_MIN && code <= FF_EFFECT_MAX:
+			c.Effects = append(c.Effects, code)
+		case code >= FF_WAVEFORM_MIN && code <= FF_WAVEFORM_MAX:
+			c.Waveforms = append(c.Waveforms, code)
+		case code == FF_GAIN:
+			c.Gain = true
+		case code == FF_AUTOCENTER:
+			c.Autocenter = true
+		}
+	}
+
+	return c
+}
+
+// Supports returns true if the device supports the given effect type or
+// waveform.
+func (c *FFCapabilities) Supports(code EvCode) bool {
+	for _, s := range c.Effects {
+		if s == code {
+			return true
+		}
+	}
+
+	for _, s := range c.Waveforms {
+		if s == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Best returns the first of the given effect types or waveforms the device
+// supports, in order of preference.
+func (c *FFCapabilities) Best(codes ...EvCode) (EvCode, bool) {
+	for _, code := range codes {
+		if c.Supports(code) {
+			return code, true
+		}
+	}
+
+	return 0, false
+}
+
+// Check validates an effect against the capabilities, see Effect.Validate.
+func (c *FFCapabilities) Check(e *Effect) error {
+	return e.Validate(append(append([]EvCode{}, c.Effects...), c.Waveforms...))
+}
+
+// FFEffectsCount returns the number of force feedback effects that can be
+// uploaded to the device at once.
+func (d *InputDevice) FFEffectsCount() (int, error) {
+	n, err := ioctlEVIOCGEFFECTS(d.fd())
+	if err != nil {
+		return 0, fmt.Errorf("Cannot get number of effects: %v", err)
+	}
+
+	return int(n), nil
+}
+
+// FFCapabilities returns the force feedback capabilities of the device.
+func (d *InputDevice) FFCapabilities() (FFCapabilities, error) {
+	n, err := d.FFEffectsCount()
+	if err != nil {
+		return FFCapabilities{}, err
+	}
+
+	return ffCapabilitiesOf(d.CapableEvents(EV_FF), n), nil
+}
@@ -0,0 +1,38 @@
+package evdev
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFFCapabilities(t *testing.T) {
+	c := ffCapabilitiesOf([]EvCode{FF_RUMBLE, FF_PERIODIC, FF_SQUARE, FF_SINE, FF_GAIN}, 16)
+
+	want := FFCapabilities{
+		Effects:    []EvCode{FF_RUMBLE, FF_PERIODIC},
+		Waveforms:  []EvCode{FF_SQUARE, FF_SINE},
+		Gain:       true,
+		MaxEffects: 16,
+	}
+
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("ffCapabilitiesOf() = %+v, want %+v", c, want)
+	}
+
+	if best, ok := c.Best(FF_TRIANGLE, FF_SINE, FF_SQUARE); !ok || best != FF_SINE {
+		t.Errorf("Best() = %v, %v, want FF_SINE", best, ok)
+	}
+
+	if _, ok := c.Best(FF_CONSTANT); ok {
+		t.Error("Best(FF_CONSTANT) succeeded")
+	}
+
+	if err := c.Check(NewPeriodicEffect(FF_SINE, time.Millisecond, 1, time.Second)); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+
+	if err := c.Check(NewPeriodicEffect(FF_TRIANGLE, time.Millisecond, 1, time.Second)); err == nil {
+		t.Error("Check() of unsupported waveform succeeded")
+	}
+}
@@ -0,0 +1,312 @@
+package evdev
+
+// FramePolicy selects how a FrameAssembler handles streams that violate the
+// evdev protocol.
+type FramePolicy int
+
+const (
+	// FramePassThrough delivers events unchanged and only counts violations.
+	FramePassThrough FramePolicy = iota
+	// FrameNormalize repairs violating frames: duplicate REL/ABS codes are
+	// coalesced, frames with duplicate key codes are split, SYN_CONFIG events
+	// are removed and missing SYN_REPORT events are synthesized.
+	FrameNormalize
+	// FrameDrop discards frames that violate the protocol.
+	FrameDrop
+)
+
+// defaultMaxFrameSize is the number of events after which a frame is
+// considered to be missing its SYN_REPORT.
+const defaultMaxFrameSize = 1024
+
+// FrameStats counts the protocol violations a FrameAssembler encountered.
+type FrameStats struct {
+	Frames         uint64 // frames delivered
+	DroppedFrames  uint64 // frames discarded due to FrameDrop or SYN_DROPPED
+	DuplicateCodes uint64 // frames with a code reported more than once
+	MissingReports uint64 // frames that did not end with SYN_REPORT
+	SynConfig      uint64 // SYN_CONFIG events seen
+}
+
+// FrameAssembler groups a stream of events into frames, i.e. all events up
+// to and including a SYN_REPORT. Cheap hardware routinely violates the
+// protocol, so the assembler detects duplicate codes within a frame, missing
+// SYN_REPORT events and interleaved SYN_CONFIG events and handles them
+// according to its FramePolicy.
+//
+// A missing SYN_REPORT is detected when the timestamp changes within a frame,
+// as the kernel stamps all events of a frame with the same time, or when the
+// frame exceeds the maximum frame size.
+type FrameAssembler struct {
+	policy       FramePolicy
+	maxFrameSize int
+	pending      []InputEvent
+	stats        FrameStats
+	dropping     bool // discarding events up to the next SYN_REPORT
+	discarded    int  // number of events discarded while dropping
+}
+
+// NewFrameAssembler creates a FrameAssembler with the given policy.
+func NewFrameAssembler(policy FramePolicy) *FrameAssembler {
+	return &FrameAssembler{
+		policy:       policy,
+		maxFrameSize: defaultMaxFrameSize,
+	}
+}
+
+// SetMaxFrameSize sets the number of events after which a frame is
+// considered to be missing its SYN_REPORT.
+func (fa *FrameAssembler) SetMaxFrameSize(n int) {
+	if n > 0 {
+		fa.maxFrameSize = n
+	}
+}
+
+// Stats returns the violation counters.
+func (fa *FrameAssembler) Stats() FrameStats {
+	return fa.stats
+}
+
+// Push adds an event to the assembler and returns the frames it completed,
+// if any. After a SYN_DROPPED event, the pending events are discarded and a
+// frame consisting of only the SYN_DROPPED event is returned, so consumers
+// can re-synchronize their state. As required by the protocol, all events
+// up to and including the next SYN_REPORT are discarded as well. The
+// SYN_DROPPED frame is not counted in FrameStats.Frames.
+func (fa *FrameAssembler) Push(e InputEvent) [][]InputEvent {
+	frames := [][]InputEvent{}
+
+	if e.Type == EV_SYN && e.Code == SYN_DROPPED {
+		if len(fa.pending) > 0 {
+			fa.stats.DroppedFrames++
+		}
+
+		fa.pending = nil
+		fa.dropping = true
+		fa.discarded = 0
+
+		return append(frames, []InputEvent{e})
+	}
+
+	if fa.dropping {
+		if e.Type == EV_SYN && e.Code == SYN_REPORT {
+			if fa.discarded > 0 {
+				fa.stats.DroppedFrames++
+			}
+
+			fa.dropping = false
+		} else {
+			fa.discarded++
+		}
+
+		return nil
+	}
+
+	if len(fa.pending) > 0 &&
+		(fa.pending[0].Time != e.Time || len(fa.pending) >= fa.maxFrameSize) {
+		fa.stats.MissingReports++
+
+		if frame := fa.finish(true); frame != nil {
+			frames = append(frames, frame...)
+		}
+	}
+
+	if e.Type == EV_SYN && e.Code == SYN_CONFIG {
+		fa.stats.SynConfig++
+	}
+
+	fa.pending = append(fa.pending, e)
+
+	if e.Type == EV_SYN && e.Code == SYN_REPORT {
+		if frame := fa.finish(false); frame != nil {
+			frames = append(frames, frame...)
+		}
+	}
+
+	if len(frames) == 0 {
+		return nil
+	}
+
+	return frames
+}
+
+// Flush returns the pending events as a frame, treating them as if their
+// SYN_REPORT was missing. Returns nil if there are no pending events.
+func (fa *FrameAssembler) Flush() [][]InputEvent {
+	if len(fa.pending) == 0 {
+		return nil
+	}
+
+	fa.stats.MissingReports++
+
+	return fa.finish(true)
+}
+
+// finish completes the pending frame and applies the policy.
+func (fa *FrameAssembler) finish(missingReport bool) [][]InputEvent {
+	frame := fa.pending
+	fa.pending = nil
+
+	duplicates := hasDuplicateCodes(frame)
+	if duplicates {
+		fa.stats.DuplicateCodes++
+	}
+
+	synConfig := false
+	for _, e := range frame {
+		if e.Type == EV_SYN && e.Code == SYN_CONFIG {
+			synConfig = true
+		}
+	}
+
+	violation := missingReport || duplicates || synConfig
+
+	switch {
+	case !violation, fa.policy == FramePassThrough:
+		fa.stats.Frames++
+		return [][]InputEvent{frame}
+
+	case fa.policy == FrameDrop:
+		fa.stats.DroppedFrames++
+		return nil
+	}
+
+	// FrameNormalize
+	clean := make([]InputEvent, 0, len(frame)+1)
+	for _, e := range frame {
+		if e.Type == EV_SYN && (e.Code == SYN_CONFIG || e.Code == SYN_REPORT) {
+			continue
+		}
+
+		clean = append(clean, e)
+	}
+
+	if len(clean) == 0 {
+		return nil
+	}
+
+	frames := [][]InputEvent{}
+
+	for _, f := range splitDuplicateKeys(Coalesce(clean)) {
+		f = append(f, InputEvent{Time: f[len(f)-1].Time, Type: EV_SYN, Code: SYN_REPORT})
+		frames = append(frames, f)
+		fa.stats.Frames++
+	}
+
+	return frames
+}
+
+// hasDuplicateCodes returns true if any code appears more than once in the
+// frame. ABS_MT_SLOT changes and SYN_MT_REPORT events begin a new section in
+// which codes may repeat.
+func hasDuplicateCodes(frame []InputEvent) bool {
+	type key struct {
+		t EvType
+		c EvCode
+	}
+
+	seen := map[key]bool{}
+
+	for _, e := range frame {
+		if e.Type == EV_SYN || (e.Type == EV_ABS && e.Code == ABS_MT_SLOT) {
+			seen = map[key]bool{}
+			continue
+		}
+
+		k := key{e.Type, e.Code}
+		if seen[k] {
+			return true
+		}
+
+		seen[k] = true
+	}
+
+	return false
+}
+
+// splitDuplicateKeys splits events into several frames so that no
+// non-REL/ABS code appears more than once per frame, which keeps e.g. a key
+// press and release reported within one frame intact.
+func splitDuplicateKeys(events []InputEvent) [][]InputEvent {
+	type key struct {
+		t EvType
+		c EvCode
+	}
+
+	frames := [][]InputEvent{}
+	current := []InputEvent{}
+	seen := map[key]bool{}
+
+	for _, e := range events {
+		k := key{e.Type, e.Code}
+
+		if e.Type != EV_REL && e.Type != EV_ABS && e.Type != EV_SYN && seen[k] {
+			frames = append(frames, current)
+			current = []InputEvent{}
+			seen = map[key]bool{}
+		}
+
+		seen[k] = true
+		current = append(current, e)
+	}
+
+	if len(current) > 0 {
+		frames = append(frames, current)
+	}
+
+	return frames
+}
+
+// FrameReader reads complete frames from a device, with its quirks and
+// stored settings applied, see UseSettingsStore.
+type FrameReader struct {
+	dev    *InputDevice
+	fa     *FrameAssembler
+	filter *deviceFilter
+	frames [][]InputEvent
+}
+
+// NewFrameReader creates a FrameReader that assembles the events read from d
+// with the given policy.
+func NewFrameReader(d *InputDevice, policy FramePolicy) *FrameReader {
+	return &FrameReader{
+		dev: d,
+		fa:  NewFrameAssembler(policy),
+	}
+}
+
+// Assembler returns the FrameAssembler used by the reader, e.g. to query its
+// statistics.
+func (fr *FrameReader) Assembler() *FrameAssembler {
+	return fr.fa
+}
+
+// ReadFrame blocks until a complete frame has been read from the device.
+func (fr *FrameReader) ReadFrame() ([]InputEvent, error) {
+	if fr.filter == nil {
+		filter, err := newDeviceFilter(fr.dev)
+		if err != nil {
+			return nil, err
+		}
+
+		fr.filter = filter
+	}
+
+	for len(fr.frames) == 0 {
+		events, err := fr.dev.Read()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range events {
+			for _, fe := range fr.filter.Push(e) {
+				fr.frames = append(fr.frames, fr.fa.Push(fe)...)
+			}
+		}
+	}
+
+	frame := fr.frames[0]
+	fr.frames = fr.frames[1:]
+
+	return frame, nil
+}
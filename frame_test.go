@@ -0,0 +1,149 @@
+package evdev
+
+import (
+	"reflect"
+	"syscall"
+	"testing"
+)
+
+func pushAll(fa *FrameAssembler, events []InputEvent) [][]InputEvent {
+	frames := [][]InputEvent{}
+
+	for _, e := range events {
+		frames = append(frames, fa.Push(e)...)
+	}
+
+	return frames
+}
+
+func TestFrameAssembler(t *testing.T) {
+	t1 := syscall.Timeval{Sec: 1}
+	t2 := syscall.Timeval{Sec: 2}
+
+	report := func(tv syscall.Timeval) InputEvent {
+		return InputEvent{Time: tv, Type: EV_SYN, Code: SYN_REPORT}
+	}
+
+	tests := []struct {
+		name   string
+		policy FramePolicy
+		events []InputEvent
+		want   [][]InputEvent
+		stats  FrameStats
+	}{
+		{
+			name:   "well formed",
+			policy: FrameNormalize,
+			events: []InputEvent{
+				{Time: t1, Type: EV_REL, Code: REL_X, Value: 1},
+				report(t1),
+			},
+			want: [][]InputEvent{{
+				{Time: t1, Type: EV_REL, Code: REL_X, Value: 1},
+				report(t1),
+			}},
+			stats: FrameStats{Frames: 1},
+		},
+		{
+			name:   "duplicate rel normalized",
+			policy: FrameNormalize,
+			events: []InputEvent{
+				{Time: t1, Type: EV_REL, Code: REL_X, Value: 1},
+				{Time: t1, Type: EV_REL, Code: REL_X, Value: 2},
+				report(t1),
+			},
+			want: [][]InputEvent{{
+				{Time: t1, Type: EV_REL, Code: REL_X, Value: 3},
+				report(t1),
+			}},
+			stats: FrameStats{Frames: 1, DuplicateCodes: 1},
+		},
+		{
+			name:   "duplicate key split",
+			policy: FrameNormalize,
+			events: []InputEvent{
+				{Time: t1, Type: EV_KEY, Code: KEY_A, Value: 1},
+				{Time: t1, Type: EV_KEY, Code: KEY_A, Value: 0},
+				report(t1),
+			},
+			want: [][]InputEvent{
+				{{Time: t1, Type: EV_KEY, Code: KEY_A, Value: 1}, report(t1)},
+				{{Time: t1, Type: EV_KEY, Code: KEY_A, Value: 0}, report(t1)},
+			},
+			stats: FrameStats{Frames: 2, DuplicateCodes: 1},
+		},
+		{
+			name:   "missing report synthesized",
+			policy: FrameNormalize,
+			events: []InputEvent{
+				{Time: t1, Type: EV_KEY, Code: KEY_A, Value: 1},
+				{Time: t1, Type: EV_SYN, Code: SYN_CONFIG},
+				{Time: t2, Type: EV_KEY, Code: KEY_A, Value: 0},
+				report(t2),
+			},
+			want: [][]InputEvent{
+				{{Time: t1, Type: EV_KEY, Code: KEY_A, Value: 1}, report(t1)},
+				{{Time: t2, Type: EV_KEY, Code: KEY_A, Value: 0}, report(t2)},
+			},
+			stats: FrameStats{Frames: 2, MissingReports: 1, SynConfig: 1},
+		},
+		{
+			name:   "missing report dropped",
+			policy: FrameDrop,
+			events: []InputEvent{
+				{Time: t1, Type: EV_KEY, Code: KEY_A, Value: 1},
+				{Time: t2, Type: EV_KEY, Code: KEY_A, Value: 0},
+				report(t2),
+			},
+			want: [][]InputEvent{
+				{{Time: t2, Type: EV_KEY, Code: KEY_A, Value: 0}, report(t2)},
+			},
+			stats: FrameStats{Frames: 1, DroppedFrames: 1, MissingReports: 1},
+		},
+		{
+			name:   "pass through",
+			policy: FramePassThrough,
+			events: []InputEvent{
+				{Time: t1, Type: EV_KEY, Code: KEY_A, Value: 1},
+				{Time: t2, Type: EV_KEY, Code: KEY_A, Value: 0},
+				report(t2),
+			},
+			want: [][]InputEvent{
+				{{Time: t1, Type: EV_KEY, Code: KEY_A, Value: 1}},
+				{{Time: t2, Type: EV_KEY, Code: KEY_A, Value: 0}, report(t2)},
+			},
+			stats: FrameStats{Frames: 2, MissingReports: 1},
+		},
+		{
+			name:   "syn dropped",
+			policy: FrameNormalize,
+			events: []InputEvent{
+				{Time: t1, Type: EV_KEY, Code: KEY_A, Value: 1},
+				{Time: t1, Type: EV_SYN, Code: SYN_DROPPED},
+				// the rest of the partial frame is discarded
+				{Time: t2, Type: EV_KEY, Code: KEY_B, Value: 1},
+				report(t2),
+				{Time: t2, Type: EV_KEY, Code: KEY_C, Value: 1},
+				report(t2),
+			},
+			want: [][]InputEvent{
+				{{Time: t1, Type: EV_SYN, Code: SYN_DROPPED}},
+				{{Time: t2, Type: EV_KEY, Code: KEY_C, Value: 1}, report(t2)},
+			},
+			stats: FrameStats{Frames: 1, DroppedFrames: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fa := NewFrameAssembler(tt.policy)
+
+			if got := pushAll(fa, tt.events); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Push() = %v, want %v", got, tt.want)
+			}
+
+			if got := fa.Stats(); got != tt.stats {
+				t.Errorf("Stats() = %+v, want %+v", got, tt.stats)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+//go:build go1.18
+// +build go1.18
+
+package evdev
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func FuzzCaptureReader(f *testing.F) {
+	buf := &bytes.Buffer{}
+	cw, _ := NewCaptureWriter(buf)
+	cw.AddDevice(CaptureDevice{
+		Name:         "kb",
+		Capabilities: Capabilities{Codes: map[EvType][]EvCode{EV_KEY: {KEY_A}}},
+		AbsInfos:     map[EvCode]AbsInfo{ABS_X: {Maximum: 10}},
+	})
+	cw.Write(&CaptureEvent{Type: EV_KEY, Code: KEY_A, Value: 1})
+
+	f.Add(buf.Bytes())
+	f.Add(captureMagic[:])
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		cr, err := NewCaptureReader(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+
+		cr.ReadAll()
+	})
+}
+
+func FuzzFrameAssembler(f *testing.F) {
+	f.Add([]byte{1, 0, 30, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fa := NewFrameAssembler(FrameNormalize)
+		fa.SetMaxFrameSize(8)
+
+		for ; len(data) >= 8; data = data[8:] {
+			fa.Push(InputEvent{
+				Type:  EvType(binary.LittleEndian.Uint16(data)),
+				Code:  EvCode(binary.LittleEndian.Uint16(data[2:])),
+				Value: int32(binary.LittleEndian.Uint32(data[4:])),
+			})
+		}
+
+		fa.Flush()
+	})
+}
+
+func FuzzParseScript(f *testing.F) {
+	f.Add("type == EV_KEY && code == KEY_CAPSLOCK -> code = KEY_ESC")
+	f.Add("state(KEY_LEFTSHIFT) && value % 2 -> value = -value / 3")
+
+	f.Fuzz(func(t *testing.T, src string) {
+		s, err := ParseScript(src)
+		if err != nil {
+			return
+		}
+
+		s.ProcessFrame([]InputEvent{{Type: EV_KEY, Code: KEY_A, Value: 1}, {Type: EV_SYN}})
+	})
+}
@@ -0,0 +1,271 @@
+package evdev
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"syscall"
+	"time"
+)
+
+// TrafficModel produces the frames of a synthetic event stream for a
+// Generator.
+type TrafficModel interface {
+	// Next returns the time from the previous frame to the next one and
+	// the events of the next frame, without the SYN_REPORT.
+	Next(r *rand.Rand) (time.Duration, []InputEvent)
+}
+
+// jittered returns d varied randomly by the relative standard deviation
+// jitter, but at least min.
+func jittered(r *rand.Rand, d time.Duration, jitter float64, min time.Duration) time.Duration {
+	d = time.Duration(float64(d) * (1 + jitter*r.NormFloat64()))
+	if d < min {
+		return min
+	}
+
+	return d
+}
+
+// TypingModel models typing at a given rate, with key presses spaced and
+// held for randomly varying times.
+type TypingModel struct {
+	KeysPerSecond float64
+	Hold          time.Duration // mean time a key is held, defaults to 80ms
+	Jitter        float64       // relative standard deviation of the timing
+	Keys          []EvCode      // keys to type, defaults to KEY_A to KEY_Z
+
+	held    EvCode
+	holding bool
+	hold    time.Duration
+}
+
+var typingDefaultKeys = []EvCode{
+	KEY_A, KEY_B, KEY_C, KEY_D, KEY_E, KEY_F, KEY_G, KEY_H, KEY_I, KEY_J, KEY_K, KEY_L, KEY_M,
+	KEY_N, KEY_O, KEY_P, KEY_Q, KEY_R, KEY_S, KEY_T, KEY_U, KEY_V, KEY_W, KEY_X, KEY_Y, KEY_Z,
+}
+
+// Next implements TrafficModel.
+func (m *TypingModel) Next(r *rand.Rand) (time.Duration, []InputEvent) {
+	hold := m.Hold
+	if hold <= 0 {
+		hold = 80 * time.Millisecond
+	}
+
+	if m.holding {
+		m.holding = false
+		return m.hold, []InputEvent{{Type: EV_KEY, Code: m.held, Value: int32(KeyUp)}}
+	}
+
+	keys := m.Keys
+	if len(keys) == 0 {
+		keys = typingDefaultKeys
+	}
+
+	interval := time.Second
+	if m.KeysPerSecond > 0 {
+		interval = time.Duration(float64(time.Second) / m.KeysPerSecond)
+	}
+
+	// the interval is measured between presses, the previous hold has
+	// passed already
+	gap := jittered(r, interval, m.Jitter, time.Millisecond) - m.hold
+	if gap < time.Millisecond {
+		gap = time.Millisecond
+	}
+
+	m.hold = jittered(r, hold, m.Jitter, time.Millisecond)
+	m.held = keys[r.Intn(len(keys))]
+	m.holding = true
+
+	return gap, []InputEvent{{Type: EV_KEY, Code: m.held, Value: int32(KeyDown)}}
+}
+
+// MouseModel models a mouse moving at a given speed in a randomly wandering
+// direction, with noise added to each report.
+type MouseModel struct {
+	Rate   float64 // reports per second, defaults to 125
+	Speed  float64 // mean distance per report
+	Turn   float64 // standard deviation of the direction change per report, in radians
+	Jitter float64 // standard deviation of the noise per report
+
+	angle float64
+}
+
+// Next implements TrafficModel.
+func (m *MouseModel) Next(r *rand.Rand) (time.Duration, []InputEvent) {
+	rate := m.Rate
+	if rate <= 0 {
+		rate = 125
+	}
+
+	m.angle += m.Turn * r.NormFloat64()
+
+	dx := int32(math.Round(m.Speed*math.Cos(m.angle) + m.Jitter*r.NormFloat64()))
+	dy := int32(math.Round(m.Speed*math.Sin(m.angle) + m.Jitter*r.NormFloat64()))
+
+	events := []InputEvent{}
+	if dx != 0 {
+		events = append(events, InputEvent{Type: EV_REL, Code: REL_X, Value: dx})
+	}
+	if dy != 0 {
+		events = append(events, InputEvent{Type: EV_REL, Code: REL_Y, Value: dy})
+	}
+
+	return time.Duration(float64(time.Second) / rate), events
+}
+
+// SwipeModel models single finger swipes in random directions on a type-B
+// multitouch surface, such as one created with the Touchscreen preset.
+type SwipeModel struct {
+	Width, Height  int32
+	Rate           float64       // reports per second while touching, defaults to 60
+	Duration       time.Duration // mean duration of a swipe, defaults to 200ms
+	Pause          time.Duration // mean pause between swipes, defaults to 500ms
+	Jitter         float64       // relative standard deviation of the timing
+	PositionJitter float64       // standard deviation of the position noise
+
+	nextID         int32
+	step, steps    int
+	x0, y0, x1, y1 float64
+	touching       bool
+}
+
+// Next implements TrafficModel.
+func (m *SwipeModel) Next(r *rand.Rand) (time.Duration, []InputEvent) {
+	rate := m.Rate
+	if rate <= 0 {
+		rate = 60
+	}
+
+	duration := m.Duration
+	if duration <= 0 {
+		duration = 200 * time.Millisecond
+	}
+
+	pause := m.Pause
+	if pause <= 0 {
+		pause = 500 * time.Millisecond
+	}
+
+	interval := time.Duration(float64(time.Second) / rate)
+
+	if m.touching && m.step == m.steps {
+		m.touching = false
+
+		return interval, []InputEvent{
+			{Type: EV_ABS, Code: ABS_MT_TRACKING_ID, Value: -1},
+			{Type: EV_KEY, Code: BTN_TOUCH, Value: 0},
+		}
+	}
+
+	var gap time.Duration
+	events := []InputEvent{}
+
+	if !m.touching {
+		m.x0, m.y0 = r.Float64()*float64(m.Width-1), r.Float64()*float64(m.Height-1)
+		m.x1, m.y1 = r.Float64()*float64(m.Width-1), r.Float64()*float64(m.Height-1)
+		m.steps = int(jittered(r, duration, m.Jitter, interval) / interval)
+		m.step = 0
+		m.touching = true
+
+		gap = jittered(r, pause, m.Jitter, interval)
+		events = append(events,
+			InputEvent{Type: EV_ABS, Code: ABS_MT_TRACKING_ID, Value: m.nextID},
+			InputEvent{Type: EV_KEY, Code: BTN_TOUCH, Value: 1})
+		m.nextID = (m.nextID + 1) & 0xffff
+	} else {
+		m.step++
+		gap = interval
+	}
+
+	f := float64(m.step) / float64(m.steps)
+	x := m.clamp(m.x0+(m.x1-m.x0)*f+m.PositionJitter*r.NormFloat64(), m.Width)
+	y := m.clamp(m.y0+(m.y1-m.y0)*f+m.PositionJitter*r.NormFloat64(), m.Height)
+
+	events = append(events,
+		InputEvent{Type: EV_ABS, Code: ABS_MT_POSITION_X, Value: x},
+		InputEvent{Type: EV_ABS, Code: ABS_MT_POSITION_Y, Value: y},
+		InputEvent{Type: EV_ABS, Code: ABS_X, Value: x},
+		InputEvent{Type: EV_ABS, Code: ABS_Y, Value: y})
+
+	return gap, events
+}
+
+func (m *SwipeModel) clamp(v float64, size int32) int32 {
+	switch {
+	case v < 0:
+		return 0
+	case v > float64(size-1):
+		return size - 1
+	}
+
+	return int32(math.Round(v))
+}
+
+// Generator is an EventSource producing the synthetic events of a
+// TrafficModel, e.g. to soak-test event processing. Each Read returns one
+// frame, stamped with the generator's clock, which starts at the time it
+// was created.
+type Generator struct {
+	model    TrafficModel
+	rand     *rand.Rand
+	start    time.Time
+	elapsed  time.Duration
+	realtime bool
+}
+
+// NewGenerator creates a Generator. The same seed and model parameters
+// produce the same events.
+func NewGenerator(model TrafficModel, seed int64) *Generator {
+	return &Generator{
+		model: model,
+		rand:  rand.New(rand.NewSource(seed)),
+		start: time.Now(),
+	}
+}
+
+// SetRealtime makes Read wait until the time of the frame it returns, so
+// the events arrive at the modeled rate. By default, Read returns frames as
+// fast as they are consumed.
+func (g *Generator) SetRealtime(realtime bool) {
+	g.realtime = realtime
+}
+
+// Read returns the next frame, including the SYN_REPORT. It never fails.
+func (g *Generator) Read() ([]InputEvent, error) {
+	gap, events := g.model.Next(g.rand)
+	g.elapsed += gap
+
+	at := g.start.Add(g.elapsed)
+	if g.realtime {
+		SleepUntil(context.Background(), at, DefaultSpin)
+	}
+
+	tv := syscall.NsecToTimeval(at.UnixNano())
+
+	frame := make([]InputEvent, 0, len(events)+1)
+	for _, e := range events {
+		e.Time = tv
+		frame = append(frame, e)
+	}
+
+	return append(frame, InputEvent{Time: tv, Type: EV_SYN, Code: SYN_REPORT}), nil
+}
+
+// Inject writes n frames to a virtual device in real time.
+func (g *Generator) Inject(u *UInputDevice, n int) error {
+	realtime := g.realtime
+	g.realtime = true
+	defer func() { g.realtime = realtime }()
+
+	for i := 0; i < n; i++ {
+		frame, _ := g.Read()
+
+		if err := u.Write(frame...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package evdev
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func generate(g *Generator, n int) [][]InputEvent {
+	frames := [][]InputEvent{}
+	for i := 0; i < n; i++ {
+		frame, _ := g.Read()
+		frames = append(frames, frame)
+	}
+
+	return frames
+}
+
+func TestGenerator(t *testing.T) {
+	models := map[string]func() TrafficModel{
+		"typing": func() TrafficModel { return &TypingModel{KeysPerSecond: 8, Jitter: 0.3} },
+		"mouse":  func() TrafficModel { return &MouseModel{Speed: 5, Turn: 0.2, Jitter: 1} },
+		"swipe":  func() TrafficModel { return &SwipeModel{Width: 800, Height: 600, Jitter: 0.2, PositionJitter: 2} },
+	}
+	for name, model := range models {
+		t.Run(name, func(t *testing.T) {
+			ga, gb := NewGenerator(model(), 1), NewGenerator(model(), 1)
+
+			// the clock starts at creation
+			gb.start = ga.start
+
+			a, b := generate(ga, 200), generate(gb, 200)
+
+			if !reflect.DeepEqual(a, b) {
+				t.Errorf("the same seed produced different events")
+			}
+
+			last := a[0][0].Time
+			for _, frame := range a {
+				end := frame[len(frame)-1]
+				if end.Type != EV_SYN || end.Code != SYN_REPORT {
+					t.Fatalf("frame %v doesn't end with SYN_REPORT", frame)
+				}
+
+				if end.Time.Nano() < last.Nano() {
+					t.Fatalf("time went backwards in %v", frame)
+				}
+
+				last = end.Time
+			}
+		})
+	}
+}
+
+func TestTypingModel(t *testing.T) {
+	g := NewGenerator(&TypingModel{KeysPerSecond: 10, Keys: []EvCode{KEY_A}}, 1)
+	frames := generate(g, 100)
+
+	for i, frame := range frames {
+		want := int32(KeyDown)
+		if i%2 == 1 {
+			want = int32(KeyUp)
+		}
+
+		if frame[0].Code != KEY_A || frame[0].Value != want {
+			t.Fatalf("frame %d = %v, want KEY_A %d", i, frame, want)
+		}
+	}
+
+	// without jitter, presses are exactly 100ms apart
+	first, last := frames[0][0].Time, frames[98][0].Time
+	if d := time.Duration(last.Nano() - first.Nano()); d != 49*100*time.Millisecond {
+		t.Errorf("49 keys took %v, want %v", d, 49*100*time.Millisecond)
+	}
+}
+
+func TestSwipeModel(t *testing.T) {
+	g := NewGenerator(&SwipeModel{Width: 100, Height: 50, PositionJitter: 20}, 1)
+
+	touching := false
+	swipes := 0
+
+	for _, frame := range generate(g, 500) {
+		for _, e := range frame {
+			switch {
+			case e.Code == ABS_MT_TRACKING_ID && e.Value >= 0:
+				if touching {
+					t.Fatalf("touch down while touching")
+				}
+				touching = true
+				swipes++
+
+			case e.Code == ABS_MT_TRACKING_ID:
+				touching = false
+
+			case e.Code == ABS_MT_POSITION_X && (e.Value < 0 || e.Value >= 100),
+				e.Code == ABS_MT_POSITION_Y && (e.Value < 0 || e.Value >= 50):
+				t.Fatalf("position %v out of range", e)
+			}
+		}
+	}
+
+	if swipes < 10 {
+		t.Errorf("%d swipes, want more", swipes)
+	}
+}
@@ -0,0 +1,199 @@
+package evdev
+
+import (
+	"sync"
+	"time"
+)
+
+// HapticDevice is the part of the force feedback API a HapticQueue uses. It
+// is implemented by InputDevice.
+type HapticDevice interface {
+	UploadEffect(e *Effect) error
+	EraseEffect(id int16) error
+	PlayEffect(id int16, count int32) error
+	StopEffect(id int16) error
+}
+
+// Pulse is one step of a HapticPattern.
+type Pulse struct {
+	Strong   uint16        // magnitude of the strong rumble motor
+	Weak     uint16        // magnitude of the weak rumble motor
+	Duration time.Duration // up to 65.535s
+	Pause    time.Duration // silence after the pulse
+}
+
+// HapticPattern is a sequence of rumble pulses.
+type HapticPattern struct {
+	Pulses []Pulse
+
+	// Patterns with a higher priority preempt the one playing, which is
+	// discarded. Patterns of equal or lower priority are queued.
+	Priority int
+}
+
+// HapticQueue plays rumble patterns on a device one after another, so
+// applications don't need to manage the kernel's effect slots and timing
+// themselves. All pulses are played through a single FF_RUMBLE effect.
+type HapticQueue struct {
+	dev     HapticDevice
+	onError func(error)
+	effect  Effect // only used by the run goroutine
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []HapticPattern
+	current *HapticPattern
+	preempt chan struct{} // closed to stop the current pattern
+	closed  bool
+	done    chan struct{}
+}
+
+// NewHapticQueue creates a HapticQueue for dev. Errors of the device are
+// passed to onError, which may be nil, and end the pattern playing.
+func NewHapticQueue(dev HapticDevice, onError func(error)) *HapticQueue {
+	q := &HapticQueue{
+		dev:     dev,
+		onError: onError,
+		effect:  Effect{Type: FF_RUMBLE, ID: -1},
+		done:    make(chan struct{}),
+	}
+	q.cond = sync.NewCond(&q.mu)
+
+	go q.run()
+
+	return q
+}
+
+// Play queues a pattern, or plays it immediately if it has a higher priority
+// than the one playing.
+func (q *HapticQueue) Play(p HapticPattern) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return
+	}
+
+	// keep the queue ordered by priority, and by time within a priority
+	i := len(q.queue)
+	for i > 0 && q.queue[i-1].Priority < p.Priority {
+		i--
+	}
+
+	q.queue = append(q.queue, HapticPattern{})
+	copy(q.queue[i+1:], q.queue[i:])
+	q.queue[i] = p
+
+	if q.current != nil && p.Priority > q.current.Priority {
+		q.stopCurrent()
+	}
+
+	q.cond.Signal()
+}
+
+// stopCurrent preempts the pattern playing. q.mu must be held.
+func (q *HapticQueue) stopCurrent() {
+	if q.preempt != nil {
+		close(q.preempt)
+		q.preempt = nil
+	}
+}
+
+// Clear discards all queued patterns and stops the one playing.
+func (q *HapticQueue) Clear() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.queue = nil
+	q.stopCurrent()
+}
+
+// Close stops playing, waits for the queue to stop and erases its effect
+// from the device.
+func (q *HapticQueue) Close() {
+	q.mu.Lock()
+	q.closed = true
+	q.queue = nil
+	q.stopCurrent()
+	q.cond.Signal()
+	q.mu.Unlock()
+
+	<-q.done
+
+	if q.effect.ID >= 0 {
+		q.report(q.dev.EraseEffect(q.effect.ID))
+		q.effect.ID = -1
+	}
+}
+
+func (q *HapticQueue) report(err error) {
+	if err != nil && q.onError != nil {
+		q.onError(err)
+	}
+}
+
+func (q *HapticQueue) run() {
+	defer close(q.done)
+
+	for {
+		q.mu.Lock()
+
+		for len(q.queue) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+
+		if q.closed {
+			q.mu.Unlock()
+			return
+		}
+
+		p := q.queue[0]
+		q.queue = q.queue[1:]
+		q.current = &p
+		preempt := make(chan struct{})
+		q.preempt = preempt
+
+		q.mu.Unlock()
+
+		q.playPattern(&p, preempt)
+
+		q.mu.Lock()
+		q.current = nil
+		if q.preempt == preempt {
+			q.preempt = nil
+		}
+		q.mu.Unlock()
+	}
+}
+
+func (q *HapticQueue) playPattern(p *HapticPattern, preempt chan struct{}) {
+	for _, pulse := range p.Pulses {
+		ms := pulse.Duration / time.Millisecond
+		if ms > 0xffff {
+			ms = 0xffff
+		}
+
+		q.effect.Rumble = RumbleEffect{StrongMagnitude: pulse.Strong, WeakMagnitude: pulse.Weak}
+		q.effect.Replay.Length = uint16(ms)
+
+		if err := q.dev.UploadEffect(&q.effect); err != nil {
+			q.report(err)
+			return
+		}
+
+		if err := q.dev.PlayEffect(q.effect.ID, 1); err != nil {
+			q.report(err)
+			return
+		}
+
+		t := time.NewTimer(pulse.Duration + pulse.Pause)
+
+		select {
+		case <-t.C:
+		case <-preempt:
+			t.Stop()
+			q.report(q.dev.StopEffect(q.effect.ID))
+			return
+		}
+	}
+}
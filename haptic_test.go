@@ -0,0 +1,91 @@
+package evdev
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeHapticDevice struct {
+	mu     sync.Mutex
+	calls  []string
+	played chan uint16
+}
+
+func (d *fakeHapticDevice) log(format string, args ...interface{}) {
+	d.mu.Lock()
+	d.calls = append(d.calls, fmt.Sprintf(format, args...))
+	d.mu.Unlock()
+}
+
+func (d *fakeHapticDevice) UploadEffect(e *Effect) error {
+	if e.ID < 0 {
+		e.ID = 3
+	}
+
+	d.log("upload %d %d %d", e.ID, e.Rumble.StrongMagnitude, e.Replay.Length)
+
+	return nil
+}
+
+func (d *fakeHapticDevice) EraseEffect(id int16) error {
+	d.log("erase %d", id)
+	return nil
+}
+
+func (d *fakeHapticDevice) PlayEffect(id int16, count int32) error {
+	d.log("play %d", id)
+	return nil
+}
+
+func (d *fakeHapticDevice) StopEffect(id int16) error {
+	d.log("stop %d", id)
+	return nil
+}
+
+func TestHapticQueue(t *testing.T) {
+	dev := &fakeHapticDevice{}
+	q := NewHapticQueue(dev, func(err error) { t.Error(err) })
+
+	short := Pulse{Strong: 1, Duration: 10 * time.Millisecond}
+
+	// blocks the queue until preempted
+	q.Play(HapticPattern{Pulses: []Pulse{{Strong: 2, Duration: time.Hour}, short}, Priority: 3})
+
+	for {
+		dev.mu.Lock()
+		n := len(dev.calls)
+		dev.mu.Unlock()
+
+		if n == 2 {
+			break
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	// queued in order of priority
+	q.Play(HapticPattern{Pulses: []Pulse{{Strong: 4, Duration: 10 * time.Millisecond}}, Priority: 1})
+	q.Play(HapticPattern{Pulses: []Pulse{{Strong: 5, Duration: 10 * time.Millisecond}}, Priority: 2})
+
+	// preempts the pattern playing, discarding its remaining pulse
+	q.Play(HapticPattern{Pulses: []Pulse{{Strong: 6, Duration: 10 * time.Millisecond}}, Priority: 4})
+
+	time.Sleep(100 * time.Millisecond)
+	q.Close()
+
+	want := []string{
+		"upload 3 2 65535", "play 3",
+		"stop 3",
+		"upload 3 6 10", "play 3",
+		"upload 3 5 10", "play 3",
+		"upload 3 4 10", "play 3",
+		"erase 3",
+	}
+
+	if !reflect.DeepEqual(dev.calls, want) {
+		t.Errorf("calls = %q, want %q", dev.calls, want)
+	}
+}
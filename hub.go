@@ -0,0 +1,507 @@
+package evdev
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventSource is anything events can be read from, such as an InputDevice.
+type EventSource interface {
+	Read() ([]InputEvent, error)
+}
+
+// Sink receives events distributed by a Hub. If Deliver returns an error,
+// the sink is removed from the hub.
+type Sink interface {
+	Deliver(e InputEvent) error
+}
+
+// SinkFunc adapts a function to the Sink interface.
+type SinkFunc func(e InputEvent) error
+
+// Deliver calls f(e).
+func (f SinkFunc) Deliver(e InputEvent) error {
+	return f(e)
+}
+
+// cancelableSink is implemented by sinks whose delivery may block, so that
+// removing them from the hub can interrupt a pending delivery.
+type cancelableSink interface {
+	deliverOrCancel(e InputEvent, cancel <-chan struct{}) error
+}
+
+type channelSink chan<- InputEvent
+
+func (ch channelSink) Deliver(e InputEvent) error {
+	ch <- e
+	return nil
+}
+
+func (ch channelSink) deliverOrCancel(e InputEvent, cancel <-chan struct{}) error {
+	select {
+	case ch <- e:
+	case <-cancel:
+	}
+
+	return nil
+}
+
+// ChannelSink returns a Sink that sends events to ch.
+func ChannelSink(ch chan<- InputEvent) Sink {
+	return channelSink(ch)
+}
+
+type writerSink struct {
+	w io.Writer
+}
+
+func (ws writerSink) Deliver(e InputEvent) error {
+	_, err := ws.w.Write(eventBytes([]InputEvent{e}))
+	return err
+}
+
+// WriterSink returns a Sink that writes events to w in the kernel's binary
+// input_event format, e.g. to a network connection.
+func WriterSink(w io.Writer) Sink {
+	return writerSink{w: w}
+}
+
+// OverflowPolicy selects what happens when a sink's queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowDropNewest discards the event that didn't fit into the queue.
+	OverflowDropNewest OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest queued event to make room.
+	OverflowDropOldest
+	// OverflowBlock makes the hub wait until the queue has room, which
+	// stalls all other sinks as well.
+	OverflowBlock
+	// OverflowDropFrames queues the events of a frame once it is complete,
+	// and discards the whole frame if it doesn't fit, so the sink never
+	// receives partial frames. A frame larger than the queue is queued if
+	// the queue is empty.
+	OverflowDropFrames
+)
+
+// SinkStats are the metrics of a sink's queue.
+type SinkStats struct {
+	Dropped       uint64        // events discarded due to the overflow policy
+	DroppedFrames uint64        // frames during which events were discarded
+	Queued        int           // events currently queued
+	MaxQueueDepth int           // most events that were queued at once
+	Stalled       time.Duration // time the hub waited for the queue with OverflowBlock
+}
+
+// SinkOptions configure how a Hub delivers events to a sink.
+type SinkOptions struct {
+	QueueSize int // number of events queued for the sink, defaults to 64
+	Overflow  OverflowPolicy
+
+	// Allow restricts the events the sink receives, e.g. so a volume
+	// control only ever sees KEY_VOLUME* events of a keyboard. If it is not
+	// empty, only events of the listed types are delivered, limited to the
+	// listed codes unless none are given. EV_SYN events are delivered only
+	// in frames containing an allowed event, as well as SYN_DROPPED.
+	Allow map[EvType][]EvCode
+
+	// Required makes the hub stop once the sink fails, as the events would
+	// have nowhere to go, e.g. when the virtual device of a remapper is
+	// gone. Run then returns the sink's error. The hub also stops when its
+	// last sink failed.
+	Required bool
+}
+
+// HubSink is a sink added to a Hub.
+type HubSink struct {
+	dropped uint64 // accessed atomically, keep 64-bit aligned
+	hub     *Hub
+	sink    Sink
+	opts    SinkOptions
+
+	// allow-list compiled from opts.Allow, nil if all events are allowed
+	allow        map[EvType]map[EvCode]bool
+	frameAllowed bool // an allowed event was delivered in the current frame
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []InputEvent
+	frame   []InputEvent // incomplete frame held back by OverflowDropFrames
+	removed bool
+
+	// metrics, see SinkStats
+	droppedFrames uint64
+	maxDepth      int
+	stalled       time.Duration
+	frameDropped  bool // an event of the current frame was discarded
+
+	closing bool
+	done    chan struct{}
+	err     error
+}
+
+// Dropped returns the number of events discarded due to the overflow policy.
+func (hs *HubSink) Dropped() uint64 {
+	return atomic.LoadUint64(&hs.dropped)
+}
+
+// Stats returns the metrics of the sink's queue.
+func (hs *HubSink) Stats() SinkStats {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	return SinkStats{
+		Dropped:       hs.Dropped(),
+		DroppedFrames: hs.droppedFrames,
+		Queued:        len(hs.queue),
+		MaxQueueDepth: hs.maxDepth,
+		Stalled:       hs.stalled,
+	}
+}
+
+// Err returns the error returned by the sink's Deliver method, if that was
+// the reason it was removed.
+func (hs *HubSink) Err() error {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	return hs.err
+}
+
+// Done returns a channel that is closed once the sink was removed from the
+// hub, e.g. because its Deliver method failed or the hub stopped.
+func (hs *HubSink) Done() <-chan struct{} {
+	return hs.done
+}
+
+// Remove removes the sink from the hub. Queued events are discarded, and a
+// blocked delivery to a ChannelSink is abandoned.
+func (hs *HubSink) Remove() {
+	hs.hub.mu.Lock()
+	delete(hs.hub.sinks, hs)
+	hs.hub.mu.Unlock()
+
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	if hs.removed {
+		return
+	}
+
+	hs.removed = true
+	hs.queue = nil
+	close(hs.done)
+	hs.cond.Broadcast()
+}
+
+// finish makes the sink deliver its queued events and stop afterwards.
+func (hs *HubSink) finish() {
+	hs.mu.Lock()
+	hs.closing = true
+	if !hs.removed {
+		hs.queue = append(hs.queue, hs.frame...)
+		hs.frame = nil
+	}
+	hs.cond.Broadcast()
+	hs.mu.Unlock()
+}
+
+// allowed applies the allow-list to e. It is called for every event in
+// order.
+func (hs *HubSink) allowed(e *InputEvent) bool {
+	if hs.allow == nil {
+		return true
+	}
+
+	if e.Type == EV_SYN {
+		if e.Code == SYN_DROPPED {
+			return true
+		}
+
+		allowed := hs.frameAllowed
+		if e.Code == SYN_REPORT {
+			hs.frameAllowed = false
+		}
+
+		return allowed
+	}
+
+	codes, ok := hs.allow[e.Type]
+	if !ok || len(codes) > 0 && !codes[e.Code] {
+		return false
+	}
+
+	hs.frameAllowed = true
+
+	return true
+}
+
+func (hs *HubSink) push(e InputEvent) {
+	if !hs.allowed(&e) {
+		return
+	}
+
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	frameEnd := e.Type == EV_SYN && (e.Code == SYN_REPORT || e.Code == SYN_DROPPED)
+
+	if hs.opts.Overflow == OverflowDropFrames {
+		hs.frame = append(hs.frame, e)
+		if frameEnd {
+			hs.pushFrame()
+		}
+
+		return
+	}
+
+	hs.pushEvent(e)
+
+	if frameEnd {
+		if hs.frameDropped {
+			hs.droppedFrames++
+		}
+
+		hs.frameDropped = false
+	}
+}
+
+// pushEvent queues an event according to the overflow policy. hs.mu must be
+// held.
+func (hs *HubSink) pushEvent(e InputEvent) {
+	var stallStart time.Time
+
+	for len(hs.queue) >= hs.opts.QueueSize && !hs.removed {
+		switch hs.opts.Overflow {
+		case OverflowDropNewest:
+			atomic.AddUint64(&hs.dropped, 1)
+			hs.frameDropped = true
+			return
+		case OverflowDropOldest:
+			atomic.AddUint64(&hs.dropped, 1)
+			hs.frameDropped = true
+			hs.queue = hs.queue[1:]
+		default:
+			if stallStart.IsZero() {
+				stallStart = time.Now()
+			}
+
+			hs.cond.Wait()
+		}
+	}
+
+	if !stallStart.IsZero() {
+		hs.stalled += time.Since(stallStart)
+	}
+
+	if hs.removed {
+		return
+	}
+
+	hs.enqueue(e)
+}
+
+// pushFrame queues the completed frame if it fits. hs.mu must be held.
+func (hs *HubSink) pushFrame() {
+	frame := hs.frame
+	hs.frame = nil
+
+	if hs.removed {
+		return
+	}
+
+	if len(hs.queue) > 0 && len(hs.queue)+len(frame) > hs.opts.QueueSize {
+		atomic.AddUint64(&hs.dropped, uint64(len(frame)))
+		hs.droppedFrames++
+
+		return
+	}
+
+	hs.enqueue(frame...)
+}
+
+// enqueue appends events to the queue. hs.mu must be held.
+func (hs *HubSink) enqueue(events ...InputEvent) {
+	hs.queue = append(hs.queue, events...)
+	if len(hs.queue) > hs.maxDepth {
+		hs.maxDepth = len(hs.queue)
+	}
+
+	hs.cond.Broadcast()
+}
+
+func (hs *HubSink) run() {
+	defer hs.hub.wg.Done()
+
+	cs, cancelable := hs.sink.(cancelableSink)
+
+	for {
+		hs.mu.Lock()
+
+		for len(hs.queue) == 0 && !hs.removed && !hs.closing {
+			hs.cond.Wait()
+		}
+
+		if hs.removed || len(hs.queue) == 0 {
+			hs.mu.Unlock()
+			hs.Remove()
+			return
+		}
+
+		e := hs.queue[0]
+		hs.queue = hs.queue[1:]
+		hs.cond.Broadcast()
+
+		hs.mu.Unlock()
+
+		var err error
+		if cancelable {
+			err = cs.deliverOrCancel(e, hs.done)
+		} else {
+			err = hs.sink.Deliver(e)
+		}
+
+		if err != nil {
+			hs.mu.Lock()
+			hs.err = err
+			hs.mu.Unlock()
+
+			hs.Remove()
+			hs.hub.sinkFailed(hs, err)
+
+			return
+		}
+	}
+}
+
+// Hub reads events from one EventSource and distributes them to a dynamic
+// set of sinks, each with its own queue and overflow policy. This allows
+// monitoring tools to tap into a stream another consumer already reads.
+type Hub struct {
+	src   EventSource
+	mu    sync.Mutex
+	wg    sync.WaitGroup
+	sinks map[*HubSink]bool
+	done  bool
+	err   error // error of a sink that stopped the hub
+
+	// interrupt, if set, interrupts a pending read of the source once the
+	// hub is to stop
+	interrupt func()
+}
+
+// NewHub creates a Hub for the given source. Call Run to start reading.
+func NewHub(src EventSource) *Hub {
+	return &Hub{
+		src:   src,
+		sinks: map[*HubSink]bool{},
+	}
+}
+
+// AddSink adds a sink to the hub. Events are delivered to it from a separate
+// goroutine. Sinks added after Run returned receive no events.
+func (h *Hub) AddSink(s Sink, opts SinkOptions) *HubSink {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 64
+	}
+
+	hs := &HubSink{
+		hub:  h,
+		sink: s,
+		opts: opts,
+		done: make(chan struct{}),
+	}
+	hs.cond = sync.NewCond(&hs.mu)
+
+	if len(opts.Allow) > 0 {
+		hs.allow = map[EvType]map[EvCode]bool{}
+
+		for t, codes := range opts.Allow {
+			hs.allow[t] = map[EvCode]bool{}
+			for _, c := range codes {
+				hs.allow[t][c] = true
+			}
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.done {
+		hs.removed = true
+		close(hs.done)
+		return hs
+	}
+
+	h.sinks[hs] = true
+	h.wg.Add(1)
+
+	go hs.run()
+
+	return hs
+}
+
+// sinkFailed stops the hub if the failed sink was required or the last one.
+func (h *Hub) sinkFailed(hs *HubSink, err error) {
+	h.mu.Lock()
+	stop := !h.done && h.err == nil && (hs.opts.Required || len(h.sinks) == 0)
+	if stop {
+		h.err = err
+	}
+	interrupt := h.interrupt
+	h.mu.Unlock()
+
+	if stop && interrupt != nil {
+		interrupt()
+	}
+}
+
+// Run reads events from the source and distributes them until reading fails,
+// or a required or the last sink failed. The sinks then deliver the events
+// already queued for them and are removed, and Run returns the error once all
+// of them have finished. A failed sink stops the hub when the next read from
+// the source returns.
+func (h *Hub) Run() error {
+	for {
+		events, err := h.src.Read()
+
+		h.mu.Lock()
+		if h.err != nil {
+			err = h.err
+		}
+		h.mu.Unlock()
+
+		if err != nil {
+			h.mu.Lock()
+			h.done = true
+			sinks := []*HubSink{}
+			for hs := range h.sinks {
+				sinks = append(sinks, hs)
+			}
+			h.mu.Unlock()
+
+			for _, hs := range sinks {
+				hs.finish()
+			}
+
+			h.wg.Wait()
+
+			return err
+		}
+
+		h.mu.Lock()
+		sinks := make([]*HubSink, 0, len(h.sinks))
+		for hs := range h.sinks {
+			sinks = append(sinks, hs)
+		}
+		h.mu.Unlock()
+
+		for _, e := range events {
+			for _, hs := range sinks {
+				hs.push(e)
+			}
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package evdev
+
+import (
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type sliceSource struct {
+	batches [][]InputEvent
+}
+
+func (s *sliceSource) Read() ([]InputEvent, error) {
+	if len(s.batches) == 0 {
+		return nil, io.EOF
+	}
+
+	b := s.batches[0]
+	s.batches = s.batches[1:]
+
+	return b, nil
+}
+
+func TestHub(t *testing.T) {
+	events := []InputEvent{
+		{Type: EV_KEY, Code: KEY_A, Value: 1},
+		{Type: EV_SYN, Code: SYN_REPORT},
+	}
+
+	h := NewHub(&sliceSource{batches: [][]InputEvent{events}})
+
+	// the sink blocks until released, so its queue of one overflows
+	release := make(chan struct{})
+	blocked := []InputEvent{}
+	done := make(chan struct{})
+	slow := h.AddSink(SinkFunc(func(e InputEvent) error {
+		<-release
+		blocked = append(blocked, e)
+		close(done)
+		return io.ErrClosedPipe
+	}), SinkOptions{QueueSize: 1, Overflow: OverflowDropNewest})
+
+	ch := make(chan InputEvent, 2)
+	h.AddSink(ChannelSink(ch), SinkOptions{Overflow: OverflowBlock})
+
+	result := make(chan error)
+	go func() {
+		result <- h.Run()
+	}()
+
+	// queued events are delivered even though the source has ended
+	got := []InputEvent{<-ch, <-ch}
+	if !reflect.DeepEqual(got, events) {
+		t.Errorf("channel sink got %v, want %v", got, events)
+	}
+
+	close(release)
+	<-done
+
+	if err := <-result; err != io.EOF {
+		t.Errorf("Run() error = %v, want %v", err, io.EOF)
+	}
+
+	if len(blocked) != 1 || slow.Err() != io.ErrClosedPipe {
+		t.Errorf("slow sink got %v, error %v", blocked, slow.Err())
+	}
+}
+
+// wakeSource returns one frame, then waits until it is woken or a timeout
+// passed before it ends.
+type wakeSource struct {
+	frame []InputEvent
+	wake  chan struct{}
+}
+
+func (s *wakeSource) Read() ([]InputEvent, error) {
+	if s.frame != nil {
+		frame := s.frame
+		s.frame = nil
+
+		return frame, nil
+	}
+
+	select {
+	case <-s.wake:
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	return nil, io.EOF
+}
+
+func TestHub_SinkFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		required bool
+		others   int
+		want     error
+	}{
+		{name: "last sink", want: io.ErrClosedPipe},
+		{name: "required sink", required: true, others: 1, want: io.ErrClosedPipe},
+		{name: "other sinks left", others: 1, want: io.EOF},
+	}
+	for _, tt := range tests {
+		src := &wakeSource{frame: []InputEvent{{Type: EV_KEY, Code: KEY_A, Value: 1}, synReport}, wake: make(chan struct{})}
+
+		h := NewHub(src)
+		h.interrupt = func() { close(src.wake) }
+
+		h.AddSink(SinkFunc(func(InputEvent) error { return io.ErrClosedPipe }), SinkOptions{Required: tt.required})
+		for i := 0; i < tt.others; i++ {
+			h.AddSink(SinkFunc(func(InputEvent) error { return nil }), SinkOptions{})
+		}
+
+		if err := h.Run(); err != tt.want {
+			t.Errorf("%s: Run() = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestHubSink_RemoveUnblocks(t *testing.T) {
+	h := NewHub(&sliceSource{})
+
+	// nobody receives from ch, so the delivery blocks until the sink is removed
+	ch := make(chan InputEvent)
+	hs := h.AddSink(ChannelSink(ch), SinkOptions{})
+	hs.push(InputEvent{Type: EV_KEY, Code: KEY_A, Value: 1})
+
+	hs.Remove()
+
+	if err := h.Run(); err != io.EOF {
+		t.Errorf("Run() error = %v, want %v", err, io.EOF)
+	}
+}
+
+func TestHub_Allow(t *testing.T) {
+	h := NewHub(&sliceSource{batches: [][]InputEvent{{
+		{Type: EV_MSC, Code: MSC_SCAN, Value: 4},
+		{Type: EV_KEY, Code: KEY_A, Value: 1},
+		{Type: EV_SYN, Code: SYN_REPORT},
+		{Type: EV_KEY, Code: KEY_VOLUMEUP, Value: 1},
+		{Type: EV_SYN, Code: SYN_REPORT},
+		{Type: EV_SYN, Code: SYN_DROPPED},
+	}}})
+
+	ch := make(chan InputEvent, 10)
+	h.AddSink(ChannelSink(ch), SinkOptions{
+		Allow: map[EvType][]EvCode{EV_KEY: {KEY_VOLUMEUP, KEY_VOLUMEDOWN}},
+	})
+
+	if err := h.Run(); err != io.EOF {
+		t.Fatalf("Run() error = %v, want %v", err, io.EOF)
+	}
+
+	close(ch)
+
+	got := []InputEvent{}
+	for e := range ch {
+		got = append(got, e)
+	}
+
+	want := []InputEvent{
+		{Type: EV_KEY, Code: KEY_VOLUMEUP, Value: 1},
+		{Type: EV_SYN, Code: SYN_REPORT},
+		{Type: EV_SYN, Code: SYN_DROPPED},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sink got %v, want %v", got, want)
+	}
+}
+
+func TestHubSink_Stats(t *testing.T) {
+	key := func(c EvCode) InputEvent { return InputEvent{Type: EV_KEY, Code: c, Value: 1} }
+	frames := []InputEvent{
+		key(KEY_A), synReport,
+		key(KEY_B), key(KEY_C), synReport,
+		key(KEY_D), synReport,
+	}
+
+	tests := []struct {
+		policy OverflowPolicy
+		queue  []InputEvent
+		stats  SinkStats
+	}{
+		{
+			policy: OverflowDropNewest,
+			queue:  []InputEvent{key(KEY_A), synReport, key(KEY_B)},
+			stats:  SinkStats{Dropped: 4, DroppedFrames: 2, Queued: 3, MaxQueueDepth: 3},
+		},
+		{
+			policy: OverflowDropOldest,
+			queue:  []InputEvent{synReport, key(KEY_D), synReport},
+			stats:  SinkStats{Dropped: 4, DroppedFrames: 2, Queued: 3, MaxQueueDepth: 3},
+		},
+		{
+			policy: OverflowDropFrames,
+			queue:  []InputEvent{key(KEY_A), synReport},
+			stats:  SinkStats{Dropped: 5, DroppedFrames: 2, Queued: 2, MaxQueueDepth: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		// without its goroutine, the sink keeps everything queued
+		hs := &HubSink{opts: SinkOptions{QueueSize: 3, Overflow: tt.policy}, done: make(chan struct{})}
+		hs.cond = sync.NewCond(&hs.mu)
+
+		for _, e := range frames {
+			hs.push(e)
+		}
+
+		if !reflect.DeepEqual(hs.queue, tt.queue) {
+			t.Errorf("policy %d: queue = %v, want %v", tt.policy, hs.queue, tt.queue)
+		}
+
+		if s := hs.Stats(); s != tt.stats {
+			t.Errorf("policy %d: Stats() = %+v, want %+v", tt.policy, s, tt.stats)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package evdev
+
+import (
+	"fmt"
+	"math"
+	"syscall"
+)
+
+// IMUSample holds the readings of a motion sensor reported within one
+// SYN_REPORT frame. Acceleration is given in g, angular velocity in degrees
+// per second. Axes with a resolution of 0 are reported in raw device units.
+type IMUSample struct {
+	Time  syscall.Timeval
+	Accel [3]float64 // ABS_X, ABS_Y, ABS_Z
+	Gyro  [3]float64 // ABS_RX, ABS_RY, ABS_RZ
+}
+
+// IMU wraps a motion sensor device, i.e. one with the PROP_ACCELEROMETER
+// property, and reports scaled samples. It optionally estimates the device
+// orientation with a complementary filter.
+type IMU struct {
+	dev     *InputDevice
+	absInfo map[EvCode]AbsInfo
+	hasGyro bool
+	sample  IMUSample
+	dropped bool
+	filter  *deviceFilter
+	pending []InputEvent // events read through filter
+
+	// orientation estimation
+	alpha       float64
+	orientation bool
+	last        syscall.Timeval
+	pitch, roll float64
+}
+
+// NewIMU creates an IMU from d. Returns an error if d is not a motion sensor.
+func NewIMU(d *InputDevice) (*IMU, error) {
+	isIMU := false
+	for _, p := range d.Properties() {
+		if p == PROP_ACCELEROMETER {
+			isIMU = true
+		}
+	}
+
+	if !isIMU {
+		return nil, fmt.Errorf("Device is not an accelerometer")
+	}
+
+	// applies the calibration before the axes are queried
+	filter, err := newDeviceFilter(d)
+	if err != nil {
+		return nil, err
+	}
+
+	absInfo, err := d.AbsInfos()
+	if err != nil {
+		return nil, err
+	}
+
+	_, hasGyro := absInfo[ABS_RX]
+
+	m := &IMU{
+		dev:     d,
+		hasGyro: hasGyro,
+		filter:  filter,
+	}
+
+	m.seed(absInfo)
+
+	return m, nil
+}
+
+// seed sets the axis ranges and the current readings from absInfo. Axes are
+// only reported when they change, so the current values have to be queried
+// initially and after events were dropped.
+func (m *IMU) seed(absInfo map[EvCode]AbsInfo) {
+	m.absInfo = absInfo
+
+	for i := EvCode(0); i < 3; i++ {
+		m.sample.Accel[i] = m.scale(ABS_X+i, absInfo[ABS_X+i].Value)
+		m.sample.Gyro[i] = m.scale(ABS_RX+i, absInfo[ABS_RX+i].Value)
+	}
+}
+
+// HasGyro returns true if the device reports angular velocity.
+func (m *IMU) HasGyro() bool {
+	return m.hasGyro
+}
+
+// EnableOrientation enables orientation estimation. alpha weighs the
+// integrated gyro data against the accelerometer's gravity vector and must
+// be between 0 and 1; 0.98 is a common choice. Without a gyro, the
+// orientation is derived from the accelerometer only.
+func (m *IMU) EnableOrientation(alpha float64) error {
+	if alpha < 0 || alpha > 1 {
+		return fmt.Errorf("Invalid filter coefficient %f", alpha)
+	}
+
+	m.alpha = alpha
+	m.orientation = true
+
+	return nil
+}
+
+// Orientation returns the estimated pitch and roll in degrees. It is only
+// updated if EnableOrientation was called.
+func (m *IMU) Orientation() (pitch, roll float64) {
+	return m.pitch, m.roll
+}
+
+func (m *IMU) scale(code EvCode, value int32) float64 {
+	info, ok := m.absInfo[code]
+	if !ok || info.Resolution == 0 {
+		return float64(value)
+	}
+
+	return float64(value) / float64(info.Resolution)
+}
+
+// ReadSample reads events from the device until the next SYN_REPORT and
+// returns the readings of all axes at that point. Frames that were partly
+// dropped by the kernel are skipped, and the readings are queried from the
+// device again. The quirks and stored settings of the device, see
+// UseSettingsStore, are applied.
+func (m *IMU) ReadSample() (*IMUSample, error) {
+	for {
+		if len(m.pending) == 0 {
+			e, err := m.dev.ReadOne()
+			if err != nil {
+				return nil, err
+			}
+
+			m.pending = m.filter.Push(*e)
+			continue
+		}
+
+		e := m.pending[0]
+		m.pending = m.pending[1:]
+
+		switch e.Type {
+		case EV_ABS:
+			switch e.Code {
+			case ABS_X, ABS_Y, ABS_Z:
+				m.sample.Accel[e.Code-ABS_X] = m.scale(e.Code, e.Value)
+			case ABS_RX, ABS_RY, ABS_RZ:
+				m.sample.Gyro[e.Code-ABS_RX] = m.scale(e.Code, e.Value)
+			}
+
+		case EV_SYN:
+			switch e.Code {
+			case SYN_DROPPED:
+				m.dropped = true
+			case SYN_REPORT:
+				if m.dropped {
+					m.dropped = false
+
+					absInfo, err := m.dev.AbsInfos()
+					if err != nil {
+						return nil, fmt.Errorf("Cannot resync after dropped events: %v", err)
+					}
+
+					m.seed(absInfo)
+
+					// don't integrate the gyro across the gap
+					m.last = syscall.Timeval{}
+
+					continue
+				}
+
+				m.sample.Time = e.Time
+
+				if m.orientation {
+					m.updateOrientation()
+				}
+
+				s := m.sample
+				return &s, nil
+			}
+		}
+	}
+}
+
+func (m *IMU) updateOrientation() {
+	a := m.sample.Accel
+	accelPitch := math.Atan2(-a[0], math.Sqrt(a[1]*a[1]+a[2]*a[2])) * 180 / math.Pi
+	accelRoll := math.Atan2(a[1], a[2]) * 180 / math.Pi
+
+	if !m.hasGyro || (m.last.Sec == 0 && m.last.Usec == 0) {
+		m.pitch, m.roll = accelPitch, accelRoll
+		m.last = m.sample.Time
+		return
+	}
+
+	dt := float64(m.sample.Time.Nano()-m.last.Nano()) / 1e9
+	m.last = m.sample.Time
+
+	m.roll = m.alpha*(m.roll+m.sample.Gyro[0]*dt) + (1-m.alpha)*accelRoll
+	m.pitch = m.alpha*(m.pitch+m.sample.Gyro[1]*dt) + (1-m.alpha)*accelPitch
+}
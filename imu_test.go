@@ -0,0 +1,54 @@
+package evdev
+
+import (
+	"math"
+	"syscall"
+	"testing"
+)
+
+func TestIMU_seed(t *testing.T) {
+	m := &IMU{}
+	m.seed(map[EvCode]AbsInfo{
+		ABS_X:  {Value: 512, Resolution: 1024},
+		ABS_Z:  {Value: -1024, Resolution: 1024},
+		ABS_RY: {Value: 30},
+	})
+
+	if want := [3]float64{0.5, 0, -1}; m.sample.Accel != want {
+		t.Errorf("Accel = %v, want %v", m.sample.Accel, want)
+	}
+
+	// axes without resolution are reported in device units
+	if want := [3]float64{0, 30, 0}; m.sample.Gyro != want {
+		t.Errorf("Gyro = %v, want %v", m.sample.Gyro, want)
+	}
+}
+
+func TestIMU_updateOrientation(t *testing.T) {
+	near := func(a, b float64) bool {
+		return math.Abs(a-b) < 1e-9
+	}
+
+	m := &IMU{hasGyro: true, alpha: 0.5}
+
+	// lying flat, the first sample only uses the accelerometer
+	m.sample = IMUSample{Time: syscall.Timeval{Sec: 1}, Accel: [3]float64{0, 0, 1}}
+	m.updateOrientation()
+
+	if pitch, roll := m.Orientation(); pitch != 0 || roll != 0 {
+		t.Errorf("Orientation() = %v, %v, want 0, 0", pitch, roll)
+	}
+
+	// rotating around X at 90 deg/s for one second, while the accelerometer
+	// reports a roll of 45 degrees
+	m.sample = IMUSample{
+		Time:  syscall.Timeval{Sec: 2},
+		Accel: [3]float64{0, 1, 1},
+		Gyro:  [3]float64{90, 0, 0},
+	}
+	m.updateOrientation()
+
+	if pitch, roll := m.Orientation(); !near(pitch, 0) || !near(roll, 0.5*90+0.5*45) {
+		t.Errorf("Orientation() = %v, %v, want 0, 67.5", pitch, roll)
+	}
+}
@@ -1,6 +1,7 @@
 package evdev
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"syscall"
@@ -40,6 +41,15 @@ func doIoctl(fd uintptr, code uint32, ptr unsafe.Pointer) error {
 	return nil
 }
 
+func cString(b []byte) string {
+	i := bytes.IndexByte(b, 0)
+	if i < 0 {
+		return string(b)
+	}
+
+	return string(b[:i])
+}
+
 func ioctlEVIOCGVERSION(fd uintptr) (int32, error) {
 	version := int32(0)
 	code := ioctlMakeCode(ioctlDirRead, 'E', 0x01, unsafe.Sizeof(version))
@@ -82,21 +92,21 @@ func ioctlEVIOCGNAME(fd uintptr) (string, error) {
 	str := [256]byte{}
 	code := ioctlMakeCode(ioctlDirRead, 'E', 0x06, unsafe.Sizeof(str))
 	err := doIoctl(fd, code, unsafe.Pointer(&str))
-	return string(str[:]), err
+	return cString(str[:]), err
 }
 
 func ioctlEVIOCGPHYS(fd uintptr) (string, error) {
 	str := [256]byte{}
 	code := ioctlMakeCode(ioctlDirRead, 'E', 0x07, unsafe.Sizeof(str))
 	err := doIoctl(fd, code, unsafe.Pointer(&str))
-	return string(str[:]), err
+	return cString(str[:]), err
 }
 
 func ioctlEVIOCGUNIQ(fd uintptr) (string, error) {
 	str := [256]byte{}
 	code := ioctlMakeCode(ioctlDirRead, 'E', 0x08, unsafe.Sizeof(str))
 	err := doIoctl(fd, code, unsafe.Pointer(&str))
-	return string(str[:]), err
+	return cString(str[:]), err
 }
 
 func ioctlEVIOCGPROP(fd uintptr) ([]byte, error) {
@@ -153,12 +163,132 @@ func ioctlEVIOCSABS(fd uintptr, abs int, info AbsInfo) error {
 	return doIoctl(fd, code, unsafe.Pointer(&info))
 }
 
-func ioctlEVIOCGRAB(fd uintptr) error {
-	code := ioctlMakeCode(ioctlDirWrite, 'E', 0x90, 0)
-	return doIoctl(fd, code, nil)
+func ioctlEVIOCGRAB(fd uintptr, grab bool) error {
+	code := ioctlMakeCode(ioctlDirWrite, 'E', 0x90, unsafe.Sizeof(int32(0)))
+
+	// EVIOCGRAB takes its argument by value rather than by pointer
+	arg := uintptr(0)
+	if grab {
+		arg = 1
+	}
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(code), arg)
+	if errno != 0 {
+		return errors.New(errno.Error())
+	}
+
+	return nil
 }
 
 func ioctlEVIOCREVOKE(fd uintptr) error {
-	code := ioctlMakeCode(ioctlDirWrite, 'E', 0x91, 0)
+	code := ioctlMakeCode(ioctlDirWrite, 'E', 0x91, unsafe.Sizeof(int32(0)))
+	return doIoctl(fd, code, nil)
+}
+
+func ioctlEVIOCSCLOCKID(fd uintptr, clockID int32) error {
+	code := ioctlMakeCode(ioctlDirWrite, 'E', 0xa0, unsafe.Sizeof(clockID))
+	return doIoctl(fd, code, unsafe.Pointer(&clockID))
+}
+
+func ioctlEVIOCSFF(fd uintptr, effect *ffEffect) error {
+	code := ioctlMakeCode(ioctlDirWrite, 'E', 0x80, unsafe.Sizeof(*effect))
+	return doIoctl(fd, code, unsafe.Pointer(effect))
+}
+
+func ioctlEVIOCRMFF(fd uintptr, id int16) error {
+	code := ioctlMakeCode(ioctlDirWrite, 'E', 0x81, unsafe.Sizeof(int32(0)))
+
+	// EVIOCRMFF takes the effect ID by value
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(code), uintptr(id))
+	if errno != 0 {
+		return errors.New(errno.Error())
+	}
+
+	return nil
+}
+
+func ioctlEVIOCGEFFECTS(fd uintptr) (int32, error) {
+	n := int32(0)
+	code := ioctlMakeCode(ioctlDirRead, 'E', 0x84, unsafe.Sizeof(n))
+	err := doIoctl(fd, code, unsafe.Pointer(&n))
+	return n, err
+}
+
+// uinputSetup is the kernel's struct uinput_setup.
+type uinputSetup struct {
+	id           InputID
+	name         [80]byte
+	ffEffectsMax uint32
+}
+
+// uinputAbsSetup is the kernel's struct uinput_abs_setup.
+type uinputAbsSetup struct {
+	code uint16
+	_    uint16
+	info AbsInfo
+}
+
+func ioctlUIDEVCREATE(fd uintptr) error {
+	code := ioctlMakeCode(ioctlDirNone, 'U', 1, 0)
 	return doIoctl(fd, code, nil)
 }
+
+func ioctlUIDEVDESTROY(fd uintptr) error {
+	code := ioctlMakeCode(ioctlDirNone, 'U', 2, 0)
+	return doIoctl(fd, code, nil)
+}
+
+func ioctlUIDEVSETUP(fd uintptr, setup *uinputSetup) error {
+	code := ioctlMakeCode(ioctlDirWrite, 'U', 3, unsafe.Sizeof(*setup))
+	return doIoctl(fd, code, unsafe.Pointer(setup))
+}
+
+func ioctlUIABSSETUP(fd uintptr, setup *uinputAbsSetup) error {
+	code := ioctlMakeCode(ioctlDirWrite, 'U', 4, unsafe.Sizeof(*setup))
+	return doIoctl(fd, code, unsafe.Pointer(setup))
+}
+
+// ioctlUISETBIT calls one of the UI_SET_*BIT ioctls, which take their
+// argument by value.
+func ioctlUISETBIT(fd uintptr, nr int, bit int) error {
+	code := ioctlMakeCode(ioctlDirWrite, 'U', nr, unsafe.Sizeof(int32(0)))
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(code), uintptr(bit))
+	if errno != 0 {
+		return errors.New(errno.Error())
+	}
+
+	return nil
+}
+
+func ioctlUISETPHYS(fd uintptr, phys string) error {
+	b := append([]byte(phys), 0)
+	code := ioctlMakeCode(ioctlDirWrite, 'U', 108, unsafe.Sizeof(uintptr(0)))
+	return doIoctl(fd, code, unsafe.Pointer(&b[0]))
+}
+
+func ioctlUIGETSYSNAME(fd uintptr) (string, error) {
+	str := [64]byte{}
+	code := ioctlMakeCode(ioctlDirRead, 'U', 44, unsafe.Sizeof(str))
+	err := doIoctl(fd, code, unsafe.Pointer(&str))
+	return cString(str[:]), err
+}
+
+// uinputUserDev is the kernel's struct uinput_user_dev, which is written to
+// the device to set it up on kernels without UI_DEV_SETUP.
+type uinputUserDev struct {
+	name         [80]byte
+	id           InputID
+	ffEffectsMax uint32
+	absMax       [64]int32
+	absMin       [64]int32
+	absFuzz      [64]int32
+	absFlat      [64]int32
+}
+
+func ioctlUIGETVERSION(fd uintptr) (uint32, error) {
+	var version uint32
+	code := ioctlMakeCode(ioctlDirRead, 'U', 45, unsafe.Sizeof(version))
+	err := doIoctl(fd, code, unsafe.Pointer(&version))
+	return version, err
+}
@@ -0,0 +1,191 @@
+package evdev
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+)
+
+// KeyboardKey is a physical key of a keyboard. Positions and sizes are in
+// units of a regular key, with rows counted from the function key row.
+// Keys without a place on a standard PC keyboard have a Row of -1.
+type KeyboardKey struct {
+	Code   EvCode  `json:"code"`
+	Name   string  `json:"name"`
+	Row    int     `json:"row"`
+	X      float64 `json:"x"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// KeyboardDescription describes the keys of a keyboard, e.g. for on-screen
+// visualizations or key testers. ISO is set if the keyboard has the
+// additional key next to the left shift key of ISO keyboards.
+type KeyboardDescription struct {
+	Name    string        `json:"name"`
+	ID      InputID       `json:"id"`
+	ISO     bool          `json:"iso"`
+	Model   string        `json:"model,omitempty"`
+	Layout  string        `json:"layout,omitempty"`
+	Variant string        `json:"variant,omitempty"`
+	Keys    []KeyboardKey `json:"keys"`
+}
+
+// WriteJSON writes the description as JSON.
+func (kd *KeyboardDescription) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(kd)
+}
+
+type keyPlace struct {
+	row     int
+	x, w, h float64
+}
+
+// pcKeys are the places of the keys of a full-size ANSI PC keyboard.
+var pcKeys = map[EvCode]keyPlace{}
+
+// placeKeys places unit keys side by side, starting at x.
+func placeKeys(row int, x float64, codes ...EvCode) {
+	for i, c := range codes {
+		pcKeys[c] = keyPlace{row: row, x: x + float64(i), w: 1, h: 1}
+	}
+}
+
+func placeKey(row int, x, w, h float64, c EvCode) {
+	pcKeys[c] = keyPlace{row: row, x: x, w: w, h: h}
+}
+
+func init() {
+	placeKey(0, 0, 1, 1, KEY_ESC)
+	placeKeys(0, 2, KEY_F1, KEY_F2, KEY_F3, KEY_F4)
+	placeKeys(0, 6.5, KEY_F5, KEY_F6, KEY_F7, KEY_F8)
+	placeKeys(0, 11, KEY_F9, KEY_F10, KEY_F11, KEY_F12)
+	placeKeys(0, 15.25, KEY_SYSRQ, KEY_SCROLLLOCK, KEY_PAUSE)
+
+	placeKeys(1, 0, KEY_GRAVE, KEY_1, KEY_2, KEY_3, KEY_4, KEY_5, KEY_6, KEY_7, KEY_8, KEY_9, KEY_0, KEY_MINUS, KEY_EQUAL)
+	placeKey(1, 13, 2, 1, KEY_BACKSPACE)
+	placeKeys(1, 15.25, KEY_INSERT, KEY_HOME, KEY_PAGEUP)
+	placeKeys(1, 18.5, KEY_NUMLOCK, KEY_KPSLASH, KEY_KPASTERISK, KEY_KPMINUS)
+
+	placeKey(2, 0, 1.5, 1, KEY_TAB)
+	placeKeys(2, 1.5, KEY_Q, KEY_W, KEY_E, KEY_R, KEY_T, KEY_Y, KEY_U, KEY_I, KEY_O, KEY_P, KEY_LEFTBRACE, KEY_RIGHTBRACE)
+	placeKey(2, 13.5, 1.5, 1, KEY_BACKSLASH)
+	placeKeys(2, 15.25, KEY_DELETE, KEY_END, KEY_PAGEDOWN)
+	placeKeys(2, 18.5, KEY_KP7, KEY_KP8, KEY_KP9)
+	placeKey(2, 21.5, 1, 2, KEY_KPPLUS)
+
+	placeKey(3, 0, 1.75, 1, KEY_CAPSLOCK)
+	placeKeys(3, 1.75, KEY_A, KEY_S, KEY_D, KEY_F, KEY_G, KEY_H, KEY_J, KEY_K, KEY_L, KEY_SEMICOLON, KEY_APOSTROPHE)
+	placeKey(3, 12.75, 2.25, 1, KEY_ENTER)
+	placeKeys(3, 18.5, KEY_KP4, KEY_KP5, KEY_KP6)
+
+	placeKey(4, 0, 2.25, 1, KEY_LEFTSHIFT)
+	placeKey(4, 1.25, 1, 1, KEY_102ND)
+	placeKeys(4, 2.25, KEY_Z, KEY_X, KEY_C, KEY_V, KEY_B, KEY_N, KEY_M, KEY_COMMA, KEY_DOT, KEY_SLASH)
+	placeKey(4, 12.25, 2.75, 1, KEY_RIGHTSHIFT)
+	placeKey(4, 16.25, 1, 1, KEY_UP)
+	placeKeys(4, 18.5, KEY_KP1, KEY_KP2, KEY_KP3)
+	placeKey(4, 21.5, 1, 2, KEY_KPENTER)
+
+	placeKey(5, 0, 1.25, 1, KEY_LEFTCTRL)
+	placeKey(5, 1.25, 1.25, 1, KEY_LEFTMETA)
+	placeKey(5, 2.5, 1.25, 1, KEY_LEFTALT)
+	placeKey(5, 3.75, 6.25, 1, KEY_SPACE)
+	placeKey(5, 10, 1.25, 1, KEY_RIGHTALT)
+	placeKey(5, 11.25, 1.25, 1, KEY_RIGHTMETA)
+	placeKey(5, 12.5, 1.25, 1, KEY_COMPOSE)
+	placeKey(5, 13.75, 1.25, 1, KEY_RIGHTCTRL)
+	placeKeys(5, 15.25, KEY_LEFT, KEY_DOWN, KEY_RIGHT)
+	placeKey(5, 18.5, 2, 1, KEY_KP0)
+	placeKey(5, 20.5, 1, 1, KEY_KPDOT)
+}
+
+// isKeyboardKey returns true for the codes of keys, as opposed to the
+// buttons of mice, joysticks and other devices.
+func isKeyboardKey(c EvCode) bool {
+	return c > KEY_RESERVED && strings.HasPrefix(CodeName(EV_KEY, c), "KEY_")
+}
+
+// describeKeys returns the keyboard keys among codes, the placed ones by
+// row and position, followed by the others by code.
+func describeKeys(codes []EvCode) ([]KeyboardKey, bool) {
+	iso := false
+	for _, c := range codes {
+		if c == KEY_102ND {
+			iso = true
+		}
+	}
+
+	keys := []KeyboardKey{}
+
+	for _, c := range codes {
+		if !isKeyboardKey(c) {
+			continue
+		}
+
+		k := KeyboardKey{Code: c, Name: CodeName(EV_KEY, c), Row: -1}
+
+		if p, ok := pcKeys[c]; ok {
+			k.Row, k.X, k.Width, k.Height = p.row, p.x, p.w, p.h
+
+			// ISO keyboards have a shorter left shift key and a taller
+			// enter key, with the backslash key left of it
+			if iso {
+				switch c {
+				case KEY_LEFTSHIFT:
+					k.Width = 1.25
+				case KEY_BACKSLASH:
+					k.Row, k.X, k.Width = 3, 12.75, 1
+				case KEY_ENTER:
+					k.Row, k.X, k.Width, k.Height = 2, 13.75, 1.25, 2
+				}
+			}
+		}
+
+		keys = append(keys, k)
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+
+		switch {
+		case a.Row < 0 || b.Row < 0:
+			return a.Row >= 0 && b.Row < 0
+		case a.Row != b.Row:
+			return a.Row < b.Row
+		}
+
+		return a.X < b.X
+	})
+
+	return keys, iso
+}
+
+// DescribeKeyboard describes the keys of d from its capabilities, placed on
+// a standard PC keyboard where they have a place. layout optionally adds
+// the layout the keys are labeled with, as returned by
+// DetectKeyboardLayout.
+func DescribeKeyboard(d *InputDevice, layout *KeyboardLayout) (*KeyboardDescription, error) {
+	name, err := d.Name()
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := d.InputID()
+	if err != nil {
+		return nil, err
+	}
+
+	kd := &KeyboardDescription{Name: name, ID: id}
+	kd.Keys, kd.ISO = describeKeys(d.CapableEvents(EV_KEY))
+
+	if layout != nil {
+		kd.Model, kd.Layout, kd.Variant = layout.Model, layout.Layout, layout.Variant
+	}
+
+	return kd, nil
+}
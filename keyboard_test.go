@@ -0,0 +1,72 @@
+package evdev
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDescribeKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		codes []EvCode
+		iso   bool
+		want  []KeyboardKey
+	}{
+		{
+			name:  "ANSI",
+			codes: []EvCode{KEY_ENTER, KEY_BACKSLASH, KEY_LEFTSHIFT, KEY_ESC, KEY_MUTE, BTN_LEFT, KEY_A},
+			want: []KeyboardKey{
+				{Code: KEY_ESC, Name: "KEY_ESC", Row: 0, X: 0, Width: 1, Height: 1},
+				{Code: KEY_BACKSLASH, Name: "KEY_BACKSLASH", Row: 2, X: 13.5, Width: 1.5, Height: 1},
+				{Code: KEY_A, Name: "KEY_A", Row: 3, X: 1.75, Width: 1, Height: 1},
+				{Code: KEY_ENTER, Name: "KEY_ENTER", Row: 3, X: 12.75, Width: 2.25, Height: 1},
+				{Code: KEY_LEFTSHIFT, Name: "KEY_LEFTSHIFT", Row: 4, X: 0, Width: 2.25, Height: 1},
+				{Code: KEY_MUTE, Name: "KEY_MUTE", Row: -1},
+			},
+		},
+		{
+			name:  "ISO",
+			codes: []EvCode{KEY_ENTER, KEY_BACKSLASH, KEY_LEFTSHIFT, KEY_102ND},
+			iso:   true,
+			want: []KeyboardKey{
+				{Code: KEY_ENTER, Name: "KEY_ENTER", Row: 2, X: 13.75, Width: 1.25, Height: 2},
+				{Code: KEY_BACKSLASH, Name: "KEY_BACKSLASH", Row: 3, X: 12.75, Width: 1, Height: 1},
+				{Code: KEY_LEFTSHIFT, Name: "KEY_LEFTSHIFT", Row: 4, X: 0, Width: 1.25, Height: 1},
+				{Code: KEY_102ND, Name: "KEY_102ND", Row: 4, X: 1.25, Width: 1, Height: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		keys, iso := describeKeys(tt.codes)
+
+		if iso != tt.iso {
+			t.Errorf("%s: iso = %v, want %v", tt.name, iso, tt.iso)
+		}
+
+		if !reflect.DeepEqual(keys, tt.want) {
+			t.Errorf("%s: keys = %+v, want %+v", tt.name, keys, tt.want)
+		}
+	}
+}
+
+func TestKeyboardDescription_WriteJSON(t *testing.T) {
+	kd := &KeyboardDescription{Name: "Test Keyboard", Layout: "de"}
+	kd.Keys, kd.ISO = describeKeys([]EvCode{KEY_Z, KEY_102ND})
+
+	buf := &bytes.Buffer{}
+	if err := kd.WriteJSON(buf); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+
+	got := &KeyboardDescription{}
+	if err := json.Unmarshal(buf.Bytes(), got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, kd) {
+		t.Errorf("round trip = %+v, want %+v", got, kd)
+	}
+}
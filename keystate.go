@@ -0,0 +1,53 @@
+package evdev
+
+import "fmt"
+
+// KeyState is the value of an EV_KEY event.
+type KeyState int32
+
+// Key states reported in the value of EV_KEY events
+const (
+	KeyUp     KeyState = 0
+	KeyDown   KeyState = 1
+	KeyRepeat KeyState = 2
+)
+
+var keyStateNames = map[KeyState]string{
+	KeyUp:     "up",
+	KeyDown:   "down",
+	KeyRepeat: "repeat",
+}
+
+func (s KeyState) String() string {
+	name, ok := keyStateNames[s]
+	if ok {
+		return name
+	}
+
+	return "UNKNOWN"
+}
+
+// Valid returns true if s is one of KeyUp, KeyDown and KeyRepeat.
+func (s KeyState) Valid() bool {
+	return s >= KeyUp && s <= KeyRepeat
+}
+
+// KeyState returns the key state of an EV_KEY event. It fails for events of
+// other types and for values that are no valid key state.
+func (e *InputEvent) KeyState() (KeyState, error) {
+	if e.Type != EV_KEY {
+		return KeyUp, fmt.Errorf("Event of type %s has no key state", TypeName(e.Type))
+	}
+
+	s := KeyState(e.Value)
+	if !s.Valid() {
+		return KeyUp, fmt.Errorf("Invalid key state %d", e.Value)
+	}
+
+	return s, nil
+}
+
+// State returns the key state of the event. It is not necessarily Valid.
+func (e KeyEvent) State() KeyState {
+	return KeyState(e.Value)
+}
@@ -0,0 +1,33 @@
+package evdev
+
+import "testing"
+
+func TestInputEvent_KeyState(t *testing.T) {
+	tests := []struct {
+		e       InputEvent
+		want    KeyState
+		wantErr bool
+	}{
+		{e: InputEvent{Type: EV_KEY, Code: KEY_A, Value: 0}, want: KeyUp},
+		{e: InputEvent{Type: EV_KEY, Code: KEY_A, Value: 1}, want: KeyDown},
+		{e: InputEvent{Type: EV_KEY, Code: KEY_A, Value: 2}, want: KeyRepeat},
+		{e: InputEvent{Type: EV_KEY, Code: KEY_A, Value: 3}, wantErr: true},
+		{e: InputEvent{Type: EV_KEY, Code: KEY_A, Value: -1}, wantErr: true},
+		{e: InputEvent{Type: EV_REL, Code: REL_X, Value: 1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := tt.e.KeyState()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("KeyState() of %v error = %v, wantErr %v", tt.e, err, tt.wantErr)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("KeyState() of %v = %v, want %v", tt.e, got, tt.want)
+		}
+	}
+
+	if s := KeyState(7).String(); s != "UNKNOWN" {
+		t.Errorf("String() = %q, want UNKNOWN", s)
+	}
+}
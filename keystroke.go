@@ -0,0 +1,162 @@
+package evdev
+
+import (
+	"sort"
+	"time"
+)
+
+// Keystroke is one press and release of a key. Durations are encoded as
+// nanoseconds in JSON.
+type Keystroke struct {
+	Key  EvCode    `json:"key"`
+	Name string    `json:"name"`
+	Down time.Time `json:"down"` // time of the press, see InputEvent.Timestamp
+
+	Hold time.Duration `json:"hold"` // time the key was held down
+
+	// time since the previous key press, 0 for the first keystroke
+	Interval time.Duration `json:"interval"`
+}
+
+// KeyTiming summarizes the keystrokes of one key. Durations are encoded as
+// nanoseconds in JSON.
+type KeyTiming struct {
+	Key   EvCode `json:"key"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+
+	MeanHold time.Duration `json:"mean_hold"`
+	MinHold  time.Duration `json:"min_hold"`
+	MaxHold  time.Duration `json:"max_hold"`
+
+	// mean time since the previous key press, over the keystrokes that had
+	// a previous key press
+	MeanInterval time.Duration `json:"mean_interval"`
+}
+
+type keyTimingSum struct {
+	count     int
+	hold      time.Duration
+	minHold   time.Duration
+	maxHold   time.Duration
+	intervals int
+	interval  time.Duration
+}
+
+// KeystrokeRecorder computes hold times and the intervals between key
+// presses from a keyboard's event stream, for typing tutors and ergonomics
+// tools. Key repeats and buttons are ignored. It keeps per-key sums only,
+// so memory use does not grow with the number of keystrokes.
+type KeystrokeRecorder struct {
+	pressed   map[EvCode]*Keystroke
+	lastPress *InputEvent
+	sums      map[EvCode]*keyTimingSum
+}
+
+// NewKeystrokeRecorder creates a KeystrokeRecorder.
+func NewKeystrokeRecorder() *KeystrokeRecorder {
+	return &KeystrokeRecorder{
+		pressed: map[EvCode]*Keystroke{},
+		sums:    map[EvCode]*keyTimingSum{},
+	}
+}
+
+// Push processes an event and returns the keystroke it completed, if any.
+// After SYN_DROPPED, keys held at that time are forgotten.
+func (kr *KeystrokeRecorder) Push(e InputEvent) (Keystroke, bool) {
+	if e.Type == EV_SYN && e.Code == SYN_DROPPED {
+		kr.pressed = map[EvCode]*Keystroke{}
+		kr.lastPress = nil
+		return Keystroke{}, false
+	}
+
+	if e.Type != EV_KEY {
+		return Keystroke{}, false
+	}
+
+	if _, isKey := KEYName[e.Code]; !isKey {
+		return Keystroke{}, false
+	}
+
+	switch KeyState(e.Value) {
+	case KeyDown:
+		ks := &Keystroke{
+			Key:  e.Code,
+			Name: KEYName[e.Code],
+			Down: e.Timestamp(),
+		}
+
+		if kr.lastPress != nil {
+			ks.Interval = e.Since(kr.lastPress)
+		}
+
+		kr.pressed[e.Code] = ks
+		kr.lastPress = &e
+
+	case KeyUp:
+		ks, ok := kr.pressed[e.Code]
+		if !ok {
+			return Keystroke{}, false
+		}
+
+		delete(kr.pressed, e.Code)
+
+		ks.Hold = e.Timestamp().Sub(ks.Down)
+		kr.add(ks)
+
+		return *ks, true
+	}
+
+	return Keystroke{}, false
+}
+
+func (kr *KeystrokeRecorder) add(ks *Keystroke) {
+	s, ok := kr.sums[ks.Key]
+	if !ok {
+		s = &keyTimingSum{minHold: ks.Hold, maxHold: ks.Hold}
+		kr.sums[ks.Key] = s
+	}
+
+	s.count++
+	s.hold += ks.Hold
+
+	if ks.Hold < s.minHold {
+		s.minHold = ks.Hold
+	}
+
+	if ks.Hold > s.maxHold {
+		s.maxHold = ks.Hold
+	}
+
+	if ks.Interval > 0 {
+		s.intervals++
+		s.interval += ks.Interval
+	}
+}
+
+// Timings returns the timing summary of every key typed so far, ordered by
+// key code.
+func (kr *KeystrokeRecorder) Timings() []KeyTiming {
+	timings := make([]KeyTiming, 0, len(kr.sums))
+
+	for key, s := range kr.sums {
+		kt := KeyTiming{
+			Key:      key,
+			Name:     KEYName[key],
+			Count:    s.count,
+			MeanHold: s.hold / time.Duration(s.count),
+			MinHold:  s.minHold,
+			MaxHold:  s.maxHold,
+		}
+
+		if s.intervals > 0 {
+			kt.MeanInterval = s.interval / time.Duration(s.intervals)
+		}
+
+		timings = append(timings, kt)
+	}
+
+	sort.Slice(timings, func(i, j int) bool { return timings[i].Key < timings[j].Key })
+
+	return timings
+}
@@ -0,0 +1,60 @@
+package evdev
+
+import (
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func keyAt(ms int64, code EvCode, value int32) InputEvent {
+	return InputEvent{
+		Time:  syscall.NsecToTimeval(ms * int64(time.Millisecond)),
+		Type:  EV_KEY,
+		Code:  code,
+		Value: value,
+	}
+}
+
+func TestKeystrokeRecorder(t *testing.T) {
+	kr := NewKeystrokeRecorder()
+
+	events := []InputEvent{
+		keyAt(0, KEY_B, 0), // released before recording started
+		keyAt(0, KEY_A, 1),
+		keyAt(100, KEY_S, 1), // rollover: S pressed before A is released
+		keyAt(120, KEY_A, 0),
+		keyAt(150, KEY_S, 2),
+		keyAt(180, KEY_S, 0),
+		keyAt(200, BTN_LEFT, 1),
+		keyAt(300, KEY_A, 1),
+		keyAt(380, KEY_A, 0),
+	}
+
+	got := []Keystroke{}
+	for _, e := range events {
+		if ks, ok := kr.Push(e); ok {
+			got = append(got, ks)
+		}
+	}
+
+	ms := time.Millisecond
+	want := []Keystroke{
+		{Key: KEY_A, Name: "KEY_A", Down: time.Unix(0, 0), Hold: 120 * ms},
+		{Key: KEY_S, Name: "KEY_S", Down: time.Unix(0, int64(100*ms)), Hold: 80 * ms, Interval: 100 * ms},
+		{Key: KEY_A, Name: "KEY_A", Down: time.Unix(0, int64(300*ms)), Hold: 80 * ms, Interval: 200 * ms},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("keystrokes = %+v, want %+v", got, want)
+	}
+
+	wantTimings := []KeyTiming{
+		{Key: KEY_A, Name: "KEY_A", Count: 2, MeanHold: 100 * ms, MinHold: 80 * ms, MaxHold: 120 * ms, MeanInterval: 200 * ms},
+		{Key: KEY_S, Name: "KEY_S", Count: 1, MeanHold: 80 * ms, MinHold: 80 * ms, MaxHold: 80 * ms, MeanInterval: 100 * ms},
+	}
+
+	if got := kr.Timings(); !reflect.DeepEqual(got, wantTimings) {
+		t.Errorf("Timings() = %+v, want %+v", got, wantTimings)
+	}
+}
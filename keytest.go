@@ -0,0 +1,176 @@
+package evdev
+
+import (
+	"time"
+)
+
+// KeyStats are the statistics a KeyboardTest gathered for one key.
+type KeyStats struct {
+	Code     EvCode
+	Presses  int // presses, including the chattering ones
+	Repeats  int // autorepeat events
+	Chatters int // presses that followed a release within the chatter window
+
+	// presses of a key already held and releases of a key not held, which
+	// hint at lost events
+	Unpaired int
+
+	MinGap      time.Duration // shortest time between a release and the next press
+	LongestHold time.Duration // longest time the key was held, up to now if it is held
+	Held        bool
+	Stuck       bool // held longer than the stuck threshold
+}
+
+type keyTestState struct {
+	stats    KeyStats
+	pressed  time.Time // time of the last press
+	released time.Time // time of the last release, zero if there was none
+}
+
+// KeyboardTest monitors a keyboard for hardware faults. A press that follows
+// the release of the same key within the chatter window is counted as
+// chatter, as switches that bounce report a quick release and press while
+// the user holds them down. A key held longer than the stuck threshold is
+// reported as stuck, which reveals keys the keyboard never released.
+//
+// Event times are taken from the events, so recorded streams can be
+// analyzed as well. The times passed to Stats and Stuck must be on the same
+// clock, see InputEvent.Timestamp.
+type KeyboardTest struct {
+	chatterWindow time.Duration
+	stuckAfter    time.Duration
+	keys          map[EvCode]*keyTestState
+}
+
+// NewKeyboardTest creates a KeyboardTest. Typical values are a chatter
+// window of 30ms and a stuck threshold of 10s.
+func NewKeyboardTest(chatterWindow, stuckAfter time.Duration) *KeyboardTest {
+	return &KeyboardTest{
+		chatterWindow: chatterWindow,
+		stuckAfter:    stuckAfter,
+		keys:          map[EvCode]*keyTestState{},
+	}
+}
+
+// Process records an event read from the keyboard. After SYN_DROPPED, all
+// keys are considered released, as their releases may have been lost.
+func (kt *KeyboardTest) Process(e *InputEvent) {
+	if e.Type == EV_SYN && e.Code == SYN_DROPPED {
+		for _, k := range kt.keys {
+			k.stats.Held = false
+		}
+
+		return
+	}
+
+	if e.Type != EV_KEY {
+		return
+	}
+
+	k, ok := kt.keys[e.Code]
+	if !ok {
+		k = &keyTestState{stats: KeyStats{Code: e.Code}}
+		kt.keys[e.Code] = k
+	}
+
+	now := e.Timestamp()
+
+	switch KeyState(e.Value) {
+	case KeyRepeat:
+		k.stats.Repeats++
+
+	case KeyDown:
+		k.stats.Presses++
+
+		if k.stats.Held {
+			k.stats.Unpaired++
+			k.updateHold(now)
+		}
+
+		if !k.stats.Held && !k.released.IsZero() {
+			gap := now.Sub(k.released)
+			if k.stats.MinGap == 0 || gap < k.stats.MinGap {
+				k.stats.MinGap = gap
+			}
+
+			if gap < kt.chatterWindow {
+				k.stats.Chatters++
+			}
+		}
+
+		k.stats.Held = true
+		k.pressed = now
+
+	case KeyUp:
+		if !k.stats.Held {
+			k.stats.Unpaired++
+		} else {
+			k.updateHold(now)
+		}
+
+		k.stats.Held = false
+		k.released = now
+	}
+}
+
+func (k *keyTestState) updateHold(now time.Time) {
+	if d := now.Sub(k.pressed); d > k.stats.LongestHold {
+		k.stats.LongestHold = d
+	}
+}
+
+// Stats returns the statistics of all keys seen so far as of now, in
+// ascending order of their codes.
+func (kt *KeyboardTest) Stats(now time.Time) []KeyStats {
+	codes := make([]EvCode, 0, len(kt.keys))
+	for c := range kt.keys {
+		codes = append(codes, c)
+	}
+
+	stats := []KeyStats{}
+
+	for _, c := range sortCodes(codes) {
+		k := kt.keys[c]
+		s := k.stats
+
+		if s.Held {
+			if d := now.Sub(k.pressed); d > s.LongestHold {
+				s.LongestHold = d
+			}
+
+			s.Stuck = now.Sub(k.pressed) >= kt.stuckAfter
+		}
+
+		stats = append(stats, s)
+	}
+
+	return stats
+}
+
+// Chattering returns the keys that chattered at least once, in ascending
+// order.
+func (kt *KeyboardTest) Chattering() []EvCode {
+	codes := []EvCode{}
+
+	for c, k := range kt.keys {
+		if k.stats.Chatters > 0 {
+			codes = append(codes, c)
+		}
+	}
+
+	return sortCodes(codes)
+}
+
+// Stuck returns the keys held longer than the stuck threshold as of now, in
+// ascending order.
+func (kt *KeyboardTest) Stuck(now time.Time) []EvCode {
+	codes := []EvCode{}
+
+	for c, k := range kt.keys {
+		if k.stats.Held && now.Sub(k.pressed) >= kt.stuckAfter {
+			codes = append(codes, c)
+		}
+	}
+
+	return sortCodes(codes)
+}
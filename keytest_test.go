@@ -0,0 +1,56 @@
+package evdev
+
+import (
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestKeyboardTest(t *testing.T) {
+	key := func(ms int64, c EvCode, v int32) *InputEvent {
+		return &InputEvent{Time: syscall.NsecToTimeval(ms * int64(time.Millisecond)), Type: EV_KEY, Code: c, Value: v}
+	}
+	at := func(ms int64) time.Time { return time.Unix(0, ms*int64(time.Millisecond)) }
+
+	kt := NewKeyboardTest(30*time.Millisecond, time.Second)
+
+	for _, e := range []*InputEvent{
+		// KEY_A bounces once
+		key(0, KEY_A, 1), key(80, KEY_A, 0), key(85, KEY_A, 1), key(150, KEY_A, 0),
+		// KEY_B is typed twice cleanly
+		key(200, KEY_B, 1), key(250, KEY_B, 0), key(400, KEY_B, 1), key(450, KEY_B, 0),
+		// KEY_C is never released, KEY_D was pressed before the test started
+		key(500, KEY_C, 1), key(1000, KEY_C, 2), key(600, KEY_D, 0),
+	} {
+		kt.Process(e)
+	}
+
+	want := []KeyStats{
+		{Code: KEY_A, Presses: 2, Chatters: 1, MinGap: 5 * time.Millisecond, LongestHold: 80 * time.Millisecond},
+		{Code: KEY_D, Unpaired: 1},
+		{Code: KEY_C, Presses: 1, Repeats: 1, LongestHold: 1500 * time.Millisecond, Held: true, Stuck: true},
+		{Code: KEY_B, Presses: 2, MinGap: 150 * time.Millisecond, LongestHold: 50 * time.Millisecond},
+	}
+	if s := kt.Stats(at(2000)); !reflect.DeepEqual(s, want) {
+		t.Errorf("Stats() = %+v, want %+v", s, want)
+	}
+
+	if c := kt.Chattering(); !reflect.DeepEqual(c, []EvCode{KEY_A}) {
+		t.Errorf("Chattering() = %v, want [KEY_A]", c)
+	}
+
+	if c := kt.Stuck(at(1200)); len(c) != 0 {
+		t.Errorf("Stuck() = %v, want none", c)
+	}
+
+	if c := kt.Stuck(at(1500)); !reflect.DeepEqual(c, []EvCode{KEY_C}) {
+		t.Errorf("Stuck() = %v, want [KEY_C]", c)
+	}
+
+	kt.Process(&InputEvent{Type: EV_SYN, Code: SYN_DROPPED})
+
+	if c := kt.Stuck(at(2000)); len(c) != 0 {
+		t.Errorf("Stuck() after SYN_DROPPED = %v, want none", c)
+	}
+}
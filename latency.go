@@ -0,0 +1,145 @@
+package evdev
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LatencyRecorder collects latency samples, e.g. the time between the
+// timestamp of a frame and the moment it was processed, and summarizes
+// them as percentiles and histograms.
+type LatencyRecorder struct {
+	samples []time.Duration
+	sorted  bool
+}
+
+// NewLatencyRecorder creates an empty LatencyRecorder.
+func NewLatencyRecorder() *LatencyRecorder {
+	return &LatencyRecorder{}
+}
+
+// Add adds a sample.
+func (lr *LatencyRecorder) Add(d time.Duration) {
+	lr.samples = append(lr.samples, d)
+	lr.sorted = false
+}
+
+// AddFrame adds the time from the timestamp of the last event of frame
+// until now. The frame must be stamped with CLOCK_REALTIME, the default of
+// event devices.
+func (lr *LatencyRecorder) AddFrame(frame []InputEvent, now time.Time) {
+	if len(frame) > 0 {
+		lr.Add(now.Sub(frame[len(frame)-1].Timestamp()))
+	}
+}
+
+// Count returns the number of samples.
+func (lr *LatencyRecorder) Count() int {
+	return len(lr.samples)
+}
+
+func (lr *LatencyRecorder) sort() {
+	if !lr.sorted {
+		sort.Slice(lr.samples, func(i, j int) bool { return lr.samples[i] < lr.samples[j] })
+		lr.sorted = true
+	}
+}
+
+// Percentile returns the sample below or at which p percent of the samples
+// are, using the nearest-rank method, or 0 if there are no samples.
+func (lr *LatencyRecorder) Percentile(p float64) time.Duration {
+	if len(lr.samples) == 0 {
+		return 0
+	}
+
+	lr.sort()
+
+	rank := int(p/100*float64(len(lr.samples))+0.999999) - 1
+	switch {
+	case rank < 0:
+		rank = 0
+	case rank >= len(lr.samples):
+		rank = len(lr.samples) - 1
+	}
+
+	return lr.samples[rank]
+}
+
+// LatencyBucket is a bucket of a latency histogram, counting the samples
+// from Start to Start plus the bucket width.
+type LatencyBucket struct {
+	Start time.Duration
+	Count int
+}
+
+// Histogram returns the histogram of the samples with buckets of the given
+// width, from the bucket of the smallest sample to that of the largest.
+func (lr *LatencyRecorder) Histogram(width time.Duration) []LatencyBucket {
+	if len(lr.samples) == 0 || width <= 0 {
+		return nil
+	}
+
+	lr.sort()
+
+	bucketOf := func(d time.Duration) time.Duration {
+		b := d / width * width
+		if d < 0 && d%width != 0 {
+			b -= width
+		}
+
+		return b
+	}
+
+	first := bucketOf(lr.samples[0])
+	buckets := make([]LatencyBucket, int((bucketOf(lr.samples[len(lr.samples)-1])-first)/width)+1)
+
+	for i := range buckets {
+		buckets[i].Start = first + time.Duration(i)*width
+	}
+
+	for _, d := range lr.samples {
+		buckets[(bucketOf(d)-first)/width].Count++
+	}
+
+	return buckets
+}
+
+// WriteSummary writes the number of samples, common percentiles and the
+// histogram with buckets of the given width as text.
+func (lr *LatencyRecorder) WriteSummary(w io.Writer, width time.Duration) error {
+	if _, err := fmt.Fprintf(w, "samples: %d\n", lr.Count()); err != nil {
+		return err
+	}
+
+	if lr.Count() == 0 {
+		return nil
+	}
+
+	for _, p := range []float64{0, 50, 90, 99, 99.9, 100} {
+		if _, err := fmt.Fprintf(w, "p%-5g %v\n", p, lr.Percentile(p)); err != nil {
+			return err
+		}
+	}
+
+	buckets := lr.Histogram(width)
+
+	max := 0
+	for _, b := range buckets {
+		if b.Count > max {
+			max = b.Count
+		}
+	}
+
+	for _, b := range buckets {
+		bar := strings.Repeat("#", (b.Count*50+max-1)/max)
+
+		if _, err := fmt.Fprintf(w, "%12v %8d %s\n", b.Start, b.Count, bar); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package evdev
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestLatencyRecorder(t *testing.T) {
+	lr := NewLatencyRecorder()
+
+	for _, us := range []int{300, 100, 250, 120, 900, 150, 110, 130, 140, 2100} {
+		lr.Add(time.Duration(us) * time.Microsecond)
+	}
+
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{0, 100 * time.Microsecond},
+		{50, 140 * time.Microsecond},
+		{90, 900 * time.Microsecond},
+		{99, 2100 * time.Microsecond},
+		{100, 2100 * time.Microsecond},
+	}
+
+	for _, tt := range tests {
+		if got := lr.Percentile(tt.p); got != tt.want {
+			t.Errorf("Percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+
+	want := []LatencyBucket{
+		{0, 8},
+		{500 * time.Microsecond, 1},
+		{1000 * time.Microsecond, 0},
+		{1500 * time.Microsecond, 0},
+		{2000 * time.Microsecond, 1},
+	}
+
+	if got := lr.Histogram(500 * time.Microsecond); !reflect.DeepEqual(got, want) {
+		t.Errorf("Histogram() = %v, want %v", got, want)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := lr.WriteSummary(buf, 500*time.Microsecond); err != nil {
+		t.Fatalf("WriteSummary() error = %v", err)
+	}
+
+	if s := buf.String(); !strings.HasPrefix(s, "samples: 10\n") || !strings.Contains(s, "p50    140µs\n") {
+		t.Errorf("WriteSummary() wrote\n%s", s)
+	}
+}
+
+func TestLatencyRecorder_AddFrame(t *testing.T) {
+	now := time.Now()
+	frame := []InputEvent{{Time: syscall.NsecToTimeval(now.Add(-3 * time.Millisecond).UnixNano()), Type: EV_SYN, Code: SYN_REPORT}}
+
+	lr := NewLatencyRecorder()
+	lr.AddFrame(frame, now)
+	lr.AddFrame(nil, now)
+
+	if d := lr.Percentile(50); lr.Count() != 1 || d < 2*time.Millisecond || d > 4*time.Millisecond {
+		t.Errorf("AddFrame() recorded %d samples, latency %v", lr.Count(), d)
+	}
+}
@@ -0,0 +1,313 @@
+package evdev
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// KeyboardLayout is an XKB keyboard configuration, as detected by
+// DetectKeyboardLayout.
+type KeyboardLayout struct {
+	Model   string
+	Layout  string // e.g. "de" or "us,ru"
+	Variant string
+	Options string
+	Source  string // where the configuration was found
+}
+
+// layoutFiles are the system configuration files with XKB settings, in
+// the order they are consulted.
+var layoutFiles = []string{
+	"/etc/default/keyboard", // Debian and derivatives
+	"/etc/vconsole.conf",    // systemd
+}
+
+// localectlStatus returns the output of localectl status. Tests replace it.
+var localectlStatus = func() ([]byte, error) {
+	return exec.Command("localectl", "status").Output()
+}
+
+// DetectKeyboardLayout makes a best effort to find the active keyboard
+// layout. The XKB_DEFAULT_* environment variables take precedence, followed
+// by /etc/default/keyboard, /etc/vconsole.conf and the output of localectl.
+// If vconsole.conf only names a console keymap, such as "de-latin1", its
+// language part is used as the layout.
+func DetectKeyboardLayout() (KeyboardLayout, error) {
+	if l := os.Getenv("XKB_DEFAULT_LAYOUT"); l != "" {
+		return KeyboardLayout{
+			Model:   os.Getenv("XKB_DEFAULT_MODEL"),
+			Layout:  l,
+			Variant: os.Getenv("XKB_DEFAULT_VARIANT"),
+			Options: os.Getenv("XKB_DEFAULT_OPTIONS"),
+			Source:  "environment",
+		}, nil
+	}
+
+	for _, path := range layoutFiles {
+		vars, err := readShellVars(path)
+		if err != nil {
+			continue
+		}
+
+		l := KeyboardLayout{
+			Model:   vars["XKBMODEL"],
+			Layout:  vars["XKBLAYOUT"],
+			Variant: vars["XKBVARIANT"],
+			Options: vars["XKBOPTIONS"],
+			Source:  path,
+		}
+
+		if l.Layout == "" && vars["KEYMAP"] != "" {
+			l.Layout = strings.SplitN(vars["KEYMAP"], "-", 2)[0]
+		}
+
+		if l.Layout != "" {
+			return l, nil
+		}
+	}
+
+	if out, err := localectlStatus(); err == nil {
+		if l := parseLocalectl(out); l.Layout != "" {
+			return l, nil
+		}
+	}
+
+	return KeyboardLayout{}, errors.New("Cannot detect keyboard layout")
+}
+
+// readShellVars reads the assignments of a shell-style configuration file.
+func readShellVars(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	vars := map[string]string{}
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		i := strings.Index(line, "=")
+		if i < 0 {
+			continue
+		}
+
+		vars[strings.TrimSpace(line[:i])] = strings.Trim(strings.TrimSpace(line[i+1:]), `"'`)
+	}
+
+	return vars, scanner.Err()
+}
+
+func parseLocalectl(out []byte) KeyboardLayout {
+	l := KeyboardLayout{Source: "localectl"}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		value := strings.TrimSpace(parts[1])
+
+		switch strings.TrimSpace(parts[0]) {
+		case "X11 Model":
+			l.Model = value
+		case "X11 Layout":
+			l.Layout = value
+		case "X11 Variant":
+			l.Variant = value
+		case "X11 Options":
+			l.Options = value
+		}
+	}
+
+	return l
+}
+
+// Layouts returns the layouts of the configuration, one per XKB group, e.g.
+// "us" and "ru" for "us,ru".
+func (l KeyboardLayout) Layouts() []string {
+	layouts := []string{}
+
+	for _, name := range strings.Split(l.Layout, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			layouts = append(layouts, name)
+		}
+	}
+
+	return layouts
+}
+
+var (
+	anyAlt   = []EvCode{KEY_LEFTALT, KEY_RIGHTALT}
+	anyCtrl  = []EvCode{KEY_LEFTCTRL, KEY_RIGHTCTRL}
+	anyShift = []EvCode{KEY_LEFTSHIFT, KEY_RIGHTSHIFT}
+	anyMeta  = []EvCode{KEY_LEFTMETA, KEY_RIGHTMETA}
+)
+
+// layoutToggles are the XKB grp: options that select the next layout with a
+// key combination. Each combination lists the keys to be held together,
+// each as the alternatives that may be used for it.
+var layoutToggles = map[string][][]EvCode{
+	"grp:toggle":              {{KEY_RIGHTALT}},
+	"grp:lalt_toggle":         {{KEY_LEFTALT}},
+	"grp:caps_toggle":         {{KEY_CAPSLOCK}},
+	"grp:menu_toggle":         {{KEY_COMPOSE}},
+	"grp:lwin_toggle":         {{KEY_LEFTMETA}},
+	"grp:rwin_toggle":         {{KEY_RIGHTMETA}},
+	"grp:lctrl_toggle":        {{KEY_LEFTCTRL}},
+	"grp:rctrl_toggle":        {{KEY_RIGHTCTRL}},
+	"grp:lshift_toggle":       {{KEY_LEFTSHIFT}},
+	"grp:rshift_toggle":       {{KEY_RIGHTSHIFT}},
+	"grp:shifts_toggle":       {{KEY_LEFTSHIFT}, {KEY_RIGHTSHIFT}},
+	"grp:alts_toggle":         {{KEY_LEFTALT}, {KEY_RIGHTALT}},
+	"grp:ctrls_toggle":        {{KEY_LEFTCTRL}, {KEY_RIGHTCTRL}},
+	"grp:alt_shift_toggle":    {anyAlt, anyShift},
+	"grp:lalt_lshift_toggle":  {{KEY_LEFTALT}, {KEY_LEFTSHIFT}},
+	"grp:ctrl_shift_toggle":   {anyCtrl, anyShift},
+	"grp:lctrl_lshift_toggle": {{KEY_LEFTCTRL}, {KEY_LEFTSHIFT}},
+	"grp:ctrl_alt_toggle":     {anyCtrl, anyAlt},
+	"grp:alt_caps_toggle":     {anyAlt, {KEY_CAPSLOCK}},
+	"grp:shift_caps_toggle":   {anyShift, {KEY_CAPSLOCK}},
+	"grp:alt_space_toggle":    {anyAlt, {KEY_SPACE}},
+	"grp:win_space_toggle":    {anyMeta, {KEY_SPACE}},
+	"grp:ctrl_space_toggle":   {anyCtrl, {KEY_SPACE}},
+}
+
+// LayoutTracker follows the active layout of a keyboard configured with
+// several layouts, such as "us,ru", so remappings and reconstructed text can
+// depend on it. The next layout is selected by KEY_KBD_LAYOUT_NEXT and the
+// toggle combinations of the grp: options, such as Alt+Shift for
+// grp:alt_shift_toggle. A combination switches when its last key is
+// pressed. The tracker only sees the keyboard it is fed with, so layout
+// switches done elsewhere, e.g. with the mouse, must be applied with
+// SetGroup.
+type LayoutTracker struct {
+	layouts    []string
+	switchKeys map[EvCode]bool
+	toggles    [][][]EvCode
+	held       map[EvCode]bool
+	group      int
+}
+
+// NewLayoutTracker creates a LayoutTracker for l, starting with its first
+// layout. switchKeys are keys that select the next layout in addition to
+// KEY_KBD_LAYOUT_NEXT, e.g. KEY_SWITCHVIDEOMODE on keyboards whose language
+// key sends it.
+func NewLayoutTracker(l KeyboardLayout, switchKeys ...EvCode) *LayoutTracker {
+	lt := &LayoutTracker{
+		layouts:    l.Layouts(),
+		switchKeys: map[EvCode]bool{KEY_KBD_LAYOUT_NEXT: true},
+		held:       map[EvCode]bool{},
+	}
+
+	for _, c := range switchKeys {
+		lt.switchKeys[c] = true
+	}
+
+	for _, o := range strings.Split(l.Options, ",") {
+		if toggle, ok := layoutToggles[strings.TrimSpace(o)]; ok {
+			lt.toggles = append(lt.toggles, toggle)
+		}
+	}
+
+	return lt
+}
+
+// Group returns the index of the active layout.
+func (lt *LayoutTracker) Group() int {
+	return lt.group
+}
+
+// Layout returns the name of the active layout, or "" if the configuration
+// names no layouts.
+func (lt *LayoutTracker) Layout() string {
+	if lt.group >= len(lt.layouts) {
+		return ""
+	}
+
+	return lt.layouts[lt.group]
+}
+
+// SetGroup selects the active layout by index. Indexes out of range are
+// ignored.
+func (lt *LayoutTracker) SetGroup(group int) {
+	if group >= 0 && group < len(lt.layouts) {
+		lt.group = group
+	}
+}
+
+// Push processes an event and returns true if it selected another layout.
+// After SYN_DROPPED, keys held at that time are forgotten, while the active
+// layout is kept.
+func (lt *LayoutTracker) Push(e InputEvent) bool {
+	if e.Type == EV_SYN && e.Code == SYN_DROPPED {
+		lt.held = map[EvCode]bool{}
+		return false
+	}
+
+	if e.Type != EV_KEY {
+		return false
+	}
+
+	switch KeyState(e.Value) {
+	case KeyUp:
+		delete(lt.held, e.Code)
+		return false
+	case KeyRepeat:
+		return false
+	}
+
+	lt.held[e.Code] = true
+
+	if !lt.switchKeys[e.Code] && !lt.toggled(e.Code) {
+		return false
+	}
+
+	if len(lt.layouts) < 2 {
+		return false
+	}
+
+	lt.group = (lt.group + 1) % len(lt.layouts)
+
+	return true
+}
+
+// toggled returns true if pressing c completed one of the toggle
+// combinations.
+func (lt *LayoutTracker) toggled(c EvCode) bool {
+	for _, toggle := range lt.toggles {
+		completes := false
+		complete := true
+
+		for _, keys := range toggle {
+			if containsCode(keys, c) {
+				completes = true
+				continue
+			}
+
+			held := false
+			for _, k := range keys {
+				held = held || lt.held[k]
+			}
+
+			complete = complete && held
+		}
+
+		if completes && complete {
+			return true
+		}
+	}
+
+	return false
+}
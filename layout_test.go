@@ -0,0 +1,116 @@
+package evdev
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectKeyboardLayout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "layout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	debian := filepath.Join(dir, "keyboard")
+	vconsole := filepath.Join(dir, "vconsole.conf")
+
+	defer func(old []string) { layoutFiles = old }(layoutFiles)
+	layoutFiles = []string{debian, vconsole}
+
+	localectl := "   System Locale: LANG=en_US.UTF-8\n       VC Keymap: us\n      X11 Layout: us,ru\n       X11 Model: pc105\n     X11 Options: grp:alt_shift_toggle\n"
+
+	defer func(old func() ([]byte, error)) { localectlStatus = old }(localectlStatus)
+	localectlStatus = func() ([]byte, error) {
+		if localectl == "" {
+			return nil, errors.New("not found")
+		}
+
+		return []byte(localectl), nil
+	}
+
+	defer os.Setenv("XKB_DEFAULT_LAYOUT", os.Getenv("XKB_DEFAULT_LAYOUT"))
+	defer os.Setenv("XKB_DEFAULT_VARIANT", os.Getenv("XKB_DEFAULT_VARIANT"))
+	os.Unsetenv("XKB_DEFAULT_LAYOUT")
+	os.Unsetenv("XKB_DEFAULT_VARIANT")
+
+	detect := func(want KeyboardLayout) {
+		t.Helper()
+
+		got, err := DetectKeyboardLayout()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got != want {
+			t.Errorf("DetectKeyboardLayout() = %+v, want %+v", got, want)
+		}
+	}
+
+	detect(KeyboardLayout{Model: "pc105", Layout: "us,ru", Options: "grp:alt_shift_toggle", Source: "localectl"})
+
+	ioutil.WriteFile(vconsole, []byte("KEYMAP=de-latin1\nFONT=eurlatgr\n"), 0644)
+	detect(KeyboardLayout{Layout: "de", Source: vconsole})
+
+	ioutil.WriteFile(debian, []byte("# KEYBOARD CONFIGURATION FILE\nXKBMODEL=\"pc105\"\nXKBLAYOUT=\"fr\"\nXKBVARIANT=\"bepo\"\n"), 0644)
+	detect(KeyboardLayout{Model: "pc105", Layout: "fr", Variant: "bepo", Source: debian})
+
+	os.Setenv("XKB_DEFAULT_LAYOUT", "gb")
+	os.Setenv("XKB_DEFAULT_VARIANT", "extd")
+	detect(KeyboardLayout{Layout: "gb", Variant: "extd", Model: os.Getenv("XKB_DEFAULT_MODEL"), Options: os.Getenv("XKB_DEFAULT_OPTIONS"), Source: "environment"})
+
+	os.Unsetenv("XKB_DEFAULT_LAYOUT")
+	os.Remove(debian)
+	os.Remove(vconsole)
+	localectl = ""
+
+	if _, err := DetectKeyboardLayout(); err == nil {
+		t.Errorf("DetectKeyboardLayout() succeeded without any configuration")
+	}
+}
+
+func TestLayoutTracker(t *testing.T) {
+	key := func(c EvCode, v int32) InputEvent { return InputEvent{Type: EV_KEY, Code: c, Value: v} }
+
+	lt := NewLayoutTracker(KeyboardLayout{Layout: "us,ru,de", Options: "grp:alt_shift_toggle,compose:ralt"}, KEY_SWITCHVIDEOMODE)
+
+	tests := []struct {
+		in      InputEvent
+		changed bool
+		layout  string
+	}{
+		{in: key(KEY_LEFTSHIFT, 1), layout: "us"},
+		{in: key(KEY_A, 1), layout: "us"},
+		{in: key(KEY_A, 0), layout: "us"},
+		{in: key(KEY_RIGHTALT, 1), changed: true, layout: "ru"},
+		{in: key(KEY_RIGHTALT, 2), layout: "ru"},
+		{in: key(KEY_RIGHTALT, 0), layout: "ru"},
+		{in: key(KEY_LEFTSHIFT, 0), layout: "ru"},
+		{in: key(KEY_KBD_LAYOUT_NEXT, 1), changed: true, layout: "de"},
+		{in: key(KEY_KBD_LAYOUT_NEXT, 0), layout: "de"},
+		{in: key(KEY_SWITCHVIDEOMODE, 1), changed: true, layout: "us"},
+		{in: key(KEY_SWITCHVIDEOMODE, 0), layout: "us"},
+
+		// keys held before SYN_DROPPED are forgotten
+		{in: key(KEY_LEFTALT, 1), layout: "us"},
+		{in: InputEvent{Type: EV_SYN, Code: SYN_DROPPED}, layout: "us"},
+		{in: key(KEY_LEFTSHIFT, 1), layout: "us"},
+	}
+	for _, tt := range tests {
+		if got := lt.Push(tt.in); got != tt.changed {
+			t.Errorf("Push(%v) = %v, want %v", tt.in, got, tt.changed)
+		}
+
+		if got := lt.Layout(); got != tt.layout {
+			t.Errorf("Layout() after %v = %q, want %q", tt.in, got, tt.layout)
+		}
+	}
+
+	single := NewLayoutTracker(KeyboardLayout{Layout: "us", Options: "grp:caps_toggle"})
+	if single.Push(key(KEY_CAPSLOCK, 1)) || single.Group() != 0 {
+		t.Errorf("Push() switched away from the only layout")
+	}
+}
@@ -0,0 +1,195 @@
+package evdev
+
+import (
+	"context"
+	"time"
+)
+
+// MacroStep is a frame of a Macro, played Delay after the previous one.
+// Events doesn't include the SYN_REPORT that ends the frame.
+type MacroStep struct {
+	Delay  time.Duration `json:"delay"`
+	Events []InputEvent  `json:"events"`
+}
+
+// Macro is a timed sequence of key and pointer events.
+type Macro struct {
+	Steps []MacroStep `json:"steps"`
+}
+
+// Duration returns the time it takes to play the macro.
+func (m *Macro) Duration() time.Duration {
+	d := time.Duration(0)
+	for _, s := range m.Steps {
+		d += s.Delay
+	}
+
+	return d
+}
+
+// Play writes the steps of the macro with write, e.g. the Write method of a
+// UInputDevice, each followed by a SYN_REPORT, keeping their delays as
+// precisely as a Scheduler does. It returns ctx.Err() if ctx is done before
+// all steps were written.
+func (m *Macro) Play(ctx context.Context, write func(events ...InputEvent) error) error {
+	frames := make([]ScheduledFrame, 0, len(m.Steps))
+	at := time.Duration(0)
+
+	for _, s := range m.Steps {
+		at += s.Delay
+		frames = append(frames, ScheduledFrame{
+			At:     at,
+			Events: append(append([]InputEvent{}, s.Events...), InputEvent{Type: EV_SYN, Code: SYN_REPORT}),
+		})
+	}
+
+	_, err := NewScheduler(write).Run(ctx, frames)
+
+	return err
+}
+
+// isMacroEvent returns true for the events a Macro records: keys and
+// buttons without repeats, which the kernel generates on playback, and
+// pointer motion.
+func isMacroEvent(e *InputEvent) bool {
+	switch e.Type {
+	case EV_KEY:
+		return KeyState(e.Value) != KeyRepeat
+	case EV_REL:
+		return true
+	case EV_ABS:
+		return e.Code != ABS_MT_SLOT && !isMTAxis(e.Code)
+	}
+
+	return false
+}
+
+// RecordMacro records a Macro from the events read from src until stopKey
+// is pressed. The recording starts with the first recorded event. The stop
+// trigger is trimmed: neither stopKey nor the last presses of keys still
+// held when it is pressed, such as the modifiers of a shortcut, are
+// recorded.
+//
+// If ctx is done before stopKey is pressed, RecordMacro returns ctx.Err().
+// The read in progress is then abandoned, and its events are lost.
+func RecordMacro(ctx context.Context, src EventSource, stopKey EvCode) (*Macro, error) {
+	type result struct {
+		events []InputEvent
+		err    error
+	}
+
+	results := make(chan result, 1)
+	next := make(chan struct{}, 1)
+	defer close(next)
+
+	go func() {
+		for range next {
+			events, err := src.Read()
+			results <- result{events, err}
+
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	fa := NewFrameAssembler(FramePassThrough)
+	m := &Macro{}
+	held := map[EvCode]bool{}
+	var last time.Time
+
+	for {
+		next <- struct{}{}
+
+		var r result
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case r = <-results:
+		}
+
+		for _, e := range r.events {
+			for _, f := range fa.Push(e) {
+				step := MacroStep{}
+
+				for _, e := range f {
+					if e.Type == EV_KEY && e.Code == stopKey && e.Value == int32(KeyDown) {
+						return trimMacro(m, held), nil
+					}
+
+					if !isMacroEvent(&e) {
+						continue
+					}
+
+					if e.Type == EV_KEY {
+						held[e.Code] = e.Value != 0
+					}
+
+					step.Events = append(step.Events, e)
+				}
+
+				if len(step.Events) == 0 {
+					continue
+				}
+
+				now := f[len(f)-1].Timestamp()
+				if len(m.Steps) > 0 {
+					step.Delay = now.Sub(last)
+				}
+
+				last = now
+				m.Steps = append(m.Steps, step)
+			}
+		}
+
+		if r.err != nil {
+			return nil, r.err
+		}
+	}
+}
+
+// trimMacro removes the last presses of the keys in held from m, along with
+// steps left empty, whose delays are added to the following step.
+func trimMacro(m *Macro, held map[EvCode]bool) *Macro {
+	pending := map[EvCode]bool{}
+	for c, h := range held {
+		pending[c] = h
+	}
+
+	// the keys are still held, so their last events are their presses
+	steps := make([][]InputEvent, len(m.Steps))
+
+	for i := len(m.Steps) - 1; i >= 0; i-- {
+		events := m.Steps[i].Events
+
+		for j := len(events) - 1; j >= 0; j-- {
+			if e := events[j]; e.Type == EV_KEY && pending[e.Code] {
+				pending[e.Code] = false
+				continue
+			}
+
+			steps[i] = append([]InputEvent{events[j]}, steps[i]...)
+		}
+	}
+
+	trimmed := &Macro{}
+	delay := time.Duration(0)
+
+	for i, s := range m.Steps {
+		delay += s.Delay
+
+		if len(steps[i]) == 0 {
+			continue
+		}
+
+		if len(trimmed.Steps) == 0 {
+			delay = 0
+		}
+
+		trimmed.Steps = append(trimmed.Steps, MacroStep{Delay: delay, Events: steps[i]})
+		delay = 0
+	}
+
+	return trimmed
+}
@@ -0,0 +1,104 @@
+package evdev
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRecordMacro(t *testing.T) {
+	at := func(ms int64) syscall.Timeval { return syscall.NsecToTimeval(ms * int64(time.Millisecond)) }
+	ev := func(ms int64, typ EvType, c EvCode, v int32) InputEvent {
+		return InputEvent{Time: at(ms), Type: typ, Code: c, Value: v}
+	}
+	syn := func(ms int64) InputEvent { return ev(ms, EV_SYN, SYN_REPORT, 0) }
+
+	src := &sliceSource{batches: [][]InputEvent{
+		{ev(1000, EV_MSC, MSC_SCAN, 4), syn(1000)},
+		{ev(1000, EV_KEY, KEY_LEFTCTRL, 1), syn(1000), ev(1010, EV_KEY, KEY_C, 1), syn(1010)},
+		{ev(1050, EV_KEY, KEY_C, 0), ev(1050, EV_KEY, KEY_LEFTCTRL, 0), syn(1050)},
+		{ev(1100, EV_REL, REL_X, 5), ev(1100, EV_KEY, KEY_A, 2), syn(1100)},
+		{ev(1200, EV_KEY, KEY_LEFTCTRL, 1), syn(1200)},
+		{ev(1300, EV_KEY, KEY_LEFTSHIFT, 1), syn(1300)},
+		{ev(1400, EV_KEY, KEY_F12, 1), syn(1400)},
+		{ev(1500, EV_KEY, KEY_B, 1), syn(1500)},
+	}}
+
+	m, err := RecordMacro(context.Background(), src, KEY_F12)
+	if err != nil {
+		t.Fatalf("RecordMacro() error = %v", err)
+	}
+
+	want := &Macro{Steps: []MacroStep{
+		{Events: []InputEvent{ev(1000, EV_KEY, KEY_LEFTCTRL, 1)}},
+		{Delay: 10 * time.Millisecond, Events: []InputEvent{ev(1010, EV_KEY, KEY_C, 1)}},
+		{Delay: 40 * time.Millisecond, Events: []InputEvent{ev(1050, EV_KEY, KEY_C, 0), ev(1050, EV_KEY, KEY_LEFTCTRL, 0)}},
+		{Delay: 50 * time.Millisecond, Events: []InputEvent{ev(1100, EV_REL, REL_X, 5)}},
+	}}
+
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("RecordMacro() = %+v, want %+v", m, want)
+	}
+
+	if d := m.Duration(); d != 100*time.Millisecond {
+		t.Errorf("Duration() = %v, want 100ms", d)
+	}
+}
+
+func TestRecordMacro_Errors(t *testing.T) {
+	src := &sliceSource{batches: [][]InputEvent{{{Type: EV_KEY, Code: KEY_A, Value: 1}, {Type: EV_SYN, Code: SYN_REPORT}}}}
+
+	if _, err := RecordMacro(context.Background(), src, KEY_F12); err != io.EOF {
+		t.Errorf("RecordMacro() error = %v, want %v", err, io.EOF)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := RecordMacro(ctx, &delayedSource{delay: time.Second}, KEY_F12); err != context.Canceled {
+		t.Errorf("RecordMacro() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestMacro_Play(t *testing.T) {
+	m := &Macro{Steps: []MacroStep{
+		{Events: []InputEvent{{Type: EV_KEY, Code: KEY_A, Value: 1}}},
+		{Delay: 20 * time.Millisecond, Events: []InputEvent{{Type: EV_KEY, Code: KEY_A, Value: 0}}},
+	}}
+
+	got := []InputEvent{}
+	start := time.Now()
+
+	err := m.Play(context.Background(), func(events ...InputEvent) error {
+		got = append(got, events...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+
+	if d := time.Since(start); d < 20*time.Millisecond {
+		t.Errorf("Play() took %v, want at least 20ms", d)
+	}
+
+	want := []InputEvent{
+		{Type: EV_KEY, Code: KEY_A, Value: 1},
+		{Type: EV_SYN, Code: SYN_REPORT},
+		{Type: EV_KEY, Code: KEY_A, Value: 0},
+		{Type: EV_SYN, Code: SYN_REPORT},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Play() wrote %v, want %v", got, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := m.Play(ctx, func(...InputEvent) error { return nil }); err != context.Canceled {
+		t.Errorf("Play() error = %v, want %v", err, context.Canceled)
+	}
+}
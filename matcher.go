@@ -0,0 +1,559 @@
+package evdev
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// deviceInfo is a snapshot of the identity and capabilities of a device,
+// as far as they are needed to evaluate a Matcher.
+type deviceInfo struct {
+	name  string
+	phys  string
+	uniq  string
+	seat  string
+	id    InputID
+	codes map[EvType]map[EvCode]bool
+	props map[EvProp]bool
+}
+
+func deviceInfoOf(d *InputDevice) *deviceInfo {
+	info := &deviceInfo{
+		codes: map[EvType]map[EvCode]bool{},
+		props: map[EvProp]bool{},
+	}
+
+	info.name, _ = d.Name()
+	info.phys, _ = d.PhysicalLocation()
+	info.uniq, _ = d.UniqueID()
+	info.seat, _ = d.Seat()
+	info.id, _ = d.InputID()
+
+	for _, t := range d.CapableTypes() {
+		info.codes[t] = map[EvCode]bool{}
+
+		for _, c := range d.CapableEvents(t) {
+			info.codes[t][c] = true
+		}
+	}
+
+	for _, p := range d.Properties() {
+		info.props[p] = true
+	}
+
+	return info
+}
+
+// Matcher decides whether a device matches a set of criteria. Matchers are
+// either built with the Match* functions and combined with And, Or and Not,
+// or parsed from an expression with ParseMatcher.
+type Matcher struct {
+	expr  string
+	match func(info *deviceInfo) bool
+}
+
+// Match returns true if d matches.
+func (m *Matcher) Match(d *InputDevice) bool {
+	return m.match(deviceInfoOf(d))
+}
+
+// String returns the matcher as an expression that ParseMatcher accepts.
+func (m *Matcher) String() string {
+	return m.expr
+}
+
+func stringMatcher(field string, value func(info *deviceInfo) string, re *regexp.Regexp) *Matcher {
+	return &Matcher{
+		expr: fmt.Sprintf("%s~%s", field, strconv.Quote(re.String())),
+		match: func(info *deviceInfo) bool {
+			return re.MatchString(value(info))
+		},
+	}
+}
+
+// MatchName matches devices whose name matches re.
+func MatchName(re *regexp.Regexp) *Matcher {
+	return stringMatcher("Name", func(info *deviceInfo) string { return info.name }, re)
+}
+
+// MatchPhys matches devices whose physical location matches re.
+func MatchPhys(re *regexp.Regexp) *Matcher {
+	return stringMatcher("Phys", func(info *deviceInfo) string { return info.phys }, re)
+}
+
+// MatchUniq matches devices whose unique ID matches re.
+func MatchUniq(re *regexp.Regexp) *Matcher {
+	return stringMatcher("Uniq", func(info *deviceInfo) string { return info.uniq }, re)
+}
+
+func seatMatcher(re *regexp.Regexp) *Matcher {
+	return stringMatcher("Seat", func(info *deviceInfo) string { return info.seat }, re)
+}
+
+// MatchSeat matches devices assigned to the given seat, see Seat.
+func MatchSeat(seat string) *Matcher {
+	return seatMatcher(regexp.MustCompile("^" + regexp.QuoteMeta(seat) + "$"))
+}
+
+func idMatcher(field string, value func(id InputID) uint16, v uint16) *Matcher {
+	return &Matcher{
+		expr: fmt.Sprintf("%s==0x%04x", field, v),
+		match: func(info *deviceInfo) bool {
+			return value(info.id) == v
+		},
+	}
+}
+
+// MatchBus matches devices on the given bus type, e.g. BUS_USB.
+func MatchBus(bus uint16) *Matcher {
+	return idMatcher("Bus", func(id InputID) uint16 { return id.BusType }, bus)
+}
+
+// MatchVendor matches devices with the given vendor ID.
+func MatchVendor(vendor uint16) *Matcher {
+	return idMatcher("Vendor", func(id InputID) uint16 { return id.Vendor }, vendor)
+}
+
+// MatchProduct matches devices with the given product ID.
+func MatchProduct(product uint16) *Matcher {
+	return idMatcher("Product", func(id InputID) uint16 { return id.Product }, product)
+}
+
+// exprName returns name for use in a matcher expression, or v as a number
+// if name is not a known name.
+func exprName(name string, v uint16) string {
+	if name == "UNKNOWN" || name == "UNSUPPORTED" {
+		return fmt.Sprintf("0x%x", v)
+	}
+
+	return name
+}
+
+// MatchHas matches devices that support the EvType t and all given codes
+// within it.
+func MatchHas(t EvType, codes ...EvCode) *Matcher {
+	args := []string{exprName(TypeName(t), uint16(t))}
+	for _, c := range codes {
+		args = append(args, exprName(CodeName(t, c), uint16(c)))
+	}
+
+	return &Matcher{
+		expr: fmt.Sprintf("Has(%s)", strings.Join(args, ", ")),
+		match: func(info *deviceInfo) bool {
+			supported, ok := info.codes[t]
+			if !ok {
+				return false
+			}
+
+			for _, c := range codes {
+				if !supported[c] {
+					return false
+				}
+			}
+
+			return true
+		},
+	}
+}
+
+// MatchProp matches devices that have the property p.
+func MatchProp(p EvProp) *Matcher {
+	return &Matcher{
+		expr: fmt.Sprintf("Prop(%s)", exprName(PropName(p), uint16(p))),
+		match: func(info *deviceInfo) bool {
+			return info.props[p]
+		},
+	}
+}
+
+// MatchClass matches devices of the given class, see Classify.
+func MatchClass(class DeviceClass) *Matcher {
+	return &Matcher{
+		expr: fmt.Sprintf("Class(%s)", strconv.Quote(string(class))),
+		match: func(info *deviceInfo) bool {
+			for _, c := range classify(info) {
+				if c == class {
+					return true
+				}
+			}
+
+			return false
+		},
+	}
+}
+
+func joinMatchers(op string, ms []*Matcher) string {
+	exprs := []string{}
+	for _, m := range ms {
+		exprs = append(exprs, m.expr)
+	}
+
+	return "(" + strings.Join(exprs, " "+op+" ") + ")"
+}
+
+// And matches devices that match all of ms.
+func And(ms ...*Matcher) *Matcher {
+	return &Matcher{
+		expr: joinMatchers("&&", ms),
+		match: func(info *deviceInfo) bool {
+			for _, m := range ms {
+				if !m.match(info) {
+					return false
+				}
+			}
+
+			return true
+		},
+	}
+}
+
+// Or matches devices that match any of ms.
+func Or(ms ...*Matcher) *Matcher {
+	return &Matcher{
+		expr: joinMatchers("||", ms),
+		match: func(info *deviceInfo) bool {
+			for _, m := range ms {
+				if m.match(info) {
+					return true
+				}
+			}
+
+			return false
+		},
+	}
+}
+
+// Not matches devices that don't match m.
+func Not(m *Matcher) *Matcher {
+	return &Matcher{
+		expr: "!" + m.expr,
+		match: func(info *deviceInfo) bool {
+			return !m.match(info)
+		},
+	}
+}
+
+// ParseMatcher parses a matcher expression such as
+//
+//	Name~"Logitech.*" && Has(EV_REL, REL_WHEEL) && !Prop(INPUT_PROP_ACCELEROMETER)
+//
+// Supported terms are:
+//
+//	Name~"re", Phys~"re", Uniq~"re"  regular expression match
+//	Name=="s", Phys=="s", Uniq=="s"  exact match
+//	Seat~"re", Seat=="s"             udev seat, see Seat
+//	Bus==n, Vendor==n, Product==n    input ID match
+//	Has(EV_X[, CODE...])             supported event type and codes
+//	Prop(PROP)                       device property
+//	Class("class")                   device class, see Classify
+//
+// Terms can be combined with !, && and || and grouped with parentheses.
+func ParseMatcher(expr string) (*Matcher, error) {
+	tokens, err := tokenizeMatcher(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &matcherParser{tokens: tokens}
+
+	m, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("Unexpected %q in matcher expression", p.tokens[p.pos])
+	}
+
+	return m, nil
+}
+
+func tokenizeMatcher(expr string) ([]string, error) {
+	tokens := []string{}
+
+	for i := 0; i < len(expr); {
+		c := expr[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+
+		case strings.HasPrefix(expr[i:], "&&"), strings.HasPrefix(expr[i:], "||"), strings.HasPrefix(expr[i:], "=="):
+			tokens = append(tokens, expr[i:i+2])
+			i += 2
+
+		case strings.IndexByte("!()~,", c) >= 0:
+			tokens = append(tokens, expr[i:i+1])
+			i++
+
+		case c == '"':
+			j := i + 1
+			for ; j < len(expr) && expr[j] != '"'; j++ {
+				if expr[j] == '\\' {
+					j++
+				}
+			}
+
+			if j >= len(expr) {
+				return nil, fmt.Errorf("Unterminated string in matcher expression")
+			}
+
+			tokens = append(tokens, expr[i:j+1])
+			i = j + 1
+
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9':
+			j := i
+			for ; j < len(expr); j++ {
+				c := expr[j]
+				if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+					break
+				}
+			}
+
+			tokens = append(tokens, expr[i:j])
+			i = j
+
+		default:
+			return nil, fmt.Errorf("Unexpected character %q in matcher expression", c)
+		}
+	}
+
+	return tokens, nil
+}
+
+type matcherParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *matcherParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+
+	return ""
+}
+
+func (p *matcherParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *matcherParser) expect(t string) error {
+	if got := p.next(); got != t {
+		return fmt.Errorf("Expected %q in matcher expression, got %q", t, got)
+	}
+
+	return nil
+}
+
+func (p *matcherParser) parseOr() (*Matcher, error) {
+	m, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	ms := []*Matcher{m}
+
+	for p.peek() == "||" {
+		p.next()
+
+		m, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		ms = append(ms, m)
+	}
+
+	if len(ms) == 1 {
+		return m, nil
+	}
+
+	return Or(ms...), nil
+}
+
+func (p *matcherParser) parseAnd() (*Matcher, error) {
+	m, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	ms := []*Matcher{m}
+
+	for p.peek() == "&&" {
+		p.next()
+
+		m, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		ms = append(ms, m)
+	}
+
+	if len(ms) == 1 {
+		return m, nil
+	}
+
+	return And(ms...), nil
+}
+
+func (p *matcherParser) parseUnary() (*Matcher, error) {
+	if p.peek() == "!" {
+		p.next()
+
+		m, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return Not(m), nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *matcherParser) parsePrimary() (*Matcher, error) {
+	t := p.next()
+
+	switch t {
+	case "(":
+		m, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		return m, p.expect(")")
+
+	case "Has":
+		return p.parseHas()
+
+	case "Prop":
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+
+		name := p.next()
+		prop, ok := PropByName(name)
+		if !ok {
+			n, err := strconv.ParseUint(name, 0, 16)
+			if err != nil {
+				return nil, fmt.Errorf("Unknown property %q in matcher expression", name)
+			}
+
+			prop = EvProp(n)
+		}
+
+		return MatchProp(prop), p.expect(")")
+
+	case "Class":
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+
+		class, err := strconv.Unquote(p.next())
+		if err != nil {
+			return nil, fmt.Errorf("Invalid class in matcher expression")
+		}
+
+		return MatchClass(DeviceClass(class)), p.expect(")")
+
+	case "Name", "Phys", "Uniq", "Seat":
+		return p.parseStringField(t)
+
+	case "Bus", "Vendor", "Product":
+		if err := p.expect("=="); err != nil {
+			return nil, err
+		}
+
+		v, err := strconv.ParseUint(p.next(), 0, 16)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %s value in matcher expression: %v", t, err)
+		}
+
+		switch t {
+		case "Bus":
+			return MatchBus(uint16(v)), nil
+		case "Vendor":
+			return MatchVendor(uint16(v)), nil
+		default:
+			return MatchProduct(uint16(v)), nil
+		}
+	}
+
+	return nil, fmt.Errorf("Unexpected %q in matcher expression", t)
+}
+
+func (p *matcherParser) parseHas() (*Matcher, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	name := p.next()
+	t, ok := TypeByName(name)
+	if !ok {
+		n, err := strconv.ParseUint(name, 0, 16)
+		if err != nil {
+			return nil, fmt.Errorf("Unknown event type %q in matcher expression", name)
+		}
+
+		t = EvType(n)
+	}
+
+	codes := []EvCode{}
+
+	for p.peek() == "," {
+		p.next()
+
+		name := p.next()
+		c, ok := CodeByName(t, name)
+		if !ok {
+			n, err := strconv.ParseUint(name, 0, 16)
+			if err != nil {
+				return nil, fmt.Errorf("Unknown code %q for %s in matcher expression", name, TypeName(t))
+			}
+
+			c = EvCode(n)
+		}
+
+		codes = append(codes, c)
+	}
+
+	return MatchHas(t, codes...), p.expect(")")
+}
+
+func (p *matcherParser) parseStringField(field string) (*Matcher, error) {
+	op := p.next()
+	if op != "~" && op != "==" {
+		return nil, fmt.Errorf("Expected ~ or == after %s in matcher expression", field)
+	}
+
+	s, err := strconv.Unquote(p.next())
+	if err != nil {
+		return nil, fmt.Errorf("Invalid string after %s in matcher expression", field)
+	}
+
+	if op == "==" {
+		s = "^" + regexp.QuoteMeta(s) + "$"
+	}
+
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid regular expression in matcher expression: %v", err)
+	}
+
+	switch field {
+	case "Name":
+		return MatchName(re), nil
+	case "Phys":
+		return MatchPhys(re), nil
+	case "Seat":
+		return seatMatcher(re), nil
+	default:
+		return MatchUniq(re), nil
+	}
+}
@@ -0,0 +1,90 @@
+package evdev
+
+import "testing"
+
+func TestParseMatcher(t *testing.T) {
+	mouse := &deviceInfo{
+		name: "Logitech USB Receiver Mouse",
+		seat: "seat1",
+		id:   InputID{BusType: BUS_USB, Vendor: 0x046d},
+		codes: map[EvType]map[EvCode]bool{
+			EV_REL: {REL_X: true, REL_Y: true, REL_WHEEL: true},
+			EV_KEY: {BTN_LEFT: true},
+		},
+		props: map[EvProp]bool{},
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{expr: `Name~"Logitech.*"`, want: true},
+		{expr: `Name=="Logitech"`, want: false},
+		{expr: `Name~"Logitech.*" && Has(EV_REL, REL_WHEEL) && !Prop(INPUT_PROP_ACCELEROMETER)`, want: true},
+		{expr: `Has(EV_ABS) || Has(EV_KEY, BTN_LEFT, BTN_RIGHT)`, want: false},
+		{expr: `!(Has(EV_ABS) || Vendor==0x046d)`, want: false},
+		{expr: `Bus==3 && Product==0`, want: true},
+		{expr: `Seat=="seat1"`, want: true},
+		{expr: `Seat~"seat0"`, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			m, err := ParseMatcher(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseMatcher() error = %v", err)
+			}
+
+			if got := m.match(mouse); got != tt.want {
+				t.Errorf("match() = %v, want %v", got, tt.want)
+			}
+
+			// the string representation must parse to an equivalent matcher
+			m2, err := ParseMatcher(m.String())
+			if err != nil {
+				t.Fatalf("ParseMatcher(%q) error = %v", m.String(), err)
+			}
+
+			if got := m2.match(mouse); got != tt.want {
+				t.Errorf("match() of %q = %v, want %v", m.String(), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatcher_StringRoundTrip(t *testing.T) {
+	for _, m := range []*Matcher{
+		MatchHas(EV_MSC, MSC_SCAN),
+		MatchHas(EV_KEY, BTN_TOOL_PEN, 0x2ff),
+		MatchHas(0x1e),
+		MatchProp(0x1e),
+	} {
+		m2, err := ParseMatcher(m.String())
+		if err != nil {
+			t.Errorf("ParseMatcher(%q) error = %v", m.String(), err)
+			continue
+		}
+
+		if m2.String() != m.String() {
+			t.Errorf("ParseMatcher(%q).String() = %q", m.String(), m2.String())
+		}
+	}
+}
+
+func TestParseMatcherErrors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`Name~`,
+		`Name~"unterminated`,
+		`Has(EV_BOGUS)`,
+		`Has(EV_REL, KEY_A)`,
+		`Prop(PROP_BOGUS)`,
+		`Vendor==xyz`,
+		`(Has(EV_KEY)`,
+		`Has(EV_KEY) &&`,
+		`Has(EV_KEY) $`,
+	} {
+		if _, err := ParseMatcher(expr); err == nil {
+			t.Errorf("ParseMatcher(%q) succeeded, want error", expr)
+		}
+	}
+}
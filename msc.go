@@ -0,0 +1,47 @@
+package evdev
+
+import "time"
+
+// HardwareClock converts MSC_TIMESTAMP values, which some devices such as
+// touch controllers report with each frame, into a continuous time. The
+// values are a microsecond counter of the device's own clock that wraps
+// around, which makes it more precise than the kernel's timestamps, as these
+// include the delays of the bus and of interrupt handling.
+type HardwareClock struct {
+	last    uint32
+	elapsed time.Duration
+	started bool
+}
+
+// Update processes an MSC_TIMESTAMP value and returns the time elapsed since
+// the first value. Wrap-arounds of the counter are accounted for, as long as
+// consecutive values are less than about 71 minutes apart.
+func (hc *HardwareClock) Update(value int32) time.Duration {
+	v := uint32(value)
+
+	if hc.started {
+		hc.elapsed += time.Duration(v-hc.last) * time.Microsecond
+	}
+
+	hc.last = v
+	hc.started = true
+
+	return hc.elapsed
+}
+
+// Reset makes the next value the new starting point.
+func (hc *HardwareClock) Reset() {
+	*hc = HardwareClock{}
+}
+
+// FrameHardwareTimestamp returns the MSC_TIMESTAMP value of a frame, if it
+// has one.
+func FrameHardwareTimestamp(frame []InputEvent) (int32, bool) {
+	for _, e := range frame {
+		if e.Type == EV_MSC && e.Code == MSC_TIMESTAMP {
+			return e.Value, true
+		}
+	}
+
+	return 0, false
+}
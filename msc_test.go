@@ -0,0 +1,38 @@
+package evdev
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHardwareClock(t *testing.T) {
+	hc := HardwareClock{}
+
+	tests := []struct {
+		value int32
+		want  time.Duration
+	}{
+		{value: 1000, want: 0},
+		{value: 3000, want: 2 * time.Millisecond},
+		{value: -1000, want: (1<<32 - 2000) * time.Microsecond},
+		{value: 1000, want: (1 << 32) * time.Microsecond},
+	}
+	for _, tt := range tests {
+		if got := hc.Update(tt.value); got != tt.want {
+			t.Errorf("Update(%d) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	frame := []InputEvent{
+		{Type: EV_MSC, Code: MSC_TIMESTAMP, Value: 42},
+		{Type: EV_SYN, Code: SYN_REPORT},
+	}
+
+	if v, ok := FrameHardwareTimestamp(frame); !ok || v != 42 {
+		t.Errorf("FrameHardwareTimestamp() = %v, %v, want 42, true", v, ok)
+	}
+
+	if _, ok := FrameHardwareTimestamp(frame[1:]); ok {
+		t.Error("FrameHardwareTimestamp() of frame without timestamp succeeded")
+	}
+}
@@ -0,0 +1,636 @@
+package evdev
+
+import (
+	"sort"
+	"syscall"
+	"time"
+)
+
+// ContactEventType describes what happened to a touch contact.
+type ContactEventType int
+
+const (
+	// ContactDown is reported when a contact touches the surface.
+	ContactDown ContactEventType = iota
+	// ContactMove is reported when any axis of a contact changes.
+	ContactMove
+	// ContactUp is reported when a contact leaves the surface.
+	ContactUp
+)
+
+// Contact is the state of one touch contact.
+type Contact struct {
+	ID   int32            // tracking ID, assigned by the tracker for type-A devices without one
+	X, Y int32            // ABS_MT_POSITION_X and ABS_MT_POSITION_Y
+	Axes map[EvCode]int32 // all ABS_MT_* values of the contact
+	Time syscall.Timeval  // time of the last update
+
+	// time of the last update on the device's clock, if the device reports
+	// MSC_TIMESTAMP, see HardwareClock
+	HardwareTime    time.Duration
+	HasHardwareTime bool
+
+	// velocity in axis units per second, computed from the last two updates
+	VelocityX, VelocityY float64
+
+	// the position is only approximate, as on semi-MT devices, whose
+	// contacts are the corners of the bounding box of all fingers while more
+	// than one is on the surface
+	Approximate bool
+}
+
+// Predict returns the position of the contact extrapolated by d from its
+// last update using its velocity, e.g. to compensate for touch latency when
+// drawing. Keep d short, as the error grows quickly with it.
+func (c *Contact) Predict(d time.Duration) (float64, float64) {
+	return float64(c.X) + c.VelocityX*d.Seconds(), float64(c.Y) + c.VelocityY*d.Seconds()
+}
+
+// updateVelocity computes the velocity of c from its previous update prev,
+// preferring the hardware time if both have one.
+func (c *Contact) updateVelocity(prev *Contact) {
+	dt := float64(c.Time.Nano()-prev.Time.Nano()) / float64(time.Second)
+	if c.HasHardwareTime && prev.HasHardwareTime {
+		dt = (c.HardwareTime - prev.HardwareTime).Seconds()
+	}
+	if dt <= 0 {
+		return
+	}
+
+	c.VelocityX = float64(c.X-prev.X) / dt
+	c.VelocityY = float64(c.Y-prev.Y) / dt
+}
+
+// ContactEvent is a change of a contact, reported by an MTTracker at the end
+// of a frame.
+type ContactEvent struct {
+	Type    ContactEventType
+	Contact Contact
+}
+
+func isMTAxis(c EvCode) bool {
+	return c >= ABS_MT_TOUCH_MAJOR && c <= ABS_MT_TOOL_Y
+}
+
+// MTTracker decodes the multitouch protocol into per-contact events. Both the
+// slot based type-B protocol and the anonymous type-A protocol, in which each
+// contact is terminated by SYN_MT_REPORT, are supported and normalized into
+// the same stream. A device is treated as type A once a SYN_MT_REPORT is seen.
+//
+// Type-A contacts without ABS_MT_TRACKING_ID are matched to the contacts of
+// the previous frame by distance and get tracking IDs assigned by the tracker.
+//
+// The tracker also follows the BTN_TOOL_* events of the device, see
+// CurrentTool and FingerCount.
+//
+// For devices reporting MSC_TIMESTAMP, contacts carry the time of the device's
+// clock as well, which is used to compute velocities.
+//
+// Trackers created with NewMTTrackerFor follow the properties of the device,
+// see Direct, ButtonPad, Position, ClickButton and SemiMT.
+//
+// After SYN_DROPPED, all contacts are reported as lifted and events up to
+// the next SYN_REPORT are discarded. Type-B contacts that are still on the
+// surface are picked up again once they are lifted and touch down anew.
+type MTTracker struct {
+	typeA    bool
+	dropping bool
+	tools    *ToolTracker
+
+	props      map[EvProp]bool
+	absX, absY AbsInfo
+	semiMT     bool
+
+	// centroid of the last frame with two fingers, for ScrollDelta
+	centroidX, centroidY float64
+	hasCentroid          bool
+	scrollX, scrollY     float64
+	scrolled             bool
+
+	clock  HardwareClock
+	hwTime time.Duration // hardware time of the current frame
+	hasHW  bool          // the current frame has a hardware time
+
+	filter *deviceFilter // nil unless created for a device
+
+	// type B
+	slot  int
+	slots map[int]*mtSlot
+
+	// type A
+	pending []Contact
+	current Contact
+	active  []Contact
+	nextID  int32
+}
+
+type mtSlot struct {
+	contact      Contact
+	reported     Contact // the contact as of the last frame
+	active       bool
+	started      bool
+	ended        bool
+	changed      bool
+	endedContact Contact // the contact that ended in the current frame
+}
+
+// NewMTTracker creates an MTTracker.
+func NewMTTracker() *MTTracker {
+	return &MTTracker{
+		slots: map[int]*mtSlot{},
+		tools: NewToolTracker(),
+		props: map[EvProp]bool{},
+	}
+}
+
+// NewMTTrackerFor creates an MTTracker configured for d, see Configure. The
+// quirks and stored settings of d, see UseSettingsStore, are applied to the
+// events pushed.
+func NewMTTrackerFor(d *InputDevice) (*MTTracker, error) {
+	// applies the calibration before the axes are queried
+	filter, err := newDeviceFilter(d)
+	if err != nil {
+		return nil, err
+	}
+
+	absInfos, err := d.AbsInfos()
+	if err != nil {
+		return nil, err
+	}
+
+	t := NewMTTracker()
+	t.Configure(d.Properties(), absInfos)
+	t.filter = filter
+
+	return t, nil
+}
+
+// Configure sets the properties and axes of the device, as returned by
+// Properties and AbsInfos, which determine how contacts are interpreted.
+func (t *MTTracker) Configure(props []EvProp, absInfos map[EvCode]AbsInfo) {
+	t.props = map[EvProp]bool{}
+	for _, p := range props {
+		t.props[p] = true
+	}
+
+	t.absX = absInfos[ABS_MT_POSITION_X]
+	t.absY = absInfos[ABS_MT_POSITION_Y]
+	t.semiMT = t.props[PROP_SEMI_MT]
+}
+
+// SemiMT returns true if the tracker treats the device as semi-MT, see
+// SetSemiMT.
+func (t *MTTracker) SemiMT() bool {
+	return t.semiMT
+}
+
+// SetSemiMT sets whether the device is semi-MT (INPUT_PROP_SEMI_MT). Such
+// devices report the corners of the bounding box of the fingers instead of
+// their positions while more than one finger is on the surface, so the
+// contacts are flagged as Approximate then. Use ScrollDelta rather than the
+// movement of single contacts for two-finger gestures.
+func (t *MTTracker) SetSemiMT(semiMT bool) {
+	t.semiMT = semiMT
+}
+
+// ScrollDelta returns the movement of the center of two fingers in the last
+// frame, as used for two-finger scrolling, and false if two fingers were not
+// on the surface in both the last frame and the one before it. The center
+// of the bounding box of semi-MT devices is accurate, so this works for
+// them as well.
+func (t *MTTracker) ScrollDelta() (float64, float64, bool) {
+	return t.scrollX, t.scrollY, t.scrolled
+}
+
+// updateScroll updates the centroid of two fingers at the end of a frame.
+func (t *MTTracker) updateScroll() {
+	contacts := t.Contacts()
+
+	fingers := t.FingerCount()
+	if len(contacts) > fingers {
+		fingers = len(contacts)
+	}
+
+	t.scrollX, t.scrollY, t.scrolled = 0, 0, false
+
+	if fingers != 2 || len(contacts) != 2 {
+		t.hasCentroid = false
+		return
+	}
+
+	x := float64(contacts[0].X+contacts[1].X) / 2
+	y := float64(contacts[0].Y+contacts[1].Y) / 2
+
+	if t.hasCentroid {
+		t.scrollX, t.scrollY, t.scrolled = x-t.centroidX, y-t.centroidY, true
+	}
+
+	t.centroidX, t.centroidY, t.hasCentroid = x, y, true
+}
+
+// Direct returns true for devices whose contacts are on a screen, such as
+// touchscreens, as opposed to touchpads moving a pointer. It follows
+// INPUT_PROP_DIRECT, unless INPUT_PROP_POINTER is set as well.
+func (t *MTTracker) Direct() bool {
+	return t.props[PROP_DIRECT] && !t.props[PROP_POINTER]
+}
+
+// ButtonPad returns true for clickpads, touchpads without separate buttons
+// that are clicked by pressing down the whole surface (INPUT_PROP_BUTTONPAD).
+func (t *MTTracker) ButtonPad() bool {
+	return t.props[PROP_BUTTONPAD]
+}
+
+// Position maps the position of a contact for its use. For direct devices,
+// it is normalized to 0..1 over the axis ranges, ready to be scaled to the
+// screen the device is attached to. For other devices it is in millimetres
+// from the axis minimums if the device reports a resolution, or in axis
+// units if not.
+func (t *MTTracker) Position(c *Contact) (float64, float64) {
+	if t.Direct() {
+		return normalizeAxis(c.X, t.absX), normalizeAxis(c.Y, t.absY)
+	}
+
+	return axisMillimetres(c.X, t.absX), axisMillimetres(c.Y, t.absY)
+}
+
+func normalizeAxis(v int32, info AbsInfo) float64 {
+	if info.Maximum <= info.Minimum {
+		return float64(v)
+	}
+
+	return float64(v-info.Minimum) / float64(info.Maximum-info.Minimum)
+}
+
+func axisMillimetres(v int32, info AbsInfo) float64 {
+	if info.Resolution <= 0 {
+		return float64(v - info.Minimum)
+	}
+
+	return float64(v-info.Minimum) / float64(info.Resolution)
+}
+
+// ClickButton returns the button the BTN_LEFT click of the device stands
+// for. On clickpads, it depends on the number of fingers on the surface: one
+// finger clicks the left, two the right and three or more the middle button.
+// Other devices report their buttons separately, so it is always BTN_LEFT.
+func (t *MTTracker) ClickButton() EvCode {
+	if !t.ButtonPad() {
+		return BTN_LEFT
+	}
+
+	fingers := t.FingerCount()
+	if n := len(t.Contacts()); n > fingers {
+		fingers = n
+	}
+
+	switch {
+	case fingers >= 3:
+		return BTN_MIDDLE
+	case fingers == 2:
+		return BTN_RIGHT
+	default:
+		return BTN_LEFT
+	}
+}
+
+// CurrentTool returns the tool in proximity as reported by BTN_TOOL_* events.
+func (t *MTTracker) CurrentTool() Tool {
+	return t.tools.CurrentTool()
+}
+
+// FingerCount returns the number of fingers reported by BTN_TOOL_FINGER to
+// BTN_TOOL_QUINTTAP. It can exceed the number of contacts on devices that
+// track fewer slots than fingers they detect.
+func (t *MTTracker) FingerCount() int {
+	return t.tools.FingerCount()
+}
+
+// Contacts returns the contacts currently on the surface, ordered by slot for
+// type-B devices and by tracking ID for type-A devices.
+func (t *MTTracker) Contacts() []Contact {
+	if t.typeA {
+		return append([]Contact{}, t.active...)
+	}
+
+	contacts := []Contact{}
+
+	for _, i := range t.sortedSlots() {
+		if s := t.slots[i]; s.active {
+			contacts = append(contacts, s.contact)
+		}
+	}
+
+	return contacts
+}
+
+// Push processes an event and returns the contact changes of the frame it
+// completes, if any.
+func (t *MTTracker) Push(e InputEvent) []ContactEvent {
+	if t.filter == nil {
+		return t.push(e)
+	}
+
+	var events []ContactEvent
+	for _, fe := range t.filter.Push(e) {
+		events = append(events, t.push(fe)...)
+	}
+
+	return events
+}
+
+func (t *MTTracker) push(e InputEvent) []ContactEvent {
+	t.tools.Push(e)
+
+	if e.Type == EV_SYN && e.Code == SYN_DROPPED {
+		return t.drop(e.Time)
+	}
+
+	if t.dropping {
+		if e.Type == EV_SYN && e.Code == SYN_REPORT {
+			t.dropping = false
+		}
+
+		return nil
+	}
+
+	switch {
+	case e.Type == EV_MSC && e.Code == MSC_TIMESTAMP:
+		t.hwTime = t.clock.Update(e.Value)
+		t.hasHW = true
+
+	case e.Type == EV_SYN && e.Code == SYN_MT_REPORT:
+		if !t.typeA {
+			t.typeA = true
+			t.slots = map[int]*mtSlot{}
+		}
+
+		if len(t.current.Axes) > 0 {
+			t.pending = append(t.pending, t.current)
+		}
+
+		t.current = Contact{}
+
+	case e.Type == EV_SYN && e.Code == SYN_REPORT:
+		var events []ContactEvent
+
+		if t.typeA {
+			events = t.finishTypeA(e.Time)
+		} else {
+			t.current = Contact{}
+			events = t.finishTypeB(e.Time)
+		}
+
+		t.hasHW = false
+		t.updateScroll()
+
+		return events
+
+	case e.Type == EV_ABS && e.Code == ABS_MT_SLOT:
+		t.slot = int(e.Value)
+
+	case e.Type == EV_ABS && isMTAxis(e.Code):
+		// until the protocol is known, events are recorded for both
+		setContactAxis(&t.current, e)
+
+		if !t.typeA {
+			t.updateSlot(e)
+		}
+	}
+
+	return nil
+}
+
+func setContactAxis(c *Contact, e InputEvent) {
+	if c.Axes == nil {
+		c.Axes = map[EvCode]int32{}
+	}
+
+	c.Axes[e.Code] = e.Value
+	c.Time = e.Time
+
+	switch e.Code {
+	case ABS_MT_TRACKING_ID:
+		c.ID = e.Value
+	case ABS_MT_POSITION_X:
+		c.X = e.Value
+	case ABS_MT_POSITION_Y:
+		c.Y = e.Value
+	}
+}
+
+func (t *MTTracker) sortedSlots() []int {
+	slots := make([]int, 0, len(t.slots))
+	for i := range t.slots {
+		slots = append(slots, i)
+	}
+
+	sort.Ints(slots)
+
+	return slots
+}
+
+func (t *MTTracker) updateSlot(e InputEvent) {
+	s, ok := t.slots[t.slot]
+	if !ok {
+		s = &mtSlot{}
+		t.slots[t.slot] = s
+	}
+
+	if e.Code == ABS_MT_TRACKING_ID {
+		if s.active {
+			s.ended = true
+			s.endedContact = s.contact
+			s.active = false
+		}
+
+		if e.Value < 0 {
+			s.started = false
+			return
+		}
+
+		// axes not reported for the new contact keep their slot values
+		axes := map[EvCode]int32{}
+		for c, v := range s.contact.Axes {
+			axes[c] = v
+		}
+
+		s.contact.Axes = axes
+		s.active = true
+		s.started = true
+	}
+
+	if !s.active {
+		return
+	}
+
+	setContactAxis(&s.contact, e)
+	s.changed = true
+}
+
+// stamp sets the time of a contact updated in the current frame.
+func (t *MTTracker) stamp(c *Contact, tv syscall.Timeval) {
+	c.Time = tv
+	c.HardwareTime = t.hwTime
+	c.HasHardwareTime = t.hasHW
+}
+
+func (t *MTTracker) finishTypeB(tv syscall.Timeval) []ContactEvent {
+	events := []ContactEvent{}
+
+	active := 0
+	for _, s := range t.slots {
+		if s.active {
+			active++
+		}
+	}
+
+	for _, i := range t.sortedSlots() {
+		s := t.slots[i]
+		s.contact.Approximate = t.semiMT && active > 1
+
+		if s.ended {
+			t.stamp(&s.endedContact, tv)
+			events = append(events, ContactEvent{Type: ContactUp, Contact: s.endedContact})
+		}
+
+		if s.started || s.changed {
+			t.stamp(&s.contact, tv)
+		}
+
+		switch {
+		case s.started:
+			s.contact.VelocityX, s.contact.VelocityY = 0, 0
+			events = append(events, ContactEvent{Type: ContactDown, Contact: s.contact})
+		case s.changed && s.active:
+			s.contact.updateVelocity(&s.reported)
+			events = append(events, ContactEvent{Type: ContactMove, Contact: s.contact})
+		}
+
+		s.reported = s.contact
+
+		s.started, s.ended, s.changed = false, false, false
+	}
+
+	return events
+}
+
+func contactDistance(a, b *Contact) int64 {
+	dx := int64(a.X) - int64(b.X)
+	dy := int64(a.Y) - int64(b.Y)
+
+	return dx*dx + dy*dy
+}
+
+func sameAxes(a, b map[EvCode]int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for c, v := range a {
+		if w, ok := b[c]; !ok || w != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (t *MTTracker) finishTypeA(tv syscall.Timeval) []ContactEvent {
+	// the last contact of a frame may lack its SYN_MT_REPORT
+	if len(t.current.Axes) > 0 {
+		t.pending = append(t.pending, t.current)
+	}
+
+	frame := t.pending
+	t.pending = nil
+	t.current = Contact{}
+
+	previous := t.active
+	matched := make([]bool, len(previous))
+	events := []ContactEvent{}
+	active := []Contact{}
+
+	for _, c := range frame {
+		t.stamp(&c, tv)
+		best := -1
+
+		for i := range previous {
+			if matched[i] {
+				continue
+			}
+
+			_, hasID := c.Axes[ABS_MT_TRACKING_ID]
+			if hasID {
+				if previous[i].ID == c.ID {
+					best = i
+					break
+				}
+
+				continue
+			}
+
+			if best < 0 || contactDistance(&c, &previous[i]) < contactDistance(&c, &previous[best]) {
+				best = i
+			}
+		}
+
+		if best < 0 {
+			if _, hasID := c.Axes[ABS_MT_TRACKING_ID]; !hasID {
+				c.ID = t.nextID
+				t.nextID++
+			}
+
+			events = append(events, ContactEvent{Type: ContactDown, Contact: c})
+			active = append(active, c)
+
+			continue
+		}
+
+		matched[best] = true
+		c.ID = previous[best].ID
+		c.updateVelocity(&previous[best])
+		active = append(active, c)
+
+		if !sameAxes(c.Axes, previous[best].Axes) {
+			events = append(events, ContactEvent{Type: ContactMove, Contact: c})
+		}
+	}
+
+	for i := range previous {
+		if !matched[i] {
+			up := previous[i]
+			t.stamp(&up, tv)
+			events = append(events, ContactEvent{Type: ContactUp, Contact: up})
+		}
+	}
+
+	sort.SliceStable(active, func(i, j int) bool { return active[i].ID < active[j].ID })
+	t.active = active
+
+	return events
+}
+
+// drop reports all contacts as lifted and discards events up to the next
+// SYN_REPORT.
+func (t *MTTracker) drop(tv syscall.Timeval) []ContactEvent {
+	events := []ContactEvent{}
+
+	for _, c := range t.Contacts() {
+		c.Time = tv
+		c.HasHardwareTime = false
+		events = append(events, ContactEvent{Type: ContactUp, Contact: c})
+	}
+
+	t.dropping = true
+	t.hasHW = false
+	t.hasCentroid = false
+	t.scrolled = false
+	t.clock.Reset()
+	t.slots = map[int]*mtSlot{}
+	t.active = nil
+	t.pending = nil
+	t.current = Contact{}
+
+	return events
+}
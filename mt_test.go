@@ -0,0 +1,315 @@
+package evdev
+
+import (
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func abs(c EvCode, v int32) InputEvent {
+	return InputEvent{Type: EV_ABS, Code: c, Value: v}
+}
+
+var (
+	synReport   = InputEvent{Type: EV_SYN, Code: SYN_REPORT}
+	synMTReport = InputEvent{Type: EV_SYN, Code: SYN_MT_REPORT}
+)
+
+// contactSummary reduces contact events to type, ID and position.
+func contactSummary(events []ContactEvent) [][4]int32 {
+	s := [][4]int32{}
+
+	for _, e := range events {
+		s = append(s, [4]int32{int32(e.Type), e.Contact.ID, e.Contact.X, e.Contact.Y})
+	}
+
+	return s
+}
+
+func TestMTTracker(t *testing.T) {
+	down, move, up := int32(ContactDown), int32(ContactMove), int32(ContactUp)
+
+	tests := []struct {
+		name   string
+		events []InputEvent
+		want   [][4]int32
+	}{
+		{
+			name: "type B",
+			events: []InputEvent{
+				abs(ABS_MT_SLOT, 0), abs(ABS_MT_TRACKING_ID, 10),
+				abs(ABS_MT_POSITION_X, 1), abs(ABS_MT_POSITION_Y, 2),
+				abs(ABS_MT_SLOT, 1), abs(ABS_MT_TRACKING_ID, 11),
+				abs(ABS_MT_POSITION_X, 5), abs(ABS_MT_POSITION_Y, 6),
+				synReport,
+				abs(ABS_MT_SLOT, 0), abs(ABS_MT_POSITION_X, 3),
+				abs(ABS_MT_SLOT, 1), abs(ABS_MT_TRACKING_ID, -1),
+				synReport,
+				// slot 0 is reused by a new contact within one frame
+				abs(ABS_MT_SLOT, 0), abs(ABS_MT_TRACKING_ID, 12),
+				synReport,
+			},
+			want: [][4]int32{
+				{down, 10, 1, 2}, {down, 11, 5, 6},
+				{move, 10, 3, 2}, {up, 11, 5, 6},
+				{up, 10, 3, 2}, {down, 12, 3, 2},
+			},
+		},
+		{
+			name: "type A",
+			events: []InputEvent{
+				abs(ABS_MT_POSITION_X, 1), abs(ABS_MT_POSITION_Y, 2), synMTReport,
+				abs(ABS_MT_POSITION_X, 50), abs(ABS_MT_POSITION_Y, 60), synMTReport,
+				synReport,
+				// contacts are reported in a different order
+				abs(ABS_MT_POSITION_X, 51), abs(ABS_MT_POSITION_Y, 60), synMTReport,
+				abs(ABS_MT_POSITION_X, 1), abs(ABS_MT_POSITION_Y, 2), synMTReport,
+				synReport,
+				abs(ABS_MT_POSITION_X, 52), abs(ABS_MT_POSITION_Y, 60), synMTReport,
+				synReport,
+				synMTReport,
+				synReport,
+			},
+			want: [][4]int32{
+				{down, 0, 1, 2}, {down, 1, 50, 60},
+				{move, 1, 51, 60},
+				{move, 1, 52, 60}, {up, 0, 1, 2},
+				{up, 1, 52, 60},
+			},
+		},
+		{
+			name: "syn dropped",
+			events: []InputEvent{
+				abs(ABS_MT_SLOT, 0), abs(ABS_MT_TRACKING_ID, 10),
+				abs(ABS_MT_POSITION_X, 1), abs(ABS_MT_POSITION_Y, 2),
+				synReport,
+				{Type: EV_SYN, Code: SYN_DROPPED},
+				abs(ABS_MT_TRACKING_ID, -1),
+				synReport,
+				abs(ABS_MT_TRACKING_ID, 13),
+				synReport,
+			},
+			want: [][4]int32{
+				{down, 10, 1, 2},
+				{up, 10, 1, 2},
+				{down, 13, 0, 0},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mt := NewMTTracker()
+			events := []ContactEvent{}
+
+			for _, e := range tt.events {
+				events = append(events, mt.Push(e)...)
+			}
+
+			if got := contactSummary(events); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Push() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMTTracker_Velocity(t *testing.T) {
+	mt := NewMTTracker()
+
+	at := func(e InputEvent, usec int64) InputEvent {
+		e.Time = syscall.Timeval{Usec: usec}
+		return e
+	}
+
+	for _, e := range []InputEvent{
+		at(abs(ABS_MT_TRACKING_ID, 1), 0), at(abs(ABS_MT_POSITION_X, 100), 0), at(synReport, 0),
+	} {
+		mt.Push(e)
+	}
+
+	events := []ContactEvent{}
+	for _, e := range []InputEvent{
+		at(abs(ABS_MT_POSITION_X, 110), 100000), at(abs(ABS_MT_POSITION_Y, -20), 100000), at(synReport, 100000),
+	} {
+		events = append(events, mt.Push(e)...)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("Push() = %v, want one event", events)
+	}
+
+	c := events[0].Contact
+	if c.VelocityX != 100 || c.VelocityY != -200 {
+		t.Errorf("velocity = %v, %v, want 100, -200", c.VelocityX, c.VelocityY)
+	}
+
+	if x, y := c.Predict(50 * time.Millisecond); x != 115 || y != -30 {
+		t.Errorf("Predict() = %v, %v, want 115, -30", x, y)
+	}
+}
+
+func TestMTTracker_HardwareTime(t *testing.T) {
+	mt := NewMTTracker()
+
+	at := func(e InputEvent, usec int64) InputEvent {
+		e.Time = syscall.Timeval{Usec: usec}
+		return e
+	}
+
+	// the hardware clock wraps around between the frames
+	hw := func(usec uint32) InputEvent {
+		return InputEvent{Type: EV_MSC, Code: MSC_TIMESTAMP, Value: int32(usec)}
+	}
+
+	for _, e := range []InputEvent{
+		hw(0xffffffff - 24999), abs(ABS_MT_TRACKING_ID, 1), abs(ABS_MT_POSITION_X, 100), at(synReport, 0),
+	} {
+		mt.Push(e)
+	}
+
+	events := []ContactEvent{}
+	for _, e := range []InputEvent{
+		hw(25000), abs(ABS_MT_POSITION_X, 110), at(synReport, 100000),
+	} {
+		events = append(events, mt.Push(e)...)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("Push() = %v, want one event", events)
+	}
+
+	// the kernel timestamps are 100ms apart, the hardware ones 50ms
+	c := events[0].Contact
+	if !c.HasHardwareTime || c.HardwareTime != 50*time.Millisecond || c.VelocityX != 200 {
+		t.Errorf("hardware time = %v (%v), velocity %v, want 50ms, 200", c.HardwareTime, c.HasHardwareTime, c.VelocityX)
+	}
+}
+
+func TestMTTrackerProperties(t *testing.T) {
+	absInfos := map[EvCode]AbsInfo{
+		ABS_MT_POSITION_X: {Minimum: 0, Maximum: 1000, Resolution: 10},
+		ABS_MT_POSITION_Y: {Minimum: 100, Maximum: 600, Resolution: 10},
+	}
+	contact := &Contact{X: 500, Y: 350}
+	twoFingers := []InputEvent{
+		abs(ABS_MT_SLOT, 0), abs(ABS_MT_TRACKING_ID, 1),
+		abs(ABS_MT_SLOT, 1), abs(ABS_MT_TRACKING_ID, 2),
+		synReport,
+	}
+
+	tests := []struct {
+		name      string
+		props     []EvProp
+		direct    bool
+		x, y      float64
+		oneFinger EvCode
+		twoFinger EvCode
+	}{
+		{"touchscreen", []EvProp{PROP_DIRECT}, true, 0.5, 0.5, BTN_LEFT, BTN_LEFT},
+		{"touchpad", []EvProp{PROP_POINTER}, false, 50, 25, BTN_LEFT, BTN_LEFT},
+		{"clickpad", []EvProp{PROP_POINTER, PROP_BUTTONPAD}, false, 50, 25, BTN_LEFT, BTN_RIGHT},
+		{"tablet", []EvProp{PROP_POINTER, PROP_DIRECT}, false, 50, 25, BTN_LEFT, BTN_LEFT},
+	}
+
+	for _, tt := range tests {
+		mt := NewMTTracker()
+		mt.Configure(tt.props, absInfos)
+
+		if mt.Direct() != tt.direct {
+			t.Errorf("%s: Direct() = %v, want %v", tt.name, mt.Direct(), tt.direct)
+		}
+
+		if x, y := mt.Position(contact); x != tt.x || y != tt.y {
+			t.Errorf("%s: Position() = %v, %v, want %v, %v", tt.name, x, y, tt.x, tt.y)
+		}
+
+		mt.Push(abs(ABS_MT_TRACKING_ID, 1))
+		mt.Push(synReport)
+
+		if b := mt.ClickButton(); b != tt.oneFinger {
+			t.Errorf("%s: ClickButton() with one finger = %v, want %v", tt.name, b, tt.oneFinger)
+		}
+
+		for _, e := range twoFingers {
+			mt.Push(e)
+		}
+
+		if b := mt.ClickButton(); b != tt.twoFinger {
+			t.Errorf("%s: ClickButton() with two fingers = %v, want %v", tt.name, b, tt.twoFinger)
+		}
+	}
+}
+
+func TestMTTrackerSemiMT(t *testing.T) {
+	mt := NewMTTracker()
+	mt.Configure([]EvProp{PROP_POINTER, PROP_SEMI_MT}, nil)
+
+	if !mt.SemiMT() {
+		t.Fatalf("SemiMT() = false, want true")
+	}
+
+	push := func(events ...InputEvent) []ContactEvent {
+		var out []ContactEvent
+		for _, e := range events {
+			out = append(out, mt.Push(e)...)
+		}
+
+		return out
+	}
+
+	approximate := func(events []ContactEvent) []bool {
+		a := []bool{}
+		for _, e := range events {
+			a = append(a, e.Contact.Approximate)
+		}
+
+		return a
+	}
+
+	events := push(
+		abs(ABS_MT_SLOT, 0), abs(ABS_MT_TRACKING_ID, 1),
+		abs(ABS_MT_POSITION_X, 100), abs(ABS_MT_POSITION_Y, 100),
+		synReport,
+	)
+	if a := approximate(events); !reflect.DeepEqual(a, []bool{false}) {
+		t.Errorf("Approximate with one finger = %v, want [false]", a)
+	}
+
+	if _, _, ok := mt.ScrollDelta(); ok {
+		t.Errorf("ScrollDelta() with one finger succeeded")
+	}
+
+	// the second finger turns the contacts into the corners of the
+	// bounding box
+	events = push(
+		abs(ABS_MT_SLOT, 1), abs(ABS_MT_TRACKING_ID, 2),
+		abs(ABS_MT_POSITION_X, 300), abs(ABS_MT_POSITION_Y, 200),
+		synReport,
+	)
+	if a := approximate(events); !reflect.DeepEqual(a, []bool{true}) {
+		t.Errorf("Approximate with two fingers = %v, want [true]", a)
+	}
+
+	if _, _, ok := mt.ScrollDelta(); ok {
+		t.Errorf("ScrollDelta() on the first frame with two fingers succeeded")
+	}
+
+	// both fingers move down by 20
+	push(
+		abs(ABS_MT_SLOT, 0), abs(ABS_MT_POSITION_Y, 120),
+		abs(ABS_MT_SLOT, 1), abs(ABS_MT_POSITION_Y, 220),
+		synReport,
+	)
+	if dx, dy, ok := mt.ScrollDelta(); !ok || dx != 0 || dy != 20 {
+		t.Errorf("ScrollDelta() = %v, %v, %v, want 0, 20, true", dx, dy, ok)
+	}
+
+	push(abs(ABS_MT_SLOT, 1), abs(ABS_MT_TRACKING_ID, -1), synReport)
+	if _, _, ok := mt.ScrollDelta(); ok {
+		t.Errorf("ScrollDelta() after lifting a finger succeeded")
+	}
+
+	if c := mt.Contacts(); len(c) != 1 || c[0].Approximate {
+		t.Errorf("Contacts() after lifting a finger = %+v, want one exact contact", c)
+	}
+}
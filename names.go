@@ -1,5 +1,7 @@
 package evdev
 
+import "strings"
+
 // TypeName returns the name of an EvType as string, or "UNKNOWN" if the type is not valid
 func TypeName(t EvType) string {
 	name, ok := EVName[t]
@@ -20,36 +22,98 @@ func PropName(p EvProp) string {
 	return "UNKNOWN"
 }
 
+// codeNameMaps returns the name maps for the codes of the given EvType
+func codeNameMaps(t EvType) []map[EvCode]string {
+	switch t {
+	case EV_ABS:
+		return []map[EvCode]string{ABSName}
+	case EV_SYN:
+		return []map[EvCode]string{SYNName}
+	case EV_KEY:
+		return []map[EvCode]string{KEYName, BTNName}
+	case EV_SW:
+		return []map[EvCode]string{SWName}
+	case EV_LED:
+		return []map[EvCode]string{LEDName}
+	case EV_SND:
+		return []map[EvCode]string{SNDName}
+	case EV_REL:
+		return []map[EvCode]string{RELName}
+	default:
+		return nil
+	}
+}
+
 // CodeName returns the name of an EvfCode in the given EvType, or "UNKNOWN" of the code is not valid.
 func CodeName(t EvType, c EvCode) string {
-	var name string
-	var ok bool
+	maps := codeNameMaps(t)
+	if maps == nil {
+		return "UNSUPPORTED"
+	}
 
+	for _, m := range maps {
+		name, ok := m[c]
+		if ok {
+			return name
+		}
+	}
+
+	return "UNKNOWN"
+}
+
+// codeByNameMaps returns the maps from names to codes of the given EvType
+func codeByNameMaps(t EvType) []map[string]EvCode {
 	switch t {
 	case EV_ABS:
-		name, ok = ABSName[c]
+		return []map[string]EvCode{ABSByName}
 	case EV_SYN:
-		name, ok = SYNName[c]
+		return []map[string]EvCode{SYNByName}
 	case EV_KEY:
-		name, ok = KEYName[c]
-		if !ok {
-			name, ok = BTNName[c]
-		}
+		return []map[string]EvCode{KEYByName, BTNByName}
 	case EV_SW:
-		name, ok = SWName[c]
+		return []map[string]EvCode{SWByName}
 	case EV_LED:
-		name, ok = LEDName[c]
+		return []map[string]EvCode{LEDByName}
 	case EV_SND:
-		name, ok = SNDName[c]
+		return []map[string]EvCode{SNDByName}
 	case EV_REL:
-		name, ok = RELName[c]
+		return []map[string]EvCode{RELByName}
 	default:
-		return "UNSUPPORTED"
+		return nil
 	}
+}
 
-	if ok {
-		return name
+// TypeByName returns the EvType with the given name, e.g. "EV_KEY"
+func TypeByName(name string) (EvType, bool) {
+	t, ok := EVByName[name]
+	return t, ok
+}
+
+// PropByName returns the EvProp with the given name, e.g. "PROP_DIRECT".
+// The "INPUT_" prefix used by the kernel headers is accepted as well.
+func PropByName(name string) (EvProp, bool) {
+	p, ok := PROPByName[strings.TrimPrefix(name, "INPUT_")]
+	return p, ok
+}
+
+// CodeByName returns the EvCode with the given name within the given EvType,
+// e.g. "REL_WHEEL" for EV_REL
+func CodeByName(t EvType, name string) (EvCode, bool) {
+	for _, m := range codeByNameMaps(t) {
+		c, ok := m[name]
+		if ok {
+			return c, true
+		}
 	}
 
-	return "UNKNOWN"
+	return 0, false
 }
+
+// Names generated by earlier versions, which stripped the PROP_ prefix of
+// property names as a set of characters rather than as a prefix.
+const (
+	// Deprecated: Use PROP_POINTER instead.
+	PROP_INTER = PROP_POINTER
+	// Deprecated: Use PROP_POINTING_STICK instead.
+	PROP_INTING_STICK = PROP_POINTING_STICK
+)
@@ -0,0 +1,25 @@
+package evdev
+
+import "testing"
+
+func TestCodeName(t *testing.T) {
+	tests := []struct {
+		t    EvType
+		c    EvCode
+		want string
+	}{
+		{t: EV_KEY, c: KEY_A, want: "KEY_A"},
+		{t: EV_KEY, c: 0x100, want: "BTN_0"},
+		{t: EV_KEY, c: 0x110, want: "BTN_LEFT"},
+		{t: EV_KEY, c: 0x140, want: "BTN_TOOL_PEN"},
+		{t: EV_KEY, c: 0x150, want: "BTN_GEAR_DOWN"},
+		{t: EV_KEY, c: 0x2c0, want: "BTN_TRIGGER_HAPPY1"},
+		{t: EV_KEY, c: 0x2ff, want: "UNKNOWN"},
+		{t: EV_MSC, c: MSC_SCAN, want: "UNSUPPORTED"},
+	}
+	for _, tt := range tests {
+		if got := CodeName(tt.t, tt.c); got != tt.want {
+			t.Errorf("CodeName(%v, %#x) = %v, want %v", tt.t, tt.c, got, tt.want)
+		}
+	}
+}
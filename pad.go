@@ -0,0 +1,151 @@
+package evdev
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// PadEventType describes what happened on a tablet pad.
+type PadEventType int
+
+const (
+	// PadButton is reported when a button is pressed or released.
+	PadButton PadEventType = iota
+	// PadRing is reported when the finger moves on a touch ring.
+	PadRing
+	// PadStrip is reported when the finger moves on a touch strip.
+	PadStrip
+)
+
+// PadEvent is a change on a tablet pad, reported by a PadTracker at the end
+// of a frame.
+type PadEvent struct {
+	Type    PadEventType
+	Code    EvCode // the button, or the axis of the ring or strip
+	Pressed bool   // for buttons
+	Value   int32  // position of the ring or strip
+	Delta   int32  // movement on the ring since the previous position, 0 when the finger just touched
+}
+
+// padRings are the axes reporting touch rings, the others reporting
+// touch strips.
+var padRings = map[EvCode]bool{
+	ABS_WHEEL:    true,
+	ABS_THROTTLE: true,
+}
+
+var padStrips = map[EvCode]bool{
+	ABS_RX: true,
+	ABS_RY: true,
+}
+
+// PadTracker decodes the events of the pad device of a graphics tablet, the
+// companion device with the tablet's ExpressKeys, touch rings and touch
+// strips. Rings report their absolute position, which wraps around, so the
+// tracker computes the movement along the shorter way. Rings and strips
+// report 0 when the finger is lifted, which ends a movement.
+type PadTracker struct {
+	ranges  map[EvCode]int32 // number of positions of each ring
+	values  map[EvCode]int32
+	pending []PadEvent
+}
+
+// NewPadTracker creates a PadTracker for a pad with the given axes, as
+// returned by AbsInfos.
+func NewPadTracker(absInfos map[EvCode]AbsInfo) *PadTracker {
+	t := &PadTracker{
+		ranges: map[EvCode]int32{},
+		values: map[EvCode]int32{},
+	}
+
+	for c, info := range absInfos {
+		if padRings[c] {
+			t.ranges[c] = info.Maximum - info.Minimum + 1
+		}
+	}
+
+	return t
+}
+
+// Push processes an event and returns the pad events of the frame it
+// completes, if any.
+func (t *PadTracker) Push(e InputEvent) []PadEvent {
+	switch {
+	case e.Type == EV_SYN && e.Code == SYN_REPORT:
+		events := t.pending
+		t.pending = nil
+
+		return events
+
+	case e.Type == EV_SYN && e.Code == SYN_DROPPED:
+		t.pending = nil
+		t.values = map[EvCode]int32{}
+
+	case e.Type == EV_KEY:
+		_, isTool := toolCodes[e.Code]
+		_, isFinger := fingerToolCodes[e.Code]
+
+		// pads report the presence of the pad with a tool code
+		if isTool || isFinger || e.Code == BTN_STYLUS || KeyState(e.Value) == KeyRepeat {
+			break
+		}
+
+		t.pending = append(t.pending, PadEvent{Type: PadButton, Code: e.Code, Pressed: e.Value != 0})
+
+	case e.Type == EV_ABS && padRings[e.Code]:
+		delta := int32(0)
+
+		if prev := t.values[e.Code]; prev != 0 && e.Value != 0 {
+			delta = e.Value - prev
+
+			// take the shorter way around the ring
+			if n := t.ranges[e.Code]; n > 0 {
+				switch {
+				case delta > n/2:
+					delta -= n
+				case delta < -n/2:
+					delta += n
+				}
+			}
+		}
+
+		t.values[e.Code] = e.Value
+		t.pending = append(t.pending, PadEvent{Type: PadRing, Code: e.Code, Value: e.Value, Delta: delta})
+
+	case e.Type == EV_ABS && padStrips[e.Code]:
+		t.pending = append(t.pending, PadEvent{Type: PadStrip, Code: e.Code, Value: e.Value})
+	}
+
+	return nil
+}
+
+// SameTablet returns true if two devices, such as the pen and the pad
+// device of a graphics tablet, belong to the same hardware. For USB devices
+// this is the USB device, otherwise the device that created the input
+// devices, such as the HID device.
+func SameTablet(a, b *InputDevice) (bool, error) {
+	pa, err := a.SysfsPath()
+	if err != nil {
+		return false, err
+	}
+
+	pb, err := b.SysfsPath()
+	if err != nil {
+		return false, err
+	}
+
+	return hardwareOf(pa) == hardwareOf(pb), nil
+}
+
+// hardwareOf returns the sysfs directory of the hardware an input device
+// belongs to.
+func hardwareOf(path string) string {
+	for _, p := range sysfsParents(path) {
+		if _, err := os.Stat(filepath.Join(p, "idVendor")); err == nil {
+			return p
+		}
+	}
+
+	// the parent of the input directory
+	return filepath.Dir(filepath.Dir(path))
+}
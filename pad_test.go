@@ -0,0 +1,116 @@
+package evdev
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPadTracker(t *testing.T) {
+	abs := func(c EvCode, v int32) InputEvent { return InputEvent{Type: EV_ABS, Code: c, Value: v} }
+	key := func(c EvCode, v int32) InputEvent { return InputEvent{Type: EV_KEY, Code: c, Value: v} }
+	syn := InputEvent{Type: EV_SYN, Code: SYN_REPORT}
+
+	tests := []struct {
+		name   string
+		events []InputEvent
+		want   []PadEvent
+	}{
+		{
+			name:   "button",
+			events: []InputEvent{key(BTN_TOOL_PEN, 1), key(BTN_0, 1), syn, key(BTN_0, 2), syn, key(BTN_0, 0), syn},
+			want: []PadEvent{
+				{Type: PadButton, Code: BTN_0, Pressed: true},
+				{Type: PadButton, Code: BTN_0},
+			},
+		},
+		{
+			name:   "ring",
+			events: []InputEvent{abs(ABS_WHEEL, 10), syn, abs(ABS_WHEEL, 14), syn, abs(ABS_WHEEL, 11), syn, abs(ABS_WHEEL, 0), syn},
+			want: []PadEvent{
+				{Type: PadRing, Code: ABS_WHEEL, Value: 10},
+				{Type: PadRing, Code: ABS_WHEEL, Value: 14, Delta: 4},
+				{Type: PadRing, Code: ABS_WHEEL, Value: 11, Delta: -3},
+				{Type: PadRing, Code: ABS_WHEEL},
+			},
+		},
+		{
+			name:   "ring wraps around",
+			events: []InputEvent{abs(ABS_WHEEL, 70), syn, abs(ABS_WHEEL, 2), syn, abs(ABS_WHEEL, 71), syn},
+			want: []PadEvent{
+				{Type: PadRing, Code: ABS_WHEEL, Value: 70},
+				{Type: PadRing, Code: ABS_WHEEL, Value: 2, Delta: 4},
+				{Type: PadRing, Code: ABS_WHEEL, Value: 71, Delta: -3},
+			},
+		},
+		{
+			name:   "strip",
+			events: []InputEvent{abs(ABS_RX, 512), abs(ABS_RY, 8), syn},
+			want: []PadEvent{
+				{Type: PadStrip, Code: ABS_RX, Value: 512},
+				{Type: PadStrip, Code: ABS_RY, Value: 8},
+			},
+		},
+		{
+			name:   "dropped",
+			events: []InputEvent{abs(ABS_WHEEL, 10), syn, abs(ABS_WHEEL, 12), {Type: EV_SYN, Code: SYN_DROPPED}, abs(ABS_WHEEL, 20), syn},
+			want: []PadEvent{
+				{Type: PadRing, Code: ABS_WHEEL, Value: 10},
+				{Type: PadRing, Code: ABS_WHEEL, Value: 20},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tracker := NewPadTracker(map[EvCode]AbsInfo{ABS_WHEEL: {Minimum: 0, Maximum: 71}})
+		got := []PadEvent{}
+
+		for _, e := range tt.events {
+			got = append(got, tracker.Push(e)...)
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHardwareOf(t *testing.T) {
+	root, err := ioutil.TempDir("", "sysfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	defer func(old string) { sysfsRoot = old }(sysfsRoot)
+	sysfsRoot = root
+
+	usb := filepath.Join(root, "devices/pci0000:00/usb1/1-2")
+	if err := os.MkdirAll(usb, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(usb, "idVendor"), []byte("056a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pen := usb + "/1-2:1.0/0003:056A:0357.0001/input/input10"
+	pad := usb + "/1-2:1.0/0003:056A:0357.0001/input/input11"
+	i2c := filepath.Join(root, "devices/platform/i2c-0/0018:056A:5146.0002/input/input12")
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{pen, usb},
+		{pad, usb},
+		{i2c, filepath.Join(root, "devices/platform/i2c-0/0018:056A:5146.0002")},
+	}
+
+	for _, tt := range tests {
+		if got := hardwareOf(tt.path); got != tt.want {
+			t.Errorf("hardwareOf(%v) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package evdev
+
+import (
+	"fmt"
+	"sync"
+)
+
+// PanicSwitch is a safety facility for programs that grab devices. It
+// watches the events read from its devices for an emergency key
+// combination, e.g. both shift keys plus escape. Once all keys of the
+// combination are held down, it releases the grabs of all its devices and
+// runs the registered panic handlers, so a misbehaving program can't lock
+// the user out of their input devices.
+type PanicSwitch struct {
+	mu       sync.Mutex
+	combo    []EvCode
+	held     map[EvCode]bool
+	devices  []*InputDevice
+	handlers []func()
+	fired    bool
+}
+
+// NewPanicSwitch creates a PanicSwitch that triggers when all EV_KEY codes in
+// combo are held down at the same time.
+func NewPanicSwitch(combo ...EvCode) *PanicSwitch {
+	return &PanicSwitch{
+		combo: combo,
+		held:  map[EvCode]bool{},
+	}
+}
+
+// Watch makes d check every event read from it against the combination and
+// ungrabs d when the switch triggers. It is safe to call Watch while another
+// goroutine reads from d. A device can only be watched by one PanicSwitch.
+func (p *PanicSwitch) Watch(d *InputDevice) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.panicSwitch == p {
+		return nil
+	}
+
+	if d.panicSwitch != nil {
+		return fmt.Errorf("Device is already watched by another panic switch")
+	}
+
+	p.mu.Lock()
+	p.devices = append(p.devices, d)
+	p.mu.Unlock()
+
+	d.panicSwitch = p
+
+	return nil
+}
+
+// OnPanic registers a function to run when the switch triggers, e.g. to
+// destroy virtual devices or exit the program. Handlers run after all
+// devices have been ungrabbed.
+func (p *PanicSwitch) OnPanic(f func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.handlers = append(p.handlers, f)
+}
+
+// Process checks e against the combination and triggers the switch if it is
+// complete. It returns true if the switch was triggered by this event.
+// Events read from watched devices are processed automatically.
+func (p *PanicSwitch) Process(e *InputEvent) bool {
+	if e.Type != EV_KEY || len(p.combo) == 0 {
+		return false
+	}
+
+	p.mu.Lock()
+
+	p.held[e.Code] = e.Value != 0
+
+	complete := true
+	for _, c := range p.combo {
+		if !p.held[c] {
+			complete = false
+			break
+		}
+	}
+
+	// trigger only once per press of the combination
+	if !complete {
+		p.fired = false
+		p.mu.Unlock()
+		return false
+	}
+
+	if p.fired {
+		p.mu.Unlock()
+		return false
+	}
+
+	p.fired = true
+	devices := append([]*InputDevice{}, p.devices...)
+	handlers := append([]func(){}, p.handlers...)
+
+	p.mu.Unlock()
+
+	for _, d := range devices {
+		d.Ungrab()
+	}
+
+	for _, f := range handlers {
+		f()
+	}
+
+	return true
+}
@@ -0,0 +1,52 @@
+package evdev
+
+import "testing"
+
+func TestPanicSwitch(t *testing.T) {
+	p := NewPanicSwitch(KEY_LEFTSHIFT, KEY_RIGHTSHIFT, KEY_ESC)
+
+	fired := 0
+	p.OnPanic(func() { fired++ })
+
+	events := []InputEvent{
+		{Type: EV_KEY, Code: KEY_LEFTSHIFT, Value: 1},
+		{Type: EV_KEY, Code: KEY_ESC, Value: 1},
+		{Type: EV_KEY, Code: KEY_RIGHTSHIFT, Value: 1},
+		{Type: EV_KEY, Code: KEY_ESC, Value: 2},
+		{Type: EV_KEY, Code: KEY_ESC, Value: 0},
+		{Type: EV_KEY, Code: KEY_ESC, Value: 1},
+	}
+
+	want := []bool{false, false, true, false, false, true}
+
+	for i := range events {
+		if got := p.Process(&events[i]); got != want[i] {
+			t.Errorf("Process(%d) = %v, want %v", i, got, want[i])
+		}
+	}
+
+	if fired != 2 {
+		t.Errorf("handlers ran %d times, want 2", fired)
+	}
+}
+
+func TestPanicSwitch_Watch(t *testing.T) {
+	d := &InputDevice{}
+	p := NewPanicSwitch(KEY_ESC)
+
+	if err := p.Watch(d); err != nil {
+		t.Errorf("Watch() error = %v", err)
+	}
+
+	if err := p.Watch(d); err != nil {
+		t.Errorf("Watch() again error = %v", err)
+	}
+
+	if len(p.devices) != 1 {
+		t.Errorf("device watched %d times, want once", len(p.devices))
+	}
+
+	if err := NewPanicSwitch(KEY_ESC).Watch(d); err == nil {
+		t.Errorf("Watch() by a second switch succeeded, want error")
+	}
+}
@@ -0,0 +1,495 @@
+package evdev
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// CapabilityMapper is implemented by transforms that change which events a
+// stream can contain, e.g. by turning buttons into axes, so a Pipeline can
+// check that its sinks accept what the transforms produce. Transforms that
+// don't implement it are assumed to pass the capabilities on unchanged.
+type CapabilityMapper interface {
+	MapCapabilities(in Capabilities) Capabilities
+}
+
+// MapCapabilities implements CapabilityMapper for chains.
+func (c chain) MapCapabilities(in Capabilities) Capabilities {
+	for _, t := range c {
+		in = mapCapabilities(t, in)
+	}
+
+	return in
+}
+
+func mapCapabilities(t Transform, in Capabilities) Capabilities {
+	if m, ok := t.(CapabilityMapper); ok {
+		return m.MapCapabilities(in)
+	}
+
+	return in
+}
+
+// copyCapabilities returns a copy of c whose code lists can be modified.
+func copyCapabilities(c Capabilities) Capabilities {
+	out := Capabilities{
+		Codes: map[EvType][]EvCode{},
+		Props: append([]EvProp{}, c.Props...),
+	}
+
+	for t, codes := range c.Codes {
+		out.Codes[t] = append([]EvCode{}, codes...)
+	}
+
+	return out
+}
+
+// withCodes returns c with the given codes of type t added.
+func withCodes(c Capabilities, t EvType, codes ...EvCode) Capabilities {
+	c = copyCapabilities(c)
+
+	for _, code := range codes {
+		if !containsCode(c.Codes[t], code) {
+			c.Codes[t] = append(c.Codes[t], code)
+		}
+	}
+
+	sortCodes(c.Codes[t])
+
+	return c
+}
+
+// withoutCodes returns c with the given codes of type t removed.
+func withoutCodes(c Capabilities, t EvType, codes ...EvCode) Capabilities {
+	c = copyCapabilities(c)
+
+	kept := []EvCode{}
+	for _, code := range c.Codes[t] {
+		if !containsCode(codes, code) {
+			kept = append(kept, code)
+		}
+	}
+
+	if len(kept) == 0 {
+		delete(c.Codes, t)
+	} else {
+		c.Codes[t] = kept
+	}
+
+	return c
+}
+
+func containsCode(codes []EvCode, c EvCode) bool {
+	for _, code := range codes {
+		if code == c {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PipelineError lists all problems found when building a Pipeline.
+type PipelineError struct {
+	Problems []string
+}
+
+func (e *PipelineError) Error() string {
+	return "Invalid pipeline: " + strings.Join(e.Problems, "; ")
+}
+
+type pipelineStage struct {
+	name      string
+	stage     interface{}
+	transform Transform
+	sink      Sink
+	accepts   *Capabilities
+	opts      SinkOptions
+}
+
+// PipelineBuilder assembles a Pipeline from a source, transforms applied in
+// order and sinks, each a named stage.
+type PipelineBuilder struct {
+	source       *pipelineStage
+	sourceCaps   Capabilities
+	transforms   []*pipelineStage
+	sinks        []*pipelineStage
+	keepHeldKeys bool
+}
+
+// NewPipelineBuilder creates a PipelineBuilder.
+func NewPipelineBuilder() *PipelineBuilder {
+	return &PipelineBuilder{}
+}
+
+// Source sets the source of the pipeline and the capabilities of the
+// events it produces.
+func (b *PipelineBuilder) Source(name string, src EventSource, caps Capabilities) *PipelineBuilder {
+	b.source = &pipelineStage{name: name, stage: src}
+	b.sourceCaps = caps
+
+	return b
+}
+
+// Device sets a device as the source of the pipeline.
+func (b *PipelineBuilder) Device(name string, d *InputDevice) *PipelineBuilder {
+	return b.Source(name, d, d.Capabilities())
+}
+
+// Transform appends a transform to the pipeline.
+func (b *PipelineBuilder) Transform(name string, t Transform) *PipelineBuilder {
+	b.transforms = append(b.transforms, &pipelineStage{name: name, stage: t, transform: t})
+	return b
+}
+
+// Sink adds a sink that accepts any event.
+func (b *PipelineBuilder) Sink(name string, s Sink, opts SinkOptions) *PipelineBuilder {
+	b.sinks = append(b.sinks, &pipelineStage{name: name, stage: s, sink: s, opts: opts})
+	return b
+}
+
+// SinkAccepting adds a sink that only accepts the event types and codes of
+// accepts, e.g. a virtual device with those capabilities. Building fails if
+// the stages before it can produce other events. Sinks with an allow-list
+// in opts are only checked for the events the list lets through.
+func (b *PipelineBuilder) SinkAccepting(name string, s Sink, accepts Capabilities, opts SinkOptions) *PipelineBuilder {
+	b.sinks = append(b.sinks, &pipelineStage{name: name, stage: s, sink: s, accepts: &accepts, opts: opts})
+	return b
+}
+
+// KeepHeldKeys opts out of releasing the keys held on the sinks when the
+// pipeline ends, e.g. for sinks that record the stream as it was.
+func (b *PipelineBuilder) KeepHeldKeys() *PipelineBuilder {
+	b.keepHeldKeys = true
+	return b
+}
+
+// Build validates the stages and creates the pipeline. It returns a
+// *PipelineError listing all problems found: a missing source or sinks,
+// missing or duplicate names and sinks that don't accept all events the
+// stages before them can produce.
+func (b *PipelineBuilder) Build() (*Pipeline, error) {
+	problems := []string{}
+	names := map[string]bool{}
+
+	stages := append([]*pipelineStage{}, b.transforms...)
+	if b.source != nil {
+		stages = append([]*pipelineStage{b.source}, stages...)
+	} else {
+		problems = append(problems, "no source")
+	}
+
+	if len(b.sinks) == 0 {
+		problems = append(problems, "no sinks")
+	}
+
+	for _, s := range append(stages, b.sinks...) {
+		switch {
+		case s.name == "":
+			problems = append(problems, "stage without name")
+		case names[s.name]:
+			problems = append(problems, fmt.Sprintf("duplicate stage %q", s.name))
+		}
+
+		names[s.name] = true
+	}
+
+	caps := b.sourceCaps
+	producer := ""
+	if b.source != nil {
+		producer = b.source.name
+	}
+
+	transforms := make([]Transform, 0, len(b.transforms))
+	for _, s := range b.transforms {
+		caps = mapCapabilities(s.transform, caps)
+		producer = s.name
+		transforms = append(transforms, s.transform)
+	}
+
+	for _, s := range b.sinks {
+		if s.accepts == nil {
+			continue
+		}
+
+		produced := withoutCodes(caps, EV_SYN)
+		if len(s.opts.Allow) > 0 {
+			// the produced events that are allowed
+			notAllowed := capabilitiesMinus(produced, Capabilities{Codes: allowedCodes(produced, s.opts.Allow)})
+			produced = capabilitiesMinus(produced, notAllowed)
+		}
+
+		extra := capabilitiesMinus(produced, *s.accepts)
+		for t, codes := range extra.Codes {
+			problems = append(problems, fmt.Sprintf("sink %q cannot accept %s from %q", s.name, describeCodes(t, codes), producer))
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, &PipelineError{Problems: problems}
+	}
+
+	return &Pipeline{
+		source:       b.source,
+		transforms:   b.transforms,
+		sinks:        b.sinks,
+		caps:         caps,
+		chain:        Chain(transforms...),
+		keepHeldKeys: b.keepHeldKeys,
+	}, nil
+}
+
+// allowedCodes expands an allow-list of SinkOptions for the codes of caps.
+func allowedCodes(caps Capabilities, allow map[EvType][]EvCode) map[EvType][]EvCode {
+	codes := map[EvType][]EvCode{}
+
+	for t, allowed := range allow {
+		if len(allowed) == 0 {
+			codes[t] = caps.Codes[t]
+		} else {
+			codes[t] = allowed
+		}
+	}
+
+	return codes
+}
+
+// describeCodes names the codes of a type for error messages, abbreviating
+// long lists.
+func describeCodes(t EvType, codes []EvCode) string {
+	names := []string{}
+
+	for i, c := range sortCodes(append([]EvCode{}, codes...)) {
+		if i == 3 {
+			names = append(names, fmt.Sprintf("and %d more", len(codes)-i))
+			break
+		}
+
+		names = append(names, CodeName(t, c))
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// Pipeline passes the events of a source through transforms to sinks, as
+// built by a PipelineBuilder. Each sink has its own queue and overflow
+// policy, set by its SinkOptions, so a slow sink can't stall reading the
+// source unless it uses OverflowBlock. OverflowDropFrames keeps the frames
+// a sink receives intact. Stages implementing Start() error are started
+// in order before the source is read.
+//
+// A pipeline shuts down in an order that leaves no key stuck, whether it was
+// stopped or its source failed, e.g. because the device was unplugged while
+// keys were held: the source is no longer read, the frames in flight are
+// passed on and delivered, the keys still held on each sink are released
+// with synthesized key-up events (see PipelineBuilder.KeepHeldKeys), the
+// source is ungrabbed if it implements Ungrab() error, and finally the
+// stages implementing Close, with or without an error, are closed in
+// reverse order, which destroys virtual devices such as UInputDevice.Sink.
+type Pipeline struct {
+	source     *pipelineStage
+	transforms []*pipelineStage
+	sinks      []*pipelineStage
+	caps       Capabilities
+	chain      Transform
+
+	keepHeldKeys bool
+
+	mu           sync.Mutex
+	hubSinks     map[string]*HubSink
+	stopped      bool
+	sourceClosed bool // Stop closed the source
+}
+
+// heldKeySink tracks the keys held on a sink, so they can be released when
+// the pipeline ends.
+type heldKeySink struct {
+	Sink
+	held StateMap
+}
+
+func (hk *heldKeySink) Deliver(e InputEvent) error {
+	if err := hk.Sink.Deliver(e); err != nil {
+		return err
+	}
+
+	if e.Type == EV_KEY {
+		hk.held[e.Code] = e.Value != int32(KeyUp)
+	}
+
+	return nil
+}
+
+// release delivers key-up events for all held keys.
+func (hk *heldKeySink) release() error {
+	held := hk.held.Active()
+	if len(held) == 0 {
+		return nil
+	}
+
+	for _, c := range held {
+		if err := hk.Sink.Deliver(InputEvent{Type: EV_KEY, Code: c, Value: int32(KeyUp)}); err != nil {
+			return err
+		}
+
+		hk.held[c] = false
+	}
+
+	return hk.Sink.Deliver(InputEvent{Type: EV_SYN, Code: SYN_REPORT})
+}
+
+// Capabilities returns the capabilities of the events the transforms
+// produce.
+func (p *Pipeline) Capabilities() Capabilities {
+	return p.caps
+}
+
+// Stages returns the names of all stages, in the order the events pass
+// them.
+func (p *Pipeline) Stages() []string {
+	names := []string{p.source.name}
+	for _, s := range append(append([]*pipelineStage{}, p.transforms...), p.sinks...) {
+		names = append(names, s.name)
+	}
+
+	return names
+}
+
+// Sink returns the HubSink of the named sink while the pipeline runs, e.g.
+// to query the number of dropped events, or nil.
+func (p *Pipeline) Sink(name string) *HubSink {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.hubSinks[name]
+}
+
+// Stats returns the queue metrics of the sinks by name while the pipeline
+// runs, so sinks that fall behind can be spotted.
+func (p *Pipeline) Stats() map[string]SinkStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := map[string]SinkStats{}
+	for name, hs := range p.hubSinks {
+		stats[name] = hs.Stats()
+	}
+
+	return stats
+}
+
+func (p *Pipeline) stages() []*pipelineStage {
+	stages := append([]*pipelineStage{p.source}, p.transforms...)
+	return append(stages, p.sinks...)
+}
+
+// Run starts the stages and distributes the events of the source until
+// reading it fails, Stop is called or a sink fails that was added with
+// SinkOptions.Required or was the last one. All stages are closed before Run
+// returns the error that ended it, or nil if it was stopped.
+func (p *Pipeline) Run() error {
+	stages := p.stages()
+	started := 0
+
+	defer func() {
+		p.mu.Lock()
+		sourceClosed := p.sourceClosed
+		p.mu.Unlock()
+
+		for i := started - 1; i >= 0; i-- {
+			if i > 0 || !sourceClosed {
+				closeStage(stages[i].stage)
+			}
+		}
+	}()
+
+	for _, s := range stages {
+		if starter, ok := s.stage.(interface{ Start() error }); ok {
+			if err := starter.Start(); err != nil {
+				return fmt.Errorf("Cannot start %q: %v", s.name, err)
+			}
+		}
+
+		started++
+	}
+
+	hub := NewHub(NewTransformSource(p.source.stage.(EventSource), p.chain))
+	hub.interrupt = p.interruptSource
+
+	p.mu.Lock()
+	if p.stopped {
+		p.mu.Unlock()
+		return nil
+	}
+
+	p.hubSinks = map[string]*HubSink{}
+	tracked := map[string]*heldKeySink{}
+
+	for _, s := range p.sinks {
+		tracked[s.name] = &heldKeySink{Sink: s.sink, held: StateMap{}}
+		p.hubSinks[s.name] = hub.AddSink(tracked[s.name], s.opts)
+	}
+	p.mu.Unlock()
+
+	// Run returns once the sinks delivered all queued events
+	err := hub.Run()
+
+	for name, hk := range tracked {
+		if !p.keepHeldKeys && p.Sink(name).Err() == nil {
+			hk.release()
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if ungrabber, ok := p.source.stage.(interface{ Ungrab() error }); ok && !p.sourceClosed {
+		ungrabber.Ungrab()
+	}
+
+	if p.stopped {
+		return nil
+	}
+
+	return err
+}
+
+// Stop makes a running pipeline shut down and Run return nil. An
+// InputDevice source is no longer read without being released, so its
+// grab is kept until the held keys were released. Other sources are closed,
+// and must implement Close for Stop to interrupt a pending read.
+func (p *Pipeline) Stop() {
+	p.mu.Lock()
+	p.stopped = true
+	p.mu.Unlock()
+
+	p.interruptSource()
+}
+
+// interruptSource makes a pending read of the source return, as described
+// for Stop.
+func (p *Pipeline) interruptSource() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if d, ok := p.source.stage.(*InputDevice); ok {
+		d.interruptRead()
+		return
+	}
+
+	if !p.sourceClosed {
+		p.sourceClosed = true
+		closeStage(p.source.stage)
+	}
+}
+
+// closeStage closes a stage if it implements Close.
+func closeStage(stage interface{}) {
+	switch c := stage.(type) {
+	case interface{ Close() error }:
+		c.Close()
+	case interface{ Close() }:
+		c.Close()
+	}
+}
@@ -0,0 +1,372 @@
+package evdev
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// lifecycleStage records when it is started and closed.
+type lifecycleStage struct {
+	name string
+	log  *[]string
+}
+
+func (ls *lifecycleStage) Start() error {
+	*ls.log = append(*ls.log, "start "+ls.name)
+	return nil
+}
+
+func (ls *lifecycleStage) Close() error {
+	*ls.log = append(*ls.log, "close "+ls.name)
+	return nil
+}
+
+type lifecycleSource struct {
+	lifecycleStage
+	sliceSource
+}
+
+func (ls *lifecycleSource) Ungrab() error {
+	*ls.log = append(*ls.log, "ungrab "+ls.name)
+	return nil
+}
+
+type lifecycleTransform struct {
+	lifecycleStage
+	Transform
+}
+
+// blockingSource blocks reading until it is closed.
+type blockingSource struct {
+	closed chan struct{}
+}
+
+func (bs *blockingSource) Read() ([]InputEvent, error) {
+	<-bs.closed
+	return nil, errors.New("closed")
+}
+
+func (bs *blockingSource) Close() {
+	close(bs.closed)
+}
+
+func TestPipelineValidation(t *testing.T) {
+	rel := Capabilities{Codes: map[EvType][]EvCode{EV_SYN: {SYN_REPORT}, EV_REL: {REL_X, REL_Y}}}
+	keys := Capabilities{Codes: map[EvType][]EvCode{EV_SYN: {SYN_REPORT}, EV_KEY: {BTN_DPAD_LEFT, BTN_DPAD_RIGHT, BTN_SOUTH}}}
+	sink := ChannelSink(make(chan InputEvent))
+	dpad := NewButtonsToAxis(ButtonAxis{Negative: BTN_DPAD_LEFT, Positive: BTN_DPAD_RIGHT, Axis: ABS_HAT0X, Min: -1, Max: 1})
+
+	tests := []struct {
+		name     string
+		build    func(b *PipelineBuilder)
+		problems []string
+	}{
+		{
+			name:     "empty",
+			build:    func(b *PipelineBuilder) {},
+			problems: []string{"no source", "no sinks"},
+		},
+		{
+			name: "duplicate names",
+			build: func(b *PipelineBuilder) {
+				b.Source("in", &sliceSource{}, rel).Transform("in", Chain()).Sink("", sink, SinkOptions{})
+			},
+			problems: []string{`duplicate stage "in"`, "stage without name"},
+		},
+		{
+			name: "compatible",
+			build: func(b *PipelineBuilder) {
+				b.Source("in", &sliceSource{}, rel).SinkAccepting("out", sink, rel, SinkOptions{})
+			},
+		},
+		{
+			name: "converted",
+			build: func(b *PipelineBuilder) {
+				b.Source("pad", &sliceSource{}, keys).Transform("dpad", dpad).SinkAccepting("mouse", sink, rel, SinkOptions{})
+			},
+			problems: []string{
+				`sink "mouse" cannot accept BTN_SOUTH from "dpad"`,
+				`sink "mouse" cannot accept ABS_HAT0X from "dpad"`,
+			},
+		},
+		{
+			name: "allow-list",
+			build: func(b *PipelineBuilder) {
+				b.Source("pad", &sliceSource{}, keys).Transform("dpad", dpad).
+					SinkAccepting("hat", sink, Capabilities{Codes: map[EvType][]EvCode{EV_ABS: {ABS_HAT0X}}}, SinkOptions{Allow: map[EvType][]EvCode{EV_ABS: nil}})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		b := NewPipelineBuilder()
+		tt.build(b)
+
+		_, err := b.Build()
+
+		problems := []string{}
+		if pe, ok := err.(*PipelineError); ok {
+			problems = pe.Problems
+		} else if err != nil {
+			t.Fatalf("%s: Build() = %v, want a *PipelineError", tt.name, err)
+		}
+
+		want := append([]string{}, tt.problems...)
+		sort.Strings(problems)
+		sort.Strings(want)
+
+		if strings.Join(problems, "\n") != strings.Join(want, "\n") {
+			t.Errorf("%s: Build() problems = %q, want %q", tt.name, problems, want)
+		}
+	}
+}
+
+func TestPipelineRun(t *testing.T) {
+	log := []string{}
+	key := func(c EvCode, v int32) InputEvent { return InputEvent{Type: EV_KEY, Code: c, Value: v} }
+	events := []InputEvent{
+		{Type: EV_REL, Code: REL_X, Value: 1}, key(KEY_A, 1), key(KEY_B, 1), synReport,
+		key(KEY_B, 0), synReport,
+	}
+
+	src := &lifecycleSource{lifecycleStage{"source", &log}, sliceSource{batches: [][]InputEvent{events}}}
+	double := &lifecycleTransform{lifecycleStage{"double", &log}, TransformFunc(func(frame []InputEvent) []InputEvent {
+		frame[0].Value *= 2
+		return frame
+	})}
+
+	ch := make(chan InputEvent, 16)
+
+	p, err := NewPipelineBuilder().
+		Source("source", src, Capabilities{}).
+		Transform("double", double).
+		Sink("channel", ChannelSink(ch), SinkOptions{}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	if s := p.Stages(); !reflect.DeepEqual(s, []string{"source", "double", "channel"}) {
+		t.Errorf("Stages() = %v", s)
+	}
+
+	if err := p.Run(); err != io.EOF {
+		t.Errorf("Run() = %v, want io.EOF", err)
+	}
+
+	close(ch)
+
+	got := []InputEvent{}
+	for e := range ch {
+		got = append(got, e)
+	}
+
+	// KEY_A is still held when the source ends and gets released
+	want := []InputEvent{
+		{Type: EV_REL, Code: REL_X, Value: 2}, key(KEY_A, 1), key(KEY_B, 1), synReport,
+		key(KEY_B, 0), synReport,
+		key(KEY_A, 0), synReport,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("delivered %v, want %v", got, want)
+	}
+
+	if want := []string{"start source", "start double", "ungrab source", "close double", "close source"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("lifecycle = %v, want %v", log, want)
+	}
+}
+
+func TestPipelineStop(t *testing.T) {
+	p, err := NewPipelineBuilder().
+		Source("source", &blockingSource{closed: make(chan struct{})}, Capabilities{}).
+		Sink("discard", SinkFunc(func(InputEvent) error { return nil }), SinkOptions{}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	done := make(chan error)
+	go func() { done <- p.Run() }()
+
+	p.Stop()
+
+	if err := <-done; err != nil {
+		t.Errorf("Run() after Stop() = %v, want nil", err)
+	}
+}
+
+func TestPipelineStopDevice(t *testing.T) {
+	d, w := pipeDevice(t)
+	defer w.Close()
+
+	// Device issues ioctls, which must leave the read interruptible
+	p, err := NewPipelineBuilder().
+		Device("device", d).
+		Sink("discard", SinkFunc(func(InputEvent) error { return nil }), SinkOptions{}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	done := make(chan error)
+	go func() { done <- p.Run() }()
+
+	time.Sleep(10 * time.Millisecond)
+	p.Stop()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run() after Stop() = %v, want nil", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Stop() did not interrupt reading the device")
+	}
+}
+
+// frameSource reports one frame and then blocks reading until it is
+// closed.
+type frameSource struct {
+	*blockingSource
+	frame []InputEvent
+}
+
+func (fs *frameSource) Read() ([]InputEvent, error) {
+	if fs.frame != nil {
+		frame := fs.frame
+		fs.frame = nil
+
+		return frame, nil
+	}
+
+	return fs.blockingSource.Read()
+}
+
+func TestPipelineSinkFailure(t *testing.T) {
+	src := &frameSource{blockingSource: &blockingSource{closed: make(chan struct{})}, frame: []InputEvent{{Type: EV_KEY, Code: KEY_A, Value: 1}, synReport}}
+
+	p, err := NewPipelineBuilder().
+		Source("source", src, Capabilities{}).
+		Sink("output", SinkFunc(func(InputEvent) error { return io.ErrClosedPipe }), SinkOptions{Required: true}).
+		Sink("monitor", SinkFunc(func(InputEvent) error { return nil }), SinkOptions{}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	done := make(chan error)
+	go func() { done <- p.Run() }()
+
+	select {
+	case err := <-done:
+		if err != io.ErrClosedPipe {
+			t.Errorf("Run() = %v, want %v", err, io.ErrClosedPipe)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run() did not return after the required sink failed")
+	}
+}
+
+func TestPipelineTicks(t *testing.T) {
+	turbo, err := NewTransform("turbo", "BTN_SOUTH 50Hz")
+	if err != nil {
+		t.Fatalf("NewTransform() error = %v", err)
+	}
+
+	now := syscall.NsecToTimeval(time.Now().UnixNano())
+	src := &frameSource{
+		blockingSource: &blockingSource{closed: make(chan struct{})},
+		frame:          []InputEvent{{Time: now, Type: EV_KEY, Code: BTN_SOUTH, Value: 1}, {Time: now, Type: EV_SYN, Code: SYN_REPORT}},
+	}
+
+	ch := make(chan InputEvent, 64)
+	p, err := NewPipelineBuilder().
+		Source("source", src, Capabilities{}).
+		Transform("turbo", Chain(turbo)).
+		Sink("output", ChannelSink(ch), SinkOptions{}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	done := make(chan error)
+	go func() { done <- p.Run() }()
+
+	// the button pulses while the source has no further events
+	for _, want := range []int32{1, 0, 1} {
+		for {
+			e := receive(t, ch, 1)[0]
+			if e.Type == EV_KEY {
+				if e.Code != BTN_SOUTH || e.Value != want {
+					t.Fatalf("got %v, want BTN_SOUTH %d", e, want)
+				}
+
+				break
+			}
+		}
+	}
+
+	p.Stop()
+	<-done
+}
+
+// goneSource reports its events and then that the device is gone.
+type goneSource struct {
+	events []InputEvent
+}
+
+func (gs *goneSource) Read() ([]InputEvent, error) {
+	if gs.events != nil {
+		events := gs.events
+		gs.events = nil
+
+		return events, nil
+	}
+
+	return nil, &DeviceError{Kind: DeviceGone, Err: syscall.ENODEV}
+}
+
+func TestPipelineDeviceGone(t *testing.T) {
+	for _, keep := range []bool{false, true} {
+		ch := make(chan InputEvent, 16)
+		src := &goneSource{events: []InputEvent{{Type: EV_KEY, Code: KEY_LEFTSHIFT, Value: 1}, synReport}}
+
+		b := NewPipelineBuilder().
+			Source("keyboard", src, Capabilities{}).
+			Sink("output", ChannelSink(ch), SinkOptions{})
+		if keep {
+			b.KeepHeldKeys()
+		}
+
+		p, err := b.Build()
+		if err != nil {
+			t.Fatalf("Build() failed: %v", err)
+		}
+
+		if de, ok := p.Run().(*DeviceError); !ok || de.Kind != DeviceGone {
+			t.Errorf("Run() = %v, want a DeviceGone error", de)
+		}
+
+		close(ch)
+
+		got := []InputEvent{}
+		for e := range ch {
+			got = append(got, e)
+		}
+
+		want := []InputEvent{{Type: EV_KEY, Code: KEY_LEFTSHIFT, Value: 1}, synReport}
+		if !keep {
+			want = append(want, InputEvent{Type: EV_KEY, Code: KEY_LEFTSHIFT, Value: 0}, synReport)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("KeepHeldKeys %v: delivered %v, want %v", keep, got, want)
+		}
+	}
+}
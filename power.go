@@ -0,0 +1,216 @@
+package evdev
+
+import (
+	"sort"
+	"time"
+)
+
+// powerKeys are the EV_KEY codes of power management buttons.
+var powerKeys = map[EvCode]bool{
+	KEY_POWER:   true,
+	KEY_POWER2:  true,
+	KEY_SLEEP:   true,
+	KEY_SUSPEND: true,
+	KEY_WAKEUP:  true,
+}
+
+// IsPowerEvent returns true for events of power management buttons, i.e.
+// KEY_POWER, KEY_POWER2, KEY_SLEEP, KEY_SUSPEND and KEY_WAKEUP, and for
+// EV_PWR events.
+func IsPowerEvent(e *InputEvent) bool {
+	return e.Type == EV_PWR || e.Type == EV_KEY && powerKeys[e.Code]
+}
+
+// PowerEvent is a press of a power management button reported by a
+// PowerWatcher.
+type PowerEvent struct {
+	Type EvType // EV_KEY or EV_PWR
+	Code EvCode
+	Time time.Time     // time of the press
+	Held time.Duration // time the button was held, at least LongPress for long presses
+	Long bool          // held for at least LongPress, e.g. to force a power off
+}
+
+type powerButton struct {
+	pressed   bool
+	pressedAt time.Time
+	lastEdge  time.Time // time of the last accepted press or release
+	long      bool      // the long press has been reported already
+}
+
+type powerKey struct {
+	t EvType
+	c EvCode
+}
+
+// PowerWatcher decodes the presses of power management buttons, see
+// IsPowerEvent, for power management daemons. Presses and releases that
+// follow the previous one within the debounce time are ignored as contact
+// bounce. Presses are reported when the button is released, or with Long
+// set as soon as it has been held for the long press time, in which case
+// the release is not reported.
+type PowerWatcher struct {
+	debounce  time.Duration
+	longPress time.Duration
+	buttons   map[powerKey]*powerButton
+}
+
+// NewPowerWatcher creates a PowerWatcher. A longPress of 0 disables long
+// press detection.
+func NewPowerWatcher(debounce, longPress time.Duration) *PowerWatcher {
+	return &PowerWatcher{
+		debounce:  debounce,
+		longPress: longPress,
+		buttons:   map[powerKey]*powerButton{},
+	}
+}
+
+// Push processes an event and returns the press it completes, if any.
+func (w *PowerWatcher) Push(e InputEvent) (PowerEvent, bool) {
+	if e.Type == EV_SYN && e.Code == SYN_DROPPED {
+		// the releases may have been lost
+		w.buttons = map[powerKey]*powerButton{}
+		return PowerEvent{}, false
+	}
+
+	if !IsPowerEvent(&e) || KeyState(e.Value) == KeyRepeat {
+		return PowerEvent{}, false
+	}
+
+	k := powerKey{e.Type, e.Code}
+	b, ok := w.buttons[k]
+	if !ok {
+		b = &powerButton{}
+		w.buttons[k] = b
+	}
+
+	t := e.Timestamp()
+	pressed := e.Value != 0
+
+	if pressed == b.pressed || !b.lastEdge.IsZero() && t.Sub(b.lastEdge) < w.debounce {
+		return PowerEvent{}, false
+	}
+
+	b.pressed = pressed
+	b.lastEdge = t
+
+	if pressed {
+		b.pressedAt = t
+		b.long = false
+
+		return PowerEvent{}, false
+	}
+
+	if b.long {
+		return PowerEvent{}, false
+	}
+
+	held := t.Sub(b.pressedAt)
+
+	return PowerEvent{
+		Type: e.Type,
+		Code: e.Code,
+		Time: b.pressedAt,
+		Held: held,
+		Long: w.longPress > 0 && held >= w.longPress,
+	}, true
+}
+
+// Tick returns the long presses of buttons that have been held until now,
+// each only once. It returns when Tick should be called next, or the zero
+// time if no button is held.
+func (w *PowerWatcher) Tick(now time.Time) ([]PowerEvent, time.Time) {
+	events := []PowerEvent{}
+	var next time.Time
+
+	if w.longPress <= 0 {
+		return events, next
+	}
+
+	for k, b := range w.buttons {
+		if !b.pressed || b.long {
+			continue
+		}
+
+		deadline := b.pressedAt.Add(w.longPress)
+
+		if now.Before(deadline) {
+			if next.IsZero() || deadline.Before(next) {
+				next = deadline
+			}
+
+			continue
+		}
+
+		b.long = true
+		events = append(events, PowerEvent{
+			Type: k.t,
+			Code: k.c,
+			Time: b.pressedAt,
+			Held: now.Sub(b.pressedAt),
+			Long: true,
+		})
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+
+	return events, next
+}
+
+// Watch reads events from src and calls handle for each press, reporting
+// long presses while the button is still held. It returns the error that
+// ended reading.
+func (w *PowerWatcher) Watch(src EventSource, handle func(PowerEvent)) error {
+	type result struct {
+		events []InputEvent
+		err    error
+	}
+
+	results := make(chan result)
+	next := make(chan struct{})
+
+	go func() {
+		for range next {
+			events, err := src.Read()
+			results <- result{events, err}
+
+			if err != nil {
+				return
+			}
+		}
+	}()
+	defer close(next)
+
+	next <- struct{}{}
+
+	var timer <-chan time.Time
+
+	for {
+		select {
+		case r := <-results:
+			for _, e := range r.events {
+				if pe, ok := w.Push(e); ok {
+					handle(pe)
+				}
+			}
+
+			if r.err != nil {
+				return r.err
+			}
+
+			next <- struct{}{}
+
+		case <-timer:
+		}
+
+		events, at := w.Tick(time.Now())
+		for _, pe := range events {
+			handle(pe)
+		}
+
+		timer = nil
+		if !at.IsZero() {
+			timer = time.After(time.Until(at))
+		}
+	}
+}
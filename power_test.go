@@ -0,0 +1,113 @@
+package evdev
+
+import (
+	"io"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestPowerWatcher_Push(t *testing.T) {
+	w := NewPowerWatcher(20*time.Millisecond, time.Second)
+
+	events := []InputEvent{
+		// a short press with contact bounce
+		keyAt(0, KEY_POWER, 1),
+		keyAt(5, KEY_POWER, 0),
+		keyAt(8, KEY_POWER, 1),
+		keyAt(300, KEY_POWER, 0),
+		// not a power button
+		keyAt(400, KEY_A, 1),
+		// a long press of the sleep button, with autorepeat
+		keyAt(1000, KEY_SLEEP, 1),
+		keyAt(1500, KEY_SLEEP, 2),
+		keyAt(2500, KEY_SLEEP, 0),
+		// lost by SYN_DROPPED
+		keyAt(3000, KEY_WAKEUP, 1),
+		{Type: EV_SYN, Code: SYN_DROPPED},
+		keyAt(3100, KEY_WAKEUP, 0),
+		// EV_PWR
+		{Time: syscall.NsecToTimeval(4e9), Type: EV_PWR, Code: 0, Value: 1},
+		{Time: syscall.NsecToTimeval(4.1e9), Type: EV_PWR, Code: 0, Value: 0},
+	}
+
+	got := []PowerEvent{}
+	for _, e := range events {
+		if pe, ok := w.Push(e); ok {
+			got = append(got, pe)
+		}
+	}
+
+	want := []PowerEvent{
+		{Type: EV_KEY, Code: KEY_POWER, Time: time.Unix(0, 0), Held: 300 * time.Millisecond},
+		{Type: EV_KEY, Code: KEY_SLEEP, Time: time.Unix(1, 0), Held: 1500 * time.Millisecond, Long: true},
+		{Type: EV_PWR, Code: 0, Time: time.Unix(4, 0), Held: 100 * time.Millisecond},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("presses = %+v, want %+v", got, want)
+	}
+}
+
+func TestPowerWatcher_Tick(t *testing.T) {
+	w := NewPowerWatcher(0, time.Second)
+
+	w.Push(keyAt(1000, KEY_POWER, 1))
+
+	if events, next := w.Tick(time.Unix(1, 5e8)); len(events) != 0 || !next.Equal(time.Unix(2, 0)) {
+		t.Errorf("Tick() before the long press = %v, %v", events, next)
+	}
+
+	events, next := w.Tick(time.Unix(2, 0))
+	want := []PowerEvent{{Type: EV_KEY, Code: KEY_POWER, Time: time.Unix(1, 0), Held: time.Second, Long: true}}
+	if !reflect.DeepEqual(events, want) || !next.IsZero() {
+		t.Errorf("Tick() = %+v, %v, want %+v", events, next, want)
+	}
+
+	// reported only once, and not again on release
+	if events, _ := w.Tick(time.Unix(3, 0)); len(events) != 0 {
+		t.Errorf("Tick() reported the long press again: %v", events)
+	}
+
+	if pe, ok := w.Push(keyAt(4000, KEY_POWER, 0)); ok {
+		t.Errorf("release reported after a long press: %+v", pe)
+	}
+}
+
+// delayedSource returns a batch of events, then waits before failing.
+type delayedSource struct {
+	events []InputEvent
+	delay  time.Duration
+}
+
+func (s *delayedSource) Read() ([]InputEvent, error) {
+	if s.events != nil {
+		events := s.events
+		s.events = nil
+		return events, nil
+	}
+
+	time.Sleep(s.delay)
+
+	return nil, io.EOF
+}
+
+func TestPowerWatcher_Watch(t *testing.T) {
+	now := syscall.NsecToTimeval(time.Now().UnixNano())
+	src := &delayedSource{
+		events: []InputEvent{{Time: now, Type: EV_KEY, Code: KEY_POWER, Value: 1}},
+		delay:  200 * time.Millisecond,
+	}
+
+	got := []PowerEvent{}
+	err := NewPowerWatcher(0, 50*time.Millisecond).Watch(src, func(pe PowerEvent) {
+		got = append(got, pe)
+	})
+	if err != io.EOF {
+		t.Errorf("Watch() error = %v, want %v", err, io.EOF)
+	}
+
+	if len(got) != 1 || !got[0].Long || got[0].Code != KEY_POWER {
+		t.Errorf("presses = %+v, want a long press of KEY_POWER", got)
+	}
+}
@@ -0,0 +1,106 @@
+package evdev
+
+// GamepadPreset selects the capabilities of a virtual gamepad.
+type GamepadPreset int
+
+const (
+	// GamepadXbox resembles an Xbox 360 controller as driven by xpad.
+	GamepadXbox GamepadPreset = iota
+	// GamepadDS4 resembles a DualShock 4 controller as driven by hid-sony.
+	GamepadDS4
+	// GamepadGeneric is a simple gamepad with two axes and four buttons.
+	GamepadGeneric
+)
+
+// Gamepad adds the buttons and axes of a common controller to the device,
+// so emulators and games recognize it. Unless set before, the input ID of
+// the original controller is used as well. It returns b.
+func (b *UInputBuilder) Gamepad(preset GamepadPreset) *UInputBuilder {
+	stick := AbsInfo{Minimum: -32768, Maximum: 32767, Fuzz: 16, Flat: 128}
+	hat := AbsInfo{Minimum: -1, Maximum: 1}
+
+	var id InputID
+
+	switch preset {
+	case GamepadXbox:
+		id = InputID{BusType: BUS_USB, Vendor: 0x045e, Product: 0x028e, Version: 0x0110}
+		trigger := AbsInfo{Maximum: 255}
+
+		b.WithCodes(EV_KEY, BTN_SOUTH, BTN_EAST, BTN_NORTH, BTN_WEST, BTN_TL, BTN_TR,
+			BTN_SELECT, BTN_START, BTN_MODE, BTN_THUMBL, BTN_THUMBR)
+		b.WithAbs(ABS_X, stick).WithAbs(ABS_Y, stick).WithAbs(ABS_RX, stick).WithAbs(ABS_RY, stick)
+		b.WithAbs(ABS_Z, trigger).WithAbs(ABS_RZ, trigger)
+		b.WithAbs(ABS_HAT0X, hat).WithAbs(ABS_HAT0Y, hat)
+
+	case GamepadDS4:
+		id = InputID{BusType: BUS_USB, Vendor: 0x054c, Product: 0x09cc, Version: 0x8111}
+		axis := AbsInfo{Maximum: 255}
+
+		b.WithCodes(EV_KEY, BTN_SOUTH, BTN_EAST, BTN_NORTH, BTN_WEST, BTN_TL, BTN_TR, BTN_TL2, BTN_TR2,
+			BTN_SELECT, BTN_START, BTN_MODE, BTN_THUMBL, BTN_THUMBR)
+		b.WithAbs(ABS_X, axis).WithAbs(ABS_Y, axis).WithAbs(ABS_RX, axis).WithAbs(ABS_RY, axis)
+		b.WithAbs(ABS_Z, axis).WithAbs(ABS_RZ, axis)
+		b.WithAbs(ABS_HAT0X, hat).WithAbs(ABS_HAT0Y, hat)
+
+	default:
+		id = InputID{BusType: BUS_VIRTUAL}
+
+		b.WithCodes(EV_KEY, BTN_SOUTH, BTN_EAST, BTN_SELECT, BTN_START)
+		b.WithAbs(ABS_X, stick).WithAbs(ABS_Y, stick)
+	}
+
+	return b.defaultID(id)
+}
+
+// defaultID sets the input ID of the device unless it was set before.
+func (b *UInputBuilder) defaultID(id InputID) *UInputBuilder {
+	if b.id == (InputID{}) {
+		b.id = id
+	}
+
+	return b
+}
+
+// Keyboard adds the keys of a full PC keyboard, including multimedia keys,
+// together with scan codes, the lock LEDs and autorepeat by the kernel. It
+// returns b.
+func (b *UInputBuilder) Keyboard() *UInputBuilder {
+	for c := range KEYName {
+		if c >= KEY_ESC && c <= KEY_MICMUTE {
+			b.WithCodes(EV_KEY, c)
+		}
+	}
+
+	b.WithCodes(EV_MSC, MSC_SCAN)
+	b.WithCodes(EV_LED, LED_NUML, LED_CAPSL, LED_SCROLLL)
+	b.WithCodes(EV_REP)
+
+	return b.defaultID(InputID{BusType: BUS_VIRTUAL})
+}
+
+// Mouse adds the buttons and axes of a three button mouse with a wheel,
+// which also reports high-resolution scrolling. It returns b.
+func (b *UInputBuilder) Mouse() *UInputBuilder {
+	b.WithCodes(EV_KEY, BTN_LEFT, BTN_RIGHT, BTN_MIDDLE)
+	b.WithCodes(EV_REL, REL_X, REL_Y, REL_WHEEL, REL_HWHEEL, REL_WHEEL_HI_RES, REL_HWHEEL_HI_RES)
+	b.WithProps(PROP_POINTER)
+
+	return b.defaultID(InputID{BusType: BUS_VIRTUAL})
+}
+
+// Touchscreen adds the axes of a type-B multitouch screen with the given
+// number of slots. Positions range from 0 to width-1 and height-1, with
+// resolution units per millimeter. It returns b.
+func (b *UInputBuilder) Touchscreen(slots int, width, height, resolution int32) *UInputBuilder {
+	x := AbsInfo{Maximum: width - 1, Resolution: resolution}
+	y := AbsInfo{Maximum: height - 1, Resolution: resolution}
+
+	b.WithCodes(EV_KEY, BTN_TOUCH)
+	b.WithAbs(ABS_X, x).WithAbs(ABS_Y, y)
+	b.WithAbs(ABS_MT_SLOT, AbsInfo{Maximum: int32(slots) - 1})
+	b.WithAbs(ABS_MT_TRACKING_ID, AbsInfo{Maximum: 65535})
+	b.WithAbs(ABS_MT_POSITION_X, x).WithAbs(ABS_MT_POSITION_Y, y)
+	b.WithProps(PROP_DIRECT)
+
+	return b.defaultID(InputID{BusType: BUS_VIRTUAL})
+}
@@ -0,0 +1,37 @@
+package evdev
+
+type privacyFilter struct{}
+
+func (privacyFilter) ProcessFrame(frame []InputEvent) []InputEvent {
+	out := make([]InputEvent, len(frame))
+
+	for i, e := range frame {
+		switch {
+		case e.Type == EV_KEY:
+			e.Code = KEY_RESERVED
+		case e.Type == EV_MSC && e.Code == MSC_SCAN:
+			// the scan code identifies the key just as well
+			e.Value = 0
+		}
+
+		out[i] = e
+	}
+
+	return out
+}
+
+// PrivacyFilter returns a Transform that replaces the codes of all EV_KEY
+// events with KEY_RESERVED and clears the values of MSC_SCAN events, while
+// keeping the events themselves, their timing and their key values. It is
+// meant for components that need activity or metrics, e.g. typing speed,
+// but must provably not observe what is typed. It is registered as
+// "privacy".
+func PrivacyFilter() Transform {
+	return privacyFilter{}
+}
+
+func init() {
+	RegisterTransform("privacy", func(string) (Transform, error) {
+		return PrivacyFilter(), nil
+	})
+}
@@ -0,0 +1,38 @@
+package evdev
+
+import (
+	"reflect"
+	"syscall"
+	"testing"
+)
+
+func TestPrivacyFilter(t *testing.T) {
+	tv := syscall.Timeval{Sec: 1, Usec: 2}
+
+	in := []InputEvent{
+		{Time: tv, Type: EV_MSC, Code: MSC_SCAN, Value: 0x70004},
+		{Time: tv, Type: EV_KEY, Code: KEY_A, Value: 1},
+		{Time: tv, Type: EV_REL, Code: REL_X, Value: 3},
+		{Time: tv, Type: EV_SYN, Code: SYN_REPORT},
+	}
+
+	want := []InputEvent{
+		{Time: tv, Type: EV_MSC, Code: MSC_SCAN},
+		{Time: tv, Type: EV_KEY, Code: KEY_RESERVED, Value: 1},
+		{Time: tv, Type: EV_REL, Code: REL_X, Value: 3},
+		{Time: tv, Type: EV_SYN, Code: SYN_REPORT},
+	}
+
+	f, err := NewTransform("privacy", "")
+	if err != nil {
+		t.Fatalf("NewTransform() error = %v", err)
+	}
+
+	if got := f.ProcessFrame(in); !reflect.DeepEqual(got, want) {
+		t.Errorf("ProcessFrame() = %v, want %v", got, want)
+	}
+
+	if in[1].Code != KEY_A {
+		t.Error("ProcessFrame() modified its input")
+	}
+}
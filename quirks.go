@@ -0,0 +1,209 @@
+package evdev
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Quirk works around a firmware bug of a device model by passing its events
+// through transforms, e.g. a script dropping the bogus pressure a touchpad
+// reports
+//
+//	type == EV_ABS && (code == ABS_PRESSURE || code == ABS_MT_PRESSURE) -> drop
+//
+// or one swapping the ABS_Z and ABS_RZ axes of a gamepad
+//
+//	type == EV_ABS && (code == ABS_Z || code == ABS_RZ) -> code = ABS_Z + ABS_RZ - code
+//
+// FrameReader, Buttons, IMU and the MTTracker of NewMTTrackerFor apply the
+// quirks of their device automatically, followed by its settings if
+// UseSettingsStore was called. Pipelines and other consumers of
+// the raw events use NewQuirkSource or NewQuirkTransform.
+type Quirk struct {
+	Name       string          `json:"name"`
+	Vendor     uint16          `json:"vendor"`
+	Product    uint16          `json:"product"` // 0 matches all products of the vendor
+	Transforms []TransformSpec `json:"transforms"`
+}
+
+// Matches returns true if the quirk applies to a device with the given ID.
+func (q *Quirk) Matches(id InputID) bool {
+	return q.Vendor == id.Vendor && (q.Product == 0 || q.Product == id.Product)
+}
+
+var (
+	quirksMu sync.RWMutex
+
+	// quirks is the quirk database, holding the built-in quirks followed by
+	// the registered ones
+	quirks = []Quirk{}
+)
+
+// RegisterQuirk adds a quirk to the database. Quirks are applied in the
+// order they were added. An error is returned if its transforms cannot be
+// created.
+func RegisterQuirk(q Quirk) error {
+	if _, err := NewTransformChain(q.Transforms); err != nil {
+		return fmt.Errorf("Invalid quirk %q: %v", q.Name, err)
+	}
+
+	quirksMu.Lock()
+	defer quirksMu.Unlock()
+
+	quirks = append(quirks, q)
+
+	return nil
+}
+
+// LoadQuirks registers the quirks of a JSON array such as
+//
+//	[{"name": "swapped triggers", "vendor": 4660, "product": 22136,
+//	  "transforms": [{"name": "script", "config": "..."}]}]
+//
+// Nothing is registered if any of the quirks is invalid.
+func LoadQuirks(r io.Reader) error {
+	qs := []Quirk{}
+	if err := json.NewDecoder(r).Decode(&qs); err != nil {
+		return fmt.Errorf("Cannot parse quirks: %v", err)
+	}
+
+	for _, q := range qs {
+		if _, err := NewTransformChain(q.Transforms); err != nil {
+			return fmt.Errorf("Invalid quirk %q: %v", q.Name, err)
+		}
+	}
+
+	quirksMu.Lock()
+	defer quirksMu.Unlock()
+
+	quirks = append(quirks, qs...)
+
+	return nil
+}
+
+// QuirksFor returns the quirks that apply to a device with the given ID.
+func QuirksFor(id InputID) []Quirk {
+	quirksMu.RLock()
+	defer quirksMu.RUnlock()
+
+	qs := []Quirk{}
+	for _, q := range quirks {
+		if q.Matches(id) {
+			qs = append(qs, q)
+		}
+	}
+
+	return qs
+}
+
+// NewQuirkTransform returns a Transform applying the quirks of a device with
+// the given ID, which passes frames through unchanged if there are none.
+// Transforms keep state, so each device needs its own.
+func NewQuirkTransform(id InputID) (Transform, error) {
+	ts := []Transform{}
+
+	for _, q := range QuirksFor(id) {
+		t, err := NewTransformChain(q.Transforms)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid quirk %q: %v", q.Name, err)
+		}
+
+		ts = append(ts, t)
+	}
+
+	return Chain(ts...), nil
+}
+
+// Quirks returns the quirks that apply to the device.
+func (d *InputDevice) Quirks() ([]Quirk, error) {
+	id, err := d.InputID()
+	if err != nil {
+		return nil, err
+	}
+
+	return QuirksFor(id), nil
+}
+
+// NewQuirkSource returns an EventSource reading the events of d with its
+// quirks applied.
+func NewQuirkSource(d *InputDevice) (EventSource, error) {
+	id, err := d.InputID()
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := NewQuirkTransform(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTransformSource(d, t), nil
+}
+
+// deviceFilter applies the quirks of a device and its stored settings, see
+// UseSettingsStore, for the wrappers that process its events one at a time.
+// The events of a frame are held back until its SYN_REPORT, unless there
+// are no transforms to apply.
+type deviceFilter struct {
+	t     Transform // nil without transforms
+	frame []InputEvent
+}
+
+func newDeviceFilter(d *InputDevice) (*deviceFilter, error) {
+	ts := []Transform{}
+
+	if len(QuirksFor(d.id)) > 0 {
+		t, err := NewQuirkTransform(d.id)
+		if err != nil {
+			return nil, err
+		}
+
+		ts = append(ts, t)
+	}
+
+	settingsMu.RLock()
+	st := settingsStore
+	settingsMu.RUnlock()
+
+	if st != nil {
+		t, err := st.ApplyFor(d)
+		if err != nil {
+			return nil, err
+		}
+
+		ts = append(ts, t)
+	}
+
+	if len(ts) == 0 {
+		return &deviceFilter{}, nil
+	}
+
+	return &deviceFilter{t: Chain(ts...)}, nil
+}
+
+// Push adds an event and returns the events to process, i.e. the frame it
+// completes with the transforms applied.
+func (f *deviceFilter) Push(e InputEvent) []InputEvent {
+	if f.t == nil {
+		return []InputEvent{e}
+	}
+
+	if e.Type == EV_SYN && e.Code == SYN_DROPPED {
+		// the pending events are incomplete
+		f.frame = nil
+		return f.t.ProcessFrame([]InputEvent{e})
+	}
+
+	f.frame = append(f.frame, e)
+
+	if e.Type != EV_SYN || e.Code != SYN_REPORT {
+		return nil
+	}
+
+	frame := f.frame
+	f.frame = nil
+
+	return f.t.ProcessFrame(frame)
+}
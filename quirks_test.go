@@ -0,0 +1,146 @@
+package evdev
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQuirks(t *testing.T) {
+	saved := quirks
+	defer func() { quirks = saved }()
+
+	err := LoadQuirks(strings.NewReader(`[
+		{"name": "swapped triggers", "vendor": 4660, "product": 22136, "transforms": [
+			{"name": "script", "config": "type == EV_ABS && (code == ABS_Z || code == ABS_RZ) -> code = ABS_Z + ABS_RZ - code"}
+		]},
+		{"name": "bogus pressure", "vendor": 4660, "transforms": [
+			{"name": "script", "config": "type == EV_ABS && code == ABS_PRESSURE -> drop"}
+		]}
+	]`))
+	if err != nil {
+		t.Fatalf("LoadQuirks() failed: %v", err)
+	}
+
+	if err := RegisterQuirk(Quirk{Name: "broken", Vendor: 1, Transforms: []TransformSpec{{Name: "no-such-transform"}}}); err == nil {
+		t.Errorf("RegisterQuirk() with unknown transform succeeded")
+	}
+
+	if err := LoadQuirks(strings.NewReader(`[{"name": "broken", "transforms": [{"name": "script", "config": "->"}]}]`)); err == nil {
+		t.Errorf("LoadQuirks() with invalid script succeeded")
+	}
+
+	frame := []InputEvent{
+		{Type: EV_ABS, Code: ABS_Z, Value: 1},
+		{Type: EV_ABS, Code: ABS_RZ, Value: 2},
+		{Type: EV_ABS, Code: ABS_PRESSURE, Value: 3},
+		{Type: EV_SYN, Code: SYN_REPORT},
+	}
+
+	tests := []struct {
+		id     InputID
+		quirks []string
+		want   []InputEvent
+	}{
+		{
+			id:     InputID{Vendor: 4660, Product: 22136},
+			quirks: []string{"swapped triggers", "bogus pressure"},
+			want: []InputEvent{
+				{Type: EV_ABS, Code: ABS_RZ, Value: 1},
+				{Type: EV_ABS, Code: ABS_Z, Value: 2},
+				{Type: EV_SYN, Code: SYN_REPORT},
+			},
+		},
+		{
+			id:     InputID{Vendor: 4660, Product: 1},
+			quirks: []string{"bogus pressure"},
+			want:   []InputEvent{frame[0], frame[1], frame[3]},
+		},
+		{
+			id:     InputID{Vendor: 1, Product: 22136},
+			quirks: []string{},
+			want:   frame,
+		},
+	}
+
+	for _, tt := range tests {
+		names := []string{}
+		for _, q := range QuirksFor(tt.id) {
+			names = append(names, q.Name)
+		}
+
+		if !reflect.DeepEqual(names, tt.quirks) {
+			t.Errorf("QuirksFor(%+v) = %v, want %v", tt.id, names, tt.quirks)
+		}
+
+		qt, err := NewQuirkTransform(tt.id)
+		if err != nil {
+			t.Fatalf("NewQuirkTransform(%+v) failed: %v", tt.id, err)
+		}
+
+		in := append([]InputEvent{}, frame...)
+		if got := qt.ProcessFrame(in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ProcessFrame() for %+v = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestQuirks_Wrappers(t *testing.T) {
+	saved := quirks
+	defer func() { quirks = saved }()
+
+	err := RegisterQuirk(Quirk{Name: "wrong key", Vendor: 4660, Transforms: []TransformSpec{
+		{Name: "script", Config: "type == EV_KEY && code == KEY_A -> code = KEY_POWER"},
+	}})
+	if err != nil {
+		t.Fatalf("RegisterQuirk() failed: %v", err)
+	}
+
+	events := []InputEvent{
+		{Type: EV_KEY, Code: KEY_A, Value: 1},
+		{Type: EV_SYN, Code: SYN_REPORT},
+	}
+	want := []InputEvent{
+		{Type: EV_KEY, Code: KEY_POWER, Value: 1},
+		{Type: EV_SYN, Code: SYN_REPORT},
+	}
+
+	t.Run("FrameReader", func(t *testing.T) {
+		d, w := pipeDevice(t)
+		defer d.file.Close()
+		defer w.Close()
+
+		d.id = InputID{Vendor: 4660}
+
+		if _, err := w.Write(eventBytes(events)); err != nil {
+			t.Fatalf("Cannot write events: %v", err)
+		}
+
+		got, err := NewFrameReader(d, FramePassThrough).ReadFrame()
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("ReadFrame() = %v, %v, want %v", got, err, want)
+		}
+	})
+
+	t.Run("Buttons", func(t *testing.T) {
+		d, w := pipeDevice(t)
+		defer d.file.Close()
+
+		d.id = InputID{Vendor: 4660}
+
+		pressed := false
+		b := NewButtons(d, map[string]EvCode{"power": KEY_POWER})
+		b.OnPress("power", func() { pressed = true })
+
+		if _, err := w.Write(eventBytes(events)); err != nil {
+			t.Fatalf("Cannot write events: %v", err)
+		}
+		w.Close()
+
+		b.Run()
+
+		if !pressed {
+			t.Errorf("quirk was not applied to the buttons")
+		}
+	})
+}
@@ -0,0 +1,278 @@
+package evdev
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// RateLimit limits how often the events of a type, and optionally only some
+// of its codes, are delivered. All codes of a limit share one interval, so
+// e.g. REL_X and REL_Y stay in the same frames.
+type RateLimit struct {
+	Type     EvType
+	Codes    []EvCode // all codes of Type if empty
+	Interval time.Duration
+}
+
+type rateKey struct {
+	t EvType
+	c EvCode
+}
+
+// RateLimiter is a Transform that delivers the events of each RateLimit at
+// most once per interval. Events that arrive earlier are held back and
+// coalesced like Coalesce does: relative values are summed and absolute
+// values replaced by the latest one, so no motion is lost. Held events are
+// delivered with the first frame after the interval elapsed, or by Flush.
+//
+// Frames are timed by their SYN_REPORT. Events of other types and codes pass
+// through unchanged, as do multitouch axes, whose values depend on the
+// slot. Frames left without events are dropped.
+type RateLimiter struct {
+	limits  []RateLimit
+	codes   []map[EvCode]bool
+	last    []time.Time
+	pending map[rateKey]InputEvent
+	order   []rateKey // pending keys in the order they arrived
+}
+
+// NewRateLimiter creates a RateLimiter with the given limits. The first
+// matching limit applies to an event.
+func NewRateLimiter(limits ...RateLimit) *RateLimiter {
+	rl := &RateLimiter{
+		limits:  limits,
+		codes:   make([]map[EvCode]bool, len(limits)),
+		last:    make([]time.Time, len(limits)),
+		pending: map[rateKey]InputEvent{},
+	}
+
+	for i, l := range limits {
+		if len(l.Codes) == 0 {
+			continue
+		}
+
+		rl.codes[i] = map[EvCode]bool{}
+		for _, c := range l.Codes {
+			rl.codes[i][c] = true
+		}
+	}
+
+	return rl
+}
+
+// limitOf returns the index of the limit that applies to e, or -1.
+func (rl *RateLimiter) limitOf(e *InputEvent) int {
+	if e.Type == EV_ABS && (e.Code == ABS_MT_SLOT || isMTAxis(e.Code)) {
+		return -1
+	}
+
+	for i, l := range rl.limits {
+		if l.Type == e.Type && (rl.codes[i] == nil || rl.codes[i][e.Code]) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (rl *RateLimiter) hold(e InputEvent) {
+	k := rateKey{e.Type, e.Code}
+
+	held, ok := rl.pending[k]
+	if !ok {
+		rl.order = append(rl.order, k)
+	} else if e.Type == EV_REL {
+		e.Value += held.Value
+	}
+
+	rl.pending[k] = e
+}
+
+// release removes the held events of the limits marked in due and returns
+// them, dropping relative motion that sums up to zero.
+func (rl *RateLimiter) release(due []bool) []InputEvent {
+	released := []InputEvent{}
+	order := rl.order[:0]
+
+	for _, k := range rl.order {
+		e := rl.pending[k]
+
+		if i := rl.limitOf(&e); i >= 0 && !due[i] {
+			order = append(order, k)
+			continue
+		}
+
+		delete(rl.pending, k)
+
+		if e.Type != EV_REL || e.Value != 0 {
+			released = append(released, e)
+		}
+	}
+
+	rl.order = order
+
+	return released
+}
+
+// ProcessFrame implements Transform.
+func (rl *RateLimiter) ProcessFrame(frame []InputEvent) []InputEvent {
+	last := frame[len(frame)-1]
+
+	if last.Type == EV_SYN && last.Code == SYN_DROPPED {
+		rl.pending = map[rateKey]InputEvent{}
+		rl.order = nil
+
+		return frame
+	}
+
+	now := last.Timestamp()
+
+	due := make([]bool, len(rl.limits))
+	for i, l := range rl.limits {
+		due[i] = now.Sub(rl.last[i]) >= l.Interval
+	}
+
+	out := make([]InputEvent, 0, len(frame))
+	delivered := false
+
+	for _, e := range frame {
+		if e.Type == EV_SYN {
+			continue
+		}
+
+		i := rl.limitOf(&e)
+		if i < 0 {
+			out = append(out, e)
+			delivered = true
+			continue
+		}
+
+		rl.hold(e)
+	}
+
+	released := rl.release(due)
+	for _, e := range released {
+		rl.last[rl.limitOf(&e)] = now
+	}
+
+	out = append(out, released...)
+
+	if !delivered && len(released) == 0 {
+		return nil
+	}
+
+	return append(out, last)
+}
+
+// Flush returns the held events as a frame ending with a SYN_REPORT at now,
+// e.g. for a timer that delivers the end of a motion when the device
+// stopped reporting. It returns nil if no events are held.
+func (rl *RateLimiter) Flush(now time.Time) []InputEvent {
+	due := make([]bool, len(rl.limits))
+	for i := range due {
+		due[i] = true
+	}
+
+	out := rl.release(due)
+	if len(out) == 0 {
+		return nil
+	}
+
+	for _, e := range out {
+		rl.last[rl.limitOf(&e)] = now
+	}
+
+	return append(out, InputEvent{Time: syscall.NsecToTimeval(now.UnixNano()), Type: EV_SYN, Code: SYN_REPORT})
+}
+
+// parseTypeCodes parses an event type followed by codes of that type, e.g.
+// "EV_REL REL_X REL_Y".
+func parseTypeCodes(fields []string) (EvType, []EvCode, error) {
+	if len(fields) == 0 {
+		return 0, nil, fmt.Errorf("Missing event type")
+	}
+
+	t, ok := TypeByName(fields[0])
+	if !ok {
+		return 0, nil, fmt.Errorf("Unknown event type %q", fields[0])
+	}
+
+	codes := []EvCode{}
+
+	for _, name := range fields[1:] {
+		c, ok := CodeByName(t, name)
+		if !ok {
+			return 0, nil, fmt.Errorf("Unknown code %q for %s", name, TypeName(t))
+		}
+
+		codes = append(codes, c)
+	}
+
+	return t, codes, nil
+}
+
+// parseInterval parses a duration such as "8ms", or a rate such as "125Hz".
+func parseInterval(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "Hz") {
+		hz, err := strconv.ParseFloat(strings.TrimSuffix(s, "Hz"), 64)
+		if err != nil || hz <= 0 {
+			return 0, fmt.Errorf("Invalid rate %q", s)
+		}
+
+		return time.Duration(float64(time.Second) / hz), nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("Invalid interval %q", s)
+	}
+
+	return d, nil
+}
+
+// ParseRateLimits parses rate limits separated by semicolons or newlines,
+// each an event type, optional codes and an interval or rate, e.g.
+//
+//	EV_REL 125Hz; EV_ABS ABS_X ABS_Y 8ms
+func ParseRateLimits(config string) ([]RateLimit, error) {
+	limits := []RateLimit{}
+
+	for _, line := range strings.FieldsFunc(config, func(r rune) bool { return r == ';' || r == '\n' }) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("Missing interval in rate limit %q", strings.TrimSpace(line))
+		}
+
+		t, codes, err := parseTypeCodes(fields[:len(fields)-1])
+		if err != nil {
+			return nil, err
+		}
+
+		interval, err := parseInterval(fields[len(fields)-1])
+		if err != nil {
+			return nil, err
+		}
+
+		limits = append(limits, RateLimit{Type: t, Codes: codes, Interval: interval})
+	}
+
+	return limits, nil
+}
+
+func init() {
+	RegisterTransform("ratelimit", func(config string) (Transform, error) {
+		limits, err := ParseRateLimits(config)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewRateLimiter(limits...), nil
+	})
+}
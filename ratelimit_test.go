@@ -0,0 +1,102 @@
+package evdev
+
+import (
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	at := func(ms int64) syscall.Timeval { return syscall.NsecToTimeval(ms * int64(time.Millisecond)) }
+	ev := func(ms int64, typ EvType, c EvCode, v int32) InputEvent {
+		return InputEvent{Time: at(ms), Type: typ, Code: c, Value: v}
+	}
+	syn := func(ms int64) InputEvent { return ev(ms, EV_SYN, SYN_REPORT, 0) }
+
+	rl, err := NewTransform("ratelimit", "EV_REL REL_X REL_Y 10ms; EV_ABS 100Hz")
+	if err != nil {
+		t.Fatalf("NewTransform() error = %v", err)
+	}
+
+	tests := []struct {
+		frame []InputEvent
+		want  []InputEvent
+	}{
+		{
+			frame: []InputEvent{ev(100, EV_REL, REL_X, 2), ev(100, EV_REL, REL_Y, 1), syn(100)},
+			want:  []InputEvent{ev(100, EV_REL, REL_X, 2), ev(100, EV_REL, REL_Y, 1), syn(100)},
+		},
+		{
+			// held back, the key passes
+			frame: []InputEvent{ev(104, EV_REL, REL_X, 3), ev(104, EV_KEY, BTN_LEFT, 1), syn(104)},
+			want:  []InputEvent{ev(104, EV_KEY, BTN_LEFT, 1), syn(104)},
+		},
+		{
+			frame: []InputEvent{ev(106, EV_REL, REL_X, 4), ev(106, EV_REL, REL_Y, 1), ev(106, EV_REL, REL_WHEEL, 1), syn(106)},
+			want:  []InputEvent{ev(106, EV_REL, REL_WHEEL, 1), syn(106)},
+		},
+		{
+			frame: []InputEvent{ev(110, EV_REL, REL_Y, -1), syn(110)},
+			want:  []InputEvent{ev(106, EV_REL, REL_X, 7), syn(110)},
+		},
+		{
+			frame: []InputEvent{ev(111, EV_ABS, ABS_X, 10), syn(111)},
+			want:  []InputEvent{ev(111, EV_ABS, ABS_X, 10), syn(111)},
+		},
+		{
+			frame: []InputEvent{ev(115, EV_ABS, ABS_X, 20), ev(115, EV_ABS, ABS_MT_POSITION_X, 20), syn(115)},
+			want:  []InputEvent{ev(115, EV_ABS, ABS_MT_POSITION_X, 20), syn(115)},
+		},
+		{
+			frame: []InputEvent{ev(118, EV_ABS, ABS_X, 30), syn(118)},
+			want:  nil,
+		},
+		{
+			frame: []InputEvent{ev(121, EV_ABS, ABS_Y, 5), syn(121)},
+			want:  []InputEvent{ev(118, EV_ABS, ABS_X, 30), ev(121, EV_ABS, ABS_Y, 5), syn(121)},
+		},
+	}
+
+	for i, tt := range tests {
+		if got := rl.ProcessFrame(tt.frame); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("frame %d: ProcessFrame() = %v, want %v", i, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimiter_Flush(t *testing.T) {
+	rl := NewRateLimiter(RateLimit{Type: EV_REL, Interval: time.Second})
+
+	rl.ProcessFrame([]InputEvent{{Type: EV_REL, Code: REL_X, Value: 1}, {Type: EV_SYN, Code: SYN_REPORT}})
+	rl.ProcessFrame([]InputEvent{{Type: EV_REL, Code: REL_X, Value: 2}, {Type: EV_SYN, Code: SYN_REPORT}})
+	rl.ProcessFrame([]InputEvent{{Type: EV_REL, Code: REL_X, Value: 3}, {Type: EV_SYN, Code: SYN_REPORT}})
+
+	now := time.Unix(0, int64(500*time.Millisecond))
+	want := []InputEvent{
+		{Type: EV_REL, Code: REL_X, Value: 5},
+		{Time: syscall.Timeval{Usec: 500000}, Type: EV_SYN, Code: SYN_REPORT},
+	}
+
+	if got := rl.Flush(now); !reflect.DeepEqual(got, want) {
+		t.Errorf("Flush() = %v, want %v", got, want)
+	}
+
+	if got := rl.Flush(now); got != nil {
+		t.Errorf("Flush() = %v, want nil", got)
+	}
+}
+
+func TestParseRateLimitsErrors(t *testing.T) {
+	for _, config := range []string{
+		"EV_REL",
+		"EV_BOGUS 10ms",
+		"EV_REL KEY_A 10ms",
+		"EV_REL 0Hz",
+		"EV_REL fast",
+	} {
+		if _, err := ParseRateLimits(config); err == nil {
+			t.Errorf("ParseRateLimits(%q) succeeded", config)
+		}
+	}
+}
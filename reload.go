@@ -0,0 +1,267 @@
+package evdev
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ReloadableTransform is a Transform that can be replaced at runtime, e.g.
+// when its configuration changed. Replacing it takes effect between frames,
+// so no frame is processed partly by the old and partly by the new transform.
+// Keys held on the output of the old transform are released with the first
+// frame of the new one, and keys held on the input at that time are ignored
+// until they are pressed again, so no key stays stuck when a remapping
+// changes while it is in use.
+type ReloadableTransform struct {
+	mu      sync.Mutex
+	t       Transform
+	swapped bool
+	in      map[EvCode]bool // keys held on the input
+	out     map[EvCode]bool // keys held on the output
+	ignored map[EvCode]bool // keys held on the input when the transform was swapped
+}
+
+// NewReloadableTransform creates a ReloadableTransform that initially passes
+// frames through t.
+func NewReloadableTransform(t Transform) *ReloadableTransform {
+	return &ReloadableTransform{
+		t:       t,
+		in:      map[EvCode]bool{},
+		out:     map[EvCode]bool{},
+		ignored: map[EvCode]bool{},
+	}
+}
+
+// Swap replaces the transform. It waits for the frame in progress, if any.
+func (rt *ReloadableTransform) Swap(t Transform) {
+	rt.mu.Lock()
+	rt.t = t
+	rt.swapped = true
+	rt.mu.Unlock()
+}
+
+// trackKeys updates the keys held according to events.
+func trackKeys(held map[EvCode]bool, events []InputEvent) {
+	for _, e := range events {
+		if e.Type == EV_KEY && KeyState(e.Value) == KeyDown {
+			held[e.Code] = true
+		} else if e.Type == EV_KEY && KeyState(e.Value) == KeyUp {
+			delete(held, e.Code)
+		}
+	}
+}
+
+// ProcessFrame passes the frame through the current transform.
+func (rt *ReloadableTransform) ProcessFrame(frame []InputEvent) []InputEvent {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	last := frame[len(frame)-1]
+	if last.Type == EV_SYN && last.Code == SYN_DROPPED {
+		rt.in = map[EvCode]bool{}
+		rt.ignored = map[EvCode]bool{}
+	}
+
+	released := []EvCode{}
+
+	if rt.swapped {
+		rt.swapped = false
+
+		for c := range rt.out {
+			released = append(released, c)
+		}
+
+		rt.out = map[EvCode]bool{}
+		rt.ignored = map[EvCode]bool{}
+
+		for c := range rt.in {
+			rt.ignored[c] = true
+		}
+	}
+
+	trackKeys(rt.in, frame)
+
+	if len(rt.ignored) > 0 {
+		filtered := make([]InputEvent, 0, len(frame))
+
+		for _, e := range frame {
+			if e.Type == EV_KEY && rt.ignored[e.Code] {
+				if KeyState(e.Value) != KeyDown {
+					continue
+				}
+
+				delete(rt.ignored, e.Code)
+			}
+
+			filtered = append(filtered, e)
+		}
+
+		frame = filtered
+	}
+
+	out := []InputEvent{}
+	if len(frame) > 0 {
+		out = rt.t.ProcessFrame(frame)
+	}
+
+	trackKeys(rt.out, out)
+
+	if len(released) == 0 {
+		return out
+	}
+
+	// the releases go into the frame's report
+	events := []InputEvent{}
+	for _, c := range sortCodes(released) {
+		events = append(events, InputEvent{Time: last.Time, Type: EV_KEY, Code: c, Value: int32(KeyUp)})
+	}
+
+	if n := len(out); n > 0 && out[n-1].Type == EV_SYN && out[n-1].Code == SYN_REPORT {
+		return append(append(out[:n-1:n-1], events...), out[n-1])
+	}
+
+	return append(append(out, events...), InputEvent{Time: last.Time, Type: EV_SYN, Code: SYN_REPORT})
+}
+
+// MapCapabilities implements CapabilityMapper for the current transform.
+func (rt *ReloadableTransform) MapCapabilities(in Capabilities) Capabilities {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	return mapCapabilities(rt.t, in)
+}
+
+// Tick implements Ticker for the current transform, if it implements it.
+func (rt *ReloadableTransform) Tick(now time.Time) ([]InputEvent, time.Time) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if tk, ok := rt.t.(Ticker); ok {
+		events, next := tk.Tick(now)
+		trackKeys(rt.out, events)
+
+		return events, next
+	}
+
+	return nil, time.Time{}
+}
+
+// Reloader calls a load function when the program receives a signal or a
+// watched file changes, so long running programs can pick up configuration
+// changes without restarting. Loads never run concurrently.
+type Reloader struct {
+	load    func() error
+	onError func(error)
+
+	mu      sync.Mutex // serializes loads
+	signals chan os.Signal
+	stop    chan struct{}
+	wg      sync.WaitGroup
+	stopped bool
+}
+
+// NewReloader creates a Reloader for the given load function. Errors returned
+// by it are passed to onError, which may be nil.
+func NewReloader(load func() error, onError func(error)) *Reloader {
+	return &Reloader{
+		load:    load,
+		onError: onError,
+		stop:    make(chan struct{}),
+	}
+}
+
+// Reload calls the load function and returns its error.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.load()
+}
+
+func (r *Reloader) reload() {
+	if err := r.Reload(); err != nil && r.onError != nil {
+		r.onError(err)
+	}
+}
+
+// OnSignal reloads whenever one of the given signals is received, or SIGHUP
+// if none are given.
+func (r *Reloader) OnSignal(signals ...os.Signal) {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+
+	r.wg.Add(1)
+
+	go func() {
+		defer r.wg.Done()
+		defer signal.Stop(ch)
+
+		for {
+			select {
+			case <-ch:
+				r.reload()
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+// WatchFile reloads whenever the modification time or size of the file at
+// path changes, checking every interval.
+func (r *Reloader) WatchFile(path string, interval time.Duration) {
+	stat := func() (time.Time, int64) {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, -1
+		}
+
+		return fi.ModTime(), fi.Size()
+	}
+
+	lastTime, lastSize := stat()
+
+	r.wg.Add(1)
+
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				t, size := stat()
+				if t.Equal(lastTime) && size == lastSize {
+					continue
+				}
+
+				lastTime, lastSize = t, size
+				r.reload()
+
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops watching signals and files and waits for a load in progress.
+func (r *Reloader) Stop() {
+	r.mu.Lock()
+	if !r.stopped {
+		r.stopped = true
+		close(r.stop)
+	}
+	r.mu.Unlock()
+
+	r.wg.Wait()
+}
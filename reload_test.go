@@ -0,0 +1,136 @@
+package evdev
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "evdev-reload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "rules")
+	if err := ioutil.WriteFile(path, []byte("code == KEY_A -> code = KEY_B"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rt := NewReloadableTransform(Chain())
+
+	r := NewReloader(func() error {
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		s, err := ParseScript(string(src))
+		if err != nil {
+			return err
+		}
+
+		rt.Swap(s)
+
+		return nil
+	}, func(err error) {
+		t.Errorf("Reload error: %v", err)
+	})
+	defer r.Stop()
+
+	in := []InputEvent{{Type: EV_KEY, Code: KEY_A, Value: 1}}
+
+	// waits until the transform maps KEY_A to the given code
+	waitFor := func(code EvCode) {
+		deadline := time.Now().Add(time.Second)
+
+		for rt.ProcessFrame(in)[0].Code != code {
+			if time.Now().After(deadline) {
+				t.Fatalf("KEY_A not mapped to %s", CodeName(EV_KEY, code))
+			}
+
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	if got := rt.ProcessFrame(in); got[0].Code != KEY_A {
+		t.Fatalf("initial transform changed the event to %v", got)
+	}
+
+	r.OnSignal(syscall.SIGUSR2)
+	syscall.Kill(os.Getpid(), syscall.SIGUSR2)
+	waitFor(KEY_B)
+
+	r.WatchFile(path, time.Millisecond)
+	if err := ioutil.WriteFile(path, []byte("code == KEY_A -> code = KEY_ESC"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(KEY_ESC)
+}
+
+func TestReloadableTransform_Swap(t *testing.T) {
+	key := func(c EvCode, v int32) InputEvent { return InputEvent{Type: EV_KEY, Code: c, Value: v} }
+
+	remap, err := ParseScript("code == KEY_A -> code = KEY_B")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type step struct {
+		swap  Transform
+		frame []InputEvent
+		want  []InputEvent
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "release remapped key",
+			steps: []step{
+				{frame: []InputEvent{key(KEY_A, 1), synReport}, want: []InputEvent{key(KEY_B, 1), synReport}},
+				{swap: Chain(), frame: []InputEvent{key(KEY_A, 2), synReport}, want: []InputEvent{key(KEY_B, 0), synReport}},
+				{frame: []InputEvent{key(KEY_A, 0), synReport}, want: []InputEvent{synReport}},
+				{frame: []InputEvent{key(KEY_A, 1), synReport}, want: []InputEvent{key(KEY_A, 1), synReport}},
+			},
+		},
+		{
+			name: "release key before remapping",
+			steps: []step{
+				{swap: Chain(), frame: []InputEvent{key(KEY_A, 1), key(KEY_C, 1), synReport}, want: []InputEvent{key(KEY_A, 1), key(KEY_C, 1), synReport}},
+				{swap: remap, frame: []InputEvent{key(KEY_C, 0), synReport}, want: []InputEvent{key(KEY_A, 0), key(KEY_C, 0), synReport}},
+				{frame: []InputEvent{key(KEY_A, 0), synReport}, want: []InputEvent{synReport}},
+				{frame: []InputEvent{key(KEY_A, 1), synReport}, want: []InputEvent{key(KEY_B, 1), synReport}},
+			},
+		},
+		{
+			name: "nothing held",
+			steps: []step{
+				{frame: []InputEvent{key(KEY_A, 1), key(KEY_A, 0), synReport}, want: []InputEvent{key(KEY_B, 1), key(KEY_B, 0), synReport}},
+				{swap: Chain(), frame: []InputEvent{key(KEY_A, 1), synReport}, want: []InputEvent{key(KEY_A, 1), synReport}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := NewReloadableTransform(remap)
+
+			for i, s := range tt.steps {
+				if s.swap != nil {
+					rt.Swap(s.swap)
+				}
+
+				if got := rt.ProcessFrame(s.frame); !reflect.DeepEqual(got, s.want) {
+					t.Errorf("step %d: ProcessFrame() = %v, want %v", i, got, s.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package evdev
+
+import (
+	"errors"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy selects which failed reads are retried instead of returning
+// the error, see SetRetryPolicy.
+type RetryPolicy struct {
+	// Again makes reads failing with EAGAIN, as returned in non-blocking
+	// mode while no events are queued, wait and retry.
+	Again bool
+
+	// Backoff is the time to wait before retrying after EAGAIN. It doubles
+	// with each consecutive retry up to MaxBackoff. Defaults to 1ms and
+	// 100ms.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// MaxRetries limits the number of consecutive retries of a read, 0
+	// means no limit.
+	MaxRetries int
+}
+
+// SetRetryPolicy sets how Read and ReadOne handle transient errors. Reads
+// interrupted by a signal (EINTR) are always retried, and with the zero
+// policy, which is the default, without limit.
+func (d *InputDevice) SetRetryPolicy(p RetryPolicy) {
+	if p.Backoff <= 0 {
+		p.Backoff = time.Millisecond
+	}
+
+	if p.MaxBackoff < p.Backoff {
+		p.MaxBackoff = 100 * time.Millisecond
+		if p.MaxBackoff < p.Backoff {
+			p.MaxBackoff = p.Backoff
+		}
+	}
+
+	d.retryPolicy = p
+}
+
+// RetryPolicy returns the policy set with SetRetryPolicy.
+func (d *InputDevice) RetryPolicy() RetryPolicy {
+	return d.retryPolicy
+}
+
+// readRetrying calls read until it succeeds or fails with an error the
+// policy doesn't retry.
+func readRetrying(read func([]byte) (int, error), buffer []byte, p RetryPolicy) (int, error) {
+	backoff := p.Backoff
+
+	for retries := 0; ; retries++ {
+		n, err := read(buffer)
+		if err == nil || p.MaxRetries > 0 && retries >= p.MaxRetries {
+			return n, err
+		}
+
+		switch {
+		case errors.Is(err, syscall.EINTR):
+			// retry right away
+
+		case p.Again && errors.Is(err, syscall.EAGAIN):
+			time.Sleep(backoff)
+
+			if backoff *= 2; backoff > p.MaxBackoff {
+				backoff = p.MaxBackoff
+			}
+
+		default:
+			return n, err
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package evdev
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReadRetrying(t *testing.T) {
+	eintr := &os.PathError{Op: "read", Path: "/dev/input/event0", Err: syscall.EINTR}
+	eagain := &os.PathError{Op: "read", Path: "/dev/input/event0", Err: syscall.EAGAIN}
+	enodev := &os.PathError{Op: "read", Path: "/dev/input/event0", Err: syscall.ENODEV}
+
+	tests := []struct {
+		name    string
+		errs    []error
+		policy  RetryPolicy
+		wantErr error
+		reads   int
+	}{
+		{"success", nil, RetryPolicy{}, nil, 1},
+		{"EINTR", []error{eintr, eintr}, RetryPolicy{}, nil, 3},
+		{"EAGAIN not retried", []error{eagain}, RetryPolicy{}, eagain, 1},
+		{"EAGAIN", []error{eagain, eagain}, RetryPolicy{Again: true, Backoff: time.Microsecond, MaxBackoff: time.Microsecond}, nil, 3},
+		{"other error", []error{enodev}, RetryPolicy{Again: true}, enodev, 1},
+		{"max retries", []error{eintr, eintr, eintr}, RetryPolicy{MaxRetries: 2}, eintr, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reads := 0
+			read := func(b []byte) (int, error) {
+				reads++
+				if reads <= len(tt.errs) {
+					return 0, tt.errs[reads-1]
+				}
+
+				return len(b), nil
+			}
+
+			_, err := readRetrying(read, make([]byte, 8), tt.policy)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+
+			if reads != tt.reads {
+				t.Errorf("%d reads, want %d", reads, tt.reads)
+			}
+		})
+	}
+}
+
+func TestSetRetryPolicy(t *testing.T) {
+	d := &InputDevice{}
+
+	d.SetRetryPolicy(RetryPolicy{Again: true})
+	if p := d.RetryPolicy(); p.Backoff != time.Millisecond || p.MaxBackoff != 100*time.Millisecond {
+		t.Errorf("policy = %+v, want the default backoff", p)
+	}
+
+	d.SetRetryPolicy(RetryPolicy{Backoff: time.Second})
+	if p := d.RetryPolicy(); p.MaxBackoff != time.Second {
+		t.Errorf("MaxBackoff = %v, want %v", p.MaxBackoff, time.Second)
+	}
+}
@@ -0,0 +1,108 @@
+package evdev
+
+// RolloverResult is the outcome of one key combination of a RolloverTest.
+type RolloverResult struct {
+	Expected []EvCode // keys the user was asked to hold down
+	Missing  []EvCode // expected keys that were not reported, i.e. blocked
+	Ghosts   []EvCode // keys reported although they were not expected
+}
+
+// OK returns true if exactly the expected keys were reported.
+func (r *RolloverResult) OK() bool {
+	return len(r.Missing) == 0 && len(r.Ghosts) == 0
+}
+
+// RolloverTest diagnoses the N-key rollover of a keyboard from the events it
+// reports. Tooling asks the user to hold down a combination of keys, feeds
+// the events read meanwhile to Process and then calls Check with the keys of
+// the combination. Keys that are held but not reported reveal blocking, keys
+// that are reported but not held reveal ghosting.
+type RolloverTest struct {
+	held    StateMap
+	seen    StateMap
+	maxHeld int
+	results []RolloverResult
+}
+
+// NewRolloverTest creates a RolloverTest.
+func NewRolloverTest() *RolloverTest {
+	return &RolloverTest{
+		held: StateMap{},
+		seen: StateMap{},
+	}
+}
+
+// Process records an event read from the keyboard.
+func (rt *RolloverTest) Process(e *InputEvent) {
+	if e.Type != EV_KEY {
+		return
+	}
+
+	// autorepeat does not change the state
+	if KeyState(e.Value) == KeyRepeat {
+		return
+	}
+
+	rt.held[e.Code] = e.Value != 0
+
+	if KeyState(e.Value) == KeyUp {
+		return
+	}
+
+	rt.seen[e.Code] = true
+
+	if n := len(rt.held.Active()); n > rt.maxHeld {
+		rt.maxHeld = n
+	}
+}
+
+// Check compares the keys reported since the last check against the
+// expected combination and records the result. Keys that were pressed and
+// released again count as ghosts too, as keyboards often report phantom keys
+// only briefly.
+func (rt *RolloverTest) Check(expected ...EvCode) RolloverResult {
+	r := RolloverResult{
+		Expected: sortCodes(append([]EvCode{}, expected...)),
+		Missing:  []EvCode{},
+		Ghosts:   []EvCode{},
+	}
+
+	want := StateMap{}
+	for _, c := range expected {
+		want[c] = true
+
+		if !rt.held[c] {
+			r.Missing = append(r.Missing, c)
+		}
+	}
+
+	for _, c := range rt.seen.Active() {
+		if !want[c] {
+			r.Ghosts = append(r.Ghosts, c)
+		}
+	}
+
+	sortCodes(r.Missing)
+
+	// keys still held stay seen for the next combination
+	rt.seen = StateMap{}
+	for _, c := range rt.held.Active() {
+		rt.seen[c] = true
+	}
+
+	rt.results = append(rt.results, r)
+
+	return r
+}
+
+// Results returns the results of all checks so far.
+func (rt *RolloverTest) Results() []RolloverResult {
+	return rt.results
+}
+
+// MaxRollover returns the largest number of keys the keyboard reported as
+// held down at the same time, which is its practical rollover limit if the
+// user tried to hold down more keys than that.
+func (rt *RolloverTest) MaxRollover() int {
+	return rt.maxHeld
+}
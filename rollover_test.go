@@ -0,0 +1,48 @@
+package evdev
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRolloverTest(t *testing.T) {
+	key := func(c EvCode, v int32) *InputEvent {
+		return &InputEvent{Type: EV_KEY, Code: c, Value: v}
+	}
+
+	rt := NewRolloverTest()
+
+	for _, e := range []*InputEvent{
+		key(KEY_A, 1), key(KEY_S, 1), key(KEY_D, 1), key(KEY_A, 2),
+	} {
+		rt.Process(e)
+	}
+
+	if r := rt.Check(KEY_A, KEY_S, KEY_D); !r.OK() {
+		t.Errorf("Check() = %+v, want OK", r)
+	}
+
+	// KEY_G is blocked and KEY_H ghosts in briefly
+	for _, e := range []*InputEvent{
+		key(KEY_F, 1), key(KEY_H, 1), key(KEY_H, 0),
+	} {
+		rt.Process(e)
+	}
+
+	want := RolloverResult{
+		Expected: []EvCode{KEY_A, KEY_S, KEY_D, KEY_F, KEY_G},
+		Missing:  []EvCode{KEY_G},
+		Ghosts:   []EvCode{KEY_H},
+	}
+	if r := rt.Check(KEY_A, KEY_S, KEY_D, KEY_F, KEY_G); !reflect.DeepEqual(r, want) {
+		t.Errorf("Check() = %+v, want %+v", r, want)
+	}
+
+	if got := rt.MaxRollover(); got != 5 {
+		t.Errorf("MaxRollover() = %v, want 5", got)
+	}
+
+	if got := len(rt.Results()); got != 2 {
+		t.Errorf("len(Results()) = %v, want 2", got)
+	}
+}
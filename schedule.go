@@ -0,0 +1,135 @@
+package evdev
+
+import (
+	"context"
+	"runtime"
+	"time"
+)
+
+// DefaultSpin is the time before a deadline SleepUntil busy-waits instead
+// of sleeping. The timer slack of the kernel and the Go scheduler make
+// sleeps overshoot by up to about a millisecond.
+const DefaultSpin = 2 * time.Millisecond
+
+// SleepUntil waits until t, returning within microseconds of it on an idle
+// system. It sleeps until spin before t and busy-waits the rest, which
+// keeps a CPU busy for up to spin. It returns ctx.Err() if ctx is done
+// first.
+func SleepUntil(ctx context.Context, t time.Time, spin time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if d := time.Until(t) - spin; d > 0 {
+		timer := time.NewTimer(d)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	for time.Now().Before(t) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		runtime.Gosched()
+	}
+
+	return nil
+}
+
+// ScheduledFrame is a frame to inject At after the start of a schedule.
+type ScheduledFrame struct {
+	At     time.Duration
+	Events []InputEvent
+}
+
+// ScheduleFrames schedules frames, e.g. read from a capture, at the times
+// of their first events relative to the first frame. Empty frames are
+// skipped.
+func ScheduleFrames(frames [][]InputEvent) []ScheduledFrame {
+	scheduled := make([]ScheduledFrame, 0, len(frames))
+
+	for _, f := range frames {
+		if len(f) == 0 {
+			continue
+		}
+
+		at := time.Duration(0)
+		if len(scheduled) > 0 {
+			at = f[0].Timestamp().Sub(scheduled[0].Events[0].Timestamp())
+		}
+
+		scheduled = append(scheduled, ScheduledFrame{At: at, Events: f})
+	}
+
+	return scheduled
+}
+
+// ScheduleStats describes how accurately a Scheduler met its deadlines.
+type ScheduleStats struct {
+	Frames       int
+	MaxLateness  time.Duration
+	MeanLateness time.Duration
+}
+
+// Scheduler injects frames at their scheduled times with sub-millisecond
+// accuracy, for tests that are sensitive to timing. Deadlines are relative
+// to the start of a run rather than to the previous frame, so delays don't
+// accumulate.
+type Scheduler struct {
+	write func(events ...InputEvent) error
+	spin  time.Duration
+}
+
+// NewScheduler creates a Scheduler that injects frames with write, e.g. the
+// Write method of a UInputDevice.
+func NewScheduler(write func(events ...InputEvent) error) *Scheduler {
+	return &Scheduler{
+		write: write,
+		spin:  DefaultSpin,
+	}
+}
+
+// SetSpin sets how long before each deadline the scheduler busy-waits, see
+// SleepUntil. 0 disables busy-waiting.
+func (s *Scheduler) SetSpin(spin time.Duration) {
+	s.spin = spin
+}
+
+// Run injects frames at their times after now, which must be ordered. It
+// returns ctx.Err() if ctx is done before all frames were injected.
+func (s *Scheduler) Run(ctx context.Context, frames []ScheduledFrame) (ScheduleStats, error) {
+	stats := ScheduleStats{}
+	start := time.Now()
+	total := time.Duration(0)
+
+	for _, f := range frames {
+		deadline := start.Add(f.At)
+
+		if err := SleepUntil(ctx, deadline, s.spin); err != nil {
+			return stats, err
+		}
+
+		late := time.Since(deadline)
+
+		if err := s.write(f.Events...); err != nil {
+			return stats, err
+		}
+
+		stats.Frames++
+		total += late
+
+		if late > stats.MaxLateness {
+			stats.MaxLateness = late
+		}
+
+		stats.MeanLateness = total / time.Duration(stats.Frames)
+	}
+
+	return stats, nil
+}
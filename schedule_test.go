@@ -0,0 +1,66 @@
+package evdev
+
+import (
+	"context"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestScheduleFrames(t *testing.T) {
+	at := func(ms int64) syscall.Timeval { return syscall.NsecToTimeval(ms * int64(time.Millisecond)) }
+	a := []InputEvent{{Time: at(1000), Type: EV_KEY, Code: KEY_A, Value: 1}, {Time: at(1000), Type: EV_SYN}}
+	b := []InputEvent{{Time: at(1025), Type: EV_KEY, Code: KEY_A, Value: 0}, {Time: at(1025), Type: EV_SYN}}
+
+	want := []ScheduledFrame{{At: 0, Events: a}, {At: 25 * time.Millisecond, Events: b}}
+
+	for _, frames := range [][][]InputEvent{{a, {}, b}, {{}, a, b}} {
+		if got := ScheduleFrames(frames); !reflect.DeepEqual(got, want) {
+			t.Errorf("ScheduleFrames(%v) = %v, want %v", frames, got, want)
+		}
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	frames := []ScheduledFrame{}
+	for i := 0; i < 5; i++ {
+		frames = append(frames, ScheduledFrame{
+			At:     time.Duration(i) * 5 * time.Millisecond,
+			Events: []InputEvent{{Type: EV_REL, Code: REL_X, Value: int32(i)}},
+		})
+	}
+
+	start := time.Now()
+	times := []time.Duration{}
+
+	stats, err := NewScheduler(func(events ...InputEvent) error {
+		times = append(times, time.Since(start))
+		return nil
+	}).Run(context.Background(), frames)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if stats.Frames != len(frames) || len(times) != len(frames) {
+		t.Fatalf("Run() injected %d frames, want %d", stats.Frames, len(frames))
+	}
+
+	for i, d := range times {
+		if d < frames[i].At {
+			t.Errorf("frame %d injected after %v, before its time %v", i, d, frames[i].At)
+		}
+	}
+
+	// generous, as the test may run on a loaded machine
+	if stats.MaxLateness > 10*time.Millisecond || stats.MeanLateness > stats.MaxLateness {
+		t.Errorf("Run() stats = %+v", stats)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := NewScheduler(func(...InputEvent) error { return nil }).Run(ctx, frames); err != context.Canceled {
+		t.Errorf("Run() error = %v, want %v", err, context.Canceled)
+	}
+}
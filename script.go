@@ -0,0 +1,598 @@
+package evdev
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// scriptTypes are the event types whose code names can be used in scripts.
+var scriptTypes = []EvType{EV_SYN, EV_KEY, EV_REL, EV_ABS, EV_SW, EV_LED, EV_SND}
+
+// scriptStateTypes are the event types whose current values are available
+// through state().
+var scriptStateTypes = map[EvType]bool{
+	EV_KEY: true,
+	EV_ABS: true,
+	EV_SW:  true,
+	EV_LED: true,
+}
+
+type scriptKey struct {
+	t EvType
+	c EvCode
+}
+
+type scriptEnv struct {
+	e      *InputEvent
+	state  map[scriptKey]int32
+	layout int
+}
+
+type scriptExpr func(env *scriptEnv) int64
+
+type scriptAssignment struct {
+	field string
+	value scriptExpr
+}
+
+type scriptRule struct {
+	cond   scriptExpr
+	assign []scriptAssignment
+	drop   bool
+	source string // line number and text, for traces
+}
+
+// Script rewrites events according to rules written in a small expression
+// language, so remappings can be customized without recompiling. A script
+// consists of one rule per line (or separated by semicolons):
+//
+//	# Caps Lock acts as Escape
+//	type == EV_KEY && code == KEY_CAPSLOCK -> code = KEY_ESC
+//	# natural scrolling
+//	type == EV_REL && code == REL_WHEEL -> value = -value
+//	# no key repeat while Shift is held
+//	type == EV_KEY && value == 2 && state(KEY_LEFTSHIFT) -> drop
+//	# Y and Z swapped in the second layout only
+//	type == EV_KEY && layout == 1 && code == KEY_Y -> code = KEY_Z
+//
+// The condition left of -> is an expression over the fields type, code and
+// value of the event, the names of event types and codes, integer literals,
+// state(CODE), the current value of a key, switch, LED or absolute axis as
+// seen in the input stream, and layout, the index of the active keyboard
+// layout if one is tracked, otherwise 0. Supported operators are ||, &&, !,
+// ==, !=, <, <=, >, >=, +, -, *, / and %, with the precedence of Go.
+// Comparisons evaluate to 1 or 0, and any value other than 0 is true.
+// Division by zero yields 0.
+//
+// The action right of -> is either drop, which discards the event, or a
+// comma-separated list of assignments to type, code and value, whose
+// expressions are evaluated before any of them is assigned.
+//
+// Rules are applied in order, and each rule sees the event as rewritten by
+// the previous ones. Processing of an event stops once it is dropped.
+//
+// The active layout is tracked with TrackLayout or with a line such as
+//
+//	layout us,ru grp:alt_shift_toggle KEY_SWITCHVIDEOMODE
+//
+// which names the layouts, followed by the grp: toggle options of XKB and
+// the keys that select the next layout, see NewLayoutTracker.
+type Script struct {
+	rules  []*scriptRule
+	state  map[scriptKey]int32
+	layout *LayoutTracker
+}
+
+// ParseScript parses a script as described for Script.
+func ParseScript(src string) (*Script, error) {
+	s := &Script{
+		state: map[scriptKey]int32{},
+	}
+
+	for n, line := range strings.Split(src, "\n") {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+
+		for _, r := range strings.Split(line, ";") {
+			if strings.TrimSpace(r) == "" {
+				continue
+			}
+
+			if fields := strings.Fields(r); fields[0] == "layout" && !strings.Contains(r, "->") {
+				lt, err := parseLayoutLine(fields[1:])
+				if err != nil {
+					return nil, fmt.Errorf("Line %d: %v", n+1, err)
+				}
+
+				s.layout = lt
+
+				continue
+			}
+
+			rule, err := parseScriptRule(r)
+			if err != nil {
+				return nil, fmt.Errorf("Line %d: %v", n+1, err)
+			}
+
+			rule.source = fmt.Sprintf("line %d: %s", n+1, strings.TrimSpace(r))
+
+			s.rules = append(s.rules, rule)
+		}
+	}
+
+	return s, nil
+}
+
+// parseLayoutLine parses the arguments of a layout line: the layouts,
+// followed by toggle options and switch keys.
+func parseLayoutLine(args []string) (*LayoutTracker, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("Missing layouts")
+	}
+
+	options := []string{}
+	keys := []EvCode{}
+
+	for _, a := range args[1:] {
+		if _, ok := layoutToggles[a]; ok {
+			options = append(options, a)
+			continue
+		}
+
+		c, ok := CodeByName(EV_KEY, a)
+		if !ok {
+			return nil, fmt.Errorf("Unknown layout option or key %q", a)
+		}
+
+		keys = append(keys, c)
+	}
+
+	l := KeyboardLayout{Layout: args[0], Options: strings.Join(options, ","), Source: "script"}
+
+	return NewLayoutTracker(l, keys...), nil
+}
+
+// TrackLayout makes the script follow the active keyboard layout with lt,
+// which is fed with the events before they are rewritten.
+func (s *Script) TrackLayout(lt *LayoutTracker) {
+	s.layout = lt
+}
+
+// Process applies the script to an event. It returns false if the event is
+// dropped.
+func (s *Script) Process(e InputEvent) (InputEvent, bool) {
+	return s.process(e, nil)
+}
+
+// process applies the script to an event and calls matched, if not nil, for
+// each rule that applied.
+func (s *Script) process(e InputEvent, matched func(r *scriptRule)) (InputEvent, bool) {
+	if scriptStateTypes[e.Type] {
+		s.state[scriptKey{e.Type, e.Code}] = e.Value
+	}
+
+	env := &scriptEnv{e: &e, state: s.state}
+
+	if s.layout != nil {
+		s.layout.Push(e)
+		env.layout = s.layout.Group()
+	}
+
+	for _, r := range s.rules {
+		if r.cond(env) == 0 {
+			continue
+		}
+
+		if matched != nil {
+			matched(r)
+		}
+
+		if r.drop {
+			return e, false
+		}
+
+		values := make([]int64, len(r.assign))
+		for i, a := range r.assign {
+			values[i] = a.value(env)
+		}
+
+		for i, a := range r.assign {
+			switch a.field {
+			case "type":
+				e.Type = EvType(values[i])
+			case "code":
+				e.Code = EvCode(values[i])
+			case "value":
+				e.Value = int32(values[i])
+			}
+		}
+	}
+
+	return e, true
+}
+
+// ProcessFrame applies the script to all events of a frame and returns the
+// events that were not dropped.
+func (s *Script) ProcessFrame(frame []InputEvent) []InputEvent {
+	out := make([]InputEvent, 0, len(frame))
+
+	for _, e := range frame {
+		if e, ok := s.Process(e); ok {
+			out = append(out, e)
+		}
+	}
+
+	return out
+}
+
+// ProcessFrameTraced is like ProcessFrame, but also reports the rules that
+// applied to the events of the frame.
+func (s *Script) ProcessFrameTraced(frame []InputEvent) ([]InputEvent, []RuleMatch) {
+	out := make([]InputEvent, 0, len(frame))
+	matches := []RuleMatch{}
+
+	for _, in := range frame {
+		e, ok := s.process(in, func(r *scriptRule) {
+			matches = append(matches, RuleMatch{Event: in, Rule: r.source})
+		})
+
+		if ok {
+			out = append(out, e)
+		}
+	}
+
+	return out, matches
+}
+
+func parseScriptRule(src string) (*scriptRule, error) {
+	parts := strings.Split(src, "->")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("Expected exactly one -> in rule %q", strings.TrimSpace(src))
+	}
+
+	rule := &scriptRule{}
+
+	cond, err := parseScriptExpr(parts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	rule.cond = cond
+
+	if strings.TrimSpace(parts[1]) == "drop" {
+		rule.drop = true
+		return rule, nil
+	}
+
+	for _, a := range strings.Split(parts[1], ",") {
+		i := strings.Index(a, "=")
+		if i < 0 || strings.HasPrefix(a[i:], "==") {
+			return nil, fmt.Errorf("Expected assignment, got %q", strings.TrimSpace(a))
+		}
+
+		field := strings.TrimSpace(a[:i])
+		if field != "type" && field != "code" && field != "value" {
+			return nil, fmt.Errorf("Cannot assign to %q", field)
+		}
+
+		value, err := parseScriptExpr(a[i+1:])
+		if err != nil {
+			return nil, err
+		}
+
+		rule.assign = append(rule.assign, scriptAssignment{field: field, value: value})
+	}
+
+	return rule, nil
+}
+
+func parseScriptExpr(src string) (scriptExpr, error) {
+	tokens, err := tokenizeScript(src)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("Missing expression")
+	}
+
+	p := &scriptParser{tokens: tokens}
+
+	expr, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("Unexpected %q in expression", p.tokens[p.pos])
+	}
+
+	return expr, nil
+}
+
+// scriptOperators are the operators consisting of two characters.
+var scriptOperators = map[string]bool{
+	"&&": true,
+	"||": true,
+	"==": true,
+	"!=": true,
+	"<=": true,
+	">=": true,
+}
+
+func tokenizeScript(src string) ([]string, error) {
+	tokens := []string{}
+
+	for i := 0; i < len(src); {
+		c := src[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+
+		case i+1 < len(src) && scriptOperators[src[i:i+2]]:
+			tokens = append(tokens, src[i:i+2])
+			i += 2
+
+		case strings.IndexByte("!()<>+-*/%,", c) >= 0:
+			tokens = append(tokens, src[i:i+1])
+			i++
+
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9':
+			j := i
+			for ; j < len(src); j++ {
+				c := src[j]
+				if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+					break
+				}
+			}
+
+			tokens = append(tokens, src[i:j])
+			i = j
+
+		default:
+			return nil, fmt.Errorf("Unexpected character %q in expression", c)
+		}
+	}
+
+	return tokens, nil
+}
+
+// scriptPrecedence lists the binary operators from lowest to highest
+// precedence.
+var scriptPrecedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func scriptBool(b bool) int64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+func scriptBinary(op string, a, b scriptExpr) scriptExpr {
+	switch op {
+	case "||":
+		return func(env *scriptEnv) int64 { return scriptBool(a(env) != 0 || b(env) != 0) }
+	case "&&":
+		return func(env *scriptEnv) int64 { return scriptBool(a(env) != 0 && b(env) != 0) }
+	case "==":
+		return func(env *scriptEnv) int64 { return scriptBool(a(env) == b(env)) }
+	case "!=":
+		return func(env *scriptEnv) int64 { return scriptBool(a(env) != b(env)) }
+	case "<":
+		return func(env *scriptEnv) int64 { return scriptBool(a(env) < b(env)) }
+	case "<=":
+		return func(env *scriptEnv) int64 { return scriptBool(a(env) <= b(env)) }
+	case ">":
+		return func(env *scriptEnv) int64 { return scriptBool(a(env) > b(env)) }
+	case ">=":
+		return func(env *scriptEnv) int64 { return scriptBool(a(env) >= b(env)) }
+	case "+":
+		return func(env *scriptEnv) int64 { return a(env) + b(env) }
+	case "-":
+		return func(env *scriptEnv) int64 { return a(env) - b(env) }
+	case "*":
+		return func(env *scriptEnv) int64 { return a(env) * b(env) }
+	case "/":
+		return func(env *scriptEnv) int64 {
+			if d := b(env); d != 0 {
+				return a(env) / d
+			}
+			return 0
+		}
+	default:
+		return func(env *scriptEnv) int64 {
+			if d := b(env); d != 0 {
+				return a(env) % d
+			}
+			return 0
+		}
+	}
+}
+
+// maxScriptDepth limits the nesting of expressions, so scripts from
+// untrusted sources can't exhaust the stack.
+const maxScriptDepth = 64
+
+type scriptParser struct {
+	tokens []string
+	pos    int
+	depth  int
+}
+
+// enter descends into a nested expression. Call leave when done.
+func (p *scriptParser) enter() error {
+	p.depth++
+	if p.depth > maxScriptDepth {
+		return fmt.Errorf("Expression nested too deeply")
+	}
+
+	return nil
+}
+
+func (p *scriptParser) leave() {
+	p.depth--
+}
+
+func (p *scriptParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+
+	return ""
+}
+
+func (p *scriptParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *scriptParser) expect(t string) error {
+	if got := p.next(); got != t {
+		return fmt.Errorf("Expected %q in expression, got %q", t, got)
+	}
+
+	return nil
+}
+
+func (p *scriptParser) parseBinary(level int) (scriptExpr, error) {
+	if level == len(scriptPrecedence) {
+		return p.parseUnary()
+	}
+
+	expr, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op := p.peek()
+
+		found := false
+		for _, o := range scriptPrecedence[level] {
+			found = found || o == op
+		}
+
+		if !found {
+			return expr, nil
+		}
+
+		p.next()
+
+		rhs, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		expr = scriptBinary(op, expr, rhs)
+	}
+}
+
+func (p *scriptParser) parseUnary() (scriptExpr, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	switch p.peek() {
+	case "!":
+		p.next()
+
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return func(env *scriptEnv) int64 { return scriptBool(x(env) == 0) }, nil
+
+	case "-":
+		p.next()
+
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return func(env *scriptEnv) int64 { return -x(env) }, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *scriptParser) parsePrimary() (scriptExpr, error) {
+	t := p.next()
+
+	switch t {
+	case "":
+		return nil, fmt.Errorf("Unexpected end of expression")
+
+	case "(":
+		x, err := p.parseBinary(0)
+		if err != nil {
+			return nil, err
+		}
+
+		return x, p.expect(")")
+
+	case "type":
+		return func(env *scriptEnv) int64 { return int64(env.e.Type) }, nil
+
+	case "code":
+		return func(env *scriptEnv) int64 { return int64(env.e.Code) }, nil
+
+	case "value":
+		return func(env *scriptEnv) int64 { return int64(env.e.Value) }, nil
+
+	case "layout":
+		return func(env *scriptEnv) int64 { return int64(env.layout) }, nil
+
+	case "state":
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+
+		name := p.next()
+
+		k, ok := scriptCode(name)
+		if !ok || !scriptStateTypes[k.t] {
+			return nil, fmt.Errorf("No state for %q", name)
+		}
+
+		return func(env *scriptEnv) int64 { return int64(env.state[k]) }, p.expect(")")
+	}
+
+	if n, err := strconv.ParseInt(t, 0, 64); err == nil {
+		return func(env *scriptEnv) int64 { return n }, nil
+	}
+
+	if et, ok := TypeByName(t); ok {
+		return func(env *scriptEnv) int64 { return int64(et) }, nil
+	}
+
+	if k, ok := scriptCode(t); ok {
+		return func(env *scriptEnv) int64 { return int64(k.c) }, nil
+	}
+
+	return nil, fmt.Errorf("Unknown name %q in expression", t)
+}
+
+// scriptCode resolves the name of a code and the type it belongs to.
+func scriptCode(name string) (scriptKey, bool) {
+	for _, t := range scriptTypes {
+		if c, ok := CodeByName(t, name); ok {
+			return scriptKey{t, c}, true
+		}
+	}
+
+	return scriptKey{}, false
+}
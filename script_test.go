@@ -0,0 +1,127 @@
+package evdev
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScript(t *testing.T) {
+	src := `
+		# Caps Lock acts as Escape
+		type == EV_KEY && code == KEY_CAPSLOCK -> code = KEY_ESC
+		type == EV_REL && code == REL_WHEEL -> value = -value * 2
+		type == EV_KEY && value == 2 && state(KEY_LEFTSHIFT) -> drop
+		code == REL_X && type == EV_REL -> code = REL_Y, value = code ; value > 100 -> value = 100
+	`
+
+	s, err := ParseScript(src)
+	if err != nil {
+		t.Fatalf("ParseScript() error = %v", err)
+	}
+
+	tests := []struct {
+		in   InputEvent
+		want []InputEvent
+	}{
+		{
+			in:   InputEvent{Type: EV_KEY, Code: KEY_CAPSLOCK, Value: 1},
+			want: []InputEvent{{Type: EV_KEY, Code: KEY_ESC, Value: 1}},
+		},
+		{
+			in:   InputEvent{Type: EV_REL, Code: REL_WHEEL, Value: 1},
+			want: []InputEvent{{Type: EV_REL, Code: REL_WHEEL, Value: -2}},
+		},
+		{
+			in:   InputEvent{Type: EV_KEY, Code: KEY_A, Value: 2},
+			want: []InputEvent{{Type: EV_KEY, Code: KEY_A, Value: 2}},
+		},
+		{
+			in:   InputEvent{Type: EV_KEY, Code: KEY_LEFTSHIFT, Value: 1},
+			want: []InputEvent{{Type: EV_KEY, Code: KEY_LEFTSHIFT, Value: 1}},
+		},
+		{
+			in:   InputEvent{Type: EV_KEY, Code: KEY_A, Value: 2},
+			want: []InputEvent{},
+		},
+		{
+			// the assignments see the event as it was before the rule
+			in:   InputEvent{Type: EV_REL, Code: REL_X, Value: 5},
+			want: []InputEvent{{Type: EV_REL, Code: REL_Y, Value: int32(REL_X)}},
+		},
+		{
+			in:   InputEvent{Type: EV_ABS, Code: ABS_X, Value: 500},
+			want: []InputEvent{{Type: EV_ABS, Code: ABS_X, Value: 100}},
+		},
+	}
+	for _, tt := range tests {
+		if got := s.ProcessFrame([]InputEvent{tt.in}); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ProcessFrame(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseScript_Errors(t *testing.T) {
+	for _, src := range []string{
+		"type == EV_KEY",
+		"type == EV_KEY -> name = 1",
+		"type == EV_KEY -> code == 1",
+		"type == KEY_NOPE -> drop",
+		"(type == EV_KEY -> drop",
+		"state(REL_X) -> drop",
+		"type == EV_KEY $ 1 -> drop",
+		"-> drop",
+		strings.Repeat("(", 100) + "1" + strings.Repeat(")", 100) + " -> drop",
+		strings.Repeat("!", 100) + "1 -> drop",
+		"layout",
+		"layout us,de grp:nope",
+	} {
+		if _, err := ParseScript(src); err == nil {
+			t.Errorf("ParseScript(%q) succeeded", src)
+		}
+	}
+}
+
+func TestScript_Layout(t *testing.T) {
+	const rule = "type == EV_KEY && layout == 1 && code == KEY_Y -> code = KEY_Z"
+
+	s, err := ParseScript(rule)
+	if err != nil {
+		t.Fatalf("ParseScript() error = %v", err)
+	}
+
+	s.TrackLayout(NewLayoutTracker(KeyboardLayout{Layout: "us,de"}))
+
+	// the layout line makes tracking available to configurations
+	configured, err := NewTransformChain([]TransformSpec{
+		{Name: "script", Config: "layout us,de grp:alt_shift_toggle KEY_SWITCHVIDEOMODE\n" + rule},
+	})
+	if err != nil {
+		t.Fatalf("NewTransformChain() error = %v", err)
+	}
+
+	tests := []struct {
+		in   InputEvent
+		want []InputEvent
+	}{
+		{
+			in:   InputEvent{Type: EV_KEY, Code: KEY_Y, Value: 1},
+			want: []InputEvent{{Type: EV_KEY, Code: KEY_Y, Value: 1}},
+		},
+		{
+			in:   InputEvent{Type: EV_KEY, Code: KEY_KBD_LAYOUT_NEXT, Value: 1},
+			want: []InputEvent{{Type: EV_KEY, Code: KEY_KBD_LAYOUT_NEXT, Value: 1}},
+		},
+		{
+			in:   InputEvent{Type: EV_KEY, Code: KEY_Y, Value: 1},
+			want: []InputEvent{{Type: EV_KEY, Code: KEY_Z, Value: 1}},
+		},
+	}
+	for _, tr := range []Transform{s, configured} {
+		for _, tt := range tests {
+			if got := tr.ProcessFrame([]InputEvent{tt.in}); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ProcessFrame(%v) = %v, want %v", tt.in, got, tt.want)
+			}
+		}
+	}
+}
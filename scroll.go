@@ -0,0 +1,210 @@
+package evdev
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// hiResPerDetent is the REL_WHEEL_HI_RES value of one wheel detent, as
+// defined by the kernel.
+const hiResPerDetent = 120
+
+// ScrollSettings configure a ScrollNormalizer. Some mice report a different
+// number of high-resolution counts per detent than the kernel's 120, or
+// scroll too fast in one direction.
+type ScrollSettings struct {
+	CountsPerDetent int32   `json:"counts_per_detent"` // REL_*WHEEL_HI_RES counts of one detent, 120 if 0
+	Vertical        float64 `json:"vertical"`          // ratio applied to vertical scrolling, 1 if 0
+	Horizontal      float64 `json:"horizontal"`        // ratio applied to horizontal scrolling, 1 if 0
+}
+
+// DefaultScrollSettings returns settings that pass scrolling on unchanged.
+func DefaultScrollSettings() ScrollSettings {
+	return ScrollSettings{CountsPerDetent: hiResPerDetent, Vertical: 1, Horizontal: 1}
+}
+
+type scrollAxis struct {
+	lowRes, hiRes EvCode
+	ratio         float64
+	hasHiRes      bool    // the device reported high-resolution events
+	rest          float64 // fractional high-resolution counts not reported yet
+	detents       int32   // high-resolution counts towards the next detent
+}
+
+// ScrollNormalizer is a Transform that rescales the wheels of a device by
+// its ScrollSettings and reports both REL_WHEEL and REL_WHEEL_HI_RES (and
+// their horizontal counterparts) consistently in the kernel's units. Once a
+// device reported a high-resolution event for a wheel, its low-resolution
+// events are replaced by detents derived from the high-resolution ones.
+type ScrollNormalizer struct {
+	settings ScrollSettings
+	axes     [2]*scrollAxis
+}
+
+// NewScrollNormalizer creates a ScrollNormalizer.
+func NewScrollNormalizer(settings ScrollSettings) *ScrollNormalizer {
+	if settings.CountsPerDetent <= 0 {
+		settings.CountsPerDetent = hiResPerDetent
+	}
+
+	// settings stored without ratios leave scrolling unchanged
+	if settings.Vertical == 0 {
+		settings.Vertical = 1
+	}
+
+	if settings.Horizontal == 0 {
+		settings.Horizontal = 1
+	}
+
+	return &ScrollNormalizer{
+		settings: settings,
+		axes: [2]*scrollAxis{
+			{lowRes: REL_WHEEL, hiRes: REL_WHEEL_HI_RES, ratio: settings.Vertical},
+			{lowRes: REL_HWHEEL, hiRes: REL_HWHEEL_HI_RES, ratio: settings.Horizontal},
+		},
+	}
+}
+
+// ProcessFrame implements Transform.
+func (sn *ScrollNormalizer) ProcessFrame(frame []InputEvent) []InputEvent {
+	last := frame[len(frame)-1]
+
+	if last.Type == EV_SYN && last.Code == SYN_DROPPED {
+		for _, a := range sn.axes {
+			a.rest, a.detents = 0, 0
+		}
+
+		return frame
+	}
+
+	var lowRes, hiRes [2]int32
+	var hasLowRes, hasHiRes [2]bool
+	out := make([]InputEvent, 0, len(frame))
+
+	for _, e := range frame {
+		i := -1
+		for j, a := range sn.axes {
+			if e.Type == EV_REL && (e.Code == a.lowRes || e.Code == a.hiRes) {
+				i = j
+			}
+		}
+
+		switch {
+		case i < 0:
+			if e.Type != EV_SYN {
+				out = append(out, e)
+			}
+		case e.Code == sn.axes[i].hiRes:
+			hiRes[i] += e.Value
+			hasHiRes[i] = true
+		default:
+			lowRes[i] += e.Value
+			hasLowRes[i] = true
+		}
+	}
+
+	for i, a := range sn.axes {
+		if hasHiRes[i] {
+			a.hasHiRes = true
+		}
+
+		var counts float64
+
+		switch {
+		case hasHiRes[i]:
+			counts = float64(hiRes[i]) * hiResPerDetent / float64(sn.settings.CountsPerDetent)
+		case hasLowRes[i] && !a.hasHiRes:
+			counts = float64(lowRes[i]) * hiResPerDetent
+		default:
+			continue
+		}
+
+		a.rest += counts * a.ratio
+		v := math.Round(a.rest)
+		a.rest -= v
+
+		if v == 0 {
+			continue
+		}
+
+		out = append(out, InputEvent{Time: last.Time, Type: EV_REL, Code: a.hiRes, Value: int32(v)})
+
+		a.detents += int32(v)
+		if d := a.detents / hiResPerDetent; d != 0 {
+			a.detents -= d * hiResPerDetent
+			out = append(out, InputEvent{Time: last.Time, Type: EV_REL, Code: a.lowRes, Value: d})
+		}
+	}
+
+	if len(out) == 0 {
+		return nil
+	}
+
+	return append(out, last)
+}
+
+// MapCapabilities implements CapabilityMapper. Wheels are reported in both
+// resolutions.
+func (sn *ScrollNormalizer) MapCapabilities(in Capabilities) Capabilities {
+	for _, a := range sn.axes {
+		if containsCode(in.Codes[EV_REL], a.lowRes) || containsCode(in.Codes[EV_REL], a.hiRes) {
+			in = withCodes(in, EV_REL, a.lowRes, a.hiRes)
+		}
+	}
+
+	return in
+}
+
+// ParseScrollSettings parses scroll settings of space separated parameters,
+// e.g. "detent=240 vertical=0.5 horizontal=2". Parameters that are not given
+// keep their defaults.
+func ParseScrollSettings(config string) (ScrollSettings, error) {
+	s := DefaultScrollSettings()
+
+	for _, param := range strings.Fields(config) {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) != 2 {
+			return s, fmt.Errorf("Unknown parameter %q for scroll", param)
+		}
+
+		switch kv[0] {
+		case "detent":
+			v, err := strconv.ParseInt(kv[1], 10, 32)
+			if err != nil || v <= 0 {
+				return s, fmt.Errorf("Invalid value for detent: %q", kv[1])
+			}
+
+			s.CountsPerDetent = int32(v)
+
+		case "vertical", "horizontal":
+			v, err := strconv.ParseFloat(kv[1], 64)
+			if err != nil || v == 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+				return s, fmt.Errorf("Invalid value for %s: %q", kv[0], kv[1])
+			}
+
+			if kv[0] == "vertical" {
+				s.Vertical = v
+			} else {
+				s.Horizontal = v
+			}
+
+		default:
+			return s, fmt.Errorf("Unknown parameter %q for scroll", param)
+		}
+	}
+
+	return s, nil
+}
+
+func init() {
+	RegisterTransform("scroll", func(config string) (Transform, error) {
+		s, err := ParseScrollSettings(config)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewScrollNormalizer(s), nil
+	})
+}
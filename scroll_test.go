@@ -0,0 +1,79 @@
+package evdev
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScrollNormalizer(t *testing.T) {
+	rel := func(c EvCode, v int32) InputEvent { return InputEvent{Type: EV_REL, Code: c, Value: v} }
+
+	tests := []struct {
+		name   string
+		config string
+		in     [][]InputEvent
+		want   [][]InputEvent
+	}{
+		{
+			name:   "defaults",
+			config: "",
+			in: [][]InputEvent{
+				{rel(REL_WHEEL, 1), rel(REL_WHEEL_HI_RES, 120), synReport},
+				{rel(REL_X, 3), synReport},
+			},
+			want: [][]InputEvent{
+				{rel(REL_WHEEL_HI_RES, 120), rel(REL_WHEEL, 1), synReport},
+				{rel(REL_X, 3), synReport},
+			},
+		},
+		{
+			name:   "counts per detent",
+			config: "detent=240",
+			in: [][]InputEvent{
+				{rel(REL_WHEEL_HI_RES, 120), synReport},
+				{rel(REL_WHEEL, 1), rel(REL_WHEEL_HI_RES, 120), synReport},
+				{rel(REL_WHEEL_HI_RES, -360), synReport},
+			},
+			want: [][]InputEvent{
+				{rel(REL_WHEEL_HI_RES, 60), synReport},
+				{rel(REL_WHEEL_HI_RES, 60), rel(REL_WHEEL, 1), synReport},
+				{rel(REL_WHEEL_HI_RES, -180), rel(REL_WHEEL, -1), synReport},
+			},
+		},
+		{
+			name:   "low resolution ratios",
+			config: "vertical=0.5 horizontal=-1",
+			in: [][]InputEvent{
+				{rel(REL_HWHEEL, 1), synReport},
+				{rel(REL_WHEEL, 1), synReport},
+				{rel(REL_WHEEL, 1), synReport},
+			},
+			want: [][]InputEvent{
+				{rel(REL_HWHEEL_HI_RES, -120), rel(REL_HWHEEL, -1), synReport},
+				{rel(REL_WHEEL_HI_RES, 60), synReport},
+				{rel(REL_WHEEL_HI_RES, 60), rel(REL_WHEEL, 1), synReport},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		s, err := ParseScrollSettings(tt.config)
+		if err != nil {
+			t.Fatalf("%s: ParseScrollSettings() failed: %v", tt.name, err)
+		}
+
+		sn := NewScrollNormalizer(s)
+
+		for i, frame := range tt.in {
+			if got := sn.ProcessFrame(frame); !reflect.DeepEqual(got, tt.want[i]) {
+				t.Errorf("%s: frame %d = %v, want %v", tt.name, i, got, tt.want[i])
+			}
+		}
+	}
+
+	for _, config := range []string{"detent=0", "vertical=x", "vertical=0", "horizontal=NaN", "angle=10", "detent"} {
+		if _, err := ParseScrollSettings(config); err == nil {
+			t.Errorf("ParseScrollSettings(%q) succeeded", config)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package evdev
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// DefaultSeat is the seat of devices that are not assigned to a seat.
+const DefaultSeat = "seat0"
+
+// udevDataDir is where udev keeps its database. Tests point it to a fake
+// directory.
+var udevDataDir = "/run/udev/data"
+
+// Seat returns the seat the device is assigned to by the ID_SEAT property
+// in the udev database, or DefaultSeat if it isn't assigned to one.
+func (d *InputDevice) Seat() (string, error) {
+	st := syscall.Stat_t{}
+
+	if err := syscall.Fstat(int(d.fd()), &st); err != nil {
+		return "", fmt.Errorf("Cannot stat device node: %v", err)
+	}
+
+	major, minor := devNumbers(uint64(st.Rdev))
+
+	return seatOf(major, minor)
+}
+
+func seatOf(major, minor uint32) (string, error) {
+	f, err := os.Open(filepath.Join(udevDataDir, fmt.Sprintf("c%d:%d", major, minor)))
+	if os.IsNotExist(err) {
+		return DefaultSeat, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("Cannot read udev database: %v", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if seat := strings.TrimPrefix(scanner.Text(), "E:ID_SEAT="); seat != scanner.Text() && seat != "" {
+			return seat, nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("Cannot read udev database: %v", err)
+	}
+
+	return DefaultSeat, nil
+}
+
+// GroupBySeat groups devices by the seat they are assigned to. Devices whose
+// seat cannot be determined are assigned to DefaultSeat.
+func GroupBySeat(devices []*InputDevice) map[string][]*InputDevice {
+	groups := map[string][]*InputDevice{}
+
+	for _, d := range devices {
+		seat, err := d.Seat()
+		if err != nil {
+			seat = DefaultSeat
+		}
+
+		groups[seat] = append(groups[seat], d)
+	}
+
+	return groups
+}
+
+// Seats returns the names of the seats in groups, as returned by
+// GroupBySeat, in order.
+func Seats(groups map[string][]*InputDevice) []string {
+	seats := make([]string, 0, len(groups))
+	for seat := range groups {
+		seats = append(seats, seat)
+	}
+
+	sort.Strings(seats)
+
+	return seats
+}
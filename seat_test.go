@@ -0,0 +1,53 @@
+package evdev
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSeatOf(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	defer func(old string) { udevDataDir = old }(udevDataDir)
+	udevDataDir = dir
+
+	files := map[string]string{
+		"c13:64": "S:input/by-path/platform-i8042-serio-0-event-kbd\nE:ID_INPUT=1\nG:seat\n",
+		"c13:65": "E:ID_INPUT=1\nE:ID_SEAT=seat1\nG:seat\n",
+		"c13:66": "E:ID_SEAT=\n",
+	}
+
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		minor uint32
+		want  string
+	}{
+		{64, DefaultSeat},
+		{65, "seat1"},
+		{66, DefaultSeat},
+		{67, DefaultSeat}, // not in the database
+	}
+
+	for _, tt := range tests {
+		got, err := seatOf(13, tt.minor)
+		if err != nil {
+			t.Errorf("seatOf(13, %d) error = %v", tt.minor, err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("seatOf(13, %d) = %q, want %q", tt.minor, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,366 @@
+package evdev
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// deviceFingerprint returns the fingerprint of a device with the given
+// identity, see Fingerprint.
+func deviceFingerprint(id InputID, name, uniq string) string {
+	h := sha256.Sum256([]byte(name + "\x00" + uniq))
+	return fmt.Sprintf("%04x-%04x-%04x-%x", id.BusType, id.Vendor, id.Product, h[:4])
+}
+
+// Fingerprint returns an identifier of the device that stays the same across
+// reboots and when it is plugged into another port, derived from its bus,
+// vendor and product IDs, name and unique ID. Devices without a unique ID,
+// which is common, share the fingerprint with other devices of the same
+// model.
+func (d *InputDevice) Fingerprint() (string, error) {
+	id, err := d.InputID()
+	if err != nil {
+		return "", err
+	}
+
+	name, err := d.Name()
+	if err != nil {
+		return "", err
+	}
+
+	// many devices have no unique ID, in which case the ioctl fails
+	uniq, _ := d.UniqueID()
+
+	return deviceFingerprint(id, name, uniq), nil
+}
+
+// DeviceSettings are the preferences stored for a device. Axes are keyed by
+// the names of their codes, e.g. "ABS_X".
+type DeviceSettings struct {
+	Name        string                     `json:"name,omitempty"`        // the device's name, for people reading the files
+	Calibration map[string]AbsInfo         `json:"calibration,omitempty"` // axis ranges, see Apply
+	Deadzones   map[string]int32           `json:"deadzones,omitempty"`   // flat values of axes, see Apply
+	Scroll      *ScrollSettings            `json:"scroll,omitempty"`
+	Transforms  []TransformSpec            `json:"transforms,omitempty"` // e.g. remappings
+	Extra       map[string]json.RawMessage `json:"extra,omitempty"`      // settings of other packages and programs
+}
+
+// Apply sets the calibration and deadzones of the axes of d. The current
+// values of the axes are kept.
+func (s *DeviceSettings) Apply(d *InputDevice) error {
+	if len(s.Calibration) == 0 && len(s.Deadzones) == 0 {
+		return nil
+	}
+
+	infos, err := d.AbsInfos()
+	if err != nil {
+		return err
+	}
+
+	changed := map[EvCode]AbsInfo{}
+
+	for name, cal := range s.Calibration {
+		c, ok := CodeByName(EV_ABS, name)
+		if !ok {
+			return fmt.Errorf("Unknown axis %q", name)
+		}
+
+		if _, ok := infos[c]; !ok {
+			continue
+		}
+
+		cal.Value = infos[c].Value
+		changed[c] = cal
+	}
+
+	for name, flat := range s.Deadzones {
+		c, ok := CodeByName(EV_ABS, name)
+		if !ok {
+			return fmt.Errorf("Unknown axis %q", name)
+		}
+
+		info, ok := changed[c]
+		if !ok {
+			if info, ok = infos[c]; !ok {
+				continue
+			}
+		}
+
+		info.Flat = flat
+		changed[c] = info
+	}
+
+	for c, info := range changed {
+		if err := d.SetAbsInfo(c, info); err != nil {
+			return fmt.Errorf("Cannot set %s: %v", CodeName(EV_ABS, c), err)
+		}
+	}
+
+	return nil
+}
+
+// Transform returns the transforms configured by the settings: the scroll
+// settings followed by Transforms.
+func (s *DeviceSettings) Transform() (Transform, error) {
+	ts := []Transform{}
+
+	if s.Scroll != nil {
+		ts = append(ts, NewScrollNormalizer(*s.Scroll))
+	}
+
+	t, err := NewTransformChain(s.Transforms)
+	if err != nil {
+		return nil, err
+	}
+
+	return Chain(append(ts, t)...), nil
+}
+
+// SettingsHook is called with the fingerprint and the settings of a device
+// when they are loaded or saved. Returning an error aborts the operation.
+type SettingsHook func(fingerprint string, s *DeviceSettings) error
+
+// SettingsStore persists DeviceSettings as one JSON file per device
+// fingerprint in a directory, so daemons keep their preferences for each
+// device across restarts.
+type SettingsStore struct {
+	dir string
+
+	mu     sync.Mutex
+	onLoad []SettingsHook
+	onSave []SettingsHook
+}
+
+// NewSettingsStore creates a SettingsStore in dir, which is created when
+// settings are saved.
+func NewSettingsStore(dir string) *SettingsStore {
+	return &SettingsStore{dir: dir}
+}
+
+// DefaultSettingsDir returns the directory settings are stored in by
+// default, go-evdev/devices in the user's configuration directory.
+func DefaultSettingsDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "go-evdev", "devices"), nil
+}
+
+// OnLoad adds a hook called after settings were loaded, e.g. to migrate or
+// validate them.
+func (st *SettingsStore) OnLoad(h SettingsHook) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.onLoad = append(st.onLoad, h)
+}
+
+// OnSave adds a hook called before settings are saved.
+func (st *SettingsStore) OnSave(h SettingsHook) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.onSave = append(st.onSave, h)
+}
+
+func (st *SettingsStore) runHooks(hooks *[]SettingsHook, fingerprint string, s *DeviceSettings) error {
+	st.mu.Lock()
+	hs := append([]SettingsHook{}, *hooks...)
+	st.mu.Unlock()
+
+	for _, h := range hs {
+		if err := h(fingerprint, s); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (st *SettingsStore) path(fingerprint string) (string, error) {
+	if fingerprint == "" || strings.ContainsAny(fingerprint, `/\`) || strings.HasPrefix(fingerprint, ".") {
+		return "", fmt.Errorf("Invalid fingerprint %q", fingerprint)
+	}
+
+	return filepath.Join(st.dir, fingerprint+".json"), nil
+}
+
+// Load returns the settings stored for a fingerprint, or empty settings if
+// there are none.
+func (st *SettingsStore) Load(fingerprint string) (*DeviceSettings, error) {
+	path, err := st.path(fingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &DeviceSettings{}
+
+	b, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(b, s); err != nil {
+			return nil, fmt.Errorf("Cannot parse %s: %v", path, err)
+		}
+	}
+
+	if err := st.runHooks(&st.onLoad, fingerprint, s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Save stores the settings for a fingerprint. The file is replaced
+// atomically, so readers never see partially written settings.
+func (st *SettingsStore) Save(fingerprint string, s *DeviceSettings) error {
+	path, err := st.path(fingerprint)
+	if err != nil {
+		return err
+	}
+
+	if err := st.runHooks(&st.onSave, fingerprint, s); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(st.dir, 0755); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(st.dir, "."+fingerprint)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(append(b, '\n'))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("Cannot save %s: %v", path, err)
+	}
+
+	return nil
+}
+
+// Delete removes the settings stored for a fingerprint.
+func (st *SettingsStore) Delete(fingerprint string) error {
+	path, err := st.path(fingerprint)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// Fingerprints returns the fingerprints settings are stored for, sorted.
+func (st *SettingsStore) Fingerprints() ([]string, error) {
+	files, err := ioutil.ReadDir(st.dir)
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	fingerprints := []string{}
+
+	for _, f := range files {
+		name := f.Name()
+		if !f.IsDir() && strings.HasSuffix(name, ".json") && !strings.HasPrefix(name, ".") {
+			fingerprints = append(fingerprints, strings.TrimSuffix(name, ".json"))
+		}
+	}
+
+	sort.Strings(fingerprints)
+
+	return fingerprints, nil
+}
+
+// ApplyFor loads the settings stored for d, applies them to d and returns
+// the transforms they configure.
+func (st *SettingsStore) ApplyFor(d *InputDevice) (Transform, error) {
+	s, err := st.LoadFor(d)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot load settings: %v", err)
+	}
+
+	if err := s.Apply(d); err != nil {
+		return nil, fmt.Errorf("Cannot apply settings: %v", err)
+	}
+
+	t, err := s.Transform()
+	if err != nil {
+		return nil, fmt.Errorf("Invalid settings: %v", err)
+	}
+
+	return t, nil
+}
+
+var (
+	settingsMu sync.RWMutex
+
+	// settingsStore holds the settings the wrappers apply, if set
+	settingsStore *SettingsStore
+)
+
+// UseSettingsStore makes the wrappers reading from a device, i.e.
+// FrameReader, Buttons, IMU and the MTTracker of NewMTTrackerFor, apply the
+// settings stored for it in st, see ApplyFor. Its events pass through the
+// transforms of the settings after its quirks. Passing nil stops applying
+// settings. Wrappers created before are not affected.
+func UseSettingsStore(st *SettingsStore) {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+
+	settingsStore = st
+}
+
+// LoadFor returns the settings stored for d.
+func (st *SettingsStore) LoadFor(d *InputDevice) (*DeviceSettings, error) {
+	fingerprint, err := d.Fingerprint()
+	if err != nil {
+		return nil, err
+	}
+
+	return st.Load(fingerprint)
+}
+
+// SaveFor stores the settings for d, recording its name in them.
+func (st *SettingsStore) SaveFor(d *InputDevice, s *DeviceSettings) error {
+	fingerprint, err := d.Fingerprint()
+	if err != nil {
+		return err
+	}
+
+	if name, err := d.Name(); err == nil {
+		s.Name = name
+	}
+
+	return st.Save(fingerprint, s)
+}
@@ -0,0 +1,154 @@
+package evdev
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDeviceFingerprint(t *testing.T) {
+	id := InputID{BusType: 0x03, Vendor: 0x046d, Product: 0xc52b, Version: 0x111}
+
+	a := deviceFingerprint(id, "Logitech USB Receiver", "")
+	if a != deviceFingerprint(id, "Logitech USB Receiver", "") {
+		t.Errorf("deviceFingerprint() is not stable")
+	}
+
+	if a[:15] != "0003-046d-c52b-" || len(a) != 23 {
+		t.Errorf("deviceFingerprint() = %q, want 0003-046d-c52b- and a hash", a)
+	}
+
+	if a == deviceFingerprint(id, "Logitech USB Receiver", "serial") {
+		t.Errorf("deviceFingerprint() ignores the unique ID")
+	}
+}
+
+func TestSettingsStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "evdev-settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	st := NewSettingsStore(filepath.Join(dir, "devices"))
+
+	if fps, err := st.Fingerprints(); err != nil || len(fps) != 0 {
+		t.Errorf("Fingerprints() of a missing directory = %v, %v, want none", fps, err)
+	}
+
+	s, err := st.Load("0003-046d-c52b-01020304")
+	if err != nil || !reflect.DeepEqual(s, &DeviceSettings{}) {
+		t.Errorf("Load() of missing settings = %+v, %v, want empty settings", s, err)
+	}
+
+	scroll := DefaultScrollSettings()
+	scroll.CountsPerDetent = 240
+
+	want := &DeviceSettings{
+		Name:        "mouse",
+		Calibration: map[string]AbsInfo{"ABS_X": {Minimum: -100, Maximum: 100}},
+		Deadzones:   map[string]int32{"ABS_X": 8},
+		Scroll:      &scroll,
+		Transforms:  []TransformSpec{{Name: "script", Config: "type == EV_KEY && code == BTN_SIDE -> code = BTN_MIDDLE"}},
+	}
+
+	saved := 0
+	st.OnSave(func(fingerprint string, s *DeviceSettings) error {
+		saved++
+		return nil
+	})
+
+	if err := st.Save("0003-046d-c52b-01020304", want); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	if saved != 1 {
+		t.Errorf("OnSave hook called %d times, want 1", saved)
+	}
+
+	if s, err := st.Load("0003-046d-c52b-01020304"); err != nil || !reflect.DeepEqual(s, want) {
+		t.Errorf("Load() = %+v, %v, want %+v", s, err, want)
+	}
+
+	if tr, err := want.Transform(); err != nil {
+		t.Errorf("Transform() failed: %v", err)
+	} else if out := tr.ProcessFrame([]InputEvent{{Type: EV_KEY, Code: BTN_SIDE, Value: 1}, synReport}); out[0].Code != BTN_MIDDLE {
+		t.Errorf("Transform() does not apply the transforms: %v", out)
+	}
+
+	if fps, err := st.Fingerprints(); err != nil || !reflect.DeepEqual(fps, []string{"0003-046d-c52b-01020304"}) {
+		t.Errorf("Fingerprints() = %v, %v", fps, err)
+	}
+
+	st.OnLoad(func(fingerprint string, s *DeviceSettings) error {
+		return errors.New("rejected")
+	})
+
+	if _, err := st.Load("0003-046d-c52b-01020304"); err == nil {
+		t.Errorf("Load() succeeded although a hook failed")
+	}
+
+	if err := st.Delete("0003-046d-c52b-01020304"); err != nil {
+		t.Errorf("Delete() failed: %v", err)
+	}
+
+	for _, fp := range []string{"", "../x", "a/b", ".hidden"} {
+		if _, err := st.Load(fp); err == nil {
+			t.Errorf("Load(%q) succeeded", fp)
+		}
+	}
+}
+
+func TestDeviceSettings_ScrollDefaults(t *testing.T) {
+	s := DeviceSettings{}
+	if err := json.Unmarshal([]byte(`{"scroll":{"counts_per_detent":240}}`), &s); err != nil {
+		t.Fatal(err)
+	}
+
+	tr, err := s.Transform()
+	if err != nil {
+		t.Fatalf("Transform() failed: %v", err)
+	}
+
+	// two half detents make one detent at the default ratio
+	got := []InputEvent{}
+	for i := 0; i < 2; i++ {
+		got = append(got, tr.ProcessFrame([]InputEvent{{Type: EV_REL, Code: REL_WHEEL_HI_RES, Value: 120}, synReport})...)
+	}
+
+	want := []InputEvent{
+		{Type: EV_REL, Code: REL_WHEEL_HI_RES, Value: 60}, synReport,
+		{Type: EV_REL, Code: REL_WHEEL_HI_RES, Value: 60}, {Type: EV_REL, Code: REL_WHEEL, Value: 1}, synReport,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ProcessFrame() = %v, want %v", got, want)
+	}
+}
+
+func TestUseSettingsStore(t *testing.T) {
+	d, w := pipeDevice(t)
+	defer d.file.Close()
+	defer w.Close()
+
+	events := []InputEvent{{Type: EV_KEY, Code: KEY_A, Value: 1}, synReport}
+	if _, err := w.Write(eventBytes(events)); err != nil {
+		t.Fatalf("Cannot write events: %v", err)
+	}
+
+	// a pipe has no fingerprint, so its settings cannot be loaded
+	UseSettingsStore(NewSettingsStore("nonexistent"))
+
+	if _, err := NewFrameReader(d, FramePassThrough).ReadFrame(); err == nil {
+		t.Errorf("ReadFrame() without fingerprint succeeded, want error")
+	}
+
+	UseSettingsStore(nil)
+
+	if got, err := NewFrameReader(d, FramePassThrough).ReadFrame(); err != nil || !reflect.DeepEqual(got, events) {
+		t.Errorf("ReadFrame() = %v, %v, want %v", got, err, events)
+	}
+}
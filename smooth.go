@@ -0,0 +1,277 @@
+package evdev
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SmoothingKind selects the filter of a Smoothing.
+type SmoothingKind int
+
+const (
+	// SmoothEMA is an exponential moving average with a fixed weight.
+	SmoothEMA SmoothingKind = iota
+	// SmoothOneEuro is the 1€ filter, which smooths strongly at low speeds
+	// to remove jitter and less at high speeds to keep the lag low.
+	SmoothOneEuro
+)
+
+// Smoothing configures the filter applied to the events of a type, and
+// optionally only some of its codes.
+type Smoothing struct {
+	Type  EvType
+	Codes []EvCode // all codes of Type if empty
+	Kind  SmoothingKind
+
+	// weight of a new value for SmoothEMA, from 0 exclusive to 1, where 1
+	// disables smoothing
+	Alpha float64
+
+	// parameters of SmoothOneEuro: the cutoff frequency in Hz at low speeds,
+	// how fast it increases with the speed, and the cutoff frequency used to
+	// smooth the speed itself, which defaults to 1 Hz
+	MinCutoff, Beta, DCutoff float64
+}
+
+type smoothKey struct {
+	t EvType
+	c EvCode
+}
+
+type smoothState struct {
+	value    float64 // filtered value
+	speed    float64 // filtered speed, for SmoothOneEuro
+	time     time.Time
+	reported int32   // last reported absolute value
+	rest     float64 // relative motion not reported yet due to rounding
+}
+
+// Smoother is a Transform that smooths the values of EV_ABS and EV_REL
+// events, e.g. of jittery touchscreens or cheap joysticks. Absolute values
+// are filtered directly and only reported when their rounded value changes.
+// Relative values are filtered as a sequence of their own, keeping the
+// motion lost to rounding for the next event.
+//
+// Events of other types and codes pass through unchanged, as do multitouch
+// axes, whose values depend on the slot. The filters are reset after
+// SYN_DROPPED.
+type Smoother struct {
+	settings []Smoothing
+	codes    []map[EvCode]bool
+	states   map[smoothKey]*smoothState
+}
+
+// NewSmoother creates a Smoother with the given settings. The first matching
+// setting applies to an event.
+func NewSmoother(settings ...Smoothing) *Smoother {
+	s := &Smoother{
+		settings: settings,
+		codes:    make([]map[EvCode]bool, len(settings)),
+		states:   map[smoothKey]*smoothState{},
+	}
+
+	for i, st := range settings {
+		if len(st.Codes) == 0 {
+			continue
+		}
+
+		s.codes[i] = map[EvCode]bool{}
+		for _, c := range st.Codes {
+			s.codes[i][c] = true
+		}
+	}
+
+	return s
+}
+
+func (s *Smoother) settingOf(e *InputEvent) *Smoothing {
+	if e.Type != EV_ABS && e.Type != EV_REL || e.Type == EV_ABS && (e.Code == ABS_MT_SLOT || isMTAxis(e.Code)) {
+		return nil
+	}
+
+	for i := range s.settings {
+		if s.settings[i].Type == e.Type && (s.codes[i] == nil || s.codes[i][e.Code]) {
+			return &s.settings[i]
+		}
+	}
+
+	return nil
+}
+
+// oneEuroAlpha returns the weight of a new value for a low-pass filter with
+// the given cutoff frequency and sampling interval.
+func oneEuroAlpha(cutoff, dt float64) float64 {
+	tau := 1 / (2 * math.Pi * cutoff)
+	return 1 / (1 + tau/dt)
+}
+
+func (st *smoothState) filter(cfg *Smoothing, v float64, now time.Time) {
+	if cfg.Kind == SmoothEMA {
+		st.value += cfg.Alpha * (v - st.value)
+		return
+	}
+
+	// events reported within the same frame, or by a device without
+	// timestamps, are treated as a millisecond apart
+	dt := now.Sub(st.time).Seconds()
+	if dt <= 0 {
+		dt = 0.001
+	}
+
+	dcutoff := cfg.DCutoff
+	if dcutoff <= 0 {
+		dcutoff = 1
+	}
+
+	speed := (v - st.value) / dt
+	st.speed += oneEuroAlpha(dcutoff, dt) * (speed - st.speed)
+
+	cutoff := cfg.MinCutoff + cfg.Beta*math.Abs(st.speed)
+	st.value += oneEuroAlpha(cutoff, dt) * (v - st.value)
+}
+
+// ProcessFrame implements Transform.
+func (s *Smoother) ProcessFrame(frame []InputEvent) []InputEvent {
+	last := frame[len(frame)-1]
+
+	if last.Type == EV_SYN && last.Code == SYN_DROPPED {
+		s.states = map[smoothKey]*smoothState{}
+		return frame
+	}
+
+	now := last.Timestamp()
+	out := make([]InputEvent, 0, len(frame))
+
+	for _, e := range frame {
+		cfg := s.settingOf(&e)
+		if cfg == nil {
+			out = append(out, e)
+			continue
+		}
+
+		k := smoothKey{e.Type, e.Code}
+
+		st, ok := s.states[k]
+		if !ok {
+			// the first value is reported as it is
+			s.states[k] = &smoothState{value: float64(e.Value), time: now, reported: e.Value}
+			out = append(out, e)
+
+			continue
+		}
+
+		st.filter(cfg, float64(e.Value), now)
+		st.time = now
+
+		if e.Type == EV_ABS {
+			v := int32(math.Round(st.value))
+			if v == st.reported {
+				continue
+			}
+
+			st.reported = v
+			e.Value = v
+		} else {
+			st.rest += st.value
+			v := math.Round(st.rest)
+			st.rest -= v
+
+			if v == 0 {
+				continue
+			}
+
+			e.Value = int32(v)
+		}
+
+		out = append(out, e)
+	}
+
+	if len(out) == 1 && last.Type == EV_SYN && len(frame) > 1 {
+		return nil
+	}
+
+	return out
+}
+
+// ParseSmoothings parses smoothing settings separated by semicolons or
+// newlines, each an event type, optional codes, the filter and its
+// parameters, e.g.
+//
+//	EV_ABS ABS_X ABS_Y oneeuro mincutoff=1 beta=0.007; EV_REL ema alpha=0.5
+//
+// Parameters that are not given default to alpha=0.5 for ema, and
+// mincutoff=1, beta=0 and dcutoff=1 for oneeuro.
+func ParseSmoothings(config string) ([]Smoothing, error) {
+	settings := []Smoothing{}
+
+	for _, line := range strings.FieldsFunc(config, func(r rune) bool { return r == ';' || r == '\n' }) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		i := 0
+		for i < len(fields) && fields[i] != "ema" && fields[i] != "oneeuro" {
+			i++
+		}
+
+		if i == len(fields) {
+			return nil, fmt.Errorf("Missing filter in smoothing %q", strings.TrimSpace(line))
+		}
+
+		t, codes, err := parseTypeCodes(fields[:i])
+		if err != nil {
+			return nil, err
+		}
+
+		st := Smoothing{Type: t, Codes: codes, Alpha: 0.5, MinCutoff: 1, DCutoff: 1}
+		params := map[string]*float64{"alpha": &st.Alpha}
+
+		if fields[i] == "oneeuro" {
+			st.Kind = SmoothOneEuro
+			params = map[string]*float64{"mincutoff": &st.MinCutoff, "beta": &st.Beta, "dcutoff": &st.DCutoff}
+		}
+
+		for _, param := range fields[i+1:] {
+			kv := strings.SplitN(param, "=", 2)
+
+			p, ok := params[kv[0]]
+			if !ok || len(kv) != 2 {
+				return nil, fmt.Errorf("Unknown parameter %q for %s", param, fields[i])
+			}
+
+			v, err := strconv.ParseFloat(kv[1], 64)
+			if err != nil || v < 0 {
+				return nil, fmt.Errorf("Invalid value for %s: %q", kv[0], kv[1])
+			}
+
+			*p = v
+		}
+
+		if st.Kind == SmoothEMA && (st.Alpha <= 0 || st.Alpha > 1) {
+			return nil, fmt.Errorf("Invalid value for alpha: %v", st.Alpha)
+		}
+
+		if st.Kind == SmoothOneEuro && (st.MinCutoff <= 0 || st.DCutoff <= 0) {
+			return nil, fmt.Errorf("Cutoff frequencies must be positive")
+		}
+
+		settings = append(settings, st)
+	}
+
+	return settings, nil
+}
+
+func init() {
+	RegisterTransform("smooth", func(config string) (Transform, error) {
+		settings, err := ParseSmoothings(config)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewSmoother(settings...), nil
+	})
+}
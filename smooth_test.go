@@ -0,0 +1,82 @@
+package evdev
+
+import (
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func smoothFrames(t Transform, typ EvType, c EvCode, values ...int32) []int32 {
+	got := []int32{}
+
+	for i, v := range values {
+		tv := syscall.NsecToTimeval(int64(i) * int64(10*time.Millisecond))
+		out := t.ProcessFrame([]InputEvent{
+			{Time: tv, Type: typ, Code: c, Value: v},
+			{Time: tv, Type: EV_SYN, Code: SYN_REPORT},
+		})
+
+		for _, e := range out {
+			if e.Type == typ && e.Code == c {
+				got = append(got, e.Value)
+			}
+		}
+	}
+
+	return got
+}
+
+func TestSmoother(t *testing.T) {
+	tests := []struct {
+		config string
+		typ    EvType
+		code   EvCode
+		values []int32
+		want   []int32
+	}{
+		{"EV_ABS ema", EV_ABS, ABS_X, []int32{0, 10, 10, 10, 10}, []int32{0, 5, 8, 9}},
+		{"EV_REL REL_X ema alpha=0.5", EV_REL, REL_X, []int32{4, 8, 2, 2}, []int32{4, 6, 4, 3}},
+		{"EV_REL REL_X ema", EV_REL, REL_Y, []int32{4, 8}, []int32{4, 8}},
+		{"EV_ABS ABS_X oneeuro mincutoff=1", EV_ABS, ABS_X, []int32{100, 102, 98, 102, 98}, []int32{100}},
+		{"EV_ABS ema alpha=0.5", EV_ABS, ABS_MT_POSITION_X, []int32{0, 10}, []int32{0, 10}},
+	}
+
+	for _, tt := range tests {
+		s, err := NewTransform("smooth", tt.config)
+		if err != nil {
+			t.Fatalf("NewTransform(%q) error = %v", tt.config, err)
+		}
+
+		if got := smoothFrames(s, tt.typ, tt.code, tt.values...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.config, got, tt.want)
+		}
+	}
+}
+
+func TestSmoother_OneEuroFollowsFastMotion(t *testing.T) {
+	slow := NewSmoother(Smoothing{Type: EV_ABS, Kind: SmoothOneEuro, MinCutoff: 1, DCutoff: 1})
+	fast := NewSmoother(Smoothing{Type: EV_ABS, Kind: SmoothOneEuro, MinCutoff: 1, Beta: 1, DCutoff: 1})
+
+	s := smoothFrames(slow, EV_ABS, ABS_X, 0, 1000)
+	f := smoothFrames(fast, EV_ABS, ABS_X, 0, 1000)
+
+	if len(s) != 2 || len(f) != 2 || s[1] > 100 || f[1] < 990 {
+		t.Errorf("got %v without and %v with speed adaption", s, f)
+	}
+}
+
+func TestParseSmoothingsErrors(t *testing.T) {
+	for _, config := range []string{
+		"EV_ABS",
+		"EV_ABS ABS_X kalman",
+		"EV_ABS ema alpha=0",
+		"EV_ABS ema beta=1",
+		"EV_ABS oneeuro mincutoff=x",
+		"EV_ABS oneeuro mincutoff=0",
+	} {
+		if _, err := ParseSmoothings(config); err == nil {
+			t.Errorf("ParseSmoothings(%q) succeeded", config)
+		}
+	}
+}
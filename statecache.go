@@ -0,0 +1,216 @@
+package evdev
+
+import (
+	"fmt"
+	"sync"
+)
+
+// stateCache mirrors the state of a device's keys, LEDs, sounds, switches
+// and absolute axes, updated from the events read from the device.
+type stateCache struct {
+	mu       sync.Mutex
+	query    func() (map[EvType]StateMap, map[EvCode]AbsInfo, error)
+	states   map[EvType]StateMap
+	abs      map[EvCode]AbsInfo
+	dropping bool
+	err      error // set while the mirror is stale because a resync failed
+}
+
+// cachedTypes are the bit-field based types whose state can be queried
+var cachedTypes = []EvType{EV_KEY, EV_LED, EV_SND, EV_SW}
+
+// isCachedAbs returns false for the ABS_MT_* axes, whose values are per slot
+// and can't be mirrored as a single value.
+func isCachedAbs(c EvCode) bool {
+	return c < ABS_MT_SLOT || c > ABS_MT_TOOL_Y
+}
+
+// queryState queries the state mirrored by a stateCache from the device.
+func (d *InputDevice) queryState() (map[EvType]StateMap, map[EvCode]AbsInfo, error) {
+	states := map[EvType]StateMap{}
+
+	for _, t := range cachedTypes {
+		st, err := d.State(t)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Cannot get state of %s: %v", TypeName(t), err)
+		}
+
+		states[t] = st
+	}
+
+	abs, err := d.AbsInfos()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return states, abs, nil
+}
+
+func (c *stateCache) sync() error {
+	states, abs, err := c.query()
+	if err != nil {
+		return err
+	}
+
+	for code := range abs {
+		if !isCachedAbs(code) {
+			delete(abs, code)
+		}
+	}
+
+	c.mu.Lock()
+	c.states = states
+	c.abs = abs
+	c.mu.Unlock()
+
+	return nil
+}
+
+func (c *stateCache) update(e *InputEvent) {
+	if e.Type == EV_SYN {
+		switch e.Code {
+		case SYN_DROPPED:
+			c.mu.Lock()
+			c.dropping = true
+			c.mu.Unlock()
+
+		case SYN_REPORT:
+			c.mu.Lock()
+			stale := c.dropping || c.err != nil
+			c.mu.Unlock()
+
+			// events were lost, or a previous resync failed, so query the
+			// full state again
+			if stale {
+				err := c.sync()
+
+				c.mu.Lock()
+				c.dropping = false
+				c.err = err
+				c.mu.Unlock()
+			}
+		}
+
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// events between SYN_DROPPED and the next SYN_REPORT are incomplete
+	if c.dropping {
+		return
+	}
+
+	switch e.Type {
+	case EV_ABS:
+		if info, ok := c.abs[e.Code]; ok {
+			info.Value = e.Value
+			c.abs[e.Code] = info
+		}
+
+	default:
+		if st, ok := c.states[e.Type]; ok {
+			if _, ok := st[e.Code]; ok {
+				st[e.Code] = e.Value != 0
+			}
+		}
+	}
+}
+
+// EnableStateCache makes the device keep an internal mirror of its key, LED,
+// sound, switch and absolute axis state. The mirror is initialized from the
+// kernel and then updated from the events read with Read and ReadOne, so it
+// can be queried with CachedState and CachedAbsInfos without ioctls. When
+// events are lost, the mirror is re-synchronized from the kernel.
+//
+// The ABS_MT_* axes are not mirrored, as their values are per slot. Use an
+// MTTracker to follow multitouch contacts.
+func (d *InputDevice) EnableStateCache() error {
+	c := &stateCache{query: d.queryState}
+
+	if err := c.sync(); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.stateCache = c
+	d.mu.Unlock()
+
+	return nil
+}
+
+func (d *InputDevice) cachedState() (*stateCache, error) {
+	d.mu.RLock()
+	c := d.stateCache
+	d.mu.RUnlock()
+
+	if c == nil {
+		return nil, fmt.Errorf("State cache not enabled")
+	}
+
+	return c, nil
+}
+
+// CachedState returns a copy of the cached state of the given type, which
+// must be one of EV_KEY, EV_LED, EV_SND and EV_SW. The map will be empty
+// if the requested type is not supported by the device. Returns an error if
+// the mirror is stale because it could not be re-synchronized after events
+// were lost.
+func (d *InputDevice) CachedState(t EvType) (StateMap, error) {
+	c, err := d.cachedState()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.state(t)
+}
+
+func (c *stateCache) state(t EvType) (StateMap, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return nil, fmt.Errorf("Cannot resync state cache: %v", c.err)
+	}
+
+	st, ok := c.states[t]
+	if !ok {
+		return nil, fmt.Errorf("Unsupported evType %d", t)
+	}
+
+	cp := StateMap{}
+	for code, v := range st {
+		cp[code] = v
+	}
+
+	return cp, nil
+}
+
+// CachedAbsInfos returns a copy of the cached AbsInfo of all axes the device
+// supports, except for the ABS_MT_* axes. Like CachedState, it returns an
+// error if the mirror is stale.
+func (d *InputDevice) CachedAbsInfos() (map[EvCode]AbsInfo, error) {
+	c, err := d.cachedState()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.absInfos()
+}
+
+func (c *stateCache) absInfos() (map[EvCode]AbsInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return nil, fmt.Errorf("Cannot resync state cache: %v", c.err)
+	}
+
+	cp := map[EvCode]AbsInfo{}
+	for code, info := range c.abs {
+		cp[code] = info
+	}
+
+	return cp, nil
+}
@@ -0,0 +1,91 @@
+package evdev
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_stateCache_update(t *testing.T) {
+	c := &stateCache{
+		states: map[EvType]StateMap{
+			EV_KEY: {KEY_A: false, KEY_B: true},
+		},
+		abs: map[EvCode]AbsInfo{
+			ABS_X: {Value: 1, Maximum: 100},
+		},
+	}
+
+	for _, e := range []InputEvent{
+		{Type: EV_KEY, Code: KEY_A, Value: 1},
+		{Type: EV_KEY, Code: KEY_B, Value: 0},
+		{Type: EV_KEY, Code: KEY_C, Value: 1},
+		{Type: EV_ABS, Code: ABS_X, Value: 42},
+		{Type: EV_SYN, Code: SYN_REPORT},
+		{Type: EV_SYN, Code: SYN_DROPPED},
+		{Type: EV_KEY, Code: KEY_A, Value: 0},
+	} {
+		c.update(&e)
+	}
+
+	if want := (StateMap{KEY_A: true, KEY_B: false}); !reflect.DeepEqual(c.states[EV_KEY], want) {
+		t.Errorf("key state = %v, want %v", c.states[EV_KEY], want)
+	}
+
+	if want := (AbsInfo{Value: 42, Maximum: 100}); c.abs[ABS_X] != want {
+		t.Errorf("abs state = %v, want %v", c.abs[ABS_X], want)
+	}
+}
+
+func Test_stateCache_resync(t *testing.T) {
+	queryErr := errors.New("no such device")
+
+	kernel := StateMap{KEY_A: false}
+	c := &stateCache{
+		query: func() (map[EvType]StateMap, map[EvCode]AbsInfo, error) {
+			if queryErr != nil {
+				return nil, nil, queryErr
+			}
+
+			abs := map[EvCode]AbsInfo{ABS_X: {Value: 7}, ABS_MT_POSITION_X: {Value: 3}}
+			return map[EvType]StateMap{EV_KEY: kernel}, abs, nil
+		},
+		states: map[EvType]StateMap{EV_KEY: {KEY_A: false}},
+	}
+
+	// the release of KEY_A is lost, the press is part of the dropped frame
+	for _, e := range []InputEvent{
+		{Type: EV_SYN, Code: SYN_DROPPED},
+		{Type: EV_KEY, Code: KEY_A, Value: 1},
+		{Type: EV_SYN, Code: SYN_REPORT},
+	} {
+		c.update(&e)
+	}
+
+	if _, err := c.state(EV_KEY); err == nil {
+		t.Errorf("state() after failed resync succeeded, want error")
+	}
+
+	// the next frame retries the resync
+	queryErr = nil
+	kernel = StateMap{KEY_A: true}
+	c.update(&InputEvent{Type: EV_SYN, Code: SYN_REPORT})
+
+	st, err := c.state(EV_KEY)
+	if err != nil {
+		t.Fatalf("state() error = %v", err)
+	}
+
+	if want := (StateMap{KEY_A: true}); !reflect.DeepEqual(st, want) {
+		t.Errorf("state() = %v, want %v", st, want)
+	}
+
+	abs, err := c.absInfos()
+	if err != nil {
+		t.Fatalf("absInfos() error = %v", err)
+	}
+
+	if want := map[EvCode]AbsInfo{ABS_X: {Value: 7}}; !reflect.DeepEqual(abs, want) {
+		t.Errorf("absInfos() = %v, want %v", abs, want)
+	}
+}
@@ -0,0 +1,66 @@
+package evdev
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+func sortCodes(codes []EvCode) []EvCode {
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	return codes
+}
+
+// Active returns the codes that are set, in ascending order.
+func (s StateMap) Active() []EvCode {
+	codes := []EvCode{}
+
+	for c, v := range s {
+		if v {
+			codes = append(codes, c)
+		}
+	}
+
+	return sortCodes(codes)
+}
+
+// Pressed returns true if the given code is set.
+func (s StateMap) Pressed(code EvCode) bool {
+	return s[code]
+}
+
+// Diff compares s to a previous state and returns the codes that have been
+// set and cleared since, in ascending order.
+func (s StateMap) Diff(previous StateMap) (pressed, released []EvCode) {
+	pressed = []EvCode{}
+	released = []EvCode{}
+
+	for c, v := range s {
+		if v && !previous[c] {
+			pressed = append(pressed, c)
+		}
+	}
+
+	for c, v := range previous {
+		if v && !s[c] {
+			released = append(released, c)
+		}
+	}
+
+	return sortCodes(pressed), sortCodes(released)
+}
+
+// String returns the state of all codes, ordered by code.
+func (s StateMap) String() string {
+	codes := []EvCode{}
+	for c := range s {
+		codes = append(codes, c)
+	}
+
+	parts := []string{}
+	for _, c := range sortCodes(codes) {
+		parts = append(parts, fmt.Sprintf("%d:%v", c, s[c]))
+	}
+
+	return "{" + strings.Join(parts, " ") + "}"
+}
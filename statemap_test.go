@@ -0,0 +1,31 @@
+package evdev
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStateMap(t *testing.T) {
+	previous := StateMap{KEY_A: true, KEY_B: true, KEY_C: false}
+	current := StateMap{KEY_A: true, KEY_B: false, KEY_C: true, KEY_D: true}
+
+	if got, want := current.Active(), []EvCode{KEY_A, KEY_D, KEY_C}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Active() = %v, want %v", got, want)
+	}
+
+	if !current.Pressed(KEY_C) || current.Pressed(KEY_B) || current.Pressed(KEY_E) {
+		t.Errorf("Pressed() returned wrong results")
+	}
+
+	pressed, released := current.Diff(previous)
+	if want := []EvCode{KEY_D, KEY_C}; !reflect.DeepEqual(pressed, want) {
+		t.Errorf("Diff() pressed = %v, want %v", pressed, want)
+	}
+	if want := []EvCode{KEY_B}; !reflect.DeepEqual(released, want) {
+		t.Errorf("Diff() released = %v, want %v", released, want)
+	}
+
+	if got, want := previous.String(), "{30:true 46:false 48:true}"; got != want {
+		t.Errorf("String() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,183 @@
+package evdev
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultSubscriptionBufferSize is the channel buffer size of subscriptions
+// created with Subscribe.
+const defaultSubscriptionBufferSize = 64
+
+// Subscription receives the events of one type, and optionally only some of
+// its codes, from a device shared with other subscriptions.
+type Subscription struct {
+	dropped uint64 // accessed atomically, keep 64-bit aligned
+	dev     *InputDevice
+	t       EvType
+	codes   map[EvCode]bool
+	ch      chan InputEvent
+	err     error
+}
+
+// Events returns the channel the subscription's events are delivered on. It
+// is closed when the subscription is closed or reading from the device fails.
+func (s *Subscription) Events() <-chan InputEvent {
+	return s.ch
+}
+
+// Err returns the error that ended the subscription, if any. It is only
+// valid after the events channel has been closed.
+func (s *Subscription) Err() error {
+	return s.err
+}
+
+// Dropped returns the number of events discarded because the subscription's
+// buffer was full.
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Close ends the subscription and closes its events channel. Closing the
+// last subscription of a device stops its reader goroutine.
+func (s *Subscription) Close() {
+	s.dev.removeSubscription(s)
+}
+
+func (s *Subscription) wants(e *InputEvent) bool {
+	if e.Type != s.t {
+		return false
+	}
+
+	return len(s.codes) == 0 || s.codes[e.Code]
+}
+
+// subscribers manages the subscriptions of a device and the goroutine that
+// reads events for them.
+type subscribers struct {
+	mu      sync.Mutex
+	subs    map[*Subscription]bool
+	running bool
+}
+
+func (d *InputDevice) removeSubscription(s *Subscription) {
+	ss := &d.subscribers
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if !ss.subs[s] {
+		return
+	}
+
+	delete(ss.subs, s)
+	close(s.ch)
+
+	// interrupt the blocking read, so the reader goroutine ends and no
+	// further events are consumed
+	if len(ss.subs) == 0 && ss.running {
+		d.file.SetReadDeadline(time.Now())
+	}
+}
+
+// Subscribe returns a Subscription for the events of type t, optionally
+// limited to the given codes. All subscriptions of a device share one
+// reader goroutine, which is started with the first subscription and
+// stopped when the last one is closed.
+//
+// While the reader goroutine runs, it consumes all events of the device, so
+// the device must not be read from otherwise. Once the last subscription is
+// closed, Read and ReadOne can be used again.
+//
+// Events are delivered with a buffer of 64 events per subscription. If a
+// subscriber falls behind, events for it are dropped rather than stalling
+// the other subscribers.
+func (d *InputDevice) Subscribe(t EvType, codes ...EvCode) *Subscription {
+	return d.SubscribeBuffered(defaultSubscriptionBufferSize, t, codes...)
+}
+
+// SubscribeBuffered is like Subscribe, but with the given buffer size. Sizes
+// smaller than 1 are treated as 1, as events for a subscription without a
+// buffer would always be dropped.
+func (d *InputDevice) SubscribeBuffered(bufferSize int, t EvType, codes ...EvCode) *Subscription {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+
+	s := &Subscription{
+		dev:   d,
+		t:     t,
+		codes: map[EvCode]bool{},
+		ch:    make(chan InputEvent, bufferSize),
+	}
+
+	for _, c := range codes {
+		s.codes[c] = true
+	}
+
+	ss := &d.subscribers
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if ss.subs == nil {
+		ss.subs = map[*Subscription]bool{}
+	}
+
+	ss.subs[s] = true
+
+	if !ss.running {
+		ss.running = true
+		go d.readForSubscribers()
+	}
+
+	return s
+}
+
+func (d *InputDevice) readForSubscribers() {
+	ss := &d.subscribers
+
+	for {
+		events, err := d.Read()
+
+		ss.mu.Lock()
+
+		// the read was interrupted by closing the last subscription
+		if err != nil && os.IsTimeout(err) {
+			err = nil
+		}
+
+		if err != nil {
+			for s := range ss.subs {
+				delete(ss.subs, s)
+				s.err = err
+				close(s.ch)
+			}
+		}
+
+		if len(ss.subs) == 0 {
+			d.file.SetReadDeadline(time.Time{})
+			ss.running = false
+			ss.mu.Unlock()
+			return
+		}
+
+		for i := range events {
+			for s := range ss.subs {
+				if !s.wants(&events[i]) {
+					continue
+				}
+
+				select {
+				case s.ch <- events[i]:
+				default:
+					atomic.AddUint64(&s.dropped, 1)
+				}
+			}
+		}
+
+		ss.mu.Unlock()
+	}
+}
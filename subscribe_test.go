@@ -0,0 +1,135 @@
+package evdev
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func pipeDevice(t *testing.T) (*InputDevice, *os.File) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Cannot create pipe: %v", err)
+	}
+
+	return &InputDevice{file: r, readBatchSize: defaultReadBatchSize}, w
+}
+
+func receive(t *testing.T, ch <-chan InputEvent, n int) []InputEvent {
+	events := []InputEvent{}
+
+	for len(events) < n {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				return events
+			}
+			events = append(events, e)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out after %d of %d events", len(events), n)
+		}
+	}
+
+	return events
+}
+
+func TestSubscribe(t *testing.T) {
+	d, w := pipeDevice(t)
+	defer d.file.Close()
+	defer w.Close()
+
+	keys := d.Subscribe(EV_KEY)
+	keyA := d.Subscribe(EV_KEY, KEY_A)
+	full := d.SubscribeBuffered(0, EV_REL)
+
+	events := []InputEvent{
+		{Type: EV_KEY, Code: KEY_A, Value: 1},
+		{Type: EV_REL, Code: REL_X, Value: 1},
+		{Type: EV_KEY, Code: KEY_B, Value: 1},
+		{Type: EV_REL, Code: REL_X, Value: 2},
+		{Type: EV_SYN, Code: SYN_REPORT},
+	}
+
+	if _, err := w.Write(eventBytes(events)); err != nil {
+		t.Fatalf("Cannot write events: %v", err)
+	}
+
+	got := receive(t, keys.Events(), 2)
+	want := []InputEvent{events[0], events[2]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EV_KEY subscription got %v, want %v", got, want)
+	}
+
+	got = receive(t, keyA.Events(), 1)
+	want = []InputEvent{events[0]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("KEY_A subscription got %v, want %v", got, want)
+	}
+
+	// the buffer of one only holds the first REL_X event
+	got = receive(t, full.Events(), 1)
+	want = []InputEvent{events[1]}
+	if !reflect.DeepEqual(got, want) || full.Dropped() != 1 {
+		t.Errorf("full subscription got %v with %d dropped, want %v with 1 dropped", got, full.Dropped(), want)
+	}
+
+	// the source ending closes all subscriptions
+	w.Close()
+
+	for _, s := range []*Subscription{keys, keyA, full} {
+		if got := receive(t, s.Events(), 1); len(got) != 0 {
+			t.Errorf("unexpected events %v", got)
+		}
+
+		if s.Err() != io.EOF {
+			t.Errorf("Err() = %v, want %v", s.Err(), io.EOF)
+		}
+	}
+}
+
+func TestSubscription_CloseStopsReader(t *testing.T) {
+	d, w := pipeDevice(t)
+	defer d.file.Close()
+	defer w.Close()
+
+	// ioctls must leave the read interruptible
+	d.Name()
+
+	s := d.Subscribe(EV_KEY)
+	time.Sleep(10 * time.Millisecond)
+	s.Close()
+
+	if _, ok := <-s.Events(); ok {
+		t.Fatal("events channel not closed")
+	}
+
+	// once the reader has stopped, the device can be read directly again
+	deadline := time.Now().Add(time.Second)
+	for {
+		d.subscribers.mu.Lock()
+		running := d.subscribers.running
+		d.subscribers.mu.Unlock()
+
+		if !running {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("reader goroutine still running")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	events := []InputEvent{{Type: EV_KEY, Code: KEY_A, Value: 1}}
+	if _, err := w.Write(eventBytes(events)); err != nil {
+		t.Fatalf("Cannot write events: %v", err)
+	}
+
+	got, err := d.Read()
+	if err != nil || !reflect.DeepEqual(got, events) {
+		t.Errorf("Read() = %v, %v, want %v", got, err, events)
+	}
+}
@@ -0,0 +1,298 @@
+package evdev
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// sysfsRoot is where sysfs is mounted. Tests point it to a fake tree.
+var sysfsRoot = "/sys"
+
+// devNumbers splits a device number into its major and minor numbers, the
+// same way glibc's major() and minor() do.
+func devNumbers(rdev uint64) (uint32, uint32) {
+	major := ((rdev >> 8) & 0xfff) | ((rdev >> 32) & 0xfffff000)
+	minor := (rdev & 0xff) | ((rdev >> 12) & 0xffffff00)
+
+	return uint32(major), uint32(minor)
+}
+
+// SysfsPath returns the sysfs directory of the input device the node
+// belongs to, e.g. /sys/devices/.../input/input5.
+func (d *InputDevice) SysfsPath() (string, error) {
+	st := syscall.Stat_t{}
+
+	if err := syscall.Fstat(int(d.fd()), &st); err != nil {
+		return "", fmt.Errorf("Cannot stat device node: %v", err)
+	}
+
+	major, minor := devNumbers(uint64(st.Rdev))
+
+	return sysfsPathOf(major, minor)
+}
+
+func sysfsPathOf(major, minor uint32) (string, error) {
+	node, err := filepath.EvalSymlinks(filepath.Join(sysfsRoot, "dev", "char", fmt.Sprintf("%d:%d", major, minor)))
+	if err != nil {
+		return "", fmt.Errorf("Cannot resolve sysfs path: %v", err)
+	}
+
+	return filepath.Dir(node), nil
+}
+
+// sysfsParents returns path and its parent directories within
+// /sys/devices, nearest first.
+func sysfsParents(path string) []string {
+	devices := filepath.Join(sysfsRoot, "devices") + string(filepath.Separator)
+	parents := []string{}
+
+	for ; strings.HasPrefix(path, devices); path = filepath.Dir(path) {
+		parents = append(parents, path)
+	}
+
+	return parents
+}
+
+func readSysfsString(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(b)), nil
+}
+
+func readSysfsInt(path string) (int, error) {
+	s, err := readSysfsString(path)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(s)
+}
+
+// Battery describes a power supply that belongs to an input device, such
+// as the battery of a wireless controller.
+type Battery struct {
+	Name          string // name of the power_supply entry
+	Capacity      int    // charge in percent, or -1 if not reported
+	CapacityLevel string // e.g. "Normal", "Low", or empty if not reported
+	Status        string // e.g. "Charging", "Discharging", or empty if not reported
+}
+
+// Batteries returns the power supplies associated with the device. They are
+// looked up in the power_supply directory of the device's nearest sysfs
+// parent that has one. Returns an empty slice if the device has none.
+func (d *InputDevice) Batteries() ([]Battery, error) {
+	path, err := d.SysfsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	return batteriesOf(path)
+}
+
+func batteriesOf(path string) ([]Battery, error) {
+	for _, path := range sysfsParents(path) {
+		dir := filepath.Join(path, "power_supply")
+
+		entries, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		batteries := []Battery{}
+
+		for _, e := range entries {
+			supply := filepath.Join(dir, e.Name())
+
+			b := Battery{
+				Name:     e.Name(),
+				Capacity: -1,
+			}
+
+			if c, err := readSysfsInt(filepath.Join(supply, "capacity")); err == nil {
+				b.Capacity = c
+			}
+
+			b.CapacityLevel, _ = readSysfsString(filepath.Join(supply, "capacity_level"))
+			b.Status, _ = readSysfsString(filepath.Join(supply, "status"))
+
+			batteries = append(batteries, b)
+		}
+
+		return batteries, nil
+	}
+
+	return []Battery{}, nil
+}
+
+// SysfsLED is an LED class device in sysfs that belongs to an input device,
+// such as a keyboard backlight or an LED not exposed through EV_LED.
+type SysfsLED struct {
+	Name string
+	path string
+}
+
+func isSysfsLED(path string) bool {
+	_, err := os.Stat(filepath.Join(path, "max_brightness"))
+	return err == nil
+}
+
+// SysfsLEDs returns the LED class devices associated with the device. These
+// are the LEDs registered for the input device itself (e.g. input5::capslock)
+// and those in the leds directory of its nearest sysfs parent that has one
+// (e.g. a HID keyboard's kbd_backlight).
+func (d *InputDevice) SysfsLEDs() ([]*SysfsLED, error) {
+	path, err := d.SysfsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	return sysfsLEDsOf(path)
+}
+
+func sysfsLEDsOf(path string) ([]*SysfsLED, error) {
+	leds := []*SysfsLED{}
+
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		p := filepath.Join(path, e.Name())
+		if isSysfsLED(p) {
+			leds = append(leds, &SysfsLED{Name: e.Name(), path: p})
+		}
+	}
+
+	for _, path := range sysfsParents(filepath.Dir(path)) {
+		dir := filepath.Join(path, "leds")
+
+		entries, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range entries {
+			p := filepath.Join(dir, e.Name())
+			if isSysfsLED(p) {
+				leds = append(leds, &SysfsLED{Name: e.Name(), path: p})
+			}
+		}
+
+		break
+	}
+
+	return leds, nil
+}
+
+// Brightness returns the LED's current brightness.
+func (l *SysfsLED) Brightness() (int, error) {
+	return readSysfsInt(filepath.Join(l.path, "brightness"))
+}
+
+// MaxBrightness returns the LED's maximum brightness.
+func (l *SysfsLED) MaxBrightness() (int, error) {
+	return readSysfsInt(filepath.Join(l.path, "max_brightness"))
+}
+
+// SetBrightness sets the LED's brightness. This usually requires root
+// privileges or a udev rule granting write access.
+func (l *SysfsLED) SetBrightness(brightness int) error {
+	return ioutil.WriteFile(filepath.Join(l.path, "brightness"), []byte(strconv.Itoa(brightness)), 0644)
+}
+
+// wakeupAttr returns the power/wakeup attribute of the nearest sysfs parent
+// of the device that has one. This is usually the physical device, e.g.
+// the USB device or the gpio-keys platform device.
+func (d *InputDevice) wakeupAttr() (string, error) {
+	path, err := d.SysfsPath()
+	if err != nil {
+		return "", err
+	}
+
+	return wakeupAttrOf(path)
+}
+
+func wakeupAttrOf(path string) (string, error) {
+	for _, path := range sysfsParents(path) {
+		attr := filepath.Join(path, "power", "wakeup")
+
+		if _, err := os.Stat(attr); err == nil {
+			return attr, nil
+		}
+	}
+
+	return "", fmt.Errorf("Device is not wakeup capable")
+}
+
+// WakeCapable returns true if the device can be configured as a wake source
+// through sysfs.
+func (d *InputDevice) WakeCapable() bool {
+	_, err := d.wakeupAttr()
+	return err == nil
+}
+
+// HasWakeKeys returns true if the device reports EV_PWR events or any of the
+// KEY_POWER, KEY_SLEEP and KEY_WAKEUP keys.
+func (d *InputDevice) HasWakeKeys() bool {
+	for _, t := range d.CapableTypes() {
+		switch t {
+		case EV_PWR:
+			return true
+		case EV_KEY:
+			for _, c := range d.CapableEvents(t) {
+				if c == KEY_POWER || c == KEY_SLEEP || c == KEY_WAKEUP {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// IsWakeSource returns true if the device is enabled to wake the system from
+// suspend.
+func (d *InputDevice) IsWakeSource() (bool, error) {
+	attr, err := d.wakeupAttr()
+	if err != nil {
+		return false, err
+	}
+
+	s, err := readSysfsString(attr)
+	if err != nil {
+		return false, err
+	}
+
+	return s == "enabled", nil
+}
+
+// SetWakeSource enables or disables the device to wake the system from
+// suspend. This usually requires root privileges.
+func (d *InputDevice) SetWakeSource(enabled bool) error {
+	attr, err := d.wakeupAttr()
+	if err != nil {
+		return err
+	}
+
+	s := "disabled"
+	if enabled {
+		s = "enabled"
+	}
+
+	return ioutil.WriteFile(attr, []byte(s), 0644)
+}
@@ -0,0 +1,140 @@
+package evdev
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDevNumbers(t *testing.T) {
+	makedev := func(major, minor uint64) uint64 {
+		return (minor & 0xff) | ((major & 0xfff) << 8) | ((minor &^ 0xff) << 12) | ((major &^ 0xfff) << 32)
+	}
+
+	for _, tt := range []struct{ major, minor uint32 }{
+		{13, 69},
+		{13, 0x12345},
+		{0x12345678, 0x9abcdef0},
+	} {
+		major, minor := devNumbers(makedev(uint64(tt.major), uint64(tt.minor)))
+		if major != tt.major || minor != tt.minor {
+			t.Errorf("devNumbers() = %x:%x, want %x:%x", major, minor, tt.major, tt.minor)
+		}
+	}
+}
+
+// fakeSysfs creates a sysfs tree with a HID keyboard that has a battery, a
+// backlight and a capslock LED, and whose USB device is a wake source.
+func fakeSysfs(t *testing.T) string {
+	root, err := ioutil.TempDir("", "sysfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, err = filepath.EvalSymlinks(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	usb := "devices/pci0000:00/usb1/1-1"
+	hid := usb + "/1-1:1.0/0003:046D:C52B.0001"
+	input := hid + "/input/input5"
+
+	files := map[string]string{
+		usb + "/power/wakeup":                          "enabled\n",
+		hid + "/power_supply/hidpp_battery_0/capacity": "85\n",
+		hid + "/power_supply/hidpp_battery_0/status":   "Discharging\n",
+		hid + "/leds/kbd_backlight/max_brightness":     "3\n",
+		hid + "/leds/kbd_backlight/brightness":         "1\n",
+		input + "/input5::capslock/max_brightness":     "1\n",
+		input + "/event5/dev":                          "13:69\n",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(root, name)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(root, "dev/char"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink("../../"+input+"/event5", filepath.Join(root, "dev/char/13:69")); err != nil {
+		t.Fatal(err)
+	}
+
+	return root
+}
+
+func TestSysfs(t *testing.T) {
+	root := fakeSysfs(t)
+	defer os.RemoveAll(root)
+
+	defer func(old string) { sysfsRoot = old }(sysfsRoot)
+	sysfsRoot = root
+
+	path, err := sysfsPathOf(13, 69)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hid := filepath.Join(root, "devices/pci0000:00/usb1/1-1/1-1:1.0/0003:046D:C52B.0001")
+	if want := filepath.Join(hid, "input/input5"); path != want {
+		t.Errorf("sysfsPathOf() = %v, want %v", path, want)
+	}
+
+	batteries, err := batteriesOf(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Battery{{Name: "hidpp_battery_0", Capacity: 85, Status: "Discharging"}}
+	if !reflect.DeepEqual(batteries, want) {
+		t.Errorf("batteriesOf() = %v, want %v", batteries, want)
+	}
+
+	leds, err := sysfsLEDsOf(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, l := range leds {
+		names = append(names, l.Name)
+	}
+
+	if want := []string{"input5::capslock", "kbd_backlight"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("sysfsLEDsOf() = %v, want %v", names, want)
+	}
+
+	if err := leds[1].SetBrightness(2); err != nil {
+		t.Fatal(err)
+	}
+
+	if b, err := leds[1].Brightness(); b != 2 || err != nil {
+		t.Errorf("Brightness() = %v, %v, want 2", b, err)
+	}
+
+	attr, err := wakeupAttrOf(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := filepath.Join(root, "devices/pci0000:00/usb1/1-1/power/wakeup"); attr != want {
+		t.Errorf("wakeupAttrOf() = %v, want %v", attr, want)
+	}
+
+	// the walk stops at the devices directory
+	if _, err := wakeupAttrOf(filepath.Join(root, "class/input/input5")); err == nil {
+		t.Errorf("wakeupAttrOf() outside of devices succeeded, want error")
+	}
+}
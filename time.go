@@ -0,0 +1,187 @@
+package evdev
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// Clock IDs accepted by SetClockID. They match the kernel's CLOCK_* values.
+const (
+	ClockRealtime  int32 = 0
+	ClockMonotonic int32 = 1
+	ClockBoottime  int32 = 7
+)
+
+// Timestamp returns the event's time as time.Time. The result is only
+// meaningful as wall-clock time if the device uses ClockRealtime.
+func (e *InputEvent) Timestamp() time.Time {
+	return time.Unix(0, e.Time.Nano())
+}
+
+// Since returns the time elapsed between prev and e.
+func (e *InputEvent) Since(prev *InputEvent) time.Duration {
+	return time.Duration(e.Time.Nano() - prev.Time.Nano())
+}
+
+// SetClockID sets the clock the kernel uses to timestamp events of this
+// device, e.g. ClockMonotonic.
+func (d *InputDevice) SetClockID(clockID int32) error {
+	err := ioctlEVIOCSCLOCKID(d.fd(), clockID)
+	if err != nil {
+		return fmt.Errorf("Cannot set clock ID: %v", err)
+	}
+
+	d.clockID = clockID
+
+	return nil
+}
+
+// ClockID returns the clock used to timestamp events of this device.
+func (d *InputDevice) ClockID() int32 {
+	return d.clockID
+}
+
+// Latency returns the time elapsed between the kernel timestamping e and
+// now, measured on the device's clock. For reliable results, switch the
+// device to ClockMonotonic first, as the realtime clock can jump.
+func (d *InputDevice) Latency(e *InputEvent) (time.Duration, error) {
+	return latencyOn(d.clockID, e)
+}
+
+// latencyOn returns the time elapsed since e was timestamped, measured on
+// the given clock.
+func latencyOn(clockID int32, e *InputEvent) (time.Duration, error) {
+	ts := syscall.Timespec{}
+
+	_, _, errno := syscall.Syscall(syscall.SYS_CLOCK_GETTIME, uintptr(clockID), uintptr(unsafe.Pointer(&ts)), 0)
+	if errno != 0 {
+		return 0, fmt.Errorf("Cannot read clock %d: %v", clockID, errno)
+	}
+
+	return time.Duration(ts.Nano() - e.Time.Nano()), nil
+}
+
+type latencySource struct {
+	src     EventSource
+	clockID int32
+	h       *LatencyHistogram
+}
+
+// NewLatencySource returns an EventSource that passes on the events of src
+// and records the latency of every SYN_REPORT in h, i.e. the time elapsed
+// between the kernel timestamping it and it being returned. Placed after a
+// transform chain, e.g. as the source of a Hub, it measures the end-to-end
+// latency of the pipeline. clockID must be the clock of the device the
+// events originate from, see SetClockID. h must not be used concurrently
+// with Read.
+func NewLatencySource(src EventSource, clockID int32, h *LatencyHistogram) EventSource {
+	return &latencySource{
+		src:     src,
+		clockID: clockID,
+		h:       h,
+	}
+}
+
+func (ls *latencySource) Read() ([]InputEvent, error) {
+	events, err := ls.src.Read()
+
+	for i := range events {
+		e := &events[i]
+
+		if e.Type != EV_SYN || e.Code != SYN_REPORT {
+			continue
+		}
+
+		if l, err := latencyOn(ls.clockID, e); err == nil {
+			ls.h.Add(l)
+		}
+	}
+
+	return events, err
+}
+
+// latencyReservoirSize is the number of samples a LatencyHistogram keeps to
+// compute percentiles.
+const latencyReservoirSize = 4096
+
+// LatencyHistogram collects durations into fixed buckets and computes
+// percentiles. Percentiles are exact for up to 4096 samples. Beyond that,
+// they are estimated from a uniformly chosen subset of 4096 samples, so
+// memory use stays constant during long measurements.
+type LatencyHistogram struct {
+	bounds  []time.Duration
+	counts  []uint64
+	count   int
+	samples []time.Duration
+}
+
+// NewLatencyHistogram creates a LatencyHistogram with the given upper bucket
+// bounds. Samples larger than the last bound are counted in an overflow bucket.
+func NewLatencyHistogram(bounds ...time.Duration) *LatencyHistogram {
+	b := append([]time.Duration{}, bounds...)
+	sort.Slice(b, func(i, j int) bool { return b[i] < b[j] })
+
+	return &LatencyHistogram{
+		bounds: b,
+		counts: make([]uint64, len(b)+1),
+	}
+}
+
+// Add records one sample.
+func (h *LatencyHistogram) Add(d time.Duration) {
+	i := sort.Search(len(h.bounds), func(i int) bool { return d <= h.bounds[i] })
+	h.counts[i]++
+	h.count++
+
+	if len(h.samples) < latencyReservoirSize {
+		h.samples = append(h.samples, d)
+		return
+	}
+
+	// reservoir sampling: keep each sample with equal probability
+	if j := rand.Intn(h.count); j < latencyReservoirSize {
+		h.samples[j] = d
+	}
+}
+
+// Count returns the number of recorded samples.
+func (h *LatencyHistogram) Count() int {
+	return h.count
+}
+
+// Buckets returns the upper bounds and the number of samples in each bucket.
+// The last count is the overflow bucket and has no bound.
+func (h *LatencyHistogram) Buckets() ([]time.Duration, []uint64) {
+	return h.bounds, h.counts
+}
+
+// Percentile returns the sample below which p percent of the samples fall.
+func (h *LatencyHistogram) Percentile(p float64) time.Duration {
+	if len(h.samples) == 0 {
+		return 0
+	}
+
+	s := append([]time.Duration{}, h.samples...)
+	sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+
+	i := int(p / 100 * float64(len(s)-1))
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(s) {
+		i = len(s) - 1
+	}
+
+	return s[i]
+}
+
+// Reset clears all recorded samples.
+func (h *LatencyHistogram) Reset() {
+	h.counts = make([]uint64, len(h.bounds)+1)
+	h.count = 0
+	h.samples = nil
+}
@@ -0,0 +1,87 @@
+package evdev
+
+import (
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestLatencyHistogram(t *testing.T) {
+	h := NewLatencyHistogram(time.Millisecond, 5*time.Millisecond)
+
+	for _, d := range []time.Duration{
+		500 * time.Microsecond,
+		time.Millisecond,
+		2 * time.Millisecond,
+		3 * time.Millisecond,
+		10 * time.Millisecond,
+	} {
+		h.Add(d)
+	}
+
+	_, counts := h.Buckets()
+	if want := []uint64{2, 2, 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("Buckets() = %v, want %v", counts, want)
+	}
+
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{p: 0, want: 500 * time.Microsecond},
+		{p: 50, want: 2 * time.Millisecond},
+		{p: 100, want: 10 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := h.Percentile(tt.p); got != tt.want {
+			t.Errorf("Percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestLatencyHistogram_Reservoir(t *testing.T) {
+	h := NewLatencyHistogram(time.Millisecond)
+
+	n := 3 * latencyReservoirSize
+	for i := 0; i < n; i++ {
+		h.Add(time.Duration(i%2) * 2 * time.Millisecond)
+	}
+
+	if got := h.Count(); got != n {
+		t.Errorf("Count() = %v, want %v", got, n)
+	}
+
+	if got := len(h.samples); got != latencyReservoirSize {
+		t.Errorf("kept %v samples, want %v", got, latencyReservoirSize)
+	}
+
+	_, counts := h.Buckets()
+	if want := []uint64{uint64(n / 2), uint64(n / 2)}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("Buckets() = %v, want %v", counts, want)
+	}
+
+	if got := h.Percentile(100); got != 2*time.Millisecond {
+		t.Errorf("Percentile(100) = %v, want %v", got, 2*time.Millisecond)
+	}
+}
+
+func TestLatencySource(t *testing.T) {
+	now := time.Now()
+	then := syscall.NsecToTimeval(now.Add(-time.Second).UnixNano())
+
+	h := NewLatencyHistogram(time.Millisecond)
+	src := NewLatencySource(&sliceSource{batches: [][]InputEvent{{
+		{Time: then, Type: EV_KEY, Code: KEY_A, Value: 1},
+		{Time: then, Type: EV_SYN, Code: SYN_REPORT},
+	}}}, ClockRealtime, h)
+
+	events, err := src.Read()
+	if err != nil || len(events) != 2 {
+		t.Fatalf("Read() = %v, %v", events, err)
+	}
+
+	if h.Count() != 1 || h.Percentile(50) < time.Second || h.Percentile(50) > time.Minute {
+		t.Errorf("recorded %d samples with median %v, want 1 sample of about 1s", h.Count(), h.Percentile(50))
+	}
+}
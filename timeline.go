@@ -0,0 +1,105 @@
+package evdev
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// TimelineSource is a capture to merge into a Timeline.
+type TimelineSource struct {
+	Label string // e.g. the file name, prefixed to the labels of its devices
+	R     io.Reader
+}
+
+// TimelineEvent is an event of a Timeline. Its Device field indexes the
+// timeline's devices.
+type TimelineEvent struct {
+	CaptureEvent
+	Label string // label of the device the event originates from
+}
+
+// Timeline is the time-ordered sequence of the events of several captures,
+// e.g. to analyze how the events of a keyboard and a mouse interleave.
+// Devices are labeled with their name, prefixed with the label of their
+// source if it has one.
+type Timeline struct {
+	Devices []CaptureDevice
+	Labels  []string
+	Events  []TimelineEvent
+}
+
+// NewTimeline reads all sources and merges their events by time. Events
+// with the same time keep the order of the sources.
+func NewTimeline(sources ...TimelineSource) (*Timeline, error) {
+	tl := &Timeline{}
+
+	for i, src := range sources {
+		cr, err := NewCaptureReader(src.R)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot read capture %d: %v", i, err)
+		}
+
+		events, err := cr.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("Cannot read capture %d: %v", i, err)
+		}
+
+		offset := len(tl.Devices)
+
+		for _, dev := range cr.Devices() {
+			label := dev.Name
+			if src.Label != "" {
+				label = src.Label + ":" + dev.Name
+			}
+
+			tl.Devices = append(tl.Devices, dev)
+			tl.Labels = append(tl.Labels, label)
+		}
+
+		for _, ce := range events {
+			ce.Device += offset
+			tl.Events = append(tl.Events, TimelineEvent{CaptureEvent: ce, Label: tl.Labels[ce.Device]})
+		}
+	}
+
+	sort.SliceStable(tl.Events, func(i, j int) bool {
+		return tl.Events[i].Time < tl.Events[j].Time
+	})
+
+	return tl, nil
+}
+
+// Between returns the events from time from up to, but not including, time
+// to, in nanoseconds.
+func (tl *Timeline) Between(from, to int64) []TimelineEvent {
+	start := sort.Search(len(tl.Events), func(i int) bool { return tl.Events[i].Time >= from })
+	end := sort.Search(len(tl.Events), func(i int) bool { return tl.Events[i].Time >= to })
+
+	if end < start {
+		end = start
+	}
+
+	return tl.Events[start:end]
+}
+
+// WriteText writes the timeline to w with one event per line, showing the
+// time in seconds relative to the first event, the device label, and the
+// event's type, code and value.
+func (tl *Timeline) WriteText(w io.Writer) error {
+	if len(tl.Events) == 0 {
+		return nil
+	}
+
+	start := tl.Events[0].Time
+
+	for _, e := range tl.Events {
+		_, err := fmt.Fprintf(w, "%12.6f %s %s %s %d\n", float64(e.Time-start)/1e9, e.Label,
+			TypeName(e.Type), CodeName(e.Type, e.Code), e.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package evdev
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestTimeline(t *testing.T) {
+	tl, err := NewTimeline(
+		TimelineSource{Label: "a.cap", R: writeTestCapture(t, CaptureDevice{Name: "kbd"}, 1000, 3000000)},
+		TimelineSource{R: writeTestCapture(t, CaptureDevice{Name: "mouse"}, 1000, 2000)},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"a.cap:kbd", "mouse"}; !reflect.DeepEqual(tl.Labels, want) {
+		t.Errorf("Labels = %v, want %v", tl.Labels, want)
+	}
+
+	labels := []string{}
+	devices := []int{}
+	for _, e := range tl.Events {
+		labels = append(labels, e.Label)
+		devices = append(devices, e.Device)
+	}
+
+	if want := []string{"a.cap:kbd", "mouse", "mouse", "a.cap:kbd"}; !reflect.DeepEqual(labels, want) {
+		t.Errorf("event labels = %v, want %v", labels, want)
+	}
+
+	if want := []int{0, 1, 1, 0}; !reflect.DeepEqual(devices, want) {
+		t.Errorf("event devices = %v, want %v", devices, want)
+	}
+
+	if got := tl.Between(1500, 3000000); len(got) != 1 || got[0].Time != 2000 {
+		t.Errorf("Between() = %v", got)
+	}
+
+	if got := tl.Between(5, 1); len(got) != 0 {
+		t.Errorf("Between() with an empty range = %v", got)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := tl.WriteText(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "    0.000000 a.cap:kbd EV_KEY KEY_A 1\n" +
+		"    0.000000 mouse EV_KEY KEY_A 1\n" +
+		"    0.000001 mouse EV_KEY KEY_A 1\n" +
+		"    0.002999 a.cap:kbd EV_KEY KEY_A 1\n"
+	if buf.String() != want {
+		t.Errorf("WriteText() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestTimeline_Malformed(t *testing.T) {
+	if _, err := NewTimeline(TimelineSource{R: bytes.NewBufferString("garbage!")}); err == nil {
+		t.Errorf("no error for a malformed capture")
+	}
+}
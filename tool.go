@@ -0,0 +1,142 @@
+package evdev
+
+// Tool is the tool a touch or tablet device reports to be in proximity.
+type Tool int
+
+// Tools reported through the BTN_TOOL_* codes
+const (
+	ToolNone Tool = iota
+	ToolFinger
+	ToolPen
+	ToolRubber
+	ToolBrush
+	ToolPencil
+	ToolAirbrush
+	ToolMouse
+	ToolLens
+)
+
+var toolNames = map[Tool]string{
+	ToolNone:     "none",
+	ToolFinger:   "finger",
+	ToolPen:      "pen",
+	ToolRubber:   "rubber",
+	ToolBrush:    "brush",
+	ToolPencil:   "pencil",
+	ToolAirbrush: "airbrush",
+	ToolMouse:    "mouse",
+	ToolLens:     "lens",
+}
+
+func (t Tool) String() string {
+	name, ok := toolNames[t]
+	if ok {
+		return name
+	}
+
+	return "UNKNOWN"
+}
+
+var toolCodes = map[EvCode]Tool{
+	BTN_TOOL_PEN:      ToolPen,
+	BTN_TOOL_RUBBER:   ToolRubber,
+	BTN_TOOL_BRUSH:    ToolBrush,
+	BTN_TOOL_PENCIL:   ToolPencil,
+	BTN_TOOL_AIRBRUSH: ToolAirbrush,
+	BTN_TOOL_MOUSE:    ToolMouse,
+	BTN_TOOL_LENS:     ToolLens,
+}
+
+var fingerToolCodes = map[EvCode]int{
+	BTN_TOOL_FINGER:    1,
+	BTN_TOOL_DOUBLETAP: 2,
+	BTN_TOOL_TRIPLETAP: 3,
+	BTN_TOOL_QUADTAP:   4,
+	BTN_TOOL_QUINTTAP:  5,
+}
+
+// ToolTracker follows the BTN_TOOL_* events of a device and keeps track of
+// the tool in proximity and the number of fingers on the surface. Drivers
+// switch between e.g. BTN_TOOL_FINGER and BTN_TOOL_DOUBLETAP by releasing one
+// and pressing the other within a frame, so the state is only updated at the
+// end of each frame.
+type ToolTracker struct {
+	held     map[EvCode]bool
+	tool     Tool
+	fingers  int
+	dropping bool
+}
+
+// NewToolTracker creates a ToolTracker.
+func NewToolTracker() *ToolTracker {
+	return &ToolTracker{
+		held: map[EvCode]bool{},
+	}
+}
+
+// CurrentTool returns the tool in proximity, or ToolNone.
+func (tt *ToolTracker) CurrentTool() Tool {
+	return tt.tool
+}
+
+// FingerCount returns the number of fingers on the surface as reported by
+// BTN_TOOL_FINGER to BTN_TOOL_QUINTTAP, or 0 if no finger is present.
+func (tt *ToolTracker) FingerCount() int {
+	return tt.fingers
+}
+
+// Push processes an event and returns true if it completed a frame that
+// changed the current tool or the finger count. After SYN_DROPPED, all
+// tools are considered released until they are reported again.
+func (tt *ToolTracker) Push(e InputEvent) bool {
+	switch {
+	case e.Type == EV_SYN && e.Code == SYN_DROPPED:
+		tt.held = map[EvCode]bool{}
+		tt.dropping = true
+
+	case e.Type == EV_SYN && e.Code == SYN_REPORT:
+		tt.dropping = false
+		return tt.update()
+
+	case e.Type == EV_KEY && !tt.dropping:
+		_, isTool := toolCodes[e.Code]
+		_, isFinger := fingerToolCodes[e.Code]
+
+		if isTool || isFinger {
+			tt.held[e.Code] = e.Value != 0
+		}
+	}
+
+	return false
+}
+
+func (tt *ToolTracker) update() bool {
+	tool := ToolNone
+	fingers := 0
+
+	for c, held := range tt.held {
+		if !held {
+			continue
+		}
+
+		if t, ok := toolCodes[c]; ok && (tool == ToolNone || tool == ToolFinger || t < tool) {
+			tool = t
+		}
+
+		if n, ok := fingerToolCodes[c]; ok {
+			if n > fingers {
+				fingers = n
+			}
+
+			if tool == ToolNone {
+				tool = ToolFinger
+			}
+		}
+	}
+
+	changed := tool != tt.tool || fingers != tt.fingers
+	tt.tool = tool
+	tt.fingers = fingers
+
+	return changed
+}
@@ -0,0 +1,81 @@
+package evdev
+
+import "testing"
+
+func TestToolTracker(t *testing.T) {
+	key := func(c EvCode, v int32) InputEvent {
+		return InputEvent{Type: EV_KEY, Code: c, Value: v}
+	}
+
+	tests := []struct {
+		name    string
+		events  []InputEvent
+		changed bool
+		tool    Tool
+		fingers int
+	}{
+		{
+			name:    "finger",
+			events:  []InputEvent{key(BTN_TOUCH, 1), key(BTN_TOOL_FINGER, 1)},
+			changed: true,
+			tool:    ToolFinger,
+			fingers: 1,
+		},
+		{
+			name:    "second finger",
+			events:  []InputEvent{key(BTN_TOOL_FINGER, 0), key(BTN_TOOL_DOUBLETAP, 1)},
+			changed: true,
+			tool:    ToolFinger,
+			fingers: 2,
+		},
+		{
+			name:    "unrelated key",
+			events:  []InputEvent{key(BTN_LEFT, 1)},
+			changed: false,
+			tool:    ToolFinger,
+			fingers: 2,
+		},
+		{
+			name:    "pen takes precedence",
+			events:  []InputEvent{key(BTN_TOOL_PEN, 1)},
+			changed: true,
+			tool:    ToolPen,
+			fingers: 2,
+		},
+		{
+			name:    "pen flipped to rubber",
+			events:  []InputEvent{key(BTN_TOOL_PEN, 0), key(BTN_TOOL_RUBBER, 1), key(BTN_TOOL_DOUBLETAP, 0)},
+			changed: true,
+			tool:    ToolRubber,
+			fingers: 0,
+		},
+		{
+			name:    "dropped",
+			events:  []InputEvent{{Type: EV_SYN, Code: SYN_DROPPED}, key(BTN_TOOL_FINGER, 1)},
+			changed: true,
+			tool:    ToolNone,
+			fingers: 0,
+		},
+	}
+
+	tt := NewToolTracker()
+
+	// the cases build on each other
+	for _, tc := range tests {
+		for _, e := range tc.events {
+			tt.Push(e)
+		}
+
+		if got := tt.Push(synReport); got != tc.changed {
+			t.Errorf("%s: Push() = %v, want %v", tc.name, got, tc.changed)
+		}
+
+		if got := tt.CurrentTool(); got != tc.tool {
+			t.Errorf("%s: CurrentTool() = %v, want %v", tc.name, got, tc.tool)
+		}
+
+		if got := tt.FingerCount(); got != tc.fingers {
+			t.Errorf("%s: FingerCount() = %v, want %v", tc.name, got, tc.fingers)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package evdev
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// RuleMatch records that a rule of a transform applied to an event.
+type RuleMatch struct {
+	Event InputEvent `json:"event"` // the event as it entered the transform
+	Rule  string     `json:"rule"`
+}
+
+// RuleTracer is implemented by rule based transforms, such as Script, that
+// can report which of their rules applied to a frame.
+type RuleTracer interface {
+	ProcessFrameTraced(frame []InputEvent) ([]InputEvent, []RuleMatch)
+}
+
+// TraceStep describes what one transform of a TracingChain did to a frame.
+type TraceStep struct {
+	Transform string       `json:"transform"`
+	Output    []InputEvent `json:"output"`
+	Rules     []RuleMatch  `json:"rules,omitempty"` // only for transforms implementing RuleTracer
+}
+
+// FrameTrace describes how a frame passed through a TracingChain.
+type FrameTrace struct {
+	Input  []InputEvent `json:"input"`
+	Steps  []TraceStep  `json:"steps"`
+	DryRun bool         `json:"dry_run,omitempty"`
+}
+
+// TracingChain is a chain of registered transforms that reports how each
+// frame passed through it, e.g. to debug why a remapping rule did not fire.
+// In dry-run mode, the frames are traced but not passed on.
+type TracingChain struct {
+	names  []string
+	ts     []Transform
+	trace  func(FrameTrace)
+	dryRun bool
+}
+
+// NewTracingChain creates the transforms described by specs, like
+// NewTransformChain, and calls trace for every frame processed.
+func NewTracingChain(specs []TransformSpec, trace func(FrameTrace)) (*TracingChain, error) {
+	tc := &TracingChain{
+		trace: trace,
+	}
+
+	for _, spec := range specs {
+		t, err := NewTransform(spec.Name, spec.Config)
+		if err != nil {
+			return nil, err
+		}
+
+		tc.names = append(tc.names, spec.Name)
+		tc.ts = append(tc.ts, t)
+	}
+
+	return tc, nil
+}
+
+// SetDryRun enables or disables dry-run mode, in which ProcessFrame returns
+// no events. It must not be called concurrently with ProcessFrame.
+func (tc *TracingChain) SetDryRun(dryRun bool) {
+	tc.dryRun = dryRun
+}
+
+// ProcessFrame passes the frame through all transforms and reports the trace.
+func (tc *TracingChain) ProcessFrame(frame []InputEvent) []InputEvent {
+	ft := FrameTrace{
+		Input:  append([]InputEvent{}, frame...),
+		Steps:  []TraceStep{},
+		DryRun: tc.dryRun,
+	}
+
+	for i, t := range tc.ts {
+		if len(frame) == 0 {
+			break
+		}
+
+		step := TraceStep{Transform: tc.names[i]}
+
+		if rt, ok := t.(RuleTracer); ok {
+			frame, step.Rules = rt.ProcessFrameTraced(frame)
+		} else {
+			frame = t.ProcessFrame(frame)
+		}
+
+		step.Output = append([]InputEvent{}, frame...)
+		ft.Steps = append(ft.Steps, step)
+	}
+
+	tc.trace(ft)
+
+	if tc.dryRun {
+		return nil
+	}
+
+	return frame
+}
+
+// TraceWriter returns a function for NewTracingChain that writes each trace
+// to w as a line of JSON. Write errors are ignored.
+func TraceWriter(w io.Writer) func(FrameTrace) {
+	enc := json.NewEncoder(w)
+
+	return func(ft FrameTrace) {
+		enc.Encode(ft)
+	}
+}
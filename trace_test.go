@@ -0,0 +1,68 @@
+package evdev
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestTracingChain(t *testing.T) {
+	traces := []FrameTrace{}
+
+	tc, err := NewTracingChain([]TransformSpec{
+		{Name: "script", Config: "code == KEY_CAPSLOCK -> code = KEY_ESC\ncode == KEY_ESC -> value = value"},
+		{Name: "test-drop-syn"},
+	}, func(ft FrameTrace) {
+		traces = append(traces, ft)
+	})
+	if err != nil {
+		t.Fatalf("NewTracingChain() error = %v", err)
+	}
+
+	caps := InputEvent{Type: EV_KEY, Code: KEY_CAPSLOCK, Value: 1}
+	esc := InputEvent{Type: EV_KEY, Code: KEY_ESC, Value: 1}
+	syn := InputEvent{Type: EV_SYN, Code: SYN_REPORT}
+
+	if got := tc.ProcessFrame([]InputEvent{caps, syn}); !reflect.DeepEqual(got, []InputEvent{esc}) {
+		t.Errorf("ProcessFrame() = %v, want %v", got, []InputEvent{esc})
+	}
+
+	tc.SetDryRun(true)
+
+	if got := tc.ProcessFrame([]InputEvent{caps, syn}); got != nil {
+		t.Errorf("ProcessFrame() in dry-run mode = %v, want nil", got)
+	}
+
+	want := FrameTrace{
+		Input: []InputEvent{caps, syn},
+		Steps: []TraceStep{
+			{
+				Transform: "script",
+				Output:    []InputEvent{esc, syn},
+				Rules: []RuleMatch{
+					{Event: caps, Rule: "line 1: code == KEY_CAPSLOCK -> code = KEY_ESC"},
+					{Event: caps, Rule: "line 2: code == KEY_ESC -> value = value"},
+				},
+			},
+			{Transform: "test-drop-syn", Output: []InputEvent{esc}},
+		},
+	}
+
+	if len(traces) != 2 || !reflect.DeepEqual(traces[0], want) {
+		t.Fatalf("traces = %+v, want %+v", traces, want)
+	}
+
+	want.DryRun = true
+	if !reflect.DeepEqual(traces[1], want) {
+		t.Errorf("dry-run trace = %+v, want %+v", traces[1], want)
+	}
+
+	buf := &bytes.Buffer{}
+	TraceWriter(buf)(want)
+
+	got := FrameTrace{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("TraceWriter() wrote %s, error %v", buf.Bytes(), err)
+	}
+}
@@ -0,0 +1,308 @@
+package evdev
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// trackpointRest is how long a trackpoint reports no motion before the stick
+// is considered released. Trackpoints report at about 100Hz while pushed.
+const trackpointRest = 30 * time.Millisecond
+
+// TrackpointConfig configures a Trackpoint transform.
+type TrackpointConfig struct {
+	Speed        float64 // gain applied to all motion, 1 if 0
+	Acceleration float64 // additional gain per count of motion in a frame
+
+	// Inertia adds this share of the change in motion since the previous
+	// frame, so the pointer starts and changes direction more crisply
+	// (negative inertia)
+	Inertia float64
+
+	// Motion of at most DriftMax counts per axis and frame that stays the
+	// same for DriftTime is taken as drift of a released stick and
+	// subtracted from all motion until the stick reports no motion for
+	// DriftTime. 0 disables it.
+	DriftTime time.Duration
+	DriftMax  int32
+
+	// A push of at least PressThreshold counts in a frame from rest that is
+	// released again within PressTime is turned into a click of BTN_LEFT
+	// instead of motion (press-to-select). 0 disables it.
+	PressThreshold int32
+	PressTime      time.Duration
+}
+
+// Trackpoint is a Transform adding the processing pointing sticks need on
+// top of their raw REL_X and REL_Y events: acceleration, negative inertia,
+// drift compensation and press-to-select. Clicks are produced by Tick, as
+// the release of a push is only known once the stick stopped reporting.
+//
+// The times of the frames must be on the same clock as the times passed to
+// Tick, as it is the case for devices using the default CLOCK_REALTIME.
+type Trackpoint struct {
+	cfg TrackpointConfig
+
+	last         time.Time // time of the last motion
+	prevX, prevY float64   // motion of the previous frame, for the inertia
+	restX, restY float64   // fractional motion not reported yet
+
+	// drift
+	runX, runY     int32
+	runStart       time.Time
+	biasX, biasY   int32
+	driftDetecting bool
+
+	// press-to-select
+	pressing       bool
+	pressStart     time.Time
+	pressX, pressY int32 // motion held back during a push
+}
+
+// NewTrackpoint creates a Trackpoint transform.
+func NewTrackpoint(cfg TrackpointConfig) *Trackpoint {
+	if cfg.Speed == 0 {
+		cfg.Speed = 1
+	}
+
+	return &Trackpoint{cfg: cfg}
+}
+
+// ProcessFrame implements Transform.
+func (tp *Trackpoint) ProcessFrame(frame []InputEvent) []InputEvent {
+	last := frame[len(frame)-1]
+
+	if last.Type == EV_SYN && last.Code == SYN_DROPPED {
+		tp.reset()
+		return frame
+	}
+
+	var dx, dy int32
+	out := make([]InputEvent, 0, len(frame))
+
+	for _, e := range frame {
+		switch {
+		case e.Type == EV_REL && e.Code == REL_X:
+			dx += e.Value
+		case e.Type == EV_REL && e.Code == REL_Y:
+			dy += e.Value
+		case e.Type != EV_SYN:
+			out = append(out, e)
+		}
+	}
+
+	if dx != 0 || dy != 0 {
+		dx, dy = tp.motion(dx, dy, last.Timestamp())
+	}
+
+	if dx != 0 {
+		out = append(out, InputEvent{Time: last.Time, Type: EV_REL, Code: REL_X, Value: dx})
+	}
+
+	if dy != 0 {
+		out = append(out, InputEvent{Time: last.Time, Type: EV_REL, Code: REL_Y, Value: dy})
+	}
+
+	if len(out) == 0 {
+		return nil
+	}
+
+	return append(out, last)
+}
+
+// MapCapabilities implements CapabilityMapper. Press-to-select adds
+// BTN_LEFT.
+func (tp *Trackpoint) MapCapabilities(in Capabilities) Capabilities {
+	if tp.cfg.PressThreshold > 0 {
+		return withCodes(in, EV_KEY, BTN_LEFT)
+	}
+
+	return in
+}
+
+// reset forgets the motion of the stick, e.g. after SYN_DROPPED.
+func (tp *Trackpoint) reset() {
+	cfg := tp.cfg
+	*tp = Trackpoint{cfg: cfg}
+}
+
+// motion processes the motion of a frame at now and returns the motion to
+// report.
+func (tp *Trackpoint) motion(dx, dy int32, now time.Time) (int32, int32) {
+	fromRest := tp.last.IsZero() || now.Sub(tp.last) >= trackpointRest
+	if fromRest {
+		tp.prevX, tp.prevY = 0, 0
+	}
+
+	if tp.cfg.DriftTime > 0 {
+		dx, dy = tp.compensateDrift(dx, dy, now)
+	}
+
+	tp.last = now
+
+	if tp.cfg.PressThreshold > 0 {
+		switch {
+		case tp.pressing && now.Sub(tp.pressStart) > tp.cfg.PressTime:
+			// held too long for a click, the push is motion after all
+			tp.pressing = false
+			dx += tp.pressX
+			dy += tp.pressY
+
+		case tp.pressing:
+			tp.pressX += dx
+			tp.pressY += dy
+			return 0, 0
+
+		case fromRest && (abs32(dx) >= tp.cfg.PressThreshold || abs32(dy) >= tp.cfg.PressThreshold):
+			tp.pressing = true
+			tp.pressStart = now
+			tp.pressX, tp.pressY = dx, dy
+			return 0, 0
+		}
+	}
+
+	return tp.shape(dx, dy)
+}
+
+// compensateDrift detects drift at now and subtracts it from the motion.
+func (tp *Trackpoint) compensateDrift(dx, dy int32, now time.Time) (int32, int32) {
+	if !tp.last.IsZero() && now.Sub(tp.last) >= tp.cfg.DriftTime {
+		// the stick rested, any drift is gone
+		tp.biasX, tp.biasY = 0, 0
+		tp.driftDetecting = false
+	}
+
+	small := abs32(dx) <= tp.cfg.DriftMax && abs32(dy) <= tp.cfg.DriftMax
+
+	switch {
+	case !small:
+		tp.driftDetecting = false
+	case !tp.driftDetecting || dx != tp.runX || dy != tp.runY:
+		tp.driftDetecting = true
+		tp.runX, tp.runY = dx, dy
+		tp.runStart = now
+	case now.Sub(tp.runStart) >= tp.cfg.DriftTime:
+		tp.biasX, tp.biasY = dx, dy
+	}
+
+	return dx - tp.biasX, dy - tp.biasY
+}
+
+// shape applies negative inertia and acceleration.
+func (tp *Trackpoint) shape(dx, dy int32) (int32, int32) {
+	x, y := float64(dx), float64(dy)
+
+	outX := x + tp.cfg.Inertia*(x-tp.prevX)
+	outY := y + tp.cfg.Inertia*(y-tp.prevY)
+	tp.prevX, tp.prevY = x, y
+
+	gain := tp.cfg.Speed * (1 + tp.cfg.Acceleration*math.Hypot(x, y))
+
+	tp.restX += outX * gain
+	tp.restY += outY * gain
+
+	rx, ry := math.Round(tp.restX), math.Round(tp.restY)
+	tp.restX -= rx
+	tp.restY -= ry
+
+	return int32(rx), int32(ry)
+}
+
+// Tick reports a click for a push that was released at now and returns
+// its frames, or nil. It also returns when Tick should be called next, or
+// the zero time if no push is pending.
+func (tp *Trackpoint) Tick(now time.Time) ([]InputEvent, time.Time) {
+	if !tp.pressing {
+		return nil, time.Time{}
+	}
+
+	released := tp.last.Add(trackpointRest)
+	if now.Before(released) {
+		return nil, released
+	}
+
+	tp.pressing = false
+	tp.pressX, tp.pressY = 0, 0
+
+	tv := syscall.NsecToTimeval(now.UnixNano())
+
+	return []InputEvent{
+		{Time: tv, Type: EV_KEY, Code: BTN_LEFT, Value: int32(KeyDown)},
+		{Time: tv, Type: EV_SYN, Code: SYN_REPORT},
+		{Time: tv, Type: EV_KEY, Code: BTN_LEFT, Value: int32(KeyUp)},
+		{Time: tv, Type: EV_SYN, Code: SYN_REPORT},
+	}, time.Time{}
+}
+
+func abs32(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+
+	return v
+}
+
+// ParseTrackpointConfig parses a trackpoint configuration of space
+// separated parameters, e.g.
+//
+//	speed=1.5 accel=0.05 inertia=0.5 drift=2s driftmax=2 press=8 presstime=200ms
+//
+// Drift compensation is disabled unless drift is given, and press-to-select
+// unless press is given. driftmax defaults to 2 and presstime to 200ms.
+func ParseTrackpointConfig(config string) (TrackpointConfig, error) {
+	cfg := TrackpointConfig{Speed: 1, DriftMax: 2, PressTime: 200 * time.Millisecond}
+
+	floats := map[string]*float64{"speed": &cfg.Speed, "accel": &cfg.Acceleration, "inertia": &cfg.Inertia}
+	ints := map[string]*int32{"driftmax": &cfg.DriftMax, "press": &cfg.PressThreshold}
+	durations := map[string]*time.Duration{"drift": &cfg.DriftTime, "presstime": &cfg.PressTime}
+
+	for _, param := range strings.Fields(config) {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) != 2 {
+			return cfg, fmt.Errorf("Unknown parameter %q for trackpoint", param)
+		}
+
+		var err error
+		negative := false
+
+		if p, ok := floats[kv[0]]; ok {
+			*p, err = strconv.ParseFloat(kv[1], 64)
+			negative = *p < 0
+		} else if p, ok := ints[kv[0]]; ok {
+			var v int64
+			v, err = strconv.ParseInt(kv[1], 10, 32)
+			*p = int32(v)
+			negative = v < 0
+		} else if p, ok := durations[kv[0]]; ok {
+			*p, err = time.ParseDuration(kv[1])
+			negative = *p < 0
+		} else {
+			return cfg, fmt.Errorf("Unknown parameter %q for trackpoint", param)
+		}
+
+		if err != nil || negative {
+			return cfg, fmt.Errorf("Invalid value for %s: %q", kv[0], kv[1])
+		}
+	}
+
+	if cfg.Speed == 0 {
+		return cfg, fmt.Errorf("Invalid value for speed: 0")
+	}
+
+	return cfg, nil
+}
+
+func init() {
+	RegisterTransform("trackpoint", func(config string) (Transform, error) {
+		cfg, err := ParseTrackpointConfig(config)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewTrackpoint(cfg), nil
+	})
+}
@@ -0,0 +1,136 @@
+package evdev
+
+import (
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestTrackpoint(t *testing.T) {
+	at := func(ms int64) syscall.Timeval { return syscall.NsecToTimeval(ms * int64(time.Millisecond)) }
+	motion := func(ms int64, dx, dy int32) []InputEvent {
+		frame := []InputEvent{}
+		if dx != 0 {
+			frame = append(frame, InputEvent{Time: at(ms), Type: EV_REL, Code: REL_X, Value: dx})
+		}
+		if dy != 0 {
+			frame = append(frame, InputEvent{Time: at(ms), Type: EV_REL, Code: REL_Y, Value: dy})
+		}
+
+		return append(frame, InputEvent{Time: at(ms), Type: EV_SYN, Code: SYN_REPORT})
+	}
+
+	tests := []struct {
+		name   string
+		config string
+		in     [][]InputEvent
+		want   [][]InputEvent
+	}{
+		{
+			name:   "inertia",
+			config: "inertia=0.5",
+			in:     [][]InputEvent{motion(0, 4, 0), motion(10, 4, 2), motion(20, 2, 2), motion(100, 2, 0)},
+			want:   [][]InputEvent{motion(0, 6, 0), motion(10, 4, 3), motion(20, 1, 2), motion(100, 3, 0)},
+		},
+		{
+			name:   "acceleration",
+			config: "speed=0.5 accel=0.25",
+			in:     [][]InputEvent{motion(0, 4, 0), motion(10, 1, 0), motion(20, 0, 8)},
+			want:   [][]InputEvent{motion(0, 4, 0), motion(10, 1, 0), motion(20, 0, 12)},
+		},
+		{
+			name:   "drift",
+			config: "drift=30ms",
+			in: [][]InputEvent{
+				motion(0, 1, 0), motion(10, 1, 0), motion(20, 1, 0), motion(30, 1, 0),
+				motion(40, 5, 0), motion(50, 1, 0),
+				// the stick rested, so the drift is gone
+				motion(100, 1, 0),
+			},
+			want: [][]InputEvent{
+				motion(0, 1, 0), motion(10, 1, 0), motion(20, 1, 0), nil,
+				motion(40, 4, 0), nil,
+				motion(100, 1, 0),
+			},
+		},
+		{
+			name:   "long push",
+			config: "press=8 presstime=20ms",
+			in:     [][]InputEvent{motion(0, 0, 10), motion(10, 0, 8), motion(20, 0, 8), motion(30, 0, 8), motion(40, 0, 1)},
+			want:   [][]InputEvent{nil, nil, nil, motion(30, 0, 34), motion(40, 0, 1)},
+		},
+	}
+
+	for _, tt := range tests {
+		cfg, err := ParseTrackpointConfig(tt.config)
+		if err != nil {
+			t.Fatalf("%s: ParseTrackpointConfig() failed: %v", tt.name, err)
+		}
+
+		tp := NewTrackpoint(cfg)
+
+		for i, frame := range tt.in {
+			if got := tp.ProcessFrame(frame); !reflect.DeepEqual(got, tt.want[i]) {
+				t.Errorf("%s: frame %d = %v, want %v", tt.name, i, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestTrackpointPressToSelect(t *testing.T) {
+	ms := func(ms int64) time.Time { return time.Unix(0, ms*int64(time.Millisecond)) }
+	frame := func(ms int64, dy int32) []InputEvent {
+		tv := syscall.NsecToTimeval(ms * int64(time.Millisecond))
+		return []InputEvent{{Time: tv, Type: EV_REL, Code: REL_Y, Value: dy}, {Time: tv, Type: EV_SYN, Code: SYN_REPORT}}
+	}
+
+	tp := NewTrackpoint(TrackpointConfig{PressThreshold: 8, PressTime: 200 * time.Millisecond})
+
+	// weak motion passes, the push after it is motion as well
+	if got := tp.ProcessFrame(frame(0, 2)); len(got) != 2 {
+		t.Errorf("ProcessFrame() of weak motion = %v, want it passed", got)
+	}
+
+	if got := tp.ProcessFrame(frame(10, 9)); len(got) != 2 {
+		t.Errorf("ProcessFrame() of a push while moving = %v, want it passed", got)
+	}
+
+	// a push from rest is held back
+	for _, f := range [][]InputEvent{frame(100, 10), frame(110, -3)} {
+		if got := tp.ProcessFrame(f); got != nil {
+			t.Errorf("ProcessFrame() of a push = %v, want nil", got)
+		}
+	}
+
+	if events, next := tp.Tick(ms(120)); events != nil || !next.Equal(ms(140)) {
+		t.Errorf("Tick() before the release = %v, %v, want nil, %v", events, next, ms(140))
+	}
+
+	events, next := tp.Tick(ms(140))
+	if len(events) != 4 || events[0].Code != BTN_LEFT || events[0].Value != 1 || events[2].Value != 0 || !next.IsZero() {
+		t.Errorf("Tick() after the release = %v, %v, want a click of BTN_LEFT", events, next)
+	}
+
+	if events, _ := tp.Tick(ms(200)); events != nil {
+		t.Errorf("Tick() after the click = %v, want nil", events)
+	}
+}
+
+func TestParseTrackpointConfig(t *testing.T) {
+	cfg, err := ParseTrackpointConfig("speed=1.5 accel=0.05 inertia=0.5 drift=2s driftmax=3 press=8 presstime=150ms")
+	want := TrackpointConfig{
+		Speed: 1.5, Acceleration: 0.05, Inertia: 0.5,
+		DriftTime: 2 * time.Second, DriftMax: 3,
+		PressThreshold: 8, PressTime: 150 * time.Millisecond,
+	}
+	if err != nil || cfg != want {
+		t.Errorf("ParseTrackpointConfig() = %+v, %v, want %+v", cfg, err, want)
+	}
+
+	for _, config := range []string{"speed", "speed=0", "inertia=-1", "drift=2", "press=x", "foo=1"} {
+		if _, err := ParseTrackpointConfig(config); err == nil {
+			t.Errorf("ParseTrackpointConfig(%q) succeeded", config)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package evdev
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Transform processes the event stream of a device frame by frame, e.g. to
+// remap keys or filter motion. A frame is passed as all events up to and
+// including a SYN_REPORT, or the single SYN_DROPPED event a FrameAssembler
+// reports after a buffer overrun. ProcessFrame returns the frame to pass on,
+// which may be empty, contain events of several frames or be the input slice
+// itself.
+type Transform interface {
+	ProcessFrame(frame []InputEvent) []InputEvent
+}
+
+// Ticker is implemented by transforms that emit events over time, such as
+// Turbo. Tick returns the events due by now and when it wants to be called
+// next, or the zero time if not before the next frame. Sources created with
+// NewTransformSource, and thus pipelines, call Tick after each read and at
+// the requested times.
+type Ticker interface {
+	Tick(now time.Time) ([]InputEvent, time.Time)
+}
+
+// TransformFunc adapts a function to the Transform interface.
+type TransformFunc func(frame []InputEvent) []InputEvent
+
+// ProcessFrame calls f(frame).
+func (f TransformFunc) ProcessFrame(frame []InputEvent) []InputEvent {
+	return f(frame)
+}
+
+type chain []Transform
+
+func (c chain) ProcessFrame(frame []InputEvent) []InputEvent {
+	for _, t := range c {
+		if len(frame) == 0 {
+			break
+		}
+
+		frame = t.ProcessFrame(frame)
+	}
+
+	return frame
+}
+
+// Tick implements Ticker for the transforms of the chain that implement it.
+// The events of their ticks pass through the transforms after them.
+func (c chain) Tick(now time.Time) ([]InputEvent, time.Time) {
+	out := []InputEvent{}
+	var next time.Time
+
+	for i, t := range c {
+		tk, ok := t.(Ticker)
+		if !ok {
+			continue
+		}
+
+		events, at := tk.Tick(now)
+		if len(events) > 0 {
+			out = append(out, chain(c[i+1:]).ProcessFrame(events)...)
+		}
+
+		if !at.IsZero() && (next.IsZero() || at.Before(next)) {
+			next = at
+		}
+	}
+
+	return out, next
+}
+
+// Chain returns a Transform that applies the given transforms in order.
+// Once a frame is empty, the remaining transforms are skipped.
+func Chain(ts ...Transform) Transform {
+	return chain(ts)
+}
+
+// TransformFactory creates a Transform from its configuration, whose format
+// is defined by the transform.
+type TransformFactory func(config string) (Transform, error)
+
+var (
+	transformsMu sync.RWMutex
+	transforms   = map[string]TransformFactory{
+		"script": func(config string) (Transform, error) {
+			return ParseScript(config)
+		},
+	}
+)
+
+// RegisterTransform makes a transform available by name, so it can be used
+// in a chain built with NewTransformChain. It is meant to be called from the
+// init function of the package implementing the transform, and panics if the
+// name is already registered.
+func RegisterTransform(name string, f TransformFactory) {
+	transformsMu.Lock()
+	defer transformsMu.Unlock()
+
+	if _, ok := transforms[name]; ok {
+		panic(fmt.Sprintf("Transform %q is already registered", name))
+	}
+
+	transforms[name] = f
+}
+
+// Transforms returns the names of all registered transforms, sorted.
+func Transforms() []string {
+	transformsMu.RLock()
+	defer transformsMu.RUnlock()
+
+	names := make([]string, 0, len(transforms))
+	for name := range transforms {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// NewTransform creates a registered transform with the given configuration.
+func NewTransform(name, config string) (Transform, error) {
+	transformsMu.RLock()
+	f, ok := transforms[name]
+	transformsMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("Unknown transform %q", name)
+	}
+
+	t, err := f(config)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot create transform %q: %v", name, err)
+	}
+
+	return t, nil
+}
+
+// TransformSpec names a registered transform and its configuration.
+type TransformSpec struct {
+	Name   string `json:"name"`
+	Config string `json:"config"`
+}
+
+// NewTransformChain creates the transforms described by specs and chains
+// them in order.
+func NewTransformChain(specs []TransformSpec) (Transform, error) {
+	ts := make([]Transform, 0, len(specs))
+
+	for _, spec := range specs {
+		t, err := NewTransform(spec.Name, spec.Config)
+		if err != nil {
+			return nil, err
+		}
+
+		ts = append(ts, t)
+	}
+
+	return Chain(ts...), nil
+}
+
+type sourceResult struct {
+	events []InputEvent
+	err    error
+}
+
+type transformSource struct {
+	src EventSource
+	t   Transform
+	fa  *FrameAssembler
+	err error
+
+	// with a Ticker, src is read in the background
+	ticker  Ticker
+	results chan sourceResult
+	reading bool
+	next    time.Time
+}
+
+// NewTransformSource returns an EventSource that reads events from src,
+// groups them into frames and passes them through t. It can be used as the
+// source of a Hub. When src fails, the pending events are passed through t
+// as a frame before the error is returned. If t implements Ticker, src is
+// read from a separate goroutine, so the events of the ticks can be
+// returned while a read is pending.
+func NewTransformSource(src EventSource, t Transform) EventSource {
+	ts := &transformSource{
+		src: src,
+		t:   t,
+		fa:  NewFrameAssembler(FramePassThrough),
+	}
+
+	if tk, ok := t.(Ticker); ok {
+		ts.ticker = tk
+		ts.results = make(chan sourceResult, 1)
+	}
+
+	return ts
+}
+
+// process passes the events read from src through the transform.
+func (ts *transformSource) process(events []InputEvent, err error) []InputEvent {
+	frames := [][]InputEvent{}
+	for _, e := range events {
+		frames = append(frames, ts.fa.Push(e)...)
+	}
+
+	if err != nil {
+		ts.err = err
+		frames = append(frames, ts.fa.Flush()...)
+	}
+
+	out := []InputEvent{}
+	for _, f := range frames {
+		out = append(out, ts.t.ProcessFrame(f)...)
+	}
+
+	return out
+}
+
+// tick calls the ticker and appends its events to out.
+func (ts *transformSource) tick(out []InputEvent) []InputEvent {
+	events, next := ts.ticker.Tick(time.Now())
+	ts.next = next
+
+	return append(out, events...)
+}
+
+func (ts *transformSource) Read() ([]InputEvent, error) {
+	for ts.err == nil {
+		if ts.ticker == nil {
+			if out := ts.process(ts.src.Read()); len(out) > 0 {
+				return out, nil
+			}
+
+			continue
+		}
+
+		if !ts.reading {
+			ts.reading = true
+
+			go func() {
+				events, err := ts.src.Read()
+				ts.results <- sourceResult{events, err}
+			}()
+		}
+
+		var timer <-chan time.Time
+		if !ts.next.IsZero() {
+			timer = time.After(time.Until(ts.next))
+		}
+
+		out := []InputEvent{}
+
+		select {
+		case r := <-ts.results:
+			ts.reading = false
+			out = ts.tick(ts.process(r.events, r.err))
+		case <-timer:
+			out = ts.tick(out)
+		}
+
+		if len(out) > 0 {
+			return out, nil
+		}
+	}
+
+	return nil, ts.err
+}
+
+// runTicking reads events from src, passes them through t frame by frame
+// and writes them with write. tick is called after each read and whenever
+// the time it returned last is reached, and its events are written as well.
+// It returns the error that ended reading or writing.
+func runTicking(src EventSource, t Transform, tick func(now time.Time) ([]InputEvent, time.Time), write func(events ...InputEvent) error) error {
+	type result struct {
+		events []InputEvent
+		err    error
+	}
+
+	// buffered, so the reader can finish a read after writing failed
+	results := make(chan result, 1)
+	next := make(chan struct{})
+
+	go func() {
+		for range next {
+			events, err := src.Read()
+			results <- result{events, err}
+
+			if err != nil {
+				return
+			}
+		}
+	}()
+	defer close(next)
+
+	next <- struct{}{}
+
+	fa := NewFrameAssembler(FramePassThrough)
+
+	var timer <-chan time.Time
+
+	for {
+		select {
+		case r := <-results:
+			for _, e := range r.events {
+				for _, f := range fa.Push(e) {
+					if err := write(t.ProcessFrame(f)...); err != nil {
+						return err
+					}
+				}
+			}
+
+			if r.err != nil {
+				return r.err
+			}
+
+			next <- struct{}{}
+
+		case <-timer:
+		}
+
+		events, at := tick(time.Now())
+		if err := write(events...); err != nil {
+			return err
+		}
+
+		timer = nil
+		if !at.IsZero() {
+			timer = time.After(time.Until(at))
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package evdev
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+func init() {
+	RegisterTransform("test-drop-syn", func(config string) (Transform, error) {
+		return TransformFunc(func(frame []InputEvent) []InputEvent {
+			out := []InputEvent{}
+			for _, e := range frame {
+				if e.Type != EV_SYN {
+					out = append(out, e)
+				}
+			}
+			return out
+		}), nil
+	})
+}
+
+func TestNewTransformChain(t *testing.T) {
+	c, err := NewTransformChain([]TransformSpec{
+		{Name: "script", Config: "code == KEY_A -> code = KEY_B"},
+		{Name: "script", Config: "code == KEY_B -> value = 2"},
+		{Name: "test-drop-syn"},
+	})
+	if err != nil {
+		t.Fatalf("NewTransformChain() error = %v", err)
+	}
+
+	src := NewTransformSource(&sliceSource{batches: [][]InputEvent{
+		{{Type: EV_KEY, Code: KEY_A, Value: 1}},
+		{{Type: EV_SYN, Code: SYN_REPORT}, {Type: EV_KEY, Code: KEY_C, Value: 1}},
+	}}, c)
+
+	want := [][]InputEvent{
+		{{Type: EV_KEY, Code: KEY_B, Value: 2}},
+		// the incomplete frame is flushed when the source ends
+		{{Type: EV_KEY, Code: KEY_C, Value: 1}},
+	}
+
+	for _, w := range want {
+		got, err := src.Read()
+		if err != nil || !reflect.DeepEqual(got, w) {
+			t.Errorf("Read() = %v, %v, want %v", got, err, w)
+		}
+	}
+
+	if _, err := src.Read(); err != io.EOF {
+		t.Errorf("Read() error = %v, want %v", err, io.EOF)
+	}
+
+	if _, err := NewTransformChain([]TransformSpec{{Name: "nope"}}); err == nil {
+		t.Error("NewTransformChain() with unknown transform succeeded")
+	}
+
+	if _, err := NewTransformChain([]TransformSpec{{Name: "script", Config: "->"}}); err == nil {
+		t.Error("NewTransformChain() with invalid script succeeded")
+	}
+}
@@ -0,0 +1,232 @@
+package evdev
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// TurboButton configures a button that is repeatedly pressed and released
+// while it is held, at Frequency pulses per second, staying pressed for the
+// fraction Duty of each pulse.
+type TurboButton struct {
+	Code      EvCode
+	Frequency float64
+	Duty      float64
+}
+
+// maxTurboFrequency is the highest frequency ParseTurboButtons accepts, so
+// turbo buttons can't keep a pipeline ticking continuously.
+const maxTurboFrequency = 500
+
+func (tb *TurboButton) durations() (time.Duration, time.Duration) {
+	period := float64(time.Second) / tb.Frequency
+	on := time.Duration(period * tb.Duty)
+
+	return on, time.Duration(period) - on
+}
+
+type turboState struct {
+	TurboButton
+	held    bool      // the button is held on the input device
+	pressed bool      // the button is pressed on the output
+	next    time.Time // when the output toggles next
+}
+
+// Turbo is a Transform that turns held buttons into a series of presses.
+// The first press is passed on immediately, further ones are produced by
+// Tick. If Tick is called late, missed pulses are skipped rather than
+// delivered in a burst. Repeats of turbo buttons are dropped.
+//
+// The times of the frames must be on the same clock as the times passed to
+// Tick, as it is the case for devices using the default CLOCK_REALTIME.
+type Turbo struct {
+	buttons map[EvCode]*turboState
+	order   []*turboState
+}
+
+// NewTurbo creates a Turbo transform for the given buttons.
+func NewTurbo(buttons ...TurboButton) *Turbo {
+	t := &Turbo{buttons: map[EvCode]*turboState{}}
+
+	for _, b := range buttons {
+		s := &turboState{TurboButton: b}
+		t.buttons[b.Code] = s
+		t.order = append(t.order, s)
+	}
+
+	return t
+}
+
+// ProcessFrame implements Transform.
+func (t *Turbo) ProcessFrame(frame []InputEvent) []InputEvent {
+	last := frame[len(frame)-1]
+
+	if last.Type == EV_SYN && last.Code == SYN_DROPPED {
+		// the held buttons are released on the output once Tick is called
+		for _, s := range t.buttons {
+			s.held = false
+		}
+
+		return frame
+	}
+
+	now := last.Timestamp()
+	out := make([]InputEvent, 0, len(frame))
+	consumed := false
+
+	for _, e := range frame {
+		s, ok := t.buttons[e.Code]
+		if e.Type != EV_KEY || !ok {
+			out = append(out, e)
+			continue
+		}
+
+		consumed = true
+
+		switch KeyState(e.Value) {
+		case KeyDown:
+			if s.held {
+				break
+			}
+
+			s.held = true
+			on, _ := s.durations()
+			s.next = now.Add(on)
+
+			if !s.pressed {
+				s.pressed = true
+				out = append(out, e)
+			}
+
+		case KeyUp:
+			s.held = false
+
+			if s.pressed {
+				s.pressed = false
+				out = append(out, e)
+			}
+		}
+	}
+
+	if consumed && len(out) == 1 {
+		return nil
+	}
+
+	return out
+}
+
+// Tick toggles the buttons whose pulses are due at now and returns a frame
+// with their events, or nil if none is due. It also returns when Tick
+// should be called next, or the zero time if no button is held.
+func (t *Turbo) Tick(now time.Time) ([]InputEvent, time.Time) {
+	events := []InputEvent{}
+	tv := syscall.NsecToTimeval(now.UnixNano())
+	var next time.Time
+
+	for _, s := range t.order {
+		if !s.held && s.pressed {
+			s.pressed = false
+			events = append(events, InputEvent{Time: tv, Type: EV_KEY, Code: s.Code, Value: int32(KeyUp)})
+		}
+
+		if !s.held {
+			continue
+		}
+
+		if !now.Before(s.next) {
+			s.pressed = !s.pressed
+
+			value := KeyUp
+			on, off := s.durations()
+			d := off
+			if s.pressed {
+				value = KeyDown
+				d = on
+			}
+
+			s.next = s.next.Add(d)
+			if s.next.Before(now) {
+				s.next = now.Add(d)
+			}
+
+			events = append(events, InputEvent{Time: tv, Type: EV_KEY, Code: s.Code, Value: int32(value)})
+		}
+
+		if next.IsZero() || s.next.Before(next) {
+			next = s.next
+		}
+	}
+
+	if len(events) == 0 {
+		return nil, next
+	}
+
+	return append(events, InputEvent{Time: tv, Type: EV_SYN, Code: SYN_REPORT}), next
+}
+
+// Run reads events from src, passes them through the transform frame by
+// frame and writes them with write, e.g. the Write method of a
+// UInputDevice, producing the pulses of held buttons in between. It returns
+// the error that ended reading or writing.
+func (t *Turbo) Run(src EventSource, write func(events ...InputEvent) error) error {
+	return runTicking(src, t, t.Tick, write)
+}
+
+// ParseTurboButtons parses turbo buttons separated by semicolons or
+// newlines, each the button, its frequency of up to 500Hz and optionally its
+// duty cycle, which defaults to 50%, e.g.
+//
+//	BTN_SOUTH 10Hz; BTN_EAST 15Hz 25%
+func ParseTurboButtons(config string) ([]TurboButton, error) {
+	buttons := []TurboButton{}
+
+	for _, line := range strings.FieldsFunc(config, func(r rune) bool { return r == ';' || r == '\n' }) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("Invalid turbo button %q", strings.TrimSpace(line))
+		}
+
+		c, ok := CodeByName(EV_KEY, fields[0])
+		if !ok {
+			return nil, fmt.Errorf("Unknown button %q", fields[0])
+		}
+
+		hz, err := strconv.ParseFloat(strings.TrimSuffix(fields[1], "Hz"), 64)
+		if err != nil || !(hz > 0 && hz <= maxTurboFrequency) || !strings.HasSuffix(fields[1], "Hz") {
+			return nil, fmt.Errorf("Invalid frequency %q", fields[1])
+		}
+
+		b := TurboButton{Code: c, Frequency: hz, Duty: 0.5}
+
+		if len(fields) == 3 {
+			duty, err := strconv.ParseFloat(strings.TrimSuffix(fields[2], "%"), 64)
+			if err != nil || !(duty > 0 && duty < 100) || !strings.HasSuffix(fields[2], "%") {
+				return nil, fmt.Errorf("Invalid duty cycle %q", fields[2])
+			}
+
+			b.Duty = duty / 100
+		}
+
+		buttons = append(buttons, b)
+	}
+
+	return buttons, nil
+}
+
+func init() {
+	RegisterTransform("turbo", func(config string) (Transform, error) {
+		buttons, err := ParseTurboButtons(config)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewTurbo(buttons...), nil
+	})
+}
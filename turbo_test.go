@@ -0,0 +1,120 @@
+package evdev
+
+import (
+	"io"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestTurbo(t *testing.T) {
+	at := func(ms int64) time.Time { return time.Unix(0, ms*int64(time.Millisecond)) }
+	key := func(ms int64, c EvCode, v int32) InputEvent {
+		return InputEvent{Time: syscall.NsecToTimeval(at(ms).UnixNano()), Type: EV_KEY, Code: c, Value: v}
+	}
+	syn := func(ms int64) InputEvent {
+		return InputEvent{Time: syscall.NsecToTimeval(at(ms).UnixNano()), Type: EV_SYN, Code: SYN_REPORT}
+	}
+
+	tr, err := NewTransform("turbo", "BTN_SOUTH 10Hz; BTN_EAST 5Hz 20%")
+	if err != nil {
+		t.Fatalf("NewTransform() error = %v", err)
+	}
+
+	turbo := tr.(*Turbo)
+
+	steps := []struct {
+		ms    int64
+		frame []InputEvent // passed to ProcessFrame if set, otherwise Tick is called
+		want  []InputEvent
+		next  int64 // -1 for none
+	}{
+		{ms: 0, frame: []InputEvent{key(0, BTN_SOUTH, 1), key(0, KEY_A, 1), syn(0)}, want: []InputEvent{key(0, BTN_SOUTH, 1), key(0, KEY_A, 1), syn(0)}},
+		{ms: 30, next: 50},
+		{ms: 50, want: []InputEvent{key(50, BTN_SOUTH, 0), syn(50)}, next: 100},
+		{ms: 60, frame: []InputEvent{key(60, BTN_SOUTH, 2), syn(60)}},
+		{ms: 100, want: []InputEvent{key(100, BTN_SOUTH, 1), syn(100)}, next: 150},
+		{ms: 120, frame: []InputEvent{key(120, BTN_SOUTH, 0), syn(120)}, want: []InputEvent{key(120, BTN_SOUTH, 0), syn(120)}},
+		{ms: 130, next: -1},
+
+		// 40ms pressed, 160ms released
+		{ms: 1000, frame: []InputEvent{key(1000, BTN_EAST, 1), syn(1000)}, want: []InputEvent{key(1000, BTN_EAST, 1), syn(1000)}},
+		{ms: 1500, want: []InputEvent{key(1500, BTN_EAST, 0), syn(1500)}, next: 1660},
+		{ms: 1660, want: []InputEvent{key(1660, BTN_EAST, 1), syn(1660)}, next: 1700},
+
+		// released on the output after SYN_DROPPED
+		{ms: 1670, frame: []InputEvent{{Type: EV_SYN, Code: SYN_DROPPED}}, want: []InputEvent{{Type: EV_SYN, Code: SYN_DROPPED}}},
+		{ms: 1680, want: []InputEvent{key(1680, BTN_EAST, 0), syn(1680)}, next: -1},
+	}
+
+	for _, s := range steps {
+		if s.frame != nil {
+			if got := turbo.ProcessFrame(s.frame); !reflect.DeepEqual(got, s.want) {
+				t.Errorf("%dms: ProcessFrame() = %v, want %v", s.ms, got, s.want)
+			}
+
+			continue
+		}
+
+		got, next := turbo.Tick(at(s.ms))
+		if !reflect.DeepEqual(got, s.want) {
+			t.Errorf("%dms: Tick() = %v, want %v", s.ms, got, s.want)
+		}
+
+		want := time.Time{}
+		if s.next >= 0 {
+			want = at(s.next)
+		}
+
+		if !next.Equal(want) {
+			t.Errorf("%dms: Tick() next = %v, want %v", s.ms, next, want)
+		}
+	}
+}
+
+func TestTurbo_Run(t *testing.T) {
+	now := syscall.NsecToTimeval(time.Now().UnixNano())
+	src := &delayedSource{
+		events: []InputEvent{{Time: now, Type: EV_KEY, Code: BTN_SOUTH, Value: 1}, {Time: now, Type: EV_SYN, Code: SYN_REPORT}},
+		delay:  120 * time.Millisecond,
+	}
+
+	presses := 0
+	err := NewTurbo(TurboButton{Code: BTN_SOUTH, Frequency: 50, Duty: 0.5}).Run(src, func(events ...InputEvent) error {
+		for _, e := range events {
+			if e.Type == EV_KEY && e.Code == BTN_SOUTH && e.Value == 1 {
+				presses++
+			}
+		}
+
+		return nil
+	})
+	if err != io.EOF {
+		t.Errorf("Run() error = %v, want %v", err, io.EOF)
+	}
+
+	// a press every 20ms
+	if presses < 3 || presses > 7 {
+		t.Errorf("got %d presses, want about 6", presses)
+	}
+}
+
+func TestParseTurboButtonsErrors(t *testing.T) {
+	for _, config := range []string{
+		"BTN_SOUTH",
+		"BTN_SOUTH 10",
+		"BTN_SOUTH 0Hz",
+		"BTN_SOUTH 10Hz 0%",
+		"BTN_SOUTH NaNHz",
+		"BTN_SOUTH +InfHz",
+		"BTN_SOUTH 1e300Hz",
+		"BTN_SOUTH 10Hz NaN%",
+		"BTN_SOUTH 10Hz 50",
+		"ABS_X 10Hz",
+	} {
+		if _, err := ParseTurboButtons(config); err == nil {
+			t.Errorf("ParseTurboButtons(%q) succeeded", config)
+		}
+	}
+}
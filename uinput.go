@@ -0,0 +1,382 @@
+package evdev
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+	"unsafe"
+)
+
+// uinputPath is the device node of the uinput driver.
+var uinputPath = "/dev/uinput"
+
+// devInputDir is where udev creates the event device nodes.
+var devInputDir = "/dev/input"
+
+// UInputBuilder describes a virtual input device to create through the
+// kernel's uinput driver, which requires write access to /dev/uinput.
+type UInputBuilder struct {
+	name         string
+	phys         string
+	id           InputID
+	codes        map[EvType]map[EvCode]bool
+	abs          map[EvCode]AbsInfo
+	props        map[EvProp]bool
+	ffEffectsMax uint32
+}
+
+// NewUInputBuilder creates a UInputBuilder for a device with the given name
+// and no capabilities.
+func NewUInputBuilder(name string) *UInputBuilder {
+	return &UInputBuilder{
+		name:  name,
+		codes: map[EvType]map[EvCode]bool{},
+		abs:   map[EvCode]AbsInfo{},
+		props: map[EvProp]bool{},
+	}
+}
+
+// NewUInputBuilderFrom creates a UInputBuilder for a device with the name
+// given and the ID, capabilities and axis ranges of d, e.g. to pass on the
+// events of a grabbed device after remapping them. Force feedback and key
+// repeat are left out, as the virtual device would have to implement them.
+func NewUInputBuilderFrom(d *InputDevice, name string) (*UInputBuilder, error) {
+	id, err := d.InputID()
+	if err != nil {
+		return nil, err
+	}
+
+	abs, err := d.AbsInfos()
+	if err != nil {
+		return nil, err
+	}
+
+	return cloneBuilder(name, id, d.Capabilities(), abs), nil
+}
+
+func cloneBuilder(name string, id InputID, caps Capabilities, abs map[EvCode]AbsInfo) *UInputBuilder {
+	b := NewUInputBuilder(name).WithID(id).WithProps(caps.Props...)
+
+	for t, codes := range caps.Codes {
+		switch t {
+		case EV_SYN, EV_FF, EV_REP:
+			continue
+		case EV_ABS:
+			for _, c := range codes {
+				b.WithAbs(c, abs[c])
+			}
+		default:
+			b.WithCodes(t, codes...)
+		}
+	}
+
+	return b
+}
+
+// WithID sets the bus, vendor, product and version of the device. It returns
+// b.
+func (b *UInputBuilder) WithID(id InputID) *UInputBuilder {
+	b.id = id
+	return b
+}
+
+// WithPhys sets the physical location of the device. It returns b.
+func (b *UInputBuilder) WithPhys(phys string) *UInputBuilder {
+	b.phys = phys
+	return b
+}
+
+// WithCodes adds the event type t and the given codes to the capabilities
+// of the device. Absolute axes are added with WithAbs instead. It returns b.
+func (b *UInputBuilder) WithCodes(t EvType, codes ...EvCode) *UInputBuilder {
+	if b.codes[t] == nil {
+		b.codes[t] = map[EvCode]bool{}
+	}
+
+	for _, c := range codes {
+		b.codes[t][c] = true
+	}
+
+	return b
+}
+
+// WithAbs adds an absolute axis with the given range. It returns b.
+func (b *UInputBuilder) WithAbs(code EvCode, info AbsInfo) *UInputBuilder {
+	b.WithCodes(EV_ABS, code)
+	b.abs[code] = info
+
+	return b
+}
+
+// WithProps adds device properties. It returns b.
+func (b *UInputBuilder) WithProps(props ...EvProp) *UInputBuilder {
+	for _, p := range props {
+		b.props[p] = true
+	}
+
+	return b
+}
+
+// Capabilities returns the event types and codes of the device, sorted.
+func (b *UInputBuilder) Capabilities() map[EvType][]EvCode {
+	caps := map[EvType][]EvCode{}
+
+	for t, codes := range b.codes {
+		caps[t] = make([]EvCode, 0, len(codes))
+		for c := range codes {
+			caps[t] = append(caps[t], c)
+		}
+
+		sort.Slice(caps[t], func(i, j int) bool { return caps[t][i] < caps[t][j] })
+	}
+
+	return caps
+}
+
+// AbsInfos returns the ranges of the absolute axes of the device.
+func (b *UInputBuilder) AbsInfos() map[EvCode]AbsInfo {
+	infos := map[EvCode]AbsInfo{}
+	for c, info := range b.abs {
+		infos[c] = info
+	}
+
+	return infos
+}
+
+// uinputSetupVersion is the first version of the uinput driver that supports
+// UI_DEV_SETUP and UI_ABS_SETUP.
+const uinputSetupVersion = 5
+
+// uinputBitIoctls are the ioctl numbers enabling the codes of each type.
+var uinputBitIoctls = map[EvType]int{
+	EV_KEY: 101,
+	EV_REL: 102,
+	EV_ABS: 103,
+	EV_MSC: 104,
+	EV_LED: 105,
+	EV_SND: 106,
+	EV_FF:  107,
+	EV_SW:  109,
+}
+
+// Create creates the virtual device. On kernels older than 4.5, whose
+// uinput driver lacks UI_DEV_SETUP, the device is set up the legacy way.
+func (b *UInputBuilder) Create() (*UInputDevice, error) {
+	f, err := os.OpenFile(uinputPath, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &UInputDevice{file: f}
+
+	if err := b.setup(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if err := ioctlUIDEVCREATE(f.Fd()); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("Cannot create uinput device: %v", err)
+	}
+
+	return u, nil
+}
+
+func (b *UInputBuilder) setup(f *os.File) error {
+	fd := f.Fd()
+	caps := b.Capabilities()
+
+	for t, codes := range caps {
+		if err := ioctlUISETBIT(fd, 100, int(t)); err != nil {
+			return fmt.Errorf("Cannot enable %s: %v", TypeName(t), err)
+		}
+
+		nr, ok := uinputBitIoctls[t]
+		if !ok {
+			continue
+		}
+
+		for _, c := range codes {
+			if err := ioctlUISETBIT(fd, nr, int(c)); err != nil {
+				return fmt.Errorf("Cannot enable code %d of %s: %v", c, TypeName(t), err)
+			}
+		}
+	}
+
+	for p := range b.props {
+		if err := ioctlUISETBIT(fd, 110, int(p)); err != nil {
+			return fmt.Errorf("Cannot set property %s: %v", PropName(p), err)
+		}
+	}
+
+	if b.phys != "" {
+		if err := ioctlUISETPHYS(fd, b.phys); err != nil {
+			return fmt.Errorf("Cannot set physical location: %v", err)
+		}
+	}
+
+	version, err := ioctlUIGETVERSION(fd)
+	if err != nil || version < uinputSetupVersion {
+		return b.setupLegacy(f)
+	}
+
+	setup := uinputSetup{
+		id:           b.id,
+		ffEffectsMax: b.ffEffectsMax,
+	}
+	copy(setup.name[:len(setup.name)-1], b.name)
+
+	if err := ioctlUIDEVSETUP(fd, &setup); err != nil {
+		return fmt.Errorf("Cannot set up uinput device: %v", err)
+	}
+
+	for c, info := range b.abs {
+		if err := ioctlUIABSSETUP(fd, &uinputAbsSetup{code: uint16(c), info: info}); err != nil {
+			return fmt.Errorf("Cannot set up %s: %v", CodeName(EV_ABS, c), err)
+		}
+	}
+
+	return nil
+}
+
+// setupLegacy sets up the device by writing a uinput_user_dev, which old
+// kernels expect. Axis resolutions are not supported this way.
+func (b *UInputBuilder) setupLegacy(f *os.File) error {
+	dev := b.userDev()
+
+	buf := (*[unsafe.Sizeof(uinputUserDev{})]byte)(unsafe.Pointer(&dev))
+	if _, err := f.Write(buf[:]); err != nil {
+		return fmt.Errorf("Cannot set up uinput device: %v", err)
+	}
+
+	return nil
+}
+
+func (b *UInputBuilder) userDev() uinputUserDev {
+	dev := uinputUserDev{
+		id:           b.id,
+		ffEffectsMax: b.ffEffectsMax,
+	}
+	copy(dev.name[:len(dev.name)-1], b.name)
+
+	for c, info := range b.abs {
+		if int(c) >= len(dev.absMax) {
+			continue
+		}
+
+		dev.absMax[c] = info.Maximum
+		dev.absMin[c] = info.Minimum
+		dev.absFuzz[c] = info.Fuzz
+		dev.absFlat[c] = info.Flat
+	}
+
+	return dev
+}
+
+// UInputDevice is a virtual input device created with a UInputBuilder.
+// Events written to it are delivered to the readers of its event device
+// node as if they came from hardware. The kernel timestamps them, so the
+// time of written events is ignored.
+type UInputDevice struct {
+	file *os.File
+}
+
+// Write writes events to the device. Readers only see them once a
+// SYN_REPORT has been written.
+func (u *UInputDevice) Write(events ...InputEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	if _, err := u.file.Write(eventBytes(events)); err != nil {
+		return fmt.Errorf("Cannot write to uinput device: %v", err)
+	}
+
+	return nil
+}
+
+// WriteFrame writes events followed by a SYN_REPORT.
+func (u *UInputDevice) WriteFrame(events ...InputEvent) error {
+	frame := make([]InputEvent, 0, len(events)+1)
+	frame = append(frame, events...)
+	frame = append(frame, InputEvent{Type: EV_SYN, Code: SYN_REPORT})
+
+	return u.Write(frame...)
+}
+
+type uinputSink struct {
+	u *UInputDevice
+}
+
+func (us uinputSink) Deliver(e InputEvent) error {
+	return us.u.Write(e)
+}
+
+func (us uinputSink) Close() error {
+	return us.u.Close()
+}
+
+// Sink returns a Sink writing events to the device, e.g. for a Pipeline.
+// Closing the sink destroys the device.
+func (u *UInputDevice) Sink() Sink {
+	return uinputSink{u: u}
+}
+
+// Close destroys the virtual device.
+func (u *UInputDevice) Close() error {
+	err := ioctlUIDEVDESTROY(u.file.Fd())
+	u.file.Close()
+
+	if err != nil {
+		return fmt.Errorf("Cannot destroy uinput device: %v", err)
+	}
+
+	return nil
+}
+
+// Sysname returns the name of the device in sysfs, e.g. "input17".
+func (u *UInputDevice) Sysname() (string, error) {
+	name, err := ioctlUIGETSYSNAME(u.file.Fd())
+	if err != nil {
+		return "", fmt.Errorf("Cannot get sysname of uinput device: %v", err)
+	}
+
+	return name, nil
+}
+
+// DevicePath returns the path of the event device node of the device, e.g.
+// /dev/input/event7. The node is created by udev shortly after the device,
+// so DevicePath waits up to timeout for it to appear.
+func (u *UInputDevice) DevicePath(timeout time.Duration) (string, error) {
+	sysname, err := u.Sysname()
+	if err != nil {
+		return "", err
+	}
+
+	return eventNodeOf(sysname, timeout)
+}
+
+// eventNodeOf polls for the event device node of the input device with the
+// given sysname.
+func eventNodeOf(sysname string, timeout time.Duration) (string, error) {
+	deadline := time.Now().Add(timeout)
+	pattern := filepath.Join(sysfsRoot, "devices", "virtual", "input", sysname, "event*")
+
+	for {
+		matches, _ := filepath.Glob(pattern)
+		for _, m := range matches {
+			node := filepath.Join(devInputDir, filepath.Base(m))
+			if _, err := os.Stat(node); err == nil {
+				return node, nil
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("Cannot find event device node of %s within %v", sysname, timeout)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,230 @@
+package evdev
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestUInputStructSizes(t *testing.T) {
+	if size := unsafe.Sizeof(uinputSetup{}); size != 92 {
+		t.Errorf("size of uinputSetup = %d, want 92", size)
+	}
+
+	if size := unsafe.Sizeof(uinputAbsSetup{}); size != 28 {
+		t.Errorf("size of uinputAbsSetup = %d, want 28", size)
+	}
+
+	if size := unsafe.Sizeof(uinputUserDev{}); size != 1116 {
+		t.Errorf("size of uinputUserDev = %d, want 1116", size)
+	}
+}
+
+func TestUInputBuilder_Gamepad(t *testing.T) {
+	tests := []struct {
+		preset GamepadPreset
+		id     InputID
+		keys   int
+		axes   []EvCode
+	}{
+		{
+			preset: GamepadXbox,
+			id:     InputID{BusType: BUS_USB, Vendor: 0x045e, Product: 0x028e, Version: 0x0110},
+			keys:   11,
+			axes:   []EvCode{ABS_X, ABS_Y, ABS_Z, ABS_RX, ABS_RY, ABS_RZ, ABS_HAT0X, ABS_HAT0Y},
+		},
+		{
+			preset: GamepadDS4,
+			id:     InputID{BusType: BUS_USB, Vendor: 0x054c, Product: 0x09cc, Version: 0x8111},
+			keys:   13,
+			axes:   []EvCode{ABS_X, ABS_Y, ABS_Z, ABS_RX, ABS_RY, ABS_RZ, ABS_HAT0X, ABS_HAT0Y},
+		},
+		{
+			preset: GamepadGeneric,
+			id:     InputID{BusType: BUS_VIRTUAL},
+			keys:   4,
+			axes:   []EvCode{ABS_X, ABS_Y},
+		},
+	}
+	for _, tt := range tests {
+		b := NewUInputBuilder("pad").Gamepad(tt.preset)
+		caps := b.Capabilities()
+
+		if b.id != tt.id {
+			t.Errorf("preset %d: ID = %+v, want %+v", tt.preset, b.id, tt.id)
+		}
+
+		if len(caps[EV_KEY]) != tt.keys {
+			t.Errorf("preset %d: %d keys, want %d", tt.preset, len(caps[EV_KEY]), tt.keys)
+		}
+
+		if !reflect.DeepEqual(caps[EV_ABS], tt.axes) {
+			t.Errorf("preset %d: axes %v, want %v", tt.preset, caps[EV_ABS], tt.axes)
+		}
+
+		if len(b.AbsInfos()) != len(tt.axes) {
+			t.Errorf("preset %d: %d axis ranges, want %d", tt.preset, len(b.AbsInfos()), len(tt.axes))
+		}
+	}
+
+	// an ID set before is kept
+	id := InputID{BusType: BUS_BLUETOOTH, Vendor: 1}
+	if b := NewUInputBuilder("pad").WithID(id).Gamepad(GamepadXbox); b.id != id {
+		t.Errorf("ID = %+v, want %+v", b.id, id)
+	}
+}
+
+func TestUInputBuilder_Presets(t *testing.T) {
+	kbd := NewUInputBuilder("kbd").Keyboard().Capabilities()
+	for _, c := range []EvCode{KEY_ESC, KEY_A, KEY_F24, KEY_VOLUMEUP, KEY_MICMUTE} {
+		if !hasCode(kbd[EV_KEY], c) {
+			t.Errorf("keyboard lacks %s", CodeName(EV_KEY, c))
+		}
+	}
+	if hasCode(kbd[EV_KEY], KEY_RESERVED) || hasCode(kbd[EV_KEY], BTN_LEFT) {
+		t.Errorf("keyboard has unexpected keys")
+	}
+	if _, ok := kbd[EV_REP]; !ok || len(kbd[EV_LED]) != 3 {
+		t.Errorf("keyboard capabilities = %v", kbd)
+	}
+
+	mouse := NewUInputBuilder("mouse").Mouse()
+	if caps := mouse.Capabilities(); len(caps[EV_KEY]) != 3 || !hasCode(caps[EV_REL], REL_WHEEL_HI_RES) {
+		t.Errorf("mouse capabilities = %v", caps)
+	}
+	if !mouse.props[PROP_POINTER] {
+		t.Errorf("mouse lacks PROP_POINTER")
+	}
+
+	touch := NewUInputBuilder("touch").Touchscreen(10, 1920, 1080, 12)
+	infos := touch.AbsInfos()
+	tests := []struct {
+		code EvCode
+		info AbsInfo
+	}{
+		{ABS_X, AbsInfo{Maximum: 1919, Resolution: 12}},
+		{ABS_Y, AbsInfo{Maximum: 1079, Resolution: 12}},
+		{ABS_MT_SLOT, AbsInfo{Maximum: 9}},
+		{ABS_MT_TRACKING_ID, AbsInfo{Maximum: 65535}},
+		{ABS_MT_POSITION_X, AbsInfo{Maximum: 1919, Resolution: 12}},
+		{ABS_MT_POSITION_Y, AbsInfo{Maximum: 1079, Resolution: 12}},
+	}
+	for _, tt := range tests {
+		if infos[tt.code] != tt.info {
+			t.Errorf("%s = %+v, want %+v", CodeName(EV_ABS, tt.code), infos[tt.code], tt.info)
+		}
+	}
+	if !touch.props[PROP_DIRECT] || touch.id.BusType != BUS_VIRTUAL {
+		t.Errorf("touchscreen props %v, ID %+v", touch.props, touch.id)
+	}
+}
+
+func hasCode(codes []EvCode, c EvCode) bool {
+	for _, code := range codes {
+		if code == c {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestEventNodeOf(t *testing.T) {
+	root, err := ioutil.TempDir("", "uinput")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	defer func(old string) { sysfsRoot = old }(sysfsRoot)
+	sysfsRoot = root
+
+	defer func(old string) { devInputDir = old }(devInputDir)
+	devInputDir = filepath.Join(root, "dev/input")
+
+	if _, err := eventNodeOf("input17", 20*time.Millisecond); err == nil {
+		t.Errorf("no error for a missing device")
+	}
+
+	// udev creates the node after the device appeared in sysfs
+	dir := devInputDir
+	go func() {
+		os.MkdirAll(filepath.Join(root, "devices/virtual/input/input17/event7"), 0755)
+		time.Sleep(30 * time.Millisecond)
+		os.MkdirAll(dir, 0755)
+		ioutil.WriteFile(filepath.Join(dir, "event7"), nil, 0644)
+	}()
+
+	node, err := eventNodeOf("input17", 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := filepath.Join(devInputDir, "event7"); node != want {
+		t.Errorf("node = %q, want %q", node, want)
+	}
+}
+
+func TestUInputBuilder_UserDev(t *testing.T) {
+	b := NewUInputBuilder("a very long name that does not fit into the eighty bytes the kernel has for it")
+	b.WithAbs(ABS_Y, AbsInfo{Minimum: -10, Maximum: 10, Fuzz: 1, Flat: 2, Resolution: 3})
+	b.WithID(InputID{BusType: BUS_USB, Vendor: 1, Product: 2})
+
+	dev := b.userDev()
+
+	if dev.name[79] != 0 || string(dev.name[:6]) != "a very" {
+		t.Errorf("name = %q", dev.name)
+	}
+
+	if dev.id != b.id {
+		t.Errorf("id = %+v, want %+v", dev.id, b.id)
+	}
+
+	if dev.absMin[ABS_Y] != -10 || dev.absMax[ABS_Y] != 10 || dev.absFuzz[ABS_Y] != 1 || dev.absFlat[ABS_Y] != 2 {
+		t.Errorf("ABS_Y = %d %d %d %d", dev.absMin[ABS_Y], dev.absMax[ABS_Y], dev.absFuzz[ABS_Y], dev.absFlat[ABS_Y])
+	}
+
+	if dev.absMax[ABS_X] != 0 {
+		t.Errorf("ABS_X max = %d, want 0", dev.absMax[ABS_X])
+	}
+}
+
+func TestCloneBuilder(t *testing.T) {
+	caps := Capabilities{
+		Codes: map[EvType][]EvCode{
+			EV_SYN: {SYN_REPORT},
+			EV_KEY: {BTN_TOUCH, BTN_TOOL_FINGER},
+			EV_ABS: {ABS_X, ABS_Y},
+			EV_REP: {REP_DELAY},
+			EV_FF:  {FF_RUMBLE},
+		},
+		Props: []EvProp{PROP_POINTER},
+	}
+	abs := map[EvCode]AbsInfo{
+		ABS_X: {Maximum: 1000, Resolution: 10},
+		ABS_Y: {Maximum: 500, Resolution: 10},
+	}
+
+	b := cloneBuilder("clone", InputID{BusType: BUS_I2C, Vendor: 0x1234}, caps, abs)
+
+	want := map[EvType][]EvCode{
+		EV_KEY: {BTN_TOOL_FINGER, BTN_TOUCH},
+		EV_ABS: {ABS_X, ABS_Y},
+	}
+
+	if got := b.Capabilities(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Capabilities() = %v, want %v", got, want)
+	}
+
+	if got := b.AbsInfos(); !reflect.DeepEqual(got, abs) {
+		t.Errorf("AbsInfos() = %v, want %v", got, abs)
+	}
+
+	if b.id.Vendor != 0x1234 || !b.props[PROP_POINTER] {
+		t.Errorf("clone lacks the ID or properties: %+v", b)
+	}
+}
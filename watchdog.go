@@ -0,0 +1,182 @@
+package evdev
+
+import (
+	"syscall"
+	"time"
+)
+
+// modifierKeys are the keys watched by a ModifierWatchdog.
+var modifierKeys = map[EvCode]bool{
+	KEY_LEFTCTRL:   true,
+	KEY_RIGHTCTRL:  true,
+	KEY_LEFTSHIFT:  true,
+	KEY_RIGHTSHIFT: true,
+	KEY_LEFTALT:    true,
+	KEY_RIGHTALT:   true,
+	KEY_LEFTMETA:   true,
+	KEY_RIGHTMETA:  true,
+}
+
+// ModifierWatchdog is a Transform that detects modifiers held for longer
+// than a timeout without any other input. That happens when the release of
+// a modifier was lost, e.g. over a flaky Bluetooth connection, which leaves
+// every following key press modified. Stuck modifiers are reported, and
+// released on the output if auto-release is enabled. The physical release
+// that may follow later is then dropped, as are the modifier's repeats.
+//
+// The times of the frames must be on the same clock as the times passed to
+// Tick, as it is the case for devices using the default CLOCK_REALTIME.
+type ModifierWatchdog struct {
+	timeout     time.Duration
+	autoRelease bool
+	report      func(code EvCode, held time.Duration)
+
+	held     map[EvCode]time.Time // held modifiers and when they were pressed
+	reported map[EvCode]bool      // held modifiers reported as stuck
+	released map[EvCode]bool      // modifiers released by the watchdog only
+	active   time.Time            // time of the last other input
+}
+
+// NewModifierWatchdog creates a ModifierWatchdog. report, if not nil, is
+// called from Tick with each stuck modifier and how long it was held.
+func NewModifierWatchdog(timeout time.Duration, autoRelease bool, report func(code EvCode, held time.Duration)) *ModifierWatchdog {
+	return &ModifierWatchdog{
+		timeout:     timeout,
+		autoRelease: autoRelease,
+		report:      report,
+		held:        map[EvCode]time.Time{},
+		reported:    map[EvCode]bool{},
+		released:    map[EvCode]bool{},
+	}
+}
+
+// ProcessFrame implements Transform.
+func (w *ModifierWatchdog) ProcessFrame(frame []InputEvent) []InputEvent {
+	last := frame[len(frame)-1]
+
+	if last.Type == EV_SYN && last.Code == SYN_DROPPED {
+		// the state of the keys is unknown until they are reported again
+		w.held = map[EvCode]time.Time{}
+		w.reported = map[EvCode]bool{}
+		w.released = map[EvCode]bool{}
+
+		return frame
+	}
+
+	now := last.Timestamp()
+	out := make([]InputEvent, 0, len(frame))
+	dropped := false
+
+	for _, e := range frame {
+		if e.Type == EV_SYN || e.Type == EV_MSC {
+			out = append(out, e)
+			continue
+		}
+
+		if e.Type != EV_KEY || !modifierKeys[e.Code] {
+			if e.Type != EV_KEY || KeyState(e.Value) != KeyRepeat {
+				w.active = now
+			}
+
+			out = append(out, e)
+
+			continue
+		}
+
+		switch KeyState(e.Value) {
+		case KeyDown:
+			w.held[e.Code] = now
+			w.active = now
+			delete(w.reported, e.Code)
+			delete(w.released, e.Code)
+
+		case KeyUp:
+			delete(w.held, e.Code)
+			delete(w.reported, e.Code)
+
+			if w.released[e.Code] {
+				delete(w.released, e.Code)
+				dropped = true
+
+				continue
+			}
+
+		case KeyRepeat:
+			if w.released[e.Code] {
+				dropped = true
+				continue
+			}
+		}
+
+		out = append(out, e)
+	}
+
+	if dropped && len(out) == 1 {
+		return nil
+	}
+
+	return out
+}
+
+// Tick reports the modifiers that got stuck by now and, with auto-release,
+// returns a frame releasing them, or nil. It also returns when Tick should
+// be called next, or the zero time if no modifier is held.
+func (w *ModifierWatchdog) Tick(now time.Time) ([]InputEvent, time.Time) {
+	events := []InputEvent{}
+	tv := syscall.NsecToTimeval(now.UnixNano())
+	var next time.Time
+
+	codes := []EvCode{}
+	for c := range w.held {
+		codes = append(codes, c)
+	}
+
+	for _, c := range sortCodes(codes) {
+		if w.reported[c] {
+			continue
+		}
+
+		since := w.held[c]
+
+		deadline := since
+		if w.active.After(deadline) {
+			deadline = w.active
+		}
+		deadline = deadline.Add(w.timeout)
+
+		if now.Before(deadline) {
+			if next.IsZero() || deadline.Before(next) {
+				next = deadline
+			}
+
+			continue
+		}
+
+		w.reported[c] = true
+
+		if w.report != nil {
+			w.report(c, now.Sub(since))
+		}
+
+		if w.autoRelease {
+			w.released[c] = true
+			delete(w.held, c)
+			delete(w.reported, c)
+			events = append(events, InputEvent{Time: tv, Type: EV_KEY, Code: c, Value: int32(KeyUp)})
+		}
+	}
+
+	if len(events) == 0 {
+		return nil, next
+	}
+
+	return append(events, InputEvent{Time: tv, Type: EV_SYN, Code: SYN_REPORT}), next
+}
+
+// Run reads events from src, passes them through the watchdog frame by
+// frame and writes them with write, e.g. the Write method of a
+// UInputDevice, releasing stuck modifiers in between. It returns the error
+// that ended reading or writing.
+func (w *ModifierWatchdog) Run(src EventSource, write func(events ...InputEvent) error) error {
+	return runTicking(src, w, w.Tick, write)
+}
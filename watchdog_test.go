@@ -0,0 +1,83 @@
+package evdev
+
+import (
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestModifierWatchdog(t *testing.T) {
+	at := func(ms int64) time.Time { return time.Unix(0, ms*int64(time.Millisecond)) }
+	key := func(ms int64, c EvCode, v int32) InputEvent {
+		return InputEvent{Time: syscall.NsecToTimeval(at(ms).UnixNano()), Type: EV_KEY, Code: c, Value: v}
+	}
+	syn := func(ms int64) InputEvent {
+		return InputEvent{Time: syscall.NsecToTimeval(at(ms).UnixNano()), Type: EV_SYN, Code: SYN_REPORT}
+	}
+
+	type report struct {
+		code EvCode
+		held time.Duration
+	}
+
+	reports := []report{}
+	w := NewModifierWatchdog(time.Second, true, func(code EvCode, held time.Duration) {
+		reports = append(reports, report{code: code, held: held})
+	})
+
+	steps := []struct {
+		ms    int64
+		frame []InputEvent // passed to ProcessFrame if set, otherwise Tick is called
+		want  []InputEvent
+		next  int64 // -1 for none
+	}{
+		{ms: 0, frame: []InputEvent{key(0, KEY_LEFTCTRL, 1), syn(0)}, want: []InputEvent{key(0, KEY_LEFTCTRL, 1), syn(0)}},
+		{ms: 500, next: 1000},
+
+		// other input restarts the timeout, repeats of the modifier don't
+		{ms: 800, frame: []InputEvent{key(800, KEY_C, 1), syn(800)}, want: []InputEvent{key(800, KEY_C, 1), syn(800)}},
+		{ms: 1000, next: 1800},
+		{ms: 1500, frame: []InputEvent{key(1500, KEY_LEFTCTRL, 2), syn(1500)}, want: []InputEvent{key(1500, KEY_LEFTCTRL, 2), syn(1500)}},
+		{ms: 1800, want: []InputEvent{key(1800, KEY_LEFTCTRL, 0), syn(1800)}, next: -1},
+
+		// repeats and the late release of the released modifier are dropped
+		{ms: 1900, frame: []InputEvent{key(1900, KEY_LEFTCTRL, 2), syn(1900)}},
+		{ms: 2000, frame: []InputEvent{key(2000, KEY_LEFTCTRL, 0), key(2000, KEY_C, 0), syn(2000)}, want: []InputEvent{key(2000, KEY_C, 0), syn(2000)}},
+
+		// the state is forgotten after SYN_DROPPED
+		{ms: 3000, frame: []InputEvent{key(3000, KEY_LEFTSHIFT, 1), syn(3000)}, want: []InputEvent{key(3000, KEY_LEFTSHIFT, 1), syn(3000)}},
+		{ms: 3500, next: 4000},
+		{ms: 3600, frame: []InputEvent{{Type: EV_SYN, Code: SYN_DROPPED}}, want: []InputEvent{{Type: EV_SYN, Code: SYN_DROPPED}}},
+		{ms: 5000, next: -1},
+	}
+
+	for _, s := range steps {
+		if s.frame != nil {
+			if got := w.ProcessFrame(s.frame); !reflect.DeepEqual(got, s.want) {
+				t.Errorf("%dms: ProcessFrame() = %v, want %v", s.ms, got, s.want)
+			}
+
+			continue
+		}
+
+		got, next := w.Tick(at(s.ms))
+		if !reflect.DeepEqual(got, s.want) {
+			t.Errorf("%dms: Tick() = %v, want %v", s.ms, got, s.want)
+		}
+
+		want := time.Time{}
+		if s.next >= 0 {
+			want = at(s.next)
+		}
+
+		if !next.Equal(want) {
+			t.Errorf("%dms: Tick() next = %v, want %v", s.ms, next, want)
+		}
+	}
+
+	wantReports := []report{{code: KEY_LEFTCTRL, held: 1800 * time.Millisecond}}
+	if !reflect.DeepEqual(reports, wantReports) {
+		t.Errorf("reports = %v, want %v", reports, wantReports)
+	}
+}